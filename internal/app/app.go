@@ -2,12 +2,19 @@ package app
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"excentrico-tools-go/internal/config"
@@ -15,6 +22,8 @@ import (
 	"excentrico-tools-go/internal/logger"
 	"excentrico-tools-go/internal/models"
 	"excentrico-tools-go/internal/services"
+	"excentrico-tools-go/internal/utils"
+	"excentrico-tools-go/internal/wordpress"
 
 	"github.com/goodsign/monday"
 )
@@ -29,29 +38,76 @@ type App struct {
 	tursoService        *services.TursoService
 	imageService        *services.ImageService
 	filmProcessor       *film.Processor
+	changedOnly         bool
+	forceUpdate         bool // Process films -changed-only would skip; see SetForceUpdate
+	section             string
+	concurrency         int             // Films processed at once; below 1 means one
+	dryRun              bool            // Skip every WordPress, Turso and sheet write; see SetDryRun
+	retryFilms          map[string]bool // Folded names of the only films to process; nil processes all
+	writeStatus         bool
+	skipPublished       bool
+	filmIDs             *film.IDs        // Film IDs of the last sheet read; see film.NewIDs
+	titleRows           map[string][]int // 1-based sheet rows of each title in the last sheet read
+	ctx                 context.Context  // Cancelled on interrupt; aborts in-flight requests
+	runCtx              context.Context  // Root context of the run; no new film starts once it is done
+	cancelRun           context.CancelFunc
 }
 
-// New creates a new application instance with all required services
-func New(cfg *config.Config) (*App, error) {
-	ctx := context.Background()
-
+// New creates a new application instance with all required services. Cancelling ctx
+// aborts the Google and WordPress requests in flight and stops the film loop
+func New(ctx context.Context, cfg *config.Config) (*App, error) {
 	// Initialize Google Sheets service
-	sheetsService, err := services.NewGoogleSheetsService(ctx, cfg.GoogleCredentialsPath)
+	sheetsService, err := services.NewGoogleSheetsService(ctx, cfg.GoogleCredentialsJSON)
 	if err != nil {
 		return nil, err
 	}
 
 	// Initialize Google Drive service
-	driveService, err := services.NewGoogleDriveService(ctx, cfg.GoogleCredentialsPath)
+	driveService, err := services.NewGoogleDriveService(ctx, cfg.GoogleCredentialsJSON)
 	if err != nil {
 		return nil, err
 	}
+	driveService.SetPageSize(cfg.DrivePageSize)
+	driveService.SetDriveID(cfg.DriveID)
+	driveService.SetListConcurrency(cfg.DriveListConcurrency)
 
 	// Initialize WordPress service
-	wordpressService := services.NewWordPressService(cfg.WordPressConfig)
+	wordpressService := services.NewWordPressService(ctx, cfg.WordPressConfig)
+	if err := wordpressService.VerifyPostType(); err != nil {
+		op := logger.Get().StartOperation("verify_post_type")
+		op.WithContext("post_type", cfg.WordPressConfig.PostType)
+		op.WithError(err)
+		op.Warn(&logger.WideEvent{
+			Message: fmt.Sprintf("Could not verify post type: %v", err),
+		})
+	}
+	if err := wordpressService.VerifyCategoryTaxonomy(); err != nil {
+		op := logger.Get().StartOperation("verify_category_taxonomy")
+		op.WithContext("category_taxonomy", cfg.WordPressConfig.CategoryTaxonomy)
+		op.WithError(err)
+		op.Warn(&logger.WideEvent{
+			Message: fmt.Sprintf("Could not verify category taxonomy: %v", err),
+		})
+	}
+	if err := wordpressService.VerifyTagTaxonomy(); err != nil {
+		op := logger.Get().StartOperation("verify_tag_taxonomy")
+		op.WithContext("tag_taxonomy", cfg.WordPressConfig.TagTaxonomy)
+		op.WithError(err)
+		op.Warn(&logger.WideEvent{
+			Message: fmt.Sprintf("Could not verify tag taxonomy: %v", err),
+		})
+	}
 
 	// Initialize Divi Template service
 	diviTemplateService := services.NewDiviTemplateService()
+	if cfg.FallbackImage.Enabled {
+		diviTemplateService.SetFallbackImage(&services.FallbackImage{
+			MediaID:    cfg.FallbackImage.MediaID,
+			Path:       cfg.FallbackImage.Path,
+			Background: cfg.FallbackImage.Background,
+			Gallery:    cfg.FallbackImage.Gallery,
+		})
+	}
 
 	// Initialize Turso service
 	tursoService, err := services.NewTursoService(cfg.TursoConfig)
@@ -60,11 +116,10 @@ func New(cfg *config.Config) (*App, error) {
 	}
 
 	// Initialize Image service
-	imageService := services.NewImageServiceWithConfig(
-		cfg.ImageConfig.MaxWidth,
-		cfg.ImageConfig.MaxHeight,
-		cfg.ImageConfig.Quality,
-	)
+	imageService := services.NewImageService(cfg.ImageConfig)
+	if err := imageService.CheckEncoders(); err != nil {
+		return nil, err
+	}
 
 	// Initialize Film processor
 	filmProcessor := film.NewProcessor(
@@ -74,6 +129,22 @@ func New(cfg *config.Config) (*App, error) {
 		diviTemplateService,
 		tursoService,
 	)
+	filmProcessor.SetImageFolderColumns(cfg.ImageFolderColumns)
+	filmProcessor.SetDriveIgnorePatterns(cfg.DriveIgnorePatterns)
+	filmProcessor.SetBlockIncompleteContent(cfg.BlockIncompleteContent)
+	filmProcessor.SetFilmRetries(cfg.FilmRetries)
+	filmProcessor.SetMissingFolderPolicy(cfg.MissingFolderPolicy)
+	filmProcessor.SetMediaNaming(wordpress.MediaNaming{
+		TitleTemplate: cfg.WordPressConfig.MediaTitleTemplate,
+		AltTemplate:   cfg.WordPressConfig.MediaAltTemplate,
+	})
+	filmProcessor.SetReplaceChangedMedia(cfg.WordPressConfig.ReplaceChangedMedia)
+	filmProcessor.SetProjectOptions(wordpress.ProjectOptions{
+		SlugTemplate:            cfg.WordPressConfig.SlugTemplate,
+		UniqueSlugs:             cfg.WordPressConfig.UniqueSlugs,
+		VerifyBuilder:           cfg.WordPressConfig.VerifyBuilder,
+		DetectMultipleDirectors: cfg.DetectMultipleDirectors,
+	})
 
 	return &App{
 		config:              cfg,
@@ -84,16 +155,257 @@ func New(cfg *config.Config) (*App, error) {
 		tursoService:        tursoService,
 		imageService:        imageService,
 		filmProcessor:       filmProcessor,
+		concurrency:         cfg.Concurrency,
+		ctx:                 ctx,
+		runCtx:              ctx,
 	}, nil
 }
 
 // Close cleans up resources
 func (a *App) Close() {
+	if a.cancelRun != nil {
+		a.cancelRun()
+	}
 	if a.tursoService != nil {
 		a.tursoService.Close()
 	}
 }
 
+// SetRefreshBackground makes template generation re-evaluate each film's
+// background image instead of reusing the stored choice
+func (a *App) SetRefreshBackground(refresh bool) {
+	a.diviTemplateService.SetRefreshBackground(refresh)
+}
+
+// SetImageURLsOnly makes the saved divi_template.json reference images by URL
+// instead of embedding them as base64
+func (a *App) SetImageURLsOnly(urlsOnly bool) {
+	a.diviTemplateService.SetImageURLsOnly(urlsOnly)
+}
+
+// SetTemplateOutput selects where generated templates go; see services.ParseTemplateOutput
+func (a *App) SetTemplateOutput(output string) {
+	a.diviTemplateService.SetTemplateOutput(output)
+}
+
+// SetTemplateJSON selects the layout of divi_template.json; see services.ParseTemplateJSON
+func (a *App) SetTemplateJSON(layout string) {
+	a.diviTemplateService.SetTemplateJSON(layout)
+}
+
+// SetLanguage selects the site language of generated templates and post metadata
+func (a *App) SetLanguage(lang string) {
+	a.diviTemplateService.SetLanguage(lang)
+}
+
+// SetChangedOnly restricts processing to films whose sheet row changed since
+// the last successful run, based on the row hash stored in Turso
+func (a *App) SetChangedOnly(changedOnly bool) {
+	a.changedOnly = changedOnly
+}
+
+// SetForceUpdate processes every selected film even when -changed-only finds its sheet
+// row unchanged, resending its post and rewriting divi_template.json. It is meant for
+// posts edited by hand in WordPress; with SetDryRun nothing is sent either way
+func (a *App) SetForceUpdate(forceUpdate bool) {
+	a.forceUpdate = forceUpdate
+}
+
+// SetDeadline bounds the whole run: once maxRuntime has elapsed no further film is
+// started, while the film in progress finishes. Zero or less runs without a limit
+func (a *App) SetDeadline(maxRuntime time.Duration) {
+	if maxRuntime <= 0 {
+		return
+	}
+	if a.cancelRun != nil {
+		a.cancelRun()
+	}
+	a.runCtx, a.cancelRun = context.WithTimeout(a.ctx, maxRuntime)
+}
+
+// Interrupted reports whether the run was cancelled, e.g. by Ctrl+C
+func (a *App) Interrupted() bool {
+	return errors.Is(a.ctx.Err(), context.Canceled)
+}
+
+// DeadlineExceeded reports whether the run deadline set by SetDeadline has passed
+func (a *App) DeadlineExceeded() bool {
+	return errors.Is(a.runCtx.Err(), context.DeadlineExceeded)
+}
+
+// SetDryRun runs the whole pipeline without writing anything but local files: media
+// uploads and post saves are logged and get synthetic negative IDs, Turso writes are
+// kept in memory for the rest of the run and statuses are not written to the sheet.
+// Drive files are still downloaded and divi_template.json is still written
+func (a *App) SetDryRun(dryRun bool) {
+	a.dryRun = dryRun
+	a.wordpressService.SetDryRun(dryRun)
+	a.tursoService.SetDryRun(dryRun)
+}
+
+// SetRedownload makes each processed film discard its local downloads and recorded
+// Drive metadata so every file is downloaded again
+func (a *App) SetRedownload(redownload bool) {
+	a.filmProcessor.SetRedownload(redownload)
+}
+
+// ReconcileMedia checks the WordPress media recorded in Turso for every film and
+// prunes the IDs that no longer exist, optionally re-uploading the local images
+func (a *App) ReconcileMedia(reupload bool) ([]*wordpress.ReconcileResult, error) {
+	filmIDs, err := a.tursoService.ListFilmIDs("wp_images")
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*wordpress.ReconcileResult
+	for _, filmID := range filmIDs {
+		optimizedDir := a.imageService.OptimizedDir(filepath.Join("films", filmID))
+		result, err := wordpress.ReconcileFilmMedia(a.wordpressService, a.tursoService, filmID, optimizedDir, reupload, a.mediaNaming())
+		if err != nil {
+			return results, fmt.Errorf("failed to reconcile media for %s: %v", filmID, err)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// mediaNaming returns the configured media title and alt text templates
+func (a *App) mediaNaming() wordpress.MediaNaming {
+	return wordpress.MediaNaming{
+		TitleTemplate: a.config.WordPressConfig.MediaTitleTemplate,
+		AltTemplate:   a.config.WordPressConfig.MediaAltTemplate,
+	}
+}
+
+// ResyncAltText re-derives and updates the alt text of the stored media of every film
+// matching the year and section filters. It returns the number of films and media updated
+func (a *App) ResyncAltText(year string) (int, int, error) {
+	objects, _, err := a.selectedFilms(year)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	filmCount := 0
+	mediaCount := 0
+	for _, obj := range objects {
+		filmData := wordpress.ConvertObjToFilmData(obj)
+		if filmData.TituloOriginal == "" {
+			continue
+		}
+		filmData.DetectMultipleDirectors = a.config.DetectMultipleDirectors
+		filmID := a.filmIDs.For(filmData.TituloOriginal, obj)
+		optimizedDir := a.imageService.OptimizedDir(filepath.Join("films", filmID))
+		updated, err := wordpress.ResyncAltText(a.wordpressService, a.tursoService, filmID, filmData.TituloOriginal, optimizedDir, filmData.DirectorNames(), a.mediaNaming())
+		if err != nil {
+			return filmCount, mediaCount, fmt.Errorf("failed to resync alt text for %s: %v", filmData.TituloOriginal, err)
+		}
+		filmCount++
+		mediaCount += updated
+	}
+	return filmCount, mediaCount, nil
+}
+
+// SetSection restricts processing to films whose SECCIÓN matches section,
+// compared case- and accent-insensitively; empty processes every section
+func (a *App) SetSection(section string) {
+	a.section = strings.TrimSpace(section)
+}
+
+// SetRetryFilms restricts processing to the films with the given names, compared case-
+// and accent-insensitively, e.g. those that failed in a previous run; empty processes all
+func (a *App) SetRetryFilms(names []string) {
+	a.retryFilms = nil
+	for _, name := range names {
+		if folded := utils.FoldAccents(strings.TrimSpace(name)); folded != "" {
+			if a.retryFilms == nil {
+				a.retryFilms = make(map[string]bool)
+			}
+			a.retryFilms[folded] = true
+		}
+	}
+}
+
+// SetWriteStatus makes a successful run record "Published <date>" in the film's
+// Published Status sheet column
+func (a *App) SetWriteStatus(writeStatus bool) {
+	a.writeStatus = writeStatus
+}
+
+// SetSkipPublished excludes rows whose Published Status already marks them as published
+func (a *App) SetSkipPublished(skipPublished bool) {
+	a.skipPublished = skipPublished
+}
+
+// isPublishedStatus reports whether a Published Status value starts with one of the
+// configured published values, compared case- and accent-insensitively
+func (a *App) isPublishedStatus(status string) bool {
+	folded := utils.FoldAccents(status)
+	if folded == "" {
+		return false
+	}
+	for _, value := range a.config.PublishedStatusValues {
+		if prefix := utils.FoldAccents(value); prefix != "" && strings.HasPrefix(folded, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// setFilmIDs resolves film IDs from every row of the sheet, for the app and the processor
+func (a *App) setFilmIDs(rows []map[string]any) {
+	a.filmIDs = film.NewIDs(rows)
+	a.filmProcessor.SetFilmIDs(a.filmIDs)
+}
+
+// matchesFilters reports whether a sheet row belongs to the year (when set), to the
+// section filter (when set) and to the films being retried (when set)
+func (a *App) matchesFilters(obj map[string]any, year string) bool {
+	if year != "" {
+		edicion, exists := obj["EDICIÓN"]
+		if !exists {
+			return false
+		}
+		edicionStr, _ := edicion.(string)
+		expectedEdicion := "Excéntrico " + year
+		if !strings.EqualFold(edicionStr, expectedEdicion) && utils.ExtractEditionYear(edicionStr) != year {
+			return false
+		}
+	}
+	if a.retryFilms != nil && !a.retryFilms[utils.FoldAccents(strings.TrimSpace(sheetFilmName(obj)))] {
+		return false
+	}
+	if a.section != "" {
+		seccion, _ := obj["SECCIÓN"].(string)
+		return matchesSection(seccion, a.section)
+	}
+	return true
+}
+
+// sheetFilmName returns the film name of a sheet row the way the film loop reads it
+func sheetFilmName(obj map[string]any) string {
+	if name, ok := obj["TÍTULO ORIGINAL"].(string); ok {
+		return name
+	}
+	name, _ := obj["Name"].(string)
+	return name
+}
+
+// matchesSection reports whether a SECCIÓN value matches the section filter, either
+// as a whole or as one of its comma-separated entries
+func matchesSection(seccion, section string) bool {
+	want := utils.FoldAccents(section)
+	if utils.FoldAccents(seccion) == want {
+		return true
+	}
+	for _, part := range services.ParseCategoryString(seccion) {
+		if utils.FoldAccents(part) == want {
+			return true
+		}
+	}
+	return false
+}
+
 // ListWordPressMenus fetches available WordPress navigation menus
 func (a *App) ListWordPressMenus() ([]*services.WordPressMenu, error) {
 	if a.wordpressService == nil {
@@ -102,6 +414,102 @@ func (a *App) ListWordPressMenus() ([]*services.WordPressMenu, error) {
 	return a.wordpressService.GetNavMenus()
 }
 
+// VerifyTemplateMenu checks the menu ID of a year's template against the site's navigation
+// menus and warns when it names none, since Divi then renders an empty menu. It only warns:
+// the menu list may be incomplete, and nothing is checked when it cannot be fetched
+func (a *App) VerifyTemplateMenu(year string, templateConfig *services.TemplateData) {
+	if templateConfig == nil || !templateConfig.Menu.Rendered() || a.wordpressService == nil {
+		return
+	}
+	op := logger.Get().StartOperation("verify_template_menu")
+	op.WithContext("year", year)
+	op.WithContext("menu_id", templateConfig.Menu.MenuId)
+
+	menus, err := a.wordpressService.GetNavMenus()
+	if err != nil || len(menus) == 0 {
+		if err != nil {
+			op.WithError(err)
+		}
+		op.Complete("WordPress menus unavailable; template menu not verified")
+		return
+	}
+	op.WithContext("menu_count", len(menus))
+
+	if err := checkMenuID(menus, templateConfig.Menu.MenuId); err != nil {
+		op.WithError(err)
+		op.KeepAlways()
+		op.Warn(&logger.WideEvent{
+			Message: fmt.Sprintf("Template menu of year %s: %v", year, err),
+		})
+		return
+	}
+	op.Complete(fmt.Sprintf("Template menu %s exists", templateConfig.Menu.MenuId))
+}
+
+// checkMenuID returns an error unless menuID is the ID of one of menus. A slug or name is
+// reported with the ID Divi expects in its place; an unknown value lists the valid IDs
+func checkMenuID(menus []*services.WordPressMenu, menuID string) error {
+	menuID = strings.TrimSpace(menuID)
+	var valid []string
+	for _, menu := range menus {
+		if strconv.Itoa(menu.ID) == menuID {
+			return nil
+		}
+		valid = append(valid, fmt.Sprintf("%d (%s)", menu.ID, menu.Slug))
+	}
+	for _, menu := range menus {
+		if strings.EqualFold(menu.Slug, menuID) || strings.EqualFold(menu.Name, menuID) {
+			return fmt.Errorf("menu_id %q is a menu slug or name; use its ID %d", menuID, menu.ID)
+		}
+	}
+	return fmt.Errorf("menu_id %q matches no WordPress menu; valid IDs: %s", menuID, strings.Join(valid, ", "))
+}
+
+// EditionYears returns the distinct years found in the sheet's EDICIÓN column, sorted ascending
+func (a *App) EditionYears() ([]string, error) {
+	if a.config.GoogleSheetID == "" {
+		return nil, fmt.Errorf("google sheet ID not configured")
+	}
+
+	data, err := a.sheetsService.ReadRange(a.config.GoogleSheetID, a.config.SheetRange)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 2 {
+		return []string{}, nil
+	}
+
+	edicionIndex := -1
+	for i, cell := range data[0] {
+		if header, ok := cell.(string); ok && header == "EDICIÓN" {
+			edicionIndex = i
+			break
+		}
+	}
+	if edicionIndex == -1 {
+		return nil, fmt.Errorf("sheet has no EDICIÓN column")
+	}
+
+	seen := make(map[string]bool)
+	years := make([]string, 0)
+	for _, row := range data[1:] {
+		if edicionIndex >= len(row) {
+			continue
+		}
+		edicion, ok := row[edicionIndex].(string)
+		if !ok {
+			continue
+		}
+		if year := utils.ExtractEditionYear(edicion); year != "" && !seen[year] {
+			seen[year] = true
+			years = append(years, year)
+		}
+	}
+
+	sort.Strings(years)
+	return years, nil
+}
+
 // ProcessFilms processes films from the Google Sheet with optional year filtering
 func (a *App) ProcessFilms(year string, templateConfig *services.TemplateData, metadata *models.Metadata) error {
 	l := logger.Get()
@@ -114,7 +522,18 @@ func (a *App) ProcessFilms(year string, templateConfig *services.TemplateData, m
 		log.Fatal("Google Sheet ID is not configured. Please add 'google_sheet_id' to your configuration.json file.")
 	}
 
-	data, err := a.sheetsService.ReadRange(a.config.GoogleSheetID, "TODO!A:ZZ")
+	// Wrong credentials would fail every film with the same 401; stop before the first one
+	if err := a.wordpressService.TestConnection(); err != nil {
+		if services.IsAuthError(err) {
+			op.Fail("WordPress authentication failed", err)
+			return services.ErrAuthenticationFailed
+		}
+		op.Warn(&logger.WideEvent{
+			Message: fmt.Sprintf("WordPress connection test failed: %v", err),
+		})
+	}
+
+	data, err := a.sheetsService.ReadRange(a.config.GoogleSheetID, a.config.SheetRange)
 	if err != nil {
 		op.Fail("Failed to read data from Google Sheet", err)
 		return err
@@ -148,13 +567,20 @@ func (a *App) ProcessFilms(year string, templateConfig *services.TemplateData, m
 	// Convert rows to objects and apply filtering
 	objects := make([]map[string]any, 0)
 	filteredObjects := make([]map[string]any, 0)
+	filteredRows := make([]int, 0) // 1-based sheet row of each filtered object
 	matchedCount := 0
 	excludedCount := 0
+	publishedCount := 0
+	var shortRows []map[string]any
 
 	for i := 1; i < len(data); i++ {
 		row := data[i]
 		obj := make(map[string]any)
 
+		if issue := checkRowShape(row, headers, i+1); issue != nil {
+			shortRows = append(shortRows, issue)
+		}
+
 		for j, header := range headers {
 			if j < len(row) && row[j] != nil {
 				obj[header] = row[j]
@@ -165,41 +591,55 @@ func (a *App) ProcessFilms(year string, templateConfig *services.TemplateData, m
 
 		objects = append(objects, obj)
 
-		// Apply year filter if specified
-		if year != "" {
-			if edicion, exists := obj["EDICIÓN"]; exists {
-				edicionStr := ""
-				if edicion != nil {
-					edicionStr = edicion.(string)
-				}
-
-				expectedEdicion := "Excéntrico " + year
-				if strings.EqualFold(edicionStr, expectedEdicion) {
-					filteredObjects = append(filteredObjects, obj)
-					matchedCount++
-				} else {
-					excludedCount++
-				}
-			} else {
-				excludedCount++
+		// Apply year and section filters if specified; a film must match both
+		matches := a.matchesFilters(obj, year)
+		if matches && a.skipPublished {
+			if status, _ := obj[publishedStatusHeader].(string); a.isPublishedStatus(status) {
+				publishedCount++
+				continue
 			}
-		} else {
+		}
+
+		if matches {
 			filteredObjects = append(filteredObjects, obj)
+			filteredRows = append(filteredRows, i+1)
 			matchedCount++
+		} else {
+			excludedCount++
 		}
 	}
 
+	a.setFilmIDs(objects)
+	a.titleRows = sheetRowsByTitle(data, headers)
+
+	if len(shortRows) > 0 {
+		shapeOp := l.StartOperation("check_sheet_rows")
+		shapeOp.WithContext("header_count", len(headers))
+		shapeOp.WithContext("short_row_count", len(shortRows))
+		shapeOp.WithContext("short_rows", shortRows)
+		shapeOp.Warn(&logger.WideEvent{
+			Message: fmt.Sprintf("%d sheet rows have fewer populated cells than headers", len(shortRows)),
+		})
+	}
+
+	op.WithContext("short_row_count", len(shortRows))
 	op.WithContext("total_objects", len(objects))
 	op.WithContext("filtered_objects", len(filteredObjects))
 	op.WithContext("matched_count", matchedCount)
 	op.WithContext("excluded_count", excludedCount)
+	if a.skipPublished {
+		op.WithContext("skipped_published_count", publishedCount)
+	}
 	
 	if year != "" {
 		op.WithContext("year_filter", year)
 	}
+	if a.section != "" {
+		op.WithContext("section_filter", a.section)
+	}
 
 	if len(filteredObjects) > 0 {
-		err := a.processFilteredObjects(filteredObjects, year, templateConfig, metadata)
+		err := a.processFilteredObjects(filteredObjects, filteredRows, headers, year, templateConfig, metadata)
 		if err != nil {
 			op.Fail("Failed to process filtered objects", err)
 			return err
@@ -213,7 +653,8 @@ func (a *App) ProcessFilms(year string, templateConfig *services.TemplateData, m
 }
 
 // processFilteredObjects processes the filtered film objects
-func (a *App) processFilteredObjects(filteredObjects []map[string]any, year string, templateConfig *services.TemplateData, metadata *models.Metadata) error {
+// sheetRows holds the 1-based sheet row of each object, used to write statuses back
+func (a *App) processFilteredObjects(filteredObjects []map[string]any, sheetRows []int, headers []string, year string, templateConfig *services.TemplateData, metadata *models.Metadata) error {
 	l := logger.Get()
 	op := l.StartOperation("process_filtered_objects")
 	op.WithContext("total_films", len(filteredObjects))
@@ -225,11 +666,49 @@ func (a *App) processFilteredObjects(filteredObjects []map[string]any, year stri
 		return err
 	}
 
-	var processedCount, successCount, errorCount int
+	var processedCount, successCount, errorCount, unchangedCount, statusWrittenCount, skippedNoFolderCount int
+	workers := max(a.concurrency, 1)
+	progress := newBatchProgress(year, len(filteredObjects), workers)
 
-	for _, obj := range filteredObjects {
-		processedCount++
+	statusColumn := -1
+	op.WithContext("dry_run", a.dryRun)
+	if a.writeStatus && !a.dryRun {
+		for j, header := range headers {
+			if header == publishedStatusHeader {
+				statusColumn = j
+				break
+			}
+		}
+		if statusColumn == -1 {
+			op.Warn(&logger.WideEvent{
+				Message: fmt.Sprintf("Sheet has no '%s' column; statuses will not be written", publishedStatusHeader),
+			})
+		}
+	}
 
+	postURLColumn := -1
+	if a.config.PostURLColumn != "" && !a.dryRun {
+		postURLColumn = slices.Index(headers, a.config.PostURLColumn)
+		if postURLColumn == -1 {
+			op.Warn(&logger.WideEvent{
+				Message: fmt.Sprintf("Sheet has no '%s' column; post URLs will not be written", a.config.PostURLColumn),
+			})
+		}
+	}
+	if postURLColumn != -1 {
+		a.filmProcessor.SetPermalinkWriter(func(filmData map[string]any, link string) error {
+			return a.writePostURL(filmData, link, postURLColumn)
+		})
+	} else {
+		a.filmProcessor.SetPermalinkWriter(nil)
+	}
+
+	// Counters, progress and the film locks are shared by the workers
+	var countsMu sync.Mutex
+	filmLocks := make(map[string]*sync.Mutex)
+	var authErr error
+
+	processFilm := func(idx int, obj map[string]any) {
 		filmName := "unnamed_film"
 		filmSeccion := "unnamed_section"
 		filmDirect := "unammed_directs"
@@ -245,37 +724,312 @@ func (a *App) processFilteredObjects(filteredObjects []map[string]any, year stri
 			filmDirect = name.(string)
 		}
 
-		filmID := strings.ToLower(strings.ReplaceAll(filmName, " ", "_"))
-		SaveMetadata(filmName, CreateMetadata(filmName, filmSeccion, filmDirect, metadata, year))
+		filmID := a.filmIDs.For(filmName, obj)
+
+		// Rows that still share a film ID share its directory; process them one at a time
+		countsMu.Lock()
+		processedCount++
+		filmIndex := processedCount
+		filmLock, ok := filmLocks[filmID]
+		if !ok {
+			filmLock = &sync.Mutex{}
+			filmLocks[filmID] = filmLock
+		}
+		countsMu.Unlock()
+		filmLock.Lock()
+		defer filmLock.Unlock()
+
+		// Row hashes are keyed like the rest of the film's Turso metadata
+		hashFilmID := filmID
+		if a.rowUnchanged(hashFilmID, obj) {
+			countsMu.Lock()
+			unchangedCount++
+			progress.skip()
+			countsMu.Unlock()
+			return
+		}
+
+		SaveMetadata(filmID, CreateMetadata(filmName, filmSeccion, filmDirect, metadata, year, a.diviTemplateService.TemplateLanguage(templateConfig)))
 
 		filmOp := l.StartOperation("process_single_film")
 		filmOp.WithFilm(filmID, filmName, year, filmSeccion)
-		filmOp.WithContext("film_index", processedCount)
+		filmOp.WithContext("film_index", filmIndex)
 		filmOp.WithContext("total_films", len(filteredObjects))
 
-		if err := a.filmProcessor.ProcessSingleFilm(obj, baseDir, year, filmName, templateConfig); err != nil {
+		filmStart := time.Now()
+		// Only an interrupt cuts the film in progress short; the run deadline lets it finish
+		err := a.filmProcessor.ProcessSingleFilm(a.ctx, obj, baseDir, year, filmName, templateConfig)
+		countsMu.Lock()
+		progress.record(time.Since(filmStart))
+		countsMu.Unlock()
+		if errors.Is(err, film.ErrNoDriveFolder) {
+			filmOp.Complete(fmt.Sprintf("Skipped film '%s': no Drive folder", filmName))
+			countsMu.Lock()
+			skippedNoFolderCount++
+			countsMu.Unlock()
+		} else if err != nil {
 			filmOp.Fail(fmt.Sprintf("Failed to process film '%s'", filmName), err)
+			countsMu.Lock()
 			errorCount++
+			if services.IsAuthError(err) && authErr == nil {
+				// Credentials were revoked mid-run; the remaining films would fail the same way
+				authErr = err
+			}
+			countsMu.Unlock()
 		} else {
 			filmOp.Complete(fmt.Sprintf("Successfully processed film '%s'", filmName))
+			countsMu.Lock()
 			successCount++
+			countsMu.Unlock()
+
+			if statusColumn != -1 {
+				written, err := a.writePublishedStatus(obj, sheetRows[idx], statusColumn)
+				if err != nil {
+					filmOp.Warn(&logger.WideEvent{
+						Message: fmt.Sprintf("Failed to write published status for '%s': %v", filmName, err),
+					})
+				} else if written {
+					countsMu.Lock()
+					statusWrittenCount++
+					countsMu.Unlock()
+				}
+			}
+
+			// Hash the row as it now reads in the sheet, with the status and post URL
+			// written back, so -changed-only skips it next run
+			if err := a.tursoService.SaveSheetRowHash(hashFilmID, rowHash(obj)); err != nil {
+				filmOp.Warn(&logger.WideEvent{
+					Message: fmt.Sprintf("Failed to save sheet row hash for '%s'", filmName),
+				})
+			}
 		}
 	}
 
-	op.WithCounts(processedCount, 0, 0, 0, 0, 0)
+	op.WithContext("concurrency", workers)
+	notStartedCount := 0
+	slots := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for idx, obj := range filteredObjects {
+		slots <- struct{}{}
+		countsMu.Lock()
+		stopped := authErr != nil
+		countsMu.Unlock()
+		if stopped || a.runCtx.Err() != nil {
+			// The run was interrupted, its deadline passed or authentication failed; leave the
+			// remaining films for the next run
+			<-slots
+			notStartedCount = len(filteredObjects) - idx
+			break
+		}
+		wg.Add(1)
+		go func(idx int, obj map[string]any) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			processFilm(idx, obj)
+		}(idx, obj)
+	}
+	wg.Wait()
+
+	if authErr != nil {
+		op.Fail("WordPress authentication failed", authErr)
+		return services.ErrAuthenticationFailed
+	}
+
+	op.WithCounts(processedCount, 0, 0, unchangedCount, 0, 0)
 	op.WithContext("success_count", successCount)
 	op.WithContext("error_count", errorCount)
-	op.Complete(fmt.Sprintf("Processing completed: %d total, %d successful, %d failed", processedCount, successCount, errorCount))
+	op.WithContext("changed_only", a.changedOnly)
+	op.WithContext("force_update", a.forceUpdate)
+	op.WithContext("unchanged_count", unchangedCount)
+	if skippedNoFolderCount > 0 {
+		op.WithContext("skipped_no_folder_count", skippedNoFolderCount)
+	}
+	if a.writeStatus {
+		op.WithContext("status_written_count", statusWrittenCount)
+	}
+	if a.Interrupted() {
+		op.WithContext("interrupted", true)
+		op.WithContext("interrupted_skipped_count", notStartedCount)
+		op.KeepAlways()
+	} else if notStartedCount > 0 {
+		op.WithContext("deadline_exceeded", true)
+		op.WithContext("deadline_skipped_count", notStartedCount)
+		op.KeepAlways()
+	}
+
+	// Quantify what content-hash dedup saved across the run
+	mediaTally := a.filmProcessor.MediaTally()
+	reusedMedia, uploadedMedia, bytesSaved := mediaTally.Totals()
+	op.WithContext("media_reused_count", reusedMedia)
+	op.WithContext("media_uploaded_count", uploadedMedia)
+	op.WithContext("media_bytes_saved", bytesSaved)
+
+	// Surface films whose director headshots could not be matched so editors can chase them
+	if missing := a.diviTemplateService.MissingDirectorImages(); len(missing) > 0 {
+		op.WithContext("films_missing_director_images", missing)
+		op.WithContext("films_missing_director_images_count", len(missing))
+	}
+
+	// Surface films lacking a synopsis or credits for quality control
+	if missing := a.filmProcessor.MissingContent(); len(missing) > 0 {
+		op.WithContext("films_missing_content", missing)
+		op.WithContext("films_missing_content_count", len(missing))
+	}
+
+	// List the exact Drive assets that failed to download so they can be retried
+	if failures := a.filmProcessor.DownloadFailures(); len(failures) > 0 {
+		failedFiles := make(map[string][]string, len(failures))
+		for filmID, filmFailures := range failures {
+			for _, failure := range filmFailures {
+				failedFiles[filmID] = append(failedFiles[filmID], fmt.Sprintf("%s (%s)", failure.Path(), failure.FileID))
+			}
+		}
+		op.WithContext("films_with_failed_downloads", failedFiles)
+		op.WithContext("films_with_failed_downloads_count", len(failedFiles))
+	}
+	if a.Interrupted() {
+		op.Complete(fmt.Sprintf("Run interrupted: %d total, %d successful, %d failed, %d unchanged, %d not started; %s", processedCount, successCount, errorCount, unchangedCount, notStartedCount, mediaTally))
+		return nil
+	}
+	if notStartedCount > 0 {
+		op.Complete(fmt.Sprintf("Run deadline exceeded: %d total, %d successful, %d failed, %d unchanged, %d not started; %s", processedCount, successCount, errorCount, unchangedCount, notStartedCount, mediaTally))
+		return nil
+	}
+	op.Complete(fmt.Sprintf("Processing completed: %d total, %d successful, %d failed, %d unchanged; %s", processedCount, successCount, errorCount, unchangedCount, mediaTally))
 
 	return nil
 }
 
-func CreateMetadata(movieName string, seccion string, direccion string , metadata *models.Metadata, year string) string {
+// publishedStatusHeader is the sheet column that records whether a film was published
+const publishedStatusHeader = "Published Status"
+
+// writePublishedStatus marks a film's sheet row as published with today's date.
+// Rows already marked as published keep their original date, so repeated runs are
+// no-ops. It reports whether the cell was written
+func (a *App) writePublishedStatus(obj map[string]any, sheetRow int, statusColumn int) (bool, error) {
+	current, _ := obj[publishedStatusHeader].(string)
+	if a.isPublishedStatus(current) {
+		return false, nil
+	}
+
+	status := "Published " + time.Now().Format("2006-01-02")
+	if err := a.sheetsService.WriteCell(a.config.GoogleSheetID, config.SheetTab(a.config.SheetRange), sheetRow, statusColumn, status); err != nil {
+		return false, err
+	}
+
+	obj[publishedStatusHeader] = status
+	return true, nil
+}
+
+// writePostURL records a project's permalink in the post URL column of the film's row,
+// found by its TÍTULO ORIGINAL. Rows already holding the link are not written again, and
+// titles shared by several rows are refused rather than guessed
+func (a *App) writePostURL(obj map[string]any, link string, column int) error {
+	if current, _ := obj[a.config.PostURLColumn].(string); strings.TrimSpace(current) == link {
+		return nil
+	}
+	title, _ := obj["TÍTULO ORIGINAL"].(string)
+	rows := a.titleRows[strings.TrimSpace(title)]
+	if len(rows) == 0 {
+		return fmt.Errorf("no sheet row has the title %q", title)
+	}
+	if len(rows) > 1 {
+		return fmt.Errorf("sheet rows %v share the title %q", rows, title)
+	}
+
+	if err := a.sheetsService.WriteCell(a.config.GoogleSheetID, config.SheetTab(a.config.SheetRange), rows[0], column, link); err != nil {
+		return err
+	}
+	obj[a.config.PostURLColumn] = link
+	return nil
+}
+
+// sheetRowsByTitle maps each TÍTULO ORIGINAL of the sheet data to the 1-based sheet rows
+// holding it
+func sheetRowsByTitle(data [][]interface{}, headers []string) map[string][]int {
+	column := slices.Index(headers, "TÍTULO ORIGINAL")
+	rows := make(map[string][]int)
+	if column == -1 {
+		return rows
+	}
+	for i := 1; i < len(data); i++ {
+		if column >= len(data[i]) {
+			continue
+		}
+		if title, ok := data[i][column].(string); ok && strings.TrimSpace(title) != "" {
+			rows[strings.TrimSpace(title)] = append(rows[strings.TrimSpace(title)], i+1)
+		}
+	}
+	return rows
+}
+
+// checkRowShape reports a data row that is shorter than the header row or holds nil
+// cells before its last value. It returns nil for well-formed rows; otherwise a
+// summary with the 1-based sheet row number, the title when present and the cell counts
+func checkRowShape(row []interface{}, headers []string, sheetRow int) map[string]any {
+	nilCells := 0
+	for j := 0; j < len(row) && j < len(headers); j++ {
+		if row[j] == nil {
+			nilCells++
+		}
+	}
+	if len(row) >= len(headers) && nilCells == 0 {
+		return nil
+	}
+
+	issue := map[string]any{
+		"sheet_row":      sheetRow,
+		"cell_count":     len(row),
+		"expected_cells": len(headers),
+		"nil_cells":      nilCells,
+	}
+	for j, header := range headers {
+		if header == "TÍTULO ORIGINAL" && j < len(row) {
+			if title, ok := row[j].(string); ok {
+				issue["title"] = title
+			}
+		}
+	}
+	return issue
+}
+
+// rowUnchanged reports whether -changed-only skips a film: its sheet row hashes the same
+// as after its last successful run. -force-update never skips
+func (a *App) rowUnchanged(filmID string, obj map[string]any) bool {
+	if !a.changedOnly || a.forceUpdate {
+		return false
+	}
+	var storedHash string
+	err := a.tursoService.GetSheetRowHash(filmID, &storedHash)
+	return err == nil && storedHash == rowHash(obj)
+}
+
+// rowHash returns a stable content hash of a sheet row
+func rowHash(obj map[string]any) string {
+	// json.Marshal sorts map keys, so equal rows always produce the same bytes
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateMetadata builds the film's metadata line in the site language (Spanish unless lang is "en")
+func CreateMetadata(movieName string, seccion string, direccion string , metadata *models.Metadata, year string, lang string) string {
 	section :=  strings.ToUpper(seccion);
+
+	programme := "Programación Excéntrico " + year
+	from, to := " del ", " al "
+	if lang == services.LanguageEnglish {
+		programme = "Excéntrico " + year + " Programme"
+		from, to = " from ", " to "
+	}
+	result := section + " " + year + " - " + movieName + " - " + strings.ToUpper(strings.Replace(direccion, "y", "&", -1)) + " - " + programme
 	
 	// Handle nil metadata
 	if metadata == nil {
-		return section + " " + year + " - " + movieName + " - " + strings.ToUpper(strings.Replace(direccion, "y", "&", -1)) + " - " + "Programación Excéntrico " + year
+		return result
 	}
 	
 	// Check if Cities and Dates arrays have elements
@@ -287,28 +1041,27 @@ func CreateMetadata(movieName string, seccion string, direccion string , metadat
 	dateFrom := ""
 	dateTo := ""
 	if len(metadata.Dates) > 0 && len(metadata.Dates[0]) > 0 {
-		dateFrom = parseDate(metadata.Dates[0][0])
+		dateFrom = parseDate(metadata.Dates[0][0], lang)
 		if len(metadata.Dates[0]) > 1 {
-			dateTo = parseDate(metadata.Dates[0][1])
+			dateTo = parseDate(metadata.Dates[0][1], lang)
 		}
 	}
 	
 	// Build the metadata string
-	result := section + " " + year + " - " + movieName + " - " + strings.ToUpper(strings.Replace(direccion, "y", "&", -1)) + " - " + "Programación Excéntrico " + year
 	if city != "" {
 		result += " " + city
 	}
 	if dateFrom != "" {
-		result += " del " + dateFrom
+		result += from + dateFrom
 		if dateTo != "" {
-			result += " al " + dateTo
+			result += to + dateTo
 		}
 	}
 	
 	return result
 }
 
-func parseDate(date string) string {
+func parseDate(date string, lang string) string {
 	dateParts := strings.Split(date, "-")
 	year, err := strconv.Atoi(dateParts[0])
 	if err != nil {
@@ -323,6 +1076,9 @@ func parseDate(date string) string {
 		fmt.Println("Could not parse day:", err)
 	}
 	formatedDay := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.Local)
+	if lang == services.LanguageEnglish {
+		return monday.Format(formatedDay, "Monday 02 January", monday.LocaleEnUS)
+	}
 	return monday.Format(formatedDay, "Monday 02 de January", monday.LocaleEsES)
 }
 
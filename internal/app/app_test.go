@@ -0,0 +1,550 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"excentrico-tools-go/internal/config"
+	"excentrico-tools-go/internal/film"
+	"excentrico-tools-go/internal/logger"
+	"excentrico-tools-go/internal/models"
+	"excentrico-tools-go/internal/services"
+)
+
+func TestRowUnchanged(t *testing.T) {
+	row := map[string]any{"TÍTULO ORIGINAL": "La película", "SECCIÓN": "Cortos"}
+	edited := map[string]any{"TÍTULO ORIGINAL": "La película", "SECCIÓN": "Largos"}
+
+	tests := []struct {
+		name        string
+		changedOnly bool
+		forceUpdate bool
+		dryRun      bool
+		stored      map[string]any // row hashed after the last successful run; nil when never run
+		want        bool
+	}{
+		{name: "changed-only skips an unchanged row", changedOnly: true, stored: row, want: true},
+		{name: "changed-only processes an edited row", changedOnly: true, stored: edited},
+		{name: "changed-only processes a new row", changedOnly: true},
+		{name: "without changed-only every row is processed", stored: row},
+		{name: "force-update processes an unchanged row", changedOnly: true, forceUpdate: true, stored: row},
+		{name: "force-update alone", forceUpdate: true, stored: row},
+		{name: "dry run skips an unchanged row", changedOnly: true, dryRun: true, stored: row, want: true},
+		{name: "dry run with force-update processes an unchanged row", changedOnly: true, forceUpdate: true, dryRun: true, stored: row},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			turso := newTestTurso(t)
+			if tt.stored != nil {
+				if err := turso.SaveSheetRowHash("la-pelicula", rowHash(tt.stored)); err != nil {
+					t.Fatalf("SaveSheetRowHash: %v", err)
+				}
+			}
+			turso.SetDryRun(tt.dryRun)
+			a := &App{tursoService: turso, dryRun: tt.dryRun}
+			a.SetChangedOnly(tt.changedOnly)
+			a.SetForceUpdate(tt.forceUpdate)
+
+			if got := a.rowUnchanged("la-pelicula", row); got != tt.want {
+				t.Errorf("rowUnchanged = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRowHash(t *testing.T) {
+	row := map[string]any{"TÍTULO ORIGINAL": "La película", "SECCIÓN": "Cortos", "DURACIÓN": "12"}
+	tests := []struct {
+		name string
+		row  map[string]any
+		same bool
+	}{
+		{name: "same cells built in another order", row: map[string]any{"DURACIÓN": "12", "SECCIÓN": "Cortos", "TÍTULO ORIGINAL": "La película"}, same: true},
+		{name: "edited cell", row: map[string]any{"TÍTULO ORIGINAL": "La película", "SECCIÓN": "Largos", "DURACIÓN": "12"}},
+		{name: "new column", row: map[string]any{"TÍTULO ORIGINAL": "La película", "SECCIÓN": "Cortos", "DURACIÓN": "12", "PUBLICADO": "SI"}},
+		{name: "cleared cell", row: map[string]any{"TÍTULO ORIGINAL": "La película", "SECCIÓN": "Cortos", "DURACIÓN": ""}},
+		{name: "trailing space", row: map[string]any{"TÍTULO ORIGINAL": "La película ", "SECCIÓN": "Cortos", "DURACIÓN": "12"}},
+	}
+	want := rowHash(row)
+	if want == "" {
+		t.Fatal("rowHash returned no hash")
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rowHash(tt.row); (got == want) != tt.same {
+				t.Errorf("rowHash = %s, original %s, want same: %v", got, want, tt.same)
+			}
+		})
+	}
+}
+
+func TestCheckRowShape(t *testing.T) {
+	headers := []string{"TÍTULO ORIGINAL", "SECCIÓN", "DURACIÓN", "PAÍS"}
+	tests := []struct {
+		name string
+		row  []interface{}
+		want map[string]any // nil for a well-formed row
+	}{
+		{name: "full row", row: []interface{}{"La película", "Cortos", "12", "España"}},
+		{name: "row longer than headers", row: []interface{}{"La película", "Cortos", "12", "España", "extra"}},
+		{name: "empty strings are populated cells", row: []interface{}{"La película", "", "", ""}},
+		{
+			name: "trailing cells missing",
+			row:  []interface{}{"La película", "Cortos"},
+			want: map[string]any{"sheet_row": 7, "cell_count": 2, "expected_cells": 4, "nil_cells": 0, "title": "La película"},
+		},
+		{
+			name: "nil cell mid-row",
+			row:  []interface{}{"La película", nil, "12", "España"},
+			want: map[string]any{"sheet_row": 7, "cell_count": 4, "expected_cells": 4, "nil_cells": 1, "title": "La película"},
+		},
+		{
+			name: "empty row",
+			row:  []interface{}{},
+			want: map[string]any{"sheet_row": 7, "cell_count": 0, "expected_cells": 4, "nil_cells": 0},
+		},
+		{
+			name: "nil title",
+			row:  []interface{}{nil, "Cortos"},
+			want: map[string]any{"sheet_row": 7, "cell_count": 2, "expected_cells": 4, "nil_cells": 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkRowShape(tt.row, headers, 7); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("checkRowShape = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesFilters(t *testing.T) {
+	row := func(edicion, seccion string) map[string]any {
+		return map[string]any{"TÍTULO ORIGINAL": "La película", "EDICIÓN": edicion, "SECCIÓN": seccion}
+	}
+	tests := []struct {
+		name    string
+		year    string
+		section string
+		retry   []string // films being retried
+		row     map[string]any
+		want    bool
+	}{
+		{name: "no filters", row: row("Excéntrico 2024", "Cortos"), want: true},
+		{name: "year only, matching", year: "2025", row: row("Excéntrico 2025", "Cortos"), want: true},
+		{name: "year only, unaccented edition", year: "2025", row: row("EXCENTRICO 2025", "Cortos"), want: true},
+		{name: "year only, other edition", year: "2025", row: row("Excéntrico 2024", "Cortos")},
+		{name: "year only, no edition", year: "2025", row: map[string]any{"SECCIÓN": "Cortos"}},
+		{name: "section only, matching", section: "Cortos", row: row("Excéntrico 2024", "Cortos"), want: true},
+		{name: "section only, accents and case ignored", section: "seccion oficial", row: row("Excéntrico 2024", "SECCIÓN OFICIAL"), want: true},
+		{name: "section only, one of several", section: "Animación", row: row("Excéntrico 2024", "Cortos, Animacion"), want: true},
+		{name: "section only, other section", section: "Largos", row: row("Excéntrico 2024", "Cortos")},
+		{name: "section only, partial name", section: "Corto", row: row("Excéntrico 2024", "Cortos")},
+		{name: "combined, both match", year: "2025", section: "cortos", row: row("Excéntrico 2025", "Cortos"), want: true},
+		{name: "combined, year differs", year: "2025", section: "Cortos", row: row("Excéntrico 2024", "Cortos")},
+		{name: "combined, section differs", year: "2025", section: "Largos", row: row("Excéntrico 2025", "Cortos")},
+		{name: "retried film", retry: []string{"El faro", "La película"}, row: row("Excéntrico 2024", "Cortos"), want: true},
+		{name: "retried film, accents and case ignored", retry: []string{" LA PELICULA "}, row: row("Excéntrico 2024", "Cortos"), want: true},
+		{name: "film not retried", retry: []string{"El faro"}, row: row("Excéntrico 2024", "Cortos")},
+		{name: "blank retry list", retry: []string{" "}, row: row("Excéntrico 2024", "Cortos"), want: true},
+		{name: "retried film, other edition", year: "2025", retry: []string{"La película"}, row: row("Excéntrico 2024", "Cortos")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &App{}
+			a.SetSection(tt.section)
+			a.SetRetryFilms(tt.retry)
+			if got := a.matchesFilters(tt.row, tt.year); got != tt.want {
+				t.Errorf("matchesFilters = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWritePublishedStatus(t *testing.T) {
+	today := "Published " + time.Now().Format("2006-01-02")
+	tests := []struct {
+		name        string
+		sheetRange  string
+		status      string // current Published Status of the row
+		failWrite   bool
+		wantWritten bool
+		wantCell    string // range written; empty when nothing is written
+		wantStatus  string // Published Status of the row afterwards
+		wantErr     bool
+	}{
+		{name: "blank status", wantWritten: true, wantCell: "TODO!C5", wantStatus: today},
+		{name: "pending status", status: "Pendiente", wantWritten: true, wantCell: "TODO!C5", wantStatus: today},
+		{name: "configured tab", sheetRange: "Películas 2025!A:ZZ", wantWritten: true, wantCell: "Películas 2025!C5", wantStatus: today},
+		{name: "already published keeps its date", status: "Published 2025-03-01", wantStatus: "Published 2025-03-01"},
+		{name: "already publicado", status: "Publicado", wantStatus: "Publicado"},
+		{name: "write fails", failWrite: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs, sheetsService := newFakeSheets(t)
+			fs.fail = tt.failWrite
+			a := &App{
+				config: &config.Config{
+					GoogleSheetID:         "sheet-1",
+					SheetRange:            config.NormalizeSheetRange(tt.sheetRange),
+					PublishedStatusValues: []string{"published", "publicado"},
+				},
+				sheetsService: sheetsService,
+			}
+			row := map[string]any{"TÍTULO ORIGINAL": "La película", publishedStatusHeader: tt.status}
+
+			written, err := a.writePublishedStatus(row, 5, 2)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("writePublishedStatus() error = %v, want error: %v", err, tt.wantErr)
+			}
+			if written != tt.wantWritten {
+				t.Errorf("written = %v, want %v", written, tt.wantWritten)
+			}
+			if tt.wantErr {
+				if row[publishedStatusHeader] != tt.status {
+					t.Errorf("status = %q after a failed write, want it unchanged", row[publishedStatusHeader])
+				}
+				return
+			}
+
+			var wantWrites map[string][][]interface{}
+			if tt.wantCell != "" {
+				wantWrites = map[string][][]interface{}{tt.wantCell: {{today}}}
+			}
+			if len(fs.writes) > 0 || len(wantWrites) > 0 {
+				if !reflect.DeepEqual(fs.writes, wantWrites) {
+					t.Errorf("writes = %v, want %v", fs.writes, wantWrites)
+				}
+			}
+			if row[publishedStatusHeader] != tt.wantStatus {
+				t.Errorf("status = %q, want %q", row[publishedStatusHeader], tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestIsPublishedStatus(t *testing.T) {
+	defaults := []string{"published", "publicado"}
+	tests := []struct {
+		name   string
+		values []string // configured published_status_values
+		status string
+		want   bool
+	}{
+		{name: "blank", values: defaults, status: "", want: false},
+		{name: "written by -write-status", values: defaults, status: "Published 2025-03-01", want: true},
+		{name: "spanish value", values: defaults, status: "Publicado", want: true},
+		{name: "case and surrounding space", values: defaults, status: "  PUBLISHED ", want: true},
+		{name: "pending", values: defaults, status: "Pendiente", want: false},
+		{name: "value only later in the status", values: defaults, status: "Not published", want: false},
+		{name: "accented configured value", values: []string{"Enviado a revisión"}, status: "enviado a revision", want: true},
+		{name: "status with a suffix", values: []string{"listo"}, status: "Listo ✓", want: true},
+		{name: "default value not configured", values: []string{"listo"}, status: "Published 2025-03-01", want: false},
+		{name: "blank configured value matches nothing", values: []string{""}, status: "Pendiente", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &App{config: &config.Config{PublishedStatusValues: tt.values}}
+			if got := a.isPublishedStatus(tt.status); got != tt.want {
+				t.Errorf("isPublishedStatus(%q) with %q = %v, want %v", tt.status, tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateMetadata(t *testing.T) {
+	festival := &models.Metadata{Cities: []string{"Madrid"}, Dates: [][]string{{"2025-05-02", "2025-05-04"}}}
+	tests := []struct {
+		name     string
+		metadata *models.Metadata
+		lang     string
+		want     string
+	}{
+		{
+			name:     "spanish",
+			metadata: festival,
+			lang:     services.LanguageSpanish,
+			want:     "CORTOS 2025 - La playa - ANA RUIZ & LUIS GIL - Programación Excéntrico 2025 Madrid del viernes 02 de mayo al domingo 04 de mayo",
+		},
+		{
+			name:     "spanish by default",
+			metadata: festival,
+			want:     "CORTOS 2025 - La playa - ANA RUIZ & LUIS GIL - Programación Excéntrico 2025 Madrid del viernes 02 de mayo al domingo 04 de mayo",
+		},
+		{
+			name:     "english",
+			metadata: festival,
+			lang:     services.LanguageEnglish,
+			want:     "CORTOS 2025 - La playa - ANA RUIZ & LUIS GIL - Excéntrico 2025 Programme Madrid from Friday 02 May to Sunday 04 May",
+		},
+		{
+			name:     "english single day",
+			metadata: &models.Metadata{Cities: []string{"Madrid"}, Dates: [][]string{{"2025-05-02"}}},
+			lang:     services.LanguageEnglish,
+			want:     "CORTOS 2025 - La playa - ANA RUIZ & LUIS GIL - Excéntrico 2025 Programme Madrid from Friday 02 May",
+		},
+		{
+			name: "english without festival metadata",
+			lang: services.LanguageEnglish,
+			want: "CORTOS 2025 - La playa - ANA RUIZ & LUIS GIL - Excéntrico 2025 Programme",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CreateMetadata("La playa", "Cortos", "Ana Ruiz y Luis Gil", tt.metadata, "2025", tt.lang); got != tt.want {
+				t.Errorf("CreateMetadata() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessFilmsChecksAuthentication(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int // Answer to the connection test
+		wantErr   error
+		wantReads int
+	}{
+		{name: "rejected credentials stop the run", status: http.StatusUnauthorized, wantErr: services.ErrAuthenticationFailed},
+		{name: "server error only warns", status: http.StatusInternalServerError, wantReads: 1},
+		{name: "valid credentials", status: http.StatusOK, wantReads: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.WriteHeader(tt.status)
+				if tt.status == http.StatusUnauthorized {
+					w.Write([]byte(`{"code":"rest_not_logged_in","message":"You are not currently logged in."}`))
+					return
+				}
+				w.Write([]byte(`{"id": 1, "name": "editor"}`))
+			}))
+			defer srv.Close()
+
+			fs, sheetsService := newFakeSheets(t)
+			a := &App{
+				config:           &config.Config{GoogleSheetID: "sheet-1", SheetRange: config.DefaultSheetRange},
+				sheetsService:    sheetsService,
+				wordpressService: services.NewWordPressService(context.Background(), config.WordPressConfig{BaseURL: srv.URL}),
+			}
+			err := a.ProcessFilms("2025", services.DefaultTemplateData(), nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ProcessFilms() error %v, want %v", err, tt.wantErr)
+			}
+			if requests != 1 {
+				t.Errorf("WordPress requests = %d, want 1", requests)
+			}
+			if fs.reads != tt.wantReads {
+				t.Errorf("sheet reads = %d, want %d", fs.reads, tt.wantReads)
+			}
+		})
+	}
+}
+
+func TestProcessFilmsDeadline(t *testing.T) {
+	const filmDuration = 100 * time.Millisecond // Time the fake WordPress takes to create each project
+	tests := []struct {
+		name        string
+		deadline    time.Duration
+		wantStarted int
+	}{
+		{name: "no deadline", wantStarted: 4},
+		{name: "deadline after the run", deadline: time.Minute, wantStarted: 4},
+		{name: "deadline during the first film", deadline: filmDuration / 3, wantStarted: 1},
+		{name: "deadline during the second film", deadline: filmDuration + filmDuration/3, wantStarted: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Films are written below the working directory
+			wd, err := os.Getwd()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chdir(t.TempDir()); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { os.Chdir(wd) })
+
+			var created atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch {
+				case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/wp/v2/project"):
+					time.Sleep(filmDuration)
+					id := 100 + created.Add(1)
+					w.WriteHeader(http.StatusCreated)
+					w.Write([]byte(`{"id": ` + strconv.Itoa(int(id)) + `, "status": "draft"}`))
+				case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/wp/v2/users/me"):
+					w.Write([]byte(`{"id": 1, "name": "editor"}`))
+				case r.Method == http.MethodGet:
+					w.Write([]byte(`[]`))
+				default:
+					w.Write([]byte(`{}`))
+				}
+			}))
+			defer srv.Close()
+
+			turso := newTestTurso(t)
+			wp := services.NewWordPressService(context.Background(), config.WordPressConfig{BaseURL: srv.URL})
+			divi := services.NewDiviTemplateService()
+			processor := film.NewProcessor(nil, services.NewImageServiceWithConfig(1920, 1080, 85), wp, divi, turso)
+			processor.SetMissingFolderPolicy(film.MissingFolderCreateEmpty)
+			a := &App{
+				config:              &config.Config{},
+				ctx:                 context.Background(),
+				runCtx:              context.Background(),
+				tursoService:        turso,
+				wordpressService:    wp,
+				diviTemplateService: divi,
+				filmProcessor:       processor,
+			}
+			a.SetDeadline(tt.deadline)
+			defer a.Close()
+
+			var objects []map[string]any
+			for _, title := range []string{"La playa", "El faro", "El bosque", "El río"} {
+				objects = append(objects, map[string]any{"TÍTULO ORIGINAL": title, "SECCIÓN": "Cortos"})
+			}
+			events := captureEvents(t)
+			if err := a.processFilteredObjects(objects, []int{2, 3, 4, 5}, nil, "2025", services.DefaultTemplateData(), &models.Metadata{}); err != nil {
+				t.Fatalf("processFilteredObjects: %v", err)
+			}
+
+			// A film started before the deadline still finishes
+			if got := int(created.Load()); got != tt.wantStarted {
+				t.Errorf("projects created = %d, want %d", got, tt.wantStarted)
+			}
+			var summary *logger.WideEvent
+			for _, event := range events() {
+				if event.Operation == "process_filtered_objects" {
+					summary = &event
+				}
+			}
+			if summary == nil {
+				t.Fatal("no process_filtered_objects event")
+			}
+			if got := summary.Context["success_count"]; got != float64(tt.wantStarted) {
+				t.Errorf("success_count = %v, want %d", got, tt.wantStarted)
+			}
+			wantSkipped := len(objects) - tt.wantStarted
+			if wantSkipped == 0 {
+				if _, ok := summary.Context["deadline_exceeded"]; ok {
+					t.Errorf("summary %v reports an exceeded deadline", summary.Context)
+				}
+				return
+			}
+			if summary.Context["deadline_exceeded"] != true || summary.Context["deadline_skipped_count"] != float64(wantSkipped) {
+				t.Errorf("summary deadline_exceeded = %v, deadline_skipped_count = %v, want true and %d",
+					summary.Context["deadline_exceeded"], summary.Context["deadline_skipped_count"], wantSkipped)
+			}
+			if !strings.HasPrefix(summary.Message, "Run deadline exceeded") {
+				t.Errorf("summary message %q, want the deadline summary", summary.Message)
+			}
+		})
+	}
+}
+
+func TestCheckMenuID(t *testing.T) {
+	menus := []*services.WordPressMenu{
+		{ID: 7, Name: "Principal", Slug: "principal"},
+		{ID: 12, Name: "Festival 2025", Slug: "festival-2025"},
+	}
+	tests := []struct {
+		name    string
+		menuID  string
+		wantErr string
+	}{
+		{name: "valid ID", menuID: "12"},
+		{name: "valid ID with spaces", menuID: " 7 "},
+		{name: "slug", menuID: "festival-2025", wantErr: `is a menu slug or name; use its ID 12`},
+		{name: "name", menuID: "principal", wantErr: `use its ID 7`},
+		{name: "unknown ID", menuID: "99", wantErr: `menu_id "99" matches no WordPress menu; valid IDs: 7 (principal), 12 (festival-2025)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkMenuID(menus, tt.menuID)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkMenuID(%q) error %v, want none", tt.menuID, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkMenuID(%q) error %v, want one containing %q", tt.menuID, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyTemplateMenu(t *testing.T) {
+	const menusJSON = `[{"id": 7, "name": "Principal", "slug": "principal"}, {"id": 12, "name": "Festival 2025", "slug": "festival-2025"}]`
+	disabled := false
+	tests := []struct {
+		name         string
+		menuID       string
+		enabled      *bool
+		status       int // Answer to GET /wp/v2/menus
+		wantRequests int
+		wantLevel    string // Empty when no verify_template_menu event is expected
+		wantMessage  string
+	}{
+		{name: "valid menu ID", menuID: "12", status: http.StatusOK, wantRequests: 1, wantLevel: "info", wantMessage: "Template menu 12 exists"},
+		{name: "invalid menu ID", menuID: "99", status: http.StatusOK, wantRequests: 1, wantLevel: "warn", wantMessage: "Template menu of year 2025: menu_id \"99\" matches no WordPress menu; valid IDs: 7 (principal), 12 (festival-2025)"},
+		{name: "menus unavailable", menuID: "99", status: http.StatusInternalServerError, wantRequests: 1, wantLevel: "info", wantMessage: "WordPress menus unavailable; template menu not verified"},
+		{name: "no menu ID", status: http.StatusOK},
+		{name: "menu disabled", menuID: "99", enabled: &disabled, status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !strings.HasSuffix(r.URL.Path, "/wp/v2/menus") {
+					http.NotFound(w, r)
+					return
+				}
+				requests.Add(1)
+				w.WriteHeader(tt.status)
+				if tt.status == http.StatusOK {
+					w.Write([]byte(menusJSON))
+				}
+			}))
+			defer srv.Close()
+			a := &App{wordpressService: services.NewWordPressService(context.Background(), config.WordPressConfig{BaseURL: srv.URL})}
+			templateConfig := services.DefaultTemplateData()
+			templateConfig.Menu.MenuId = tt.menuID
+			templateConfig.Menu.Enabled = tt.enabled
+			events := captureEvents(t)
+
+			a.VerifyTemplateMenu("2025", templateConfig)
+
+			if got := int(requests.Load()); got != tt.wantRequests {
+				t.Errorf("menu requests = %d, want %d", got, tt.wantRequests)
+			}
+			var found bool
+			for _, event := range events() {
+				if event.Operation != "verify_template_menu" {
+					continue
+				}
+				found = true
+				if event.Level != tt.wantLevel || event.Message != tt.wantMessage {
+					t.Errorf("event %s %q, want %s %q", event.Level, event.Message, tt.wantLevel, tt.wantMessage)
+				}
+			}
+			if found != (tt.wantLevel != "") {
+				t.Errorf("verify_template_menu event logged = %v, want %v", found, tt.wantLevel != "")
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"excentrico-tools-go/internal/services"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+// fakeSheets serves the values.get and values.update routes of the Sheets API, reading
+// from rows and recording every write
+type fakeSheets struct {
+	mu     sync.Mutex
+	rows   [][]interface{}            // values returned for any read, header row first
+	reads  int                        // reads served
+	writes map[string][][]interface{} // A1 range -> values written to it
+	fail   bool                       // reject every write
+}
+
+// newFakeSheets starts a fake Sheets API and returns a GoogleSheetsService talking to it
+func newFakeSheets(t *testing.T) (*fakeSheets, *services.GoogleSheetsService) {
+	t.Helper()
+	fs := &fakeSheets{writes: make(map[string][][]interface{})}
+	srv := httptest.NewServer(http.HandlerFunc(fs.serveHTTP))
+	t.Cleanup(srv.Close)
+
+	service, err := services.NewGoogleSheetsServiceWithOptions(context.Background(),
+		option.WithEndpoint(srv.URL+"/"),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatalf("NewGoogleSheetsServiceWithOptions: %v", err)
+	}
+	return fs, service
+}
+
+func (fs *fakeSheets) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	// Paths have the form /v4/spreadsheets/<ID>/values/<range>
+	_, cell, ok := strings.Cut(r.URL.Path, "/values/")
+	if !ok || (r.Method != http.MethodPut && r.Method != http.MethodGet) {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method == http.MethodGet {
+		fs.mu.Lock()
+		defer fs.mu.Unlock()
+		fs.reads++
+		json.NewEncoder(w).Encode(&sheets.ValueRange{Range: cell, Values: fs.rows})
+		return
+	}
+	if fs.fail {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":{"code":403,"message":"The caller does not have permission"}}`))
+		return
+	}
+	var body sheets.ValueRange
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	fs.mu.Lock()
+	fs.writes[cell] = body.Values
+	fs.mu.Unlock()
+	json.NewEncoder(w).Encode(&sheets.UpdateValuesResponse{UpdatedRange: cell, UpdatedCells: 1})
+}
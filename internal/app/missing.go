@@ -0,0 +1,149 @@
+package app
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"excentrico-tools-go/internal/models"
+	"excentrico-tools-go/internal/utils"
+	"excentrico-tools-go/internal/wordpress"
+)
+
+// MissingFilm is a sheet row with no WordPress project
+type MissingFilm struct {
+	Film     string `json:"film"`
+	FilmID   string `json:"film_id"`
+	SheetRow int    `json:"sheet_row"`
+	Section  string `json:"section,omitempty"`
+	Slug     string `json:"expected_slug"`
+	// StalePostID is the post recorded in Turso for the film that no longer exists in WordPress
+	StalePostID int `json:"stale_post_id,omitempty"`
+}
+
+// MissingReport lists the selected sheet rows never created in WordPress
+type MissingReport struct {
+	Year         string         `json:"year,omitempty"`
+	Section      string         `json:"section,omitempty"`
+	CheckedRows  int            `json:"checked_rows"`
+	MissingCount int            `json:"missing_count"`
+	Films        []*MissingFilm `json:"films"`
+}
+
+// FindMissingFilms cross-references the sheet rows matching the year and section filters
+// against the WordPress projects and the post IDs recorded in Turso. A film counts as
+// created when its recorded post still exists, or when a project has its expected slug
+// or its title. Nothing is written
+func (a *App) FindMissingFilms(year string) (*MissingReport, error) {
+	objects, sheetRows, err := a.selectedFilms(year)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := a.wordpressService.GetPosts(map[string]string{"status": "any"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list WordPress projects: %v", err)
+	}
+	postIDs := make(map[int]bool)
+	slugs := make(map[string]bool)
+	titles := make(map[string]bool)
+	for _, post := range posts {
+		postIDs[post.ID] = true
+		slugs[post.Slug] = true
+		titles[wordpress.CreateWordPressSlug(post.Title.String())] = true
+	}
+
+	report := &MissingReport{Year: year, Section: a.section, Films: []*MissingFilm{}}
+	for idx, obj := range objects {
+		report.CheckedRows++
+
+		filmData := wordpress.ConvertObjToFilmData(obj)
+		title := strings.TrimSpace(filmData.TituloOriginal)
+		if title == "" {
+			continue
+		}
+		filmID := a.filmIDs.For(title, obj)
+
+		stalePostID := 0
+		metadata := &models.WordPressMetadata{}
+		if a.tursoService.GetWordPressMetadata(filmID, metadata) == nil && metadata.PostID > 0 {
+			if postIDs[metadata.PostID] {
+				continue
+			}
+			stalePostID = metadata.PostID
+		}
+
+		slug := expectedSlug(a.config.WordPressConfig.SlugTemplate, title, filmData.Seccion, year, filmData.Edicion)
+		if slugs[slug] || titles[wordpress.CreateWordPressSlug(title)] {
+			continue
+		}
+
+		report.Films = append(report.Films, &MissingFilm{
+			Film:        title,
+			FilmID:      filmID,
+			SheetRow:    sheetRows[idx],
+			Section:     filmData.Seccion,
+			Slug:        slug,
+			StalePostID: stalePostID,
+		})
+	}
+	report.MissingCount = len(report.Films)
+
+	return report, nil
+}
+
+// expectedSlug returns the slug a film's project is created with, as built by
+// CreateOrUpdateWordPressProject from the slug template; without a year filter the
+// edition year is used
+func expectedSlug(template, title, section, year, edition string) string {
+	if year == "" {
+		year = utils.ExtractEditionYear(edition)
+	}
+	return wordpress.ProjectSlug(template, title, section, year)
+}
+
+// Write saves the report to path, as CSV (one row per film) when the path ends in
+// .csv and as indented JSON otherwise
+func (r *MissingReport) Write(path string) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create report directory: %v", err)
+		}
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		file, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create report file: %v", err)
+		}
+		defer file.Close()
+
+		writer := csv.NewWriter(file)
+		writer.Write([]string{"film", "film_id", "sheet_row", "section", "expected_slug", "stale_post_id"})
+		for _, film := range r.Films {
+			stalePostID := ""
+			if film.StalePostID > 0 {
+				stalePostID = strconv.Itoa(film.StalePostID)
+			}
+			writer.Write([]string{film.Film, film.FilmID, strconv.Itoa(film.SheetRow), film.Section, film.Slug, stalePostID})
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return fmt.Errorf("failed to write report file: %v", err)
+		}
+		return nil
+	}
+
+	reportJSON, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %v", err)
+	}
+	if err := os.WriteFile(path, reportJSON, 0644); err != nil {
+		return fmt.Errorf("failed to write report file: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"excentrico-tools-go/internal/config"
+	"excentrico-tools-go/internal/models"
+	"excentrico-tools-go/internal/services"
+)
+
+// newProjectsServer serves the given projects from GET /wp/v2/project, one per page, so
+// that gap detection has to follow the pagination
+func newProjectsServer(t *testing.T, posts []*services.WordPressPost) *services.WordPressService {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || !strings.HasSuffix(r.URL.Path, "/wp/v2/project") {
+			http.NotFound(w, r)
+			return
+		}
+		page, err := strconv.Atoi(r.URL.Query().Get("page"))
+		if err != nil {
+			page = 1
+		}
+		w.Header().Set("X-WP-TotalPages", strconv.Itoa(len(posts)))
+		items := []*services.WordPressPost{}
+		if page <= len(posts) {
+			items = append(items, posts[page-1])
+		}
+		json.NewEncoder(w).Encode(items)
+	}))
+	t.Cleanup(srv.Close)
+	return services.NewWordPressService(context.Background(), config.WordPressConfig{BaseURL: srv.URL})
+}
+
+func TestFindMissingFilms(t *testing.T) {
+	rows := [][]interface{}{
+		{"TÍTULO ORIGINAL", "EDICIÓN", "SECCIÓN"},
+		{"La playa", "Excéntrico 2025", "Cortos"},   // project with the expected slug
+		{"El faro", "Excéntrico 2025", "Cortos"},    // project with the same title and another slug
+		{"El bosque", "Excéntrico 2025", "Largos"},  // post recorded in Turso
+		{"La cueva", "Excéntrico 2025", "Cortos"},   // recorded post deleted from WordPress
+		{"El río", "Excéntrico 2025", "Largos"},     // never created
+		{"", "Excéntrico 2025", "Cortos"},           // no title
+		{"La montaña", "Excéntrico 2024", "Cortos"}, // other edition
+	}
+	posts := []*services.WordPressPost{
+		{ID: 11, Slug: "el-faro-2", Title: services.WordPressRenderedField{Rendered: "El faro"}},
+		{ID: 12, Slug: "proyecto-12", Title: services.WordPressRenderedField{Rendered: "Otro título"}},
+		{ID: 13, Slug: "la-playa-cortos-2025", Title: services.WordPressRenderedField{Rendered: "Playa"}},
+	}
+
+	tests := []struct {
+		name        string
+		year        string
+		section     string
+		wantChecked int
+		want        []*MissingFilm
+	}{
+		{
+			name:        "year",
+			year:        "2025",
+			wantChecked: 6,
+			want: []*MissingFilm{
+				{Film: "La cueva", FilmID: "La cueva", SheetRow: 5, Section: "Cortos", Slug: "la-cueva-cortos-2025", StalePostID: 99},
+				{Film: "El río", FilmID: "El río", SheetRow: 6, Section: "Largos", Slug: "el-rio-largos-2025"},
+			},
+		},
+		{
+			name:        "year and section",
+			year:        "2025",
+			section:     "Largos",
+			wantChecked: 2,
+			want: []*MissingFilm{
+				{Film: "El río", FilmID: "El río", SheetRow: 6, Section: "Largos", Slug: "el-rio-largos-2025"},
+			},
+		},
+		{
+			name:        "all years",
+			wantChecked: 7,
+			want: []*MissingFilm{
+				{Film: "La cueva", FilmID: "La cueva", SheetRow: 5, Section: "Cortos", Slug: "la-cueva-cortos-2025", StalePostID: 99},
+				{Film: "El río", FilmID: "El río", SheetRow: 6, Section: "Largos", Slug: "el-rio-largos-2025"},
+				{Film: "La montaña", FilmID: "La montaña", SheetRow: 8, Section: "Cortos", Slug: "la-montana-cortos-2024"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			turso := newTestTurso(t)
+			for filmID, postID := range map[string]int{"El bosque": 12, "La cueva": 99} {
+				if err := turso.SaveWordPressMetadata(filmID, &models.WordPressMetadata{PostID: postID}); err != nil {
+					t.Fatalf("SaveWordPressMetadata: %v", err)
+				}
+			}
+			a := newValidateApp(t, rows)
+			a.tursoService = turso
+			a.wordpressService = newProjectsServer(t, posts)
+			a.SetSection(tt.section)
+
+			report, err := a.FindMissingFilms(tt.year)
+			if err != nil {
+				t.Fatalf("FindMissingFilms: %v", err)
+			}
+			if report.CheckedRows != tt.wantChecked {
+				t.Errorf("CheckedRows = %d, want %d", report.CheckedRows, tt.wantChecked)
+			}
+			if report.MissingCount != len(tt.want) {
+				t.Errorf("MissingCount = %d, want %d", report.MissingCount, len(tt.want))
+			}
+			if !reflect.DeepEqual(report.Films, tt.want) {
+				got, _ := json.Marshal(report.Films)
+				want, _ := json.Marshal(tt.want)
+				t.Errorf("missing films = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestMissingReportWrite(t *testing.T) {
+	report := &MissingReport{
+		Year:         "2025",
+		CheckedRows:  4,
+		MissingCount: 2,
+		Films: []*MissingFilm{
+			{Film: "La cueva", FilmID: "La cueva", SheetRow: 5, Section: "Cortos", Slug: "la-cueva-cortos-2025", StalePostID: 99},
+			{Film: "El río, de noche", FilmID: "El río, de noche", SheetRow: 6, Section: "Largos", Slug: "el-rio-de-noche-largos-2025"},
+		},
+	}
+	tests := []struct {
+		file    string
+		wantCSV [][]string // nil for JSON reports
+	}{
+		{file: "missing.json"},
+		{file: "reports/missing.csv", wantCSV: [][]string{
+			{"film", "film_id", "sheet_row", "section", "expected_slug", "stale_post_id"},
+			{"La cueva", "La cueva", "5", "Cortos", "la-cueva-cortos-2025", "99"},
+			{"El río, de noche", "El río, de noche", "6", "Largos", "el-rio-de-noche-largos-2025", ""},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := report.Write(path); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.wantCSV == nil {
+				var got MissingReport
+				if err := json.Unmarshal(data, &got); err != nil {
+					t.Fatalf("decoding the JSON report: %v", err)
+				}
+				if !reflect.DeepEqual(&got, report) {
+					t.Errorf("JSON report decodes to %+v, want %+v", got, report)
+				}
+				return
+			}
+			records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+			if err != nil {
+				t.Fatalf("decoding the CSV report: %v", err)
+			}
+			if !reflect.DeepEqual(records, tt.wantCSV) {
+				t.Errorf("CSV report = %q, want %q", records, tt.wantCSV)
+			}
+		})
+	}
+}
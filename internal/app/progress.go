@@ -0,0 +1,70 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"excentrico-tools-go/internal/logger"
+)
+
+// progressWindow is how many of the latest film durations the ETA is averaged over
+const progressWindow = 10
+
+// batchProgress counts the films of a batch done so far and estimates the time left
+// from the rolling average duration of the latest processed films. It is not safe for
+// concurrent use; concurrent films record their progress under the caller's lock
+type batchProgress struct {
+	year      string
+	total     int
+	workers   int // Films processed at once, dividing the time left
+	completed int
+	durations []time.Duration
+}
+
+func newBatchProgress(year string, total, workers int) *batchProgress {
+	return &batchProgress{year: year, total: total, workers: max(workers, 1)}
+}
+
+// record marks a processed film as done and emits a progress event
+func (p *batchProgress) record(duration time.Duration) {
+	p.durations = append(p.durations, duration)
+	if len(p.durations) > progressWindow {
+		p.durations = p.durations[1:]
+	}
+	p.completed++
+	p.emit()
+}
+
+// skip marks a film done without processing it, leaving the average untouched
+func (p *batchProgress) skip() {
+	p.completed++
+	p.emit()
+}
+
+// averageDuration is the mean of the recorded durations; 0 before any film was processed
+func (p *batchProgress) averageDuration() time.Duration {
+	if len(p.durations) == 0 {
+		return 0
+	}
+	var sum time.Duration
+	for _, duration := range p.durations {
+		sum += duration
+	}
+	return sum / time.Duration(len(p.durations))
+}
+
+// eta estimates the time left for the films not yet done, spread over the workers
+func (p *batchProgress) eta() time.Duration {
+	return p.averageDuration() * time.Duration(p.total-p.completed) / time.Duration(p.workers)
+}
+
+func (p *batchProgress) emit() {
+	op := logger.Get().StartOperation("batch_progress")
+	op.KeepAlways()
+	op.WithContext("year", p.year)
+	op.WithContext("films_completed", p.completed)
+	op.WithContext("films_total", p.total)
+	op.WithContext("average_film_ms", p.averageDuration().Milliseconds())
+	op.WithContext("eta_ms", p.eta().Milliseconds())
+	op.Complete(fmt.Sprintf("Progress: %d/%d films, ETA %v", p.completed, p.total, p.eta().Round(time.Second)))
+}
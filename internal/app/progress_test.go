@@ -0,0 +1,72 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBatchProgress(t *testing.T) {
+	// Each step is a processed film's duration, or 0 for a skipped film
+	tests := []struct {
+		name        string
+		total       int
+		workers     int
+		steps       []time.Duration
+		wantAverage time.Duration
+		wantETA     time.Duration
+	}{
+		{name: "first film", total: 4, workers: 1, steps: []time.Duration{2 * time.Second}, wantAverage: 2 * time.Second, wantETA: 6 * time.Second},
+		{name: "average of films", total: 4, workers: 1, steps: []time.Duration{time.Second, 3 * time.Second}, wantAverage: 2 * time.Second, wantETA: 4 * time.Second},
+		{name: "skipped films count as done", total: 4, workers: 1, steps: []time.Duration{2 * time.Second, 0}, wantAverage: 2 * time.Second, wantETA: 4 * time.Second},
+		{name: "only skipped films", total: 3, workers: 1, steps: []time.Duration{0}, wantAverage: 0, wantETA: 0},
+		{name: "workers share the time left", total: 5, workers: 2, steps: []time.Duration{2 * time.Second}, wantAverage: 2 * time.Second, wantETA: 4 * time.Second},
+		{name: "no workers counts as one", total: 2, workers: 0, steps: []time.Duration{2 * time.Second}, wantAverage: 2 * time.Second, wantETA: 2 * time.Second},
+		{
+			name:        "rolling window",
+			total:       12,
+			workers:     1,
+			steps:       []time.Duration{12 * time.Second, time.Second, time.Second, time.Second, time.Second, time.Second, time.Second, time.Second, time.Second, time.Second, time.Second},
+			wantAverage: time.Second,
+			wantETA:     time.Second,
+		},
+		{name: "batch done", total: 2, workers: 1, steps: []time.Duration{time.Second, time.Second}, wantAverage: time.Second, wantETA: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := captureEvents(t)
+			progress := newBatchProgress("2025", tt.total, tt.workers)
+			for _, step := range tt.steps {
+				if step == 0 {
+					progress.skip()
+				} else {
+					progress.record(step)
+				}
+			}
+
+			var got []map[string]any
+			for _, event := range events() {
+				if event.Operation == "batch_progress" {
+					got = append(got, event.Context)
+				}
+			}
+			if len(got) != len(tt.steps) {
+				t.Fatalf("%d progress events, want one per film (%d)", len(got), len(tt.steps))
+			}
+			for i, context := range got {
+				if completed, total := context["films_completed"], context["films_total"]; completed != float64(i+1) || total != float64(tt.total) {
+					t.Errorf("event %d: %v/%v films, want %d/%d", i, completed, total, i+1, tt.total)
+				}
+				if context["year"] != "2025" {
+					t.Errorf("event %d: year %v, want 2025", i, context["year"])
+				}
+			}
+			last := got[len(got)-1]
+			if average := last["average_film_ms"]; average != float64(tt.wantAverage.Milliseconds()) {
+				t.Errorf("average_film_ms = %v, want %d", average, tt.wantAverage.Milliseconds())
+			}
+			if eta := last["eta_ms"]; eta != float64(tt.wantETA.Milliseconds()) {
+				t.Errorf("eta_ms = %v, want %d", eta, tt.wantETA.Milliseconds())
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package app
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"excentrico-tools-go/internal/utils"
+	"excentrico-tools-go/internal/wordpress"
+)
+
+// FilmValidation lists the data-quality issues found for one film
+type FilmValidation struct {
+	Film      string   `json:"film"`
+	SheetRows []int    `json:"sheet_rows"`
+	Issues    []string `json:"issues"`
+}
+
+// ValidationReport is the result of validating every selected sheet row without processing it
+type ValidationReport struct {
+	Year            string            `json:"year,omitempty"`
+	Section         string            `json:"section,omitempty"`
+	CheckedRows     int               `json:"checked_rows"`
+	FilmsWithIssues int               `json:"films_with_issues"`
+	Films           []*FilmValidation `json:"films"`
+}
+
+// ValidateFilms reads the sheet and checks every row matching the year and section
+// filters: required fields, a usable Drive folder link and duplicate titles. Only
+// films with issues appear in the report. Nothing is downloaded or written
+func (a *App) ValidateFilms(year string) (*ValidationReport, error) {
+	objects, sheetRows, err := a.selectedFilms(year)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ValidationReport{Year: year, Section: a.section, Films: []*FilmValidation{}}
+
+	// Films are grouped by the ID their Turso metadata and folder would share
+	byFilm := make(map[string]*FilmValidation)
+	var order []string
+
+	for idx, obj := range objects {
+		sheetRow := sheetRows[idx]
+		report.CheckedRows++
+
+		filmData := wordpress.ConvertObjToFilmData(obj)
+		issues := filmData.Validate()
+		if !a.hasImageFolderLink(obj) {
+			issues = append(issues, fmt.Sprintf("no Drive folder ID in %s", strings.Join(a.config.ImageFolderColumns, ", ")))
+		}
+
+		title := strings.TrimSpace(filmData.TituloOriginal)
+		key := utils.SanitizeFilename(title)
+		if title == "" {
+			// Untitled rows cannot be duplicates of each other; keep them apart
+			title = fmt.Sprintf("(untitled row %d)", sheetRow)
+			key = title
+		}
+
+		film, seen := byFilm[key]
+		if !seen {
+			film = &FilmValidation{Film: title}
+			byFilm[key] = film
+			order = append(order, key)
+		}
+		film.SheetRows = append(film.SheetRows, sheetRow)
+		for _, issue := range issues {
+			film.Issues = append(film.Issues, fmt.Sprintf("row %d: %s", sheetRow, issue))
+		}
+	}
+
+	for _, key := range order {
+		film := byFilm[key]
+		if len(film.SheetRows) > 1 {
+			rows := make([]string, len(film.SheetRows))
+			for i, sheetRow := range film.SheetRows {
+				rows[i] = strconv.Itoa(sheetRow)
+			}
+			film.Issues = append(film.Issues, fmt.Sprintf("duplicate title in rows %s", strings.Join(rows, ", ")))
+		}
+		if len(film.Issues) > 0 {
+			report.Films = append(report.Films, film)
+		}
+	}
+	report.FilmsWithIssues = len(report.Films)
+
+	return report, nil
+}
+
+// selectedFilms reads the sheet and returns the rows matching the year and section
+// filters as header-keyed objects, along with their 1-based sheet row numbers
+func (a *App) selectedFilms(year string) ([]map[string]any, []int, error) {
+	if a.config.GoogleSheetID == "" {
+		return nil, nil, fmt.Errorf("google sheet ID not configured")
+	}
+
+	data, err := a.sheetsService.ReadRange(a.config.GoogleSheetID, a.config.SheetRange)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(data) < 2 {
+		return nil, nil, nil
+	}
+
+	headers := make([]string, 0)
+	for _, cell := range data[0] {
+		if cell != nil {
+			headers = append(headers, cell.(string))
+		}
+	}
+
+	var objects, rows []map[string]any
+	var sheetRows []int
+	for i := 1; i < len(data); i++ {
+		row := data[i]
+		obj := make(map[string]any)
+		for j, header := range headers {
+			if j < len(row) && row[j] != nil {
+				obj[header] = row[j]
+			} else {
+				obj[header] = ""
+			}
+		}
+		rows = append(rows, obj)
+		if a.matchesFilters(obj, year) {
+			objects = append(objects, obj)
+			sheetRows = append(sheetRows, i+1)
+		}
+	}
+	a.setFilmIDs(rows)
+	return objects, sheetRows, nil
+}
+
+// hasImageFolderLink reports whether any configured image folder column yields a Drive folder ID
+func (a *App) hasImageFolderLink(obj map[string]any) bool {
+	for _, column := range a.config.ImageFolderColumns {
+		if value, _ := obj[column].(string); utils.ExtractFileIDFromURL(value) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Write saves the report to path, as CSV (one row per issue) when the path ends in
+// .csv and as indented JSON otherwise
+func (r *ValidationReport) Write(path string) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create report directory: %v", err)
+		}
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		file, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create report file: %v", err)
+		}
+		defer file.Close()
+
+		writer := csv.NewWriter(file)
+		writer.Write([]string{"film", "sheet_rows", "issue"})
+		for _, film := range r.Films {
+			rows := make([]string, len(film.SheetRows))
+			for i, sheetRow := range film.SheetRows {
+				rows[i] = strconv.Itoa(sheetRow)
+			}
+			for _, issue := range film.Issues {
+				writer.Write([]string{film.Film, strings.Join(rows, " "), issue})
+			}
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return fmt.Errorf("failed to write report file: %v", err)
+		}
+		return nil
+	}
+
+	reportJSON, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %v", err)
+	}
+	if err := os.WriteFile(path, reportJSON, 0644); err != nil {
+		return fmt.Errorf("failed to write report file: %v", err)
+	}
+	return nil
+}
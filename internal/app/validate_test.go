@@ -0,0 +1,168 @@
+package app
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"excentrico-tools-go/internal/config"
+	"excentrico-tools-go/internal/film"
+)
+
+// newValidateApp returns an App reading the given sheet rows from a fake Sheets API
+func newValidateApp(t *testing.T, rows [][]interface{}) *App {
+	t.Helper()
+	fs, sheetsService := newFakeSheets(t)
+	fs.rows = rows
+	return &App{
+		config: &config.Config{
+			GoogleSheetID:      "sheet-1",
+			SheetRange:         config.DefaultSheetRange,
+			ImageFolderColumns: []string{"ENLACES"},
+		},
+		sheetsService: sheetsService,
+		filmProcessor: film.NewProcessor(nil, nil, nil, nil, nil),
+	}
+}
+
+func TestValidateFilms(t *testing.T) {
+	const folder = "https://drive.google.com/drive/folders/1AbC"
+	rows := [][]interface{}{
+		{"TÍTULO ORIGINAL", "DIRECCIÓN", "PAIS", "AÑO", "DURAC.", "EDICIÓN", "SECCIÓN", "Sinopsis extendida (máximo 70 palabras)", "ENLACES"},
+		{"La playa", "Ana Ruiz", "España", "2024", "12'", "Excéntrico 2025", "Cortos", "Una playa.", folder},
+		{"El faro", "Luis Gil", "", "24", "9'", "Excéntrico 2025", "Cortos", "Un faro.", "https://vimeo.com/12345"},
+		{"La playa ", "Ana Ruiz", "España", "2024", "12'", "Excéntrico 2025", "Largos", "Una playa.", folder},
+		{"", "Eva Sanz", "Chile", "2023", "15'", "Excéntrico 2025", "Cortos", "Sin título.", folder},
+		{"El bosque", "Raúl Paz", "Perú", "2024", "20'", "Excéntrico 2025", "Cortos", "Un bosque.", folder},
+		{"El río", "", "", "", "", "Excéntrico 2024", "Cortos", "", ""},
+	}
+
+	tests := []struct {
+		name    string
+		year    string
+		section string
+		want    *ValidationReport
+	}{
+		{
+			name: "every row",
+			want: &ValidationReport{
+				CheckedRows:     6,
+				FilmsWithIssues: 4,
+				Films: []*FilmValidation{
+					{Film: "La playa", SheetRows: []int{2, 4}, Issues: []string{"duplicate title in rows 2, 4"}},
+					{Film: "El faro", SheetRows: []int{3}, Issues: []string{
+						"row 3: missing PAIS",
+						`row 3: AÑO "24" is not a four-digit year`,
+						"row 3: no Drive folder ID in ENLACES",
+					}},
+					{Film: "(untitled row 5)", SheetRows: []int{5}, Issues: []string{"row 5: missing TÍTULO ORIGINAL"}},
+					{Film: "El río", SheetRows: []int{7}, Issues: []string{
+						"row 7: missing DIRECCIÓN",
+						"row 7: missing PAIS",
+						"row 7: missing AÑO",
+						"row 7: missing DURAC.",
+						"row 7: missing Sinopsis extendida (máximo 70 palabras)",
+						"row 7: no Drive folder ID in ENLACES",
+					}},
+				},
+			},
+		},
+		{
+			name: "year filter",
+			year: "2025",
+			want: &ValidationReport{
+				Year:            "2025",
+				CheckedRows:     5,
+				FilmsWithIssues: 3,
+				Films: []*FilmValidation{
+					{Film: "La playa", SheetRows: []int{2, 4}, Issues: []string{"duplicate title in rows 2, 4"}},
+					{Film: "El faro", SheetRows: []int{3}, Issues: []string{
+						"row 3: missing PAIS",
+						`row 3: AÑO "24" is not a four-digit year`,
+						"row 3: no Drive folder ID in ENLACES",
+					}},
+					{Film: "(untitled row 5)", SheetRows: []int{5}, Issues: []string{"row 5: missing TÍTULO ORIGINAL"}},
+				},
+			},
+		},
+		{
+			name:    "section filter leaves no duplicate",
+			year:    "2025",
+			section: "Largos",
+			want:    &ValidationReport{Year: "2025", Section: "Largos", CheckedRows: 1, Films: []*FilmValidation{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newValidateApp(t, rows)
+			a.SetSection(tt.section)
+			report, err := a.ValidateFilms(tt.year)
+			if err != nil {
+				t.Fatalf("ValidateFilms: %v", err)
+			}
+			if !reflect.DeepEqual(report, tt.want) {
+				got, _ := json.MarshalIndent(report, "", "  ")
+				want, _ := json.MarshalIndent(tt.want, "", "  ")
+				t.Errorf("report:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestValidationReportWrite(t *testing.T) {
+	report := &ValidationReport{
+		Year:            "2025",
+		CheckedRows:     3,
+		FilmsWithIssues: 2,
+		Films: []*FilmValidation{
+			{Film: "La playa", SheetRows: []int{2, 4}, Issues: []string{"duplicate title in rows 2, 4"}},
+			{Film: "El faro, de noche", SheetRows: []int{3}, Issues: []string{"row 3: missing PAIS", `row 3: AÑO "24" is not a four-digit year`}},
+		},
+	}
+	tests := []struct {
+		file    string
+		wantCSV [][]string // nil for JSON reports
+	}{
+		{file: "report.json"},
+		{file: "reports/report.CSV", wantCSV: [][]string{
+			{"film", "sheet_rows", "issue"},
+			{"La playa", "2 4", "duplicate title in rows 2, 4"},
+			{"El faro, de noche", "3", "row 3: missing PAIS"},
+			{"El faro, de noche", "3", `row 3: AÑO "24" is not a four-digit year`},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := report.Write(path); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.wantCSV == nil {
+				var got ValidationReport
+				if err := json.Unmarshal(data, &got); err != nil {
+					t.Fatalf("decoding the JSON report: %v", err)
+				}
+				if !reflect.DeepEqual(&got, report) {
+					t.Errorf("JSON report decodes to %+v, want %+v", got, report)
+				}
+				return
+			}
+			records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+			if err != nil {
+				t.Fatalf("decoding the CSV report: %v", err)
+			}
+			if !reflect.DeepEqual(records, tt.wantCSV) {
+				t.Errorf("CSV report = %q, want %q", records, tt.wantCSV)
+			}
+		})
+	}
+}
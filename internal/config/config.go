@@ -1,10 +1,17 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -13,6 +20,57 @@ type Config struct {
 	WordPressConfig       WordPressConfig `json:"wordpress_config"`
 	ImageConfig           ImageConfig     `json:"image_config"`
 	TursoConfig           TursoConfig     `json:"turso_config"`
+	// SheetRange is the A1 range holding the films, starting at column A and row 1 with the
+	// headers, or a bare tab name read as <tab>!A:ZZ. Defaults to TODO!A:ZZ
+	SheetRange string `json:"sheet_range,omitempty"`
+	// PublishedStatusValues are the Published Status prefixes that mark a film as done
+	PublishedStatusValues []string `json:"published_status_values,omitempty"`
+	// ImageFolderColumns are the sheet columns tried in order for the film's Drive image folder link
+	ImageFolderColumns []string `json:"image_folder_columns,omitempty"`
+	// PostURLColumn is the sheet column a film's project permalink is written to after it is
+	// saved; empty writes nothing
+	PostURLColumn string `json:"post_url_column,omitempty"`
+	// BlockIncompleteContent fails films with no synopsis or credits instead of publishing them
+	BlockIncompleteContent bool `json:"block_incomplete_content,omitempty"`
+	// Concurrency is how many films are processed at once; 0 or 1 processes them one by one
+	Concurrency int `json:"concurrency,omitempty"`
+	// FilmRetries is how many times a film's whole pipeline is retried after a transient failure
+	FilmRetries int `json:"film_retries,omitempty"`
+	// MissingFolderPolicy handles films without a Drive folder link: "warn" (default) creates
+	// the post and warns, "create-empty" creates it silently, "skip" creates no post
+	MissingFolderPolicy string `json:"missing_folder_policy,omitempty"`
+	// DetectMultipleDirectors splits DIRECCIÓN on commas, "+", "y" and "&" when Multi Dir is blank
+	DetectMultipleDirectors bool `json:"detect_multiple_directors,omitempty"`
+	// DriveIgnorePatterns are case-insensitive file name globs never downloaded from Drive;
+	// unset uses a built-in list and an empty list ignores nothing
+	DriveIgnorePatterns []string `json:"drive_ignore_patterns,omitempty"`
+	// DrivePageSize is how many files each Drive folder listing page requests; 0 uses the API default
+	DrivePageSize int `json:"drive_page_size,omitempty"`
+	// DriveID scopes Drive folder listings to a shared drive; empty searches every drive
+	DriveID string `json:"drive_id,omitempty"`
+	// DriveListConcurrency bounds the Drive folder listings running at once across all films;
+	// 0 uses the default of 4 and a negative value removes the limit
+	DriveListConcurrency int `json:"drive_list_concurrency,omitempty"`
+	// GoogleCredentialsEnv names an environment variable holding the service-account JSON and
+	// GoogleCredentialsCommand a shell command printing it (e.g. from a secret manager); both
+	// take precedence over GoogleCredentialsPath, which is only read when neither yields anything
+	GoogleCredentialsEnv     string `json:"google_credentials_env,omitempty"`
+	GoogleCredentialsCommand string `json:"google_credentials_command,omitempty"`
+	// GoogleCredentialsJSON is the service-account JSON resolved by Load
+	GoogleCredentialsJSON []byte `json:"-"`
+	// FallbackImage fills the header and gallery of films that yield no images
+	FallbackImage FallbackImageConfig `json:"fallback_image"`
+}
+
+// FallbackImageConfig selects a placeholder image for films without any images
+type FallbackImageConfig struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// MediaID is an existing WordPress media item; Path a local image uploaded once per run instead
+	MediaID int    `json:"media_id,omitempty"`
+	Path    string `json:"path,omitempty"`
+	// Background and Gallery choose where the fallback is used; when neither is set both are
+	Background bool `json:"background,omitempty"`
+	Gallery    bool `json:"gallery,omitempty"`
 }
 
 type WordPressConfig struct {
@@ -20,12 +78,76 @@ type WordPressConfig struct {
 	Username            string `json:"username"`
 	Password            string `json:"password"`
 	ApplicationPassword string `json:"application_password"`
+	AuthMode            string `json:"auth_mode,omitempty"`
+	// MaxConcurrentRequests bounds simultaneous WordPress API calls across all films; a
+	// negative value removes the limit
+	MaxConcurrentRequests int `json:"max_concurrent_requests,omitempty"`
+	// PostType is the post type films are published as (default project, Divi's projects)
+	PostType string `json:"post_type,omitempty"`
+	// CategoryTaxonomy is the taxonomy slug holding film sections (default project_category)
+	CategoryTaxonomy string `json:"category_taxonomy,omitempty"`
+	// TagTaxonomy is the taxonomy slug holding social tags (default post_tag)
+	TagTaxonomy string `json:"tag_taxonomy,omitempty"`
+	// AuthorID attributes created and updated posts to this user; 0 uses the authenticated user
+	AuthorID int `json:"author_id,omitempty"`
+	// MediaTitleTemplate and MediaAltTemplate format uploaded media with {film}, {name} and {folder};
+	// an empty alt template keeps the alt text derived from the image's role
+	MediaTitleTemplate string `json:"media_title_template,omitempty"`
+	MediaAltTemplate   string `json:"media_alt_template,omitempty"`
+	// SectionCategories maps sheet sections to a category ID or slug; unmapped sections are searched by year
+	SectionCategories map[string]string `json:"section_categories,omitempty"`
+	// SlugTemplate formats project slugs from {title}, {section} and {year}, transliterating
+	// accents; UniqueSlugs appends -2, -3, ... to the slug of a new project when it is taken
+	SlugTemplate string `json:"slug_template,omitempty"`
+	UniqueSlugs  bool   `json:"unique_slugs,omitempty"`
+	// VerifyBuilder re-reads each saved project and warns when its _et_pb_use_builder meta is not "on"
+	VerifyBuilder bool `json:"verify_builder,omitempty"`
+	// CreateMissingCategories creates "<section> <year>" when no category matches a section
+	CreateMissingCategories bool `json:"create_missing_categories,omitempty"`
+	// ReplaceChangedMedia deletes the previous media of an image re-uploaded because its content changed
+	ReplaceChangedMedia bool `json:"replace_changed_media,omitempty"`
+	// MediaLookupRetries retries fetching media uploaded in this run while WordPress still answers 404
+	MediaLookupRetries int `json:"media_lookup_retries,omitempty"`
+	// MaxRetries retries API requests answered with 429 or 5xx, waiting RetryBaseDelay (a Go
+	// duration such as "500ms") doubled per attempt, or the server's Retry-After
+	MaxRetries     int    `json:"max_retries,omitempty"`
+	RetryBaseDelay string `json:"retry_base_delay,omitempty"`
+	// ChunkedUploadEndpoint is a REST route (e.g. "/excentrico/v1/media-chunks") that assembles files
+	// uploaded as ranged parts; files over ChunkedUploadThresholdMB are sent to it in ChunkSizeMB parts
+	ChunkedUploadEndpoint    string `json:"chunked_upload_endpoint,omitempty"`
+	ChunkedUploadThresholdMB int    `json:"chunked_upload_threshold_mb,omitempty"`
+	ChunkSizeMB              int    `json:"chunk_size_mb,omitempty"`
 }
 
 type ImageConfig struct {
 	MaxWidth  int `json:"max_width"`
 	MaxHeight int `json:"max_height"`
 	Quality   int `json:"quality"`
+	// OutputDir is a film subdirectory for optimized images (e.g. "optimized"); empty keeps them next to the originals
+	OutputDir string `json:"output_dir,omitempty"`
+	// ChromaSubsampling is the JPEG chroma subsampling: "4:2:0" (default) or "4:4:4" for text-heavy images
+	ChromaSubsampling string `json:"chroma_subsampling,omitempty"`
+	// MaxMegapixels rejects images whose declared dimensions exceed this many megapixels before decoding them
+	MaxMegapixels float64 `json:"max_megapixels,omitempty"`
+	// MinWidth and MinHeight keep images smaller than this (icons, avatars) out of the gallery; 0 disables the check
+	MinWidth  int `json:"min_width,omitempty"`
+	MinHeight int `json:"min_height,omitempty"`
+	// SkipSmallImages drops images below the minimum entirely instead of only leaving them out of the gallery
+	SkipSmallImages bool `json:"skip_small_images,omitempty"`
+	// ResizeMode is "fit" (default, keeps the aspect ratio), "fill" (crops to the exact size)
+	// or "pad" (letterboxes to the exact size on PadColor)
+	ResizeMode string `json:"resize_mode,omitempty"`
+	// PadColor is the hex background color of "pad" mode (default #000000)
+	PadColor string `json:"pad_color,omitempty"`
+	// OptimizeWorkers is how many images of a film are optimized in parallel; 0 uses the CPU count
+	OptimizeWorkers int `json:"optimize_workers,omitempty"`
+	// Concurrency is an alias of OptimizeWorkers; Load copies whichever of the two is set into both
+	Concurrency int `json:"concurrency,omitempty"`
+	// Format is the encoding of optimized images: "jpeg" (default, _web.jpg), "png" (_web.png) or "webp" (_web.webp)
+	Format string `json:"format,omitempty"`
+	// FormatRules override Format per input, keyed by extension (".png") or MIME type ("image/png"),
+	// e.g. {"image/png": "png"} keeps PNG logos lossless while photos use Format
+	FormatRules map[string]string `json:"format_rules,omitempty"`
 }
 
 type TursoConfig struct {
@@ -33,6 +155,25 @@ type TursoConfig struct {
 	AuthToken   string `json:"auth_token"`
 }
 
+// resolveOptimizeWorkers merges the concurrency alias into optimize_workers. Setting both
+// is accepted only when they agree
+func resolveOptimizeWorkers(image *ImageConfig) error {
+	if image.OptimizeWorkers < 0 || image.Concurrency < 0 {
+		return fmt.Errorf("image optimize_workers and concurrency must not be negative")
+	}
+	if image.OptimizeWorkers != 0 && image.Concurrency != 0 && image.OptimizeWorkers != image.Concurrency {
+		return fmt.Errorf("image concurrency %d conflicts with optimize_workers %d; set only one", image.Concurrency, image.OptimizeWorkers)
+	}
+	if image.OptimizeWorkers == 0 {
+		image.OptimizeWorkers = image.Concurrency
+	}
+	image.Concurrency = image.OptimizeWorkers
+	return nil
+}
+
+// hexColor matches a #rrggbb color
+var hexColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
 func Load() (*Config, error) {
 	var configPath string
 
@@ -71,9 +212,148 @@ func Load() (*Config, error) {
 	if cfg.ImageConfig.Quality == 0 {
 		cfg.ImageConfig.Quality = 85
 	}
+	if cfg.ImageConfig.MaxMegapixels == 0 {
+		cfg.ImageConfig.MaxMegapixels = 100
+	} else if cfg.ImageConfig.MaxMegapixels < 0 {
+		return nil, fmt.Errorf("image max_megapixels must be positive")
+	}
+	switch cfg.ImageConfig.ResizeMode {
+	case "":
+		cfg.ImageConfig.ResizeMode = "fit"
+	case "fit", "fill", "pad":
+	default:
+		return nil, fmt.Errorf("image resize_mode must be one of: fit, fill, pad")
+	}
+	if cfg.ImageConfig.PadColor == "" {
+		cfg.ImageConfig.PadColor = "#000000"
+	} else if !hexColor.MatchString(cfg.ImageConfig.PadColor) {
+		return nil, fmt.Errorf("image pad_color must be a hex color like #1a1a1a")
+	}
+	if cfg.Concurrency < 0 {
+		return nil, fmt.Errorf("concurrency must not be negative")
+	}
+	if err := resolveOptimizeWorkers(&cfg.ImageConfig); err != nil {
+		return nil, err
+	}
+	if cfg.ImageConfig.MinWidth < 0 || cfg.ImageConfig.MinHeight < 0 {
+		return nil, fmt.Errorf("image min_width and min_height must not be negative")
+	}
+	switch cfg.ImageConfig.ChromaSubsampling {
+	case "":
+		cfg.ImageConfig.ChromaSubsampling = "4:2:0"
+	case "4:2:0", "4:4:4":
+	default:
+		return nil, fmt.Errorf("image chroma_subsampling must be one of: 4:2:0, 4:4:4")
+	}
+	switch cfg.ImageConfig.Format {
+	case "":
+		cfg.ImageConfig.Format = "jpeg"
+	case "jpeg", "png", "webp":
+	default:
+		return nil, fmt.Errorf("image format must be one of: jpeg, png, webp")
+	}
+	formatRules, err := normalizeFormatRules(cfg.ImageConfig.FormatRules)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ImageConfig.FormatRules = formatRules
+	if cfg.FallbackImage.Enabled {
+		if cfg.FallbackImage.MediaID <= 0 && cfg.FallbackImage.Path == "" {
+			return nil, fmt.Errorf("fallback_image needs a media_id or a path when enabled")
+		}
+		if cfg.FallbackImage.MediaID <= 0 {
+			if _, err := os.Stat(cfg.FallbackImage.Path); err != nil {
+				return nil, fmt.Errorf("fallback_image path: %v", err)
+			}
+		}
+		if !cfg.FallbackImage.Background && !cfg.FallbackImage.Gallery {
+			cfg.FallbackImage.Background = true
+			cfg.FallbackImage.Gallery = true
+		}
+	}
 	if cfg.GoogleCredentialsPath == "" {
 		cfg.GoogleCredentialsPath = "credentials.json"
 	}
+	if cfg.GoogleCredentialsEnv == "" {
+		cfg.GoogleCredentialsEnv = DefaultGoogleCredentialsEnv
+	}
+	cfg.SheetRange = NormalizeSheetRange(cfg.SheetRange)
+	if len(cfg.PublishedStatusValues) == 0 {
+		cfg.PublishedStatusValues = []string{"published", "publicado"}
+	}
+	switch cfg.MissingFolderPolicy {
+	case "":
+		cfg.MissingFolderPolicy = "warn"
+	case "warn", "create-empty", "skip":
+	default:
+		return nil, fmt.Errorf("missing_folder_policy must be one of: warn, create-empty, skip")
+	}
+	if cfg.FilmRetries < 0 {
+		return nil, fmt.Errorf("film_retries must not be negative")
+	}
+	cfg.PostURLColumn = strings.TrimSpace(cfg.PostURLColumn)
+	if cfg.ImageFolderColumns, err = imageFolderColumns(cfg.ImageFolderColumns, cfg.PostURLColumn); err != nil {
+		return nil, err
+	}
+	if cfg.DriveIgnorePatterns == nil {
+		cfg.DriveIgnorePatterns = []string{"thumbs.db", ".ds_store", "desktop.ini", "._*", "*.pdf"}
+	}
+	if cfg.DrivePageSize < 0 || cfg.DrivePageSize > 1000 {
+		return nil, fmt.Errorf("drive_page_size must be between 0 and 1000")
+	}
+	if cfg.DriveListConcurrency == 0 {
+		cfg.DriveListConcurrency = 4
+	}
+	for _, pattern := range cfg.DriveIgnorePatterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid drive_ignore_patterns entry %q: %v", pattern, err)
+		}
+	}
+	if cfg.WordPressConfig.PostType == "" {
+		cfg.WordPressConfig.PostType = "project"
+	}
+	if cfg.WordPressConfig.CategoryTaxonomy == "" {
+		cfg.WordPressConfig.CategoryTaxonomy = "project_category"
+	}
+	if cfg.WordPressConfig.TagTaxonomy == "" {
+		cfg.WordPressConfig.TagTaxonomy = "post_tag"
+	}
+	if cfg.WordPressConfig.SlugTemplate == "" {
+		cfg.WordPressConfig.SlugTemplate = "{title} {section} {year}"
+	}
+	if cfg.WordPressConfig.MediaTitleTemplate == "" {
+		cfg.WordPressConfig.MediaTitleTemplate = "{film} - {name}"
+	}
+	if cfg.WordPressConfig.MaxConcurrentRequests == 0 {
+		cfg.WordPressConfig.MaxConcurrentRequests = 4
+	}
+	if cfg.WordPressConfig.MediaLookupRetries == 0 {
+		cfg.WordPressConfig.MediaLookupRetries = 3
+	}
+	if cfg.WordPressConfig.MediaLookupRetries < 0 {
+		return nil, fmt.Errorf("wordpress media_lookup_retries must not be negative")
+	}
+	if cfg.WordPressConfig.MaxRetries == 0 {
+		cfg.WordPressConfig.MaxRetries = 3
+	}
+	if cfg.WordPressConfig.MaxRetries < 0 {
+		return nil, fmt.Errorf("wordpress max_retries must not be negative")
+	}
+	if cfg.WordPressConfig.RetryBaseDelay == "" {
+		cfg.WordPressConfig.RetryBaseDelay = "1s"
+	}
+	if delay, err := time.ParseDuration(cfg.WordPressConfig.RetryBaseDelay); err != nil || delay < 0 {
+		return nil, fmt.Errorf("wordpress retry_base_delay must be a duration such as 500ms or 2s")
+	}
+	if cfg.WordPressConfig.ChunkedUploadThresholdMB == 0 {
+		cfg.WordPressConfig.ChunkedUploadThresholdMB = 8
+	}
+	if cfg.WordPressConfig.ChunkSizeMB == 0 {
+		cfg.WordPressConfig.ChunkSizeMB = 2
+	}
+	if cfg.WordPressConfig.ChunkedUploadThresholdMB < 0 || cfg.WordPressConfig.ChunkSizeMB < 0 {
+		return nil, fmt.Errorf("wordpress chunked_upload_threshold_mb and chunk_size_mb must be positive")
+	}
 
 	if cfg.WordPressConfig.BaseURL == "" {
 		return nil, fmt.Errorf("wordpress base_url is required in configuration")
@@ -81,8 +361,19 @@ func Load() (*Config, error) {
 	if cfg.WordPressConfig.Username == "" {
 		return nil, fmt.Errorf("wordpress username is required in configuration")
 	}
-	if cfg.WordPressConfig.Password == "" && cfg.WordPressConfig.ApplicationPassword == "" {
-		return nil, fmt.Errorf("either wordpress password or application_password is required in configuration")
+	switch cfg.WordPressConfig.AuthMode {
+	case "":
+		cfg.WordPressConfig.AuthMode = "basic"
+	case "basic", "cookie", "jwt":
+	default:
+		return nil, fmt.Errorf("wordpress auth_mode must be one of: basic, cookie, jwt")
+	}
+	if cfg.WordPressConfig.AuthMode == "basic" {
+		if cfg.WordPressConfig.Password == "" && cfg.WordPressConfig.ApplicationPassword == "" {
+			return nil, fmt.Errorf("either wordpress password or application_password is required in configuration")
+		}
+	} else if cfg.WordPressConfig.Password == "" {
+		return nil, fmt.Errorf("wordpress password is required when auth_mode is %s", cfg.WordPressConfig.AuthMode)
 	}
 
 	if cfg.TursoConfig.DatabaseURL == "" {
@@ -92,6 +383,41 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("turso auth_token is required in configuration")
 	}
 
+	credentials, err := cfg.loadGoogleCredentials()
+	if err != nil {
+		return nil, err
+	}
+	cfg.GoogleCredentialsJSON = credentials
+
+	return &cfg, nil
+}
+
+// DefaultGoogleCredentialsEnv is the environment variable checked for the service-account
+// JSON when google_credentials_env is unset
+const DefaultGoogleCredentialsEnv = "GOOGLE_CREDENTIALS_JSON"
+
+// loadGoogleCredentials returns the service-account JSON from the credentials environment
+// variable, else the output of the credentials command, else the credentials file, so
+// containerized deployments need not mount a file
+func (cfg *Config) loadGoogleCredentials() ([]byte, error) {
+	if data := strings.TrimSpace(os.Getenv(cfg.GoogleCredentialsEnv)); data != "" {
+		return []byte(data), nil
+	}
+
+	if command := strings.TrimSpace(cfg.GoogleCredentialsCommand); command != "" {
+		var stderr bytes.Buffer
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Stderr = &stderr
+		output, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("google_credentials_command failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		if len(bytes.TrimSpace(output)) == 0 {
+			return nil, fmt.Errorf("google_credentials_command printed no credentials")
+		}
+		return output, nil
+	}
+
 	credentialsPath := cfg.GoogleCredentialsPath
 	if !filepath.IsAbs(credentialsPath) {
 		var err error
@@ -102,12 +428,111 @@ func Load() (*Config, error) {
 	}
 
 	if _, err := os.Stat(credentialsPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("google credentials file not found at %s", credentialsPath)
+		return nil, fmt.Errorf("google credentials file not found at %s (and %s is not set)", credentialsPath, cfg.GoogleCredentialsEnv)
 	}
 
 	cfg.GoogleCredentialsPath = credentialsPath
+	data, err := os.ReadFile(credentialsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials file: %v", err)
+	}
+	return data, nil
+}
 
-	return &cfg, nil
+// DefaultSheetRange is the range films are read from when sheet_range is unset
+const DefaultSheetRange = "TODO!A:ZZ"
+
+// DefaultImageFolderColumns are the sheet columns tried for the Drive image folder link
+// when image_folder_columns is not set
+var DefaultImageFolderColumns = []string{"ENLACES", "Web Excentrico", "Drive"}
+
+// imageFolderColumns returns the image folder columns to use. The post URL column is left
+// out of the defaults, so e.g. "Web Excentrico" can hold the permalink, but naming it in
+// an explicit list is an error: the permalink would overwrite the Drive folder link
+func imageFolderColumns(configured []string, postURLColumn string) ([]string, error) {
+	if len(configured) == 0 {
+		return slices.DeleteFunc(slices.Clone(DefaultImageFolderColumns), func(column string) bool {
+			return column == postURLColumn
+		}), nil
+	}
+	if postURLColumn != "" && slices.Contains(configured, postURLColumn) {
+		return nil, fmt.Errorf("post_url_column %q is one of image_folder_columns; the permalink would overwrite the Drive folder link", postURLColumn)
+	}
+	return configured, nil
+}
+
+// NormalizeSheetRange turns a bare tab name into its full A:ZZ range, quoting it when
+// A1 notation requires it; full ranges are kept as given and empty selects DefaultSheetRange
+func NormalizeSheetRange(sheetRange string) string {
+	sheetRange = strings.TrimSpace(sheetRange)
+	if sheetRange == "" {
+		return DefaultSheetRange
+	}
+	if strings.Contains(sheetRange, "!") {
+		return sheetRange
+	}
+	if !strings.HasPrefix(sheetRange, "'") && !plainTabName.MatchString(sheetRange) {
+		sheetRange = "'" + strings.ReplaceAll(sheetRange, "'", "''") + "'"
+	}
+	return sheetRange + "!A:ZZ"
+}
+
+// SheetTab returns the tab part of an A1 range, as used to address single cells
+func SheetTab(sheetRange string) string {
+	if i := strings.LastIndex(sheetRange, "!"); i >= 0 {
+		return sheetRange[:i]
+	}
+	return sheetRange
+}
+
+// plainTabName matches tab names usable unquoted in A1 notation
+var plainTabName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// formatRuleExtensions maps the MIME types accepted as format_rules keys to the extensions they cover
+var formatRuleExtensions = map[string][]string{
+	"image/jpeg": {".jpg", ".jpeg"},
+	"image/jpg":  {".jpg", ".jpeg"},
+	"image/png":  {".png"},
+	"image/gif":  {".gif"},
+	"image/webp": {".webp"},
+	"image/bmp":  {".bmp"},
+	"image/tiff": {".tif", ".tiff"},
+}
+
+// normalizeFormatRules validates image format rules and keys them by lowercase extension,
+// expanding MIME types to their extensions. An extension rule wins over a MIME type rule
+func normalizeFormatRules(rules map[string]string) (map[string]string, error) {
+	normalized := make(map[string]string, len(rules))
+	explicit := make(map[string]bool)
+	for input, format := range rules {
+		switch format {
+		case "jpeg", "png", "webp":
+		default:
+			return nil, fmt.Errorf("image format_rules %q must map to one of: jpeg, png, webp", input)
+		}
+		key := strings.ToLower(strings.TrimSpace(input))
+		if strings.Contains(key, "/") {
+			extensions, ok := formatRuleExtensions[key]
+			if !ok {
+				return nil, fmt.Errorf("image format_rules has unknown MIME type %q", input)
+			}
+			for _, ext := range extensions {
+				if !explicit[ext] {
+					normalized[ext] = format
+				}
+			}
+			continue
+		}
+		if key == "" || key == "." {
+			return nil, fmt.Errorf("image format_rules has an empty input")
+		}
+		if !strings.HasPrefix(key, ".") {
+			key = "." + key
+		}
+		normalized[key] = format
+		explicit[key] = true
+	}
+	return normalized, nil
 }
 
 func CreateDefaultConfig() error {
@@ -115,15 +540,18 @@ func CreateDefaultConfig() error {
 		GoogleCredentialsPath: "credentials.json",
 		GoogleSheetID:         "",
 		WordPressConfig: WordPressConfig{
-			BaseURL:             "https://your-wordpress-site.com",
-			Username:            "your-username",
-			Password:            "",
-			ApplicationPassword: "your-application-password",
+			BaseURL:               "https://your-wordpress-site.com",
+			Username:              "your-username",
+			Password:              "",
+			ApplicationPassword:   "your-application-password",
+			AuthMode:              "basic",
+			MaxConcurrentRequests: 4,
 		},
 		ImageConfig: ImageConfig{
-			MaxWidth:  1920,
-			MaxHeight: 1080,
-			Quality:   85,
+			MaxWidth:      1920,
+			MaxHeight:     1080,
+			Quality:       85,
+			MaxMegapixels: 100,
 		},
 		TursoConfig: TursoConfig{
 			DatabaseURL: "libsql://your-database-url.turso.io",
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/oauth2/google"
+)
+
+func TestImageFolderColumns(t *testing.T) {
+	tests := []struct {
+		name          string
+		configured    []string
+		postURLColumn string
+		want          []string
+		wantErr       bool
+	}{
+		{"defaults", nil, "", []string{"ENLACES", "Web Excentrico", "Drive"}, false},
+		{"defaults without the post URL column", nil, "Web Excentrico", []string{"ENLACES", "Drive"}, false},
+		{"defaults with another post URL column", nil, "URL", []string{"ENLACES", "Web Excentrico", "Drive"}, false},
+		{"explicit list", []string{"Carpeta"}, "Web Excentrico", []string{"Carpeta"}, false},
+		{"explicit list naming the post URL column", []string{"Carpeta", "Web Excentrico"}, "Web Excentrico", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := imageFolderColumns(tt.configured, tt.postURLColumn)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("imageFolderColumns error %v, want error: %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("imageFolderColumns = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if DefaultImageFolderColumns[1] != "Web Excentrico" {
+		t.Errorf("DefaultImageFolderColumns was modified: %q", DefaultImageFolderColumns)
+	}
+}
+
+func TestLoadGoogleCredentials(t *testing.T) {
+	credentialsJSON := func(projectID string) string {
+		return `{"type": "service_account", "project_id": "` + projectID + `", "client_email": "tools@` + projectID + `.iam.gserviceaccount.com", "private_key": "", "token_uri": "https://oauth2.googleapis.com/token"}`
+	}
+	dir := t.TempDir()
+	credentialsPath := filepath.Join(dir, "credentials.json")
+	if err := os.WriteFile(credentialsPath, []byte(credentialsJSON("from-file")), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		env           string
+		command       string
+		path          string
+		wantProjectID string
+		wantErr       string
+	}{
+		{name: "environment variable", env: credentialsJSON("from-env"), path: credentialsPath, wantProjectID: "from-env"},
+		{name: "environment variable without a file", env: "  " + credentialsJSON("from-env") + "\n", path: filepath.Join(dir, "missing.json"), wantProjectID: "from-env"},
+		{name: "environment variable before the command", env: credentialsJSON("from-env"), command: "echo '" + credentialsJSON("from-command") + "'", wantProjectID: "from-env"},
+		{name: "command", command: "echo '" + credentialsJSON("from-command") + "'", path: credentialsPath, wantProjectID: "from-command"},
+		{name: "failing command", command: "echo denied >&2; exit 3", path: credentialsPath, wantErr: "google_credentials_command failed"},
+		{name: "command printing nothing", command: "true", path: credentialsPath, wantErr: "printed no credentials"},
+		{name: "blank environment variable falls back to the file", env: "  ", path: credentialsPath, wantProjectID: "from-file"},
+		{name: "file", path: credentialsPath, wantProjectID: "from-file"},
+		{name: "missing file", path: filepath.Join(dir, "missing.json"), wantErr: "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("EXCENTRICO_TEST_CREDENTIALS", tt.env)
+			cfg := &Config{
+				GoogleCredentialsPath:    tt.path,
+				GoogleCredentialsEnv:     "EXCENTRICO_TEST_CREDENTIALS",
+				GoogleCredentialsCommand: tt.command,
+			}
+
+			data, err := cfg.loadGoogleCredentials()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("loadGoogleCredentials() error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadGoogleCredentials: %v", err)
+			}
+			// The services build their credentials from the resolved JSON the same way
+			credentials, err := google.CredentialsFromJSON(context.Background(), data, "https://www.googleapis.com/auth/drive")
+			if err != nil {
+				t.Fatalf("CredentialsFromJSON: %v", err)
+			}
+			if credentials.ProjectID != tt.wantProjectID {
+				t.Errorf("credentials of project %q, want %q", credentials.ProjectID, tt.wantProjectID)
+			}
+		})
+	}
+}
+
+func TestNormalizeFormatRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   map[string]string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "none", want: map[string]string{}},
+		{name: "extension", rules: map[string]string{".PNG": "png"}, want: map[string]string{".png": "png"}},
+		{name: "extension without a dot", rules: map[string]string{"png": "png"}, want: map[string]string{".png": "png"}},
+		{name: "MIME type", rules: map[string]string{"image/jpeg": "webp"}, want: map[string]string{".jpg": "webp", ".jpeg": "webp"}},
+		{name: "extension wins over MIME type", rules: map[string]string{"image/jpeg": "webp", ".jpeg": "jpeg"}, want: map[string]string{".jpg": "webp", ".jpeg": "jpeg"}},
+		{name: "unknown format", rules: map[string]string{".png": "gif"}, wantErr: true},
+		{name: "unknown MIME type", rules: map[string]string{"image/avif": "webp"}, wantErr: true},
+		{name: "empty input", rules: map[string]string{" . ": "png"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeFormatRules(tt.rules)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeFormatRules() error %v, want error: %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("normalizeFormatRules() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveOptimizeWorkers(t *testing.T) {
+	tests := []struct {
+		name            string
+		optimizeWorkers int
+		concurrency     int
+		want            int
+		wantErr         bool
+	}{
+		{name: "neither set"},
+		{name: "optimize_workers", optimizeWorkers: 4, want: 4},
+		{name: "concurrency alias", concurrency: 6, want: 6},
+		{name: "both agree", optimizeWorkers: 3, concurrency: 3, want: 3},
+		{name: "both disagree", optimizeWorkers: 3, concurrency: 8, wantErr: true},
+		{name: "negative optimize_workers", optimizeWorkers: -1, wantErr: true},
+		{name: "negative concurrency", concurrency: -2, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			image := ImageConfig{OptimizeWorkers: tt.optimizeWorkers, Concurrency: tt.concurrency}
+			err := resolveOptimizeWorkers(&image)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveOptimizeWorkers() error %v, want error: %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (image.OptimizeWorkers != tt.want || image.Concurrency != tt.want) {
+				t.Errorf("optimize_workers %d and concurrency %d, want both %d", image.OptimizeWorkers, image.Concurrency, tt.want)
+			}
+		})
+	}
+}
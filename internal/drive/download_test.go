@@ -0,0 +1,92 @@
+package drive
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"excentrico-tools-go/internal/models"
+
+	"google.golang.org/api/googleapi"
+)
+
+func TestDownloadFileError(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       *models.FileWithPath
+		blockDest  bool // put a file where the destination folder should be
+		wantErr    bool
+		wantStatus int // Drive API status the error unwraps to; 0 for local failures
+		wantPath   string
+	}{
+		{
+			name: "downloaded",
+			file: &models.FileWithPath{ID: "still-1", Name: "still-1.jpg", FolderPath: "Stills"},
+		},
+		{
+			name:       "missing on Drive",
+			file:       &models.FileWithPath{ID: "gone", Name: "still-9.jpg", FolderName: "Stills", FolderPath: "Stills"},
+			wantErr:    true,
+			wantStatus: http.StatusNotFound,
+			wantPath:   "Stills/still-9.jpg",
+		},
+		{
+			name:       "missing in a nested folder",
+			file:       &models.FileWithPath{ID: "gone", Name: "ana.jpg", FolderName: "Dir", FolderPath: "Extras/Dir"},
+			wantErr:    true,
+			wantStatus: http.StatusNotFound,
+			wantPath:   "Extras/Dir/ana.jpg",
+		},
+		{
+			name:      "destination not writable",
+			file:      &models.FileWithPath{ID: "still-1", Name: "still-1.jpg", FolderName: "Stills", FolderPath: "Stills"},
+			blockDest: true,
+			wantErr:   true,
+			wantPath:  "Stills/still-1.jpg",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fd, driveService := newFakeDrive(t, context.Background())
+			fd.addFile("stills", "still-1", "still-1.jpg")
+			filmDir := t.TempDir()
+			if tt.blockDest {
+				if err := os.WriteFile(filepath.Join(filmDir, "Stills"), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			destination := filepath.Join(filmDir, tt.file.FolderPath, tt.file.Name)
+
+			err := DownloadFile(driveService, tt.file, destination)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("DownloadFile: %v", err)
+				}
+				if data, readErr := os.ReadFile(destination); readErr != nil || string(data) != "still-1.jpg" {
+					t.Errorf("downloaded %q (%v), want the file's content", data, readErr)
+				}
+				return
+			}
+
+			var downloadErr *DriveDownloadError
+			if !errors.As(err, &downloadErr) {
+				t.Fatalf("DownloadFile error %v (%T), want a *DriveDownloadError", err, err)
+			}
+			if downloadErr.FileID != tt.file.ID || downloadErr.FileName != tt.file.Name || downloadErr.FolderPath != tt.file.FolderPath || downloadErr.Path() != tt.wantPath {
+				t.Errorf("error context %+v (path %s), want file %s %s in %s", downloadErr, downloadErr.Path(), tt.file.ID, tt.file.Name, tt.wantPath)
+			}
+			if message := err.Error(); !strings.Contains(message, tt.wantPath) || !strings.Contains(message, tt.file.ID) {
+				t.Errorf("error message %q does not name %s (%s)", message, tt.wantPath, tt.file.ID)
+			}
+
+			var apiErr *googleapi.Error
+			if isAPIErr := errors.As(err, &apiErr); isAPIErr != (tt.wantStatus != 0) || (isAPIErr && apiErr.Code != tt.wantStatus) {
+				t.Errorf("error unwraps to %v, want Drive API status %d", downloadErr.Err, tt.wantStatus)
+			}
+		})
+	}
+}
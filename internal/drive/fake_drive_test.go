@@ -0,0 +1,156 @@
+package drive
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"excentrico-tools-go/internal/services"
+
+	"google.golang.org/api/option"
+)
+
+// fakeItem is a file or folder in a fakeDrive folder
+type fakeItem struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	MimeType string `json:"mimeType"`
+}
+
+// fakeDrive serves the files.list, files.get and download routes of the Drive API from
+// an in-memory folder tree
+type fakeDrive struct {
+	mu        sync.Mutex
+	folders   map[string][]fakeItem // folder ID -> children, in listing order
+	contents  map[string][]byte     // file ID -> content served for downloads
+	failures  map[string][]int      // folder ID -> statuses returned before the listing succeeds
+	lists     map[string]int        // folder ID -> list requests received
+	downloads map[string]int        // file ID -> content downloads served
+	inFlight  int
+	peak      int           // most list requests served at the same time
+	delay     time.Duration // how long each list request takes
+}
+
+// newFakeDrive starts a fake Drive API and returns a GoogleDriveService talking to it
+func newFakeDrive(t *testing.T, ctx context.Context) (*fakeDrive, *services.GoogleDriveService) {
+	t.Helper()
+	fd := &fakeDrive{
+		folders:   make(map[string][]fakeItem),
+		failures:  make(map[string][]int),
+		lists:     make(map[string]int),
+		contents:  make(map[string][]byte),
+		downloads: make(map[string]int),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(fd.serveHTTP))
+	t.Cleanup(srv.Close)
+
+	service, err := services.NewGoogleDriveServiceWithOptions(ctx,
+		option.WithEndpoint(srv.URL+"/drive/v3/"),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatalf("NewGoogleDriveServiceWithOptions: %v", err)
+	}
+	return fd, service
+}
+
+// addFolder adds a subfolder to parent
+func (fd *fakeDrive) addFolder(parent, id, name string) {
+	fd.folders[parent] = append(fd.folders[parent], fakeItem{ID: id, Name: name, MimeType: folderMimeType})
+}
+
+// addFile adds a file to parent, downloading as its own name unless content is set
+func (fd *fakeDrive) addFile(parent, id, name string) {
+	fd.folders[parent] = append(fd.folders[parent], fakeItem{ID: id, Name: name, MimeType: "image/jpeg"})
+	fd.contents[id] = []byte(name)
+}
+
+func (fd *fakeDrive) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if fileID, ok := strings.CutPrefix(r.URL.Path, "/drive/v3/files/"); ok {
+		fd.serveFile(w, r, fileID)
+		return
+	}
+	if r.URL.Path != "/drive/v3/files" {
+		http.NotFound(w, r)
+		return
+	}
+	// The query has the form "'<folder ID>' in parents and trashed=false"
+	q := r.URL.Query().Get("q")
+	folderID := strings.TrimPrefix(q[:strings.Index(q, " in parents")], "'")
+	folderID = strings.TrimSuffix(folderID, "'")
+
+	fd.mu.Lock()
+	fd.lists[folderID]++
+	fd.inFlight++
+	if fd.inFlight > fd.peak {
+		fd.peak = fd.inFlight
+	}
+	var status int
+	if pending := fd.failures[folderID]; len(pending) > 0 {
+		status, fd.failures[folderID] = pending[0], pending[1:]
+	}
+	items := fd.folders[folderID]
+	delay := fd.delay
+	fd.mu.Unlock()
+
+	defer func() {
+		fd.mu.Lock()
+		fd.inFlight--
+		fd.mu.Unlock()
+	}()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	if status != 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": status, "message": http.StatusText(status)}})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"files": items})
+}
+
+// serveFile answers files.get for a file's metadata or, with alt=media, its content
+func (fd *fakeDrive) serveFile(w http.ResponseWriter, r *http.Request, fileID string) {
+	fd.mu.Lock()
+	content, exists := fd.contents[fileID]
+	var item *fakeItem
+	for _, children := range fd.folders {
+		for i := range children {
+			if children[i].ID == fileID {
+				item = &children[i]
+			}
+		}
+	}
+	fd.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if item == nil && !exists {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": http.StatusNotFound, "message": "File not found: " + fileID}})
+		return
+	}
+	if r.URL.Query().Get("alt") == "media" {
+		fd.mu.Lock()
+		fd.downloads[fileID]++
+		fd.mu.Unlock()
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(content)
+		return
+	}
+	json.NewEncoder(w).Encode(item)
+}
+
+// listCount returns how many list requests a folder received
+func (fd *fakeDrive) listCount(folderID string) int {
+	fd.mu.Lock()
+	defer fd.mu.Unlock()
+	return fd.lists[folderID]
+}
@@ -0,0 +1,195 @@
+package drive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"excentrico-tools-go/internal/services"
+
+	"google.golang.org/api/googleapi"
+)
+
+func TestIsTransientDriveError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"rate limited", &googleapi.Error{Code: http.StatusTooManyRequests}, true},
+		{"server error", &googleapi.Error{Code: http.StatusInternalServerError}, true},
+		{"unavailable", fmt.Errorf("failed to list files: %w", &googleapi.Error{Code: http.StatusServiceUnavailable}), true},
+		{"not found", &googleapi.Error{Code: http.StatusNotFound}, false},
+		{"forbidden", &googleapi.Error{Code: http.StatusForbidden}, false},
+		{"network failure", errors.New("connection reset by peer"), true},
+		{"cancelled", fmt.Errorf("failed to list files: %w", context.Canceled), false},
+		{"deadline exceeded", fmt.Errorf("failed to list files: %w", context.DeadlineExceeded), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransientDriveError(tt.err); got != tt.want {
+				t.Errorf("isTransientDriveError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListFilesWithRetry(t *testing.T) {
+	defer func(backoff time.Duration) { listRetryBackoff = backoff }(listRetryBackoff)
+	listRetryBackoff = time.Millisecond
+
+	tests := []struct {
+		name      string
+		failures  []int
+		wantErr   bool
+		wantLists int
+	}{
+		{"succeeds first time", nil, false, 1},
+		{"retries a server error", []int{http.StatusInternalServerError}, false, 2},
+		{"retries rate limits", []int{http.StatusTooManyRequests, http.StatusServiceUnavailable}, false, 3},
+		{"gives up after the last attempt", []int{500, 500, 500, 500}, true, listMaxAttempts},
+		{"does not retry not found", []int{http.StatusNotFound}, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fd, service := newFakeDrive(t, context.Background())
+			fd.addFile("root", "f1", "still.jpg")
+			fd.failures["root"] = tt.failures
+
+			items, err := listFilesWithRetry(service, "root")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("listFilesWithRetry error %v, want error: %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && len(items) != 1 {
+				t.Errorf("listed %d items, want 1", len(items))
+			}
+			if got := fd.listCount("root"); got != tt.wantLists {
+				t.Errorf("%d list requests, want %d", got, tt.wantLists)
+			}
+		})
+	}
+}
+
+func TestListFilesWithRetryStopsWhenCancelled(t *testing.T) {
+	defer func(backoff time.Duration) { listRetryBackoff = backoff }(listRetryBackoff)
+	listRetryBackoff = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	fd, service := newFakeDrive(t, ctx)
+	fd.failures["root"] = []int{http.StatusInternalServerError}
+
+	// The first attempt fails and the retry waits for the backoff; cancelling must end the wait
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := listFilesWithRetry(service, "root")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("listFilesWithRetry error %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("listFilesWithRetry returned after %v, want it to stop when cancelled", elapsed)
+	}
+	if got := fd.listCount("root"); got != 1 {
+		t.Errorf("%d list requests, want 1", got)
+	}
+
+	// An already cancelled context is not retried
+	fd.failures["root"] = nil
+	if _, err := listFilesWithRetry(service, "root"); err == nil {
+		t.Error("listFilesWithRetry succeeded with a cancelled context")
+	}
+	if got := fd.listCount("root"); got > 2 {
+		t.Errorf("%d list requests after cancelling, want at most 2", got)
+	}
+}
+
+// buildTree fills fd with nested folders, each holding files and further subfolders
+func buildTree(fd *fakeDrive, parent string, depth int) {
+	for i := 0; i < 3; i++ {
+		fd.addFile(parent, fmt.Sprintf("%s-file%d", parent, i), fmt.Sprintf("still%d.jpg", i))
+		if depth > 0 {
+			folder := fmt.Sprintf("%s-dir%d", parent, i)
+			fd.addFolder(parent, folder, fmt.Sprintf("Dir %d", i))
+			buildTree(fd, folder, depth-1)
+		}
+	}
+}
+
+func TestListAllFilesRecursivelyConcurrency(t *testing.T) {
+	// A sequential walk, one listing at a time, is the reference result
+	sequentialDrive, sequential := newFakeDrive(t, context.Background())
+	buildTree(sequentialDrive, "root", 3)
+	sequential.SetListConcurrency(1)
+	want, wantErrs, err := ListAllFilesRecursively(sequential, "root")
+	if err != nil || len(wantErrs) != 0 {
+		t.Fatalf("sequential listing: %v %v", err, wantErrs)
+	}
+	if sequentialDrive.peak != 1 {
+		t.Fatalf("sequential listing ran %d listings at once", sequentialDrive.peak)
+	}
+
+	tests := []struct {
+		name     string
+		limit    int
+		wantPeak int // most listings expected at once; 0 skips the check
+	}{
+		{"two at once", 2, 2},
+		{"default", services.DefaultListConcurrency, services.DefaultListConcurrency},
+		{"unlimited", -1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fd, service := newFakeDrive(t, context.Background())
+			buildTree(fd, "root", 3)
+			fd.delay = 5 * time.Millisecond
+			service.SetListConcurrency(tt.limit)
+
+			got, errs, err := ListAllFilesRecursively(service, "root")
+			if err != nil || len(errs) != 0 {
+				t.Fatalf("listing: %v %v", err, errs)
+			}
+			if len(got) != len(want) {
+				t.Fatalf("listed %d files, want %d", len(got), len(want))
+			}
+			for i := range want {
+				if got[i].ID != want[i].ID || got[i].FolderPath != want[i].FolderPath || got[i].FolderName != want[i].FolderName {
+					t.Fatalf("file %d is %s in %q, want %s in %q", i, got[i].ID, got[i].FolderPath, want[i].ID, want[i].FolderPath)
+				}
+			}
+			if tt.wantPeak > 0 && fd.peak > tt.wantPeak {
+				t.Errorf("%d listings ran at once, want at most %d", fd.peak, tt.wantPeak)
+			}
+			if fd.peak < 2 {
+				t.Errorf("%d listings ran at once, want subfolders listed in parallel", fd.peak)
+			}
+		})
+	}
+}
+
+func TestListAllFilesRecursivelySharesLimit(t *testing.T) {
+	// Several films listing at once share the service's bound
+	fd, service := newFakeDrive(t, context.Background())
+	for i := 0; i < 3; i++ {
+		buildTree(fd, fmt.Sprintf("film%d", i), 2)
+	}
+	fd.delay = 5 * time.Millisecond
+	service.SetListConcurrency(2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(folderID string) {
+			defer wg.Done()
+			if _, _, err := ListAllFilesRecursively(service, folderID); err != nil {
+				t.Errorf("listing %s: %v", folderID, err)
+			}
+		}(fmt.Sprintf("film%d", i))
+	}
+	wg.Wait()
+	if fd.peak > 2 {
+		t.Errorf("%d listings ran at once across films, want at most 2", fd.peak)
+	}
+}
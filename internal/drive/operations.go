@@ -1,42 +1,114 @@
 package drive
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"excentrico-tools-go/internal/debug"
 	"excentrico-tools-go/internal/logger"
 	"excentrico-tools-go/internal/models"
 	"excentrico-tools-go/internal/services"
 	"excentrico-tools-go/internal/utils"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
 )
 
+// Retry settings for Drive folder listing
+const listMaxAttempts = 3
+
+// listRetryBackoff is the wait before the first retry of a folder listing; it doubles per attempt
+var listRetryBackoff = 500 * time.Millisecond
+
+// ListingError records a subfolder whose contents could not be listed
+type ListingError struct {
+	FolderID   string
+	FolderPath string
+	Err        error
+}
+
+func (e *ListingError) Error() string {
+	return fmt.Sprintf("failed to list subfolder '%s' (%s): %v", e.FolderPath, e.FolderID, e.Err)
+}
+
+func (e *ListingError) Unwrap() error {
+	return e.Err
+}
+
+// folderMimeType is the MIME type Drive reports for folders
+const folderMimeType = "application/vnd.google-apps.folder"
+
+// singleFileFolder is the folder an image linked directly, rather than through a
+// folder, is filed under, so it is downloaded and shown as a gallery still
+const singleFileFolder = "Stills"
+
+// ListLinkedFiles lists the files behind an image link: the whole tree for a folder
+// link, or just the linked file, filed under Stills, for a link to a single image
+func ListLinkedFiles(driveService *services.GoogleDriveService, linkID string) ([]*models.FileWithPath, []error, error) {
+	item, err := driveService.GetFile(linkID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if item.MimeType == folderMimeType {
+		return ListAllFilesRecursively(driveService, linkID)
+	}
+
+	debug.Printf("Link %s points to a single file: %s (type: %s)", linkID, item.Name, item.MimeType)
+	return []*models.FileWithPath{{
+		ID:           item.Id,
+		Name:         item.Name,
+		MimeType:     item.MimeType,
+		Size:         fmt.Sprintf("%d", item.Size),
+		CreatedTime:  item.CreatedTime,
+		ModifiedTime: item.ModifiedTime,
+		FolderPath:   singleFileFolder,
+		FolderName:   singleFileFolder,
+	}}, nil, nil
+}
+
 // ListAllFilesRecursively lists all files in a folder and its subfolders
-func ListAllFilesRecursively(driveService *services.GoogleDriveService, folderID string) ([]*models.FileWithPath, error) {
+// Subfolders that could not be listed are returned as ListingErrors alongside the files found
+func ListAllFilesRecursively(driveService *services.GoogleDriveService, folderID string) ([]*models.FileWithPath, []error, error) {
 	return ListAllFilesRecursivelyWithPath(driveService, folderID, "")
 }
 
-// ListAllFilesRecursivelyWithPath lists all files recursively with the current path context
-func ListAllFilesRecursivelyWithPath(driveService *services.GoogleDriveService, folderID string, currentPath string) ([]*models.FileWithPath, error) {
-	var allFiles []*models.FileWithPath
+// listResult holds what was found under one folder item, in walk order
+type listResult struct {
+	files []*models.FileWithPath
+	errs  []error
+}
 
+// ListAllFilesRecursivelyWithPath lists all files recursively with the current path context
+// Sibling subfolders are listed in parallel, bounded by the Drive service's list concurrency;
+// results keep the order of a sequential walk. An error is returned only when
+// folderID itself cannot be listed; failed subfolders are collected instead
+func ListAllFilesRecursivelyWithPath(driveService *services.GoogleDriveService, folderID string, currentPath string) ([]*models.FileWithPath, []error, error) {
 	debug.Printf("Listing contents of folder ID: %s (path: %s)", folderID, currentPath)
 
-	items, err := driveService.ListFiles(folderID)
+	// ListFiles holds a list slot only while it runs, so waiting on subfolders never blocks other listings
+	items, err := listFilesWithRetry(driveService, folderID)
 	if err != nil {
 		debug.Printf("Error listing folder %s: %v", folderID, err)
-		return nil, err
+		return nil, nil, err
 	}
 
 	debug.Printf("Found %d items in folder %s", len(items), folderID)
 
+	results := make([]listResult, len(items))
+	var wg sync.WaitGroup
+
 	for i, item := range items {
 		debug.Printf("Item %d: %s (type: %s)", i+1, item.Name, item.MimeType)
 
-		if item.MimeType == "application/vnd.google-apps.folder" {
+		if item.MimeType == folderMimeType {
 			debug.Printf("Processing subfolder: %s", item.Name)
 			var newPath string
 			if currentPath == "" {
@@ -45,13 +117,18 @@ func ListAllFilesRecursivelyWithPath(driveService *services.GoogleDriveService,
 				newPath = currentPath + "/" + item.Name
 			}
 
-			subFiles, err := ListAllFilesRecursivelyWithPath(driveService, item.Id, newPath)
-			if err != nil {
-				log.Printf("Warning: Failed to list files in subfolder '%s': %v", item.Name, err)
-				continue
-			}
-			debug.Printf("Found %d files in subfolder '%s'", len(subFiles), item.Name)
-			allFiles = append(allFiles, subFiles...)
+			wg.Add(1)
+			go func(i int, subfolderID, subfolderName, newPath string) {
+				defer wg.Done()
+				subFiles, subErrs, err := ListAllFilesRecursivelyWithPath(driveService, subfolderID, newPath)
+				if err != nil {
+					debug.Printf("Failed to list files in subfolder '%s': %v", subfolderName, err)
+					results[i].errs = []error{&ListingError{FolderID: subfolderID, FolderPath: newPath, Err: err}}
+					return
+				}
+				debug.Printf("Found %d files in subfolder '%s'", len(subFiles), subfolderName)
+				results[i] = listResult{files: subFiles, errs: subErrs}
+			}(i, item.Id, item.Name, newPath)
 		} else {
 			debug.Printf("Adding file: %s (path: %s)", item.Name, currentPath)
 
@@ -71,40 +148,145 @@ func ListAllFilesRecursivelyWithPath(driveService *services.GoogleDriveService,
 				FolderPath:   currentPath,
 				FolderName:   folderName,
 			}
-			allFiles = append(allFiles, fileWithPath)
+			results[i].files = []*models.FileWithPath{fileWithPath}
 		}
 	}
 
+	wg.Wait()
+
+	var allFiles []*models.FileWithPath
+	var listingErrs []error
+	for _, result := range results {
+		allFiles = append(allFiles, result.files...)
+		listingErrs = append(listingErrs, result.errs...)
+	}
+
 	debug.Printf("Returning %d total files from folder %s", len(allFiles), folderID)
-	return allFiles, nil
+	return allFiles, listingErrs, nil
+}
+
+// listFilesWithRetry lists a folder, retrying transient errors with exponential backoff.
+// It stops as soon as the service's context ends
+func listFilesWithRetry(driveService *services.GoogleDriveService, folderID string) ([]*drive.File, error) {
+	ctx := driveService.Context()
+	var lastErr error
+	for attempt := 1; attempt <= listMaxAttempts; attempt++ {
+		items, err := driveService.ListFiles(folderID)
+		if err == nil {
+			return items, nil
+		}
+		lastErr = err
+
+		if !isTransientDriveError(err) || attempt == listMaxAttempts || ctx.Err() != nil {
+			break
+		}
+
+		backoff := listRetryBackoff * time.Duration(1<<(attempt-1))
+		debug.Printf("Transient error listing folder %s (attempt %d/%d), retrying in %v: %v", folderID, attempt, listMaxAttempts, backoff, err)
+		if err := services.SleepContext(ctx, backoff); err != nil {
+			return nil, fmt.Errorf("listing folder %s interrupted: %w", folderID, err)
+		}
+	}
+	return nil, lastErr
+}
+
+// isTransientDriveError reports whether a Drive API error is worth retrying
+// Rate limits and server errors are transient; other API errors (not found, forbidden) are not,
+// and neither is a cancelled or expired context
+func isTransientDriveError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= http.StatusInternalServerError
+	}
+	// Network-level failures carry no API status and are retried
+	return true
+}
+
+// keepFilesFromFailedFolders returns the previously recorded files that live under
+// a subfolder which could not be listed this run, so they are not dropped from metadata
+func keepFilesFromFailedFolders(existingFiles []*models.FileWithPath, listingErrs []error) []*models.FileWithPath {
+	var kept []*models.FileWithPath
+	for _, fileInfo := range existingFiles {
+		for _, listingErr := range listingErrs {
+			var le *ListingError
+			if !errors.As(listingErr, &le) {
+				continue
+			}
+			if fileInfo.FolderPath == le.FolderPath || strings.HasPrefix(fileInfo.FolderPath, le.FolderPath+"/") {
+				kept = append(kept, fileInfo)
+				break
+			}
+		}
+	}
+	return kept
+}
+
+// DriveDownloadError records a Drive file that could not be downloaded
+type DriveDownloadError struct {
+	FileID     string
+	FileName   string
+	FolderPath string
+	Err        error
+}
+
+func (e *DriveDownloadError) Error() string {
+	return fmt.Sprintf("download of '%s' (%s) failed: %v", e.Path(), e.FileID, e.Err)
+}
+
+func (e *DriveDownloadError) Unwrap() error {
+	return e.Err
+}
+
+// Path returns the file's path relative to the film's Drive folder
+func (e *DriveDownloadError) Path() string {
+	if e.FolderPath == "" {
+		return e.FileName
+	}
+	return e.FolderPath + "/" + e.FileName
 }
 
 // DownloadFile downloads a file from Google Drive to the specified destination
-func DownloadFile(driveService *services.GoogleDriveService, fileID, destinationPath string) error {
+// Failures are returned as a *DriveDownloadError carrying the file's Drive context
+func DownloadFile(driveService *services.GoogleDriveService, fileInfo *models.FileWithPath, destinationPath string) error {
 	l := logger.Get()
 	op := l.StartOperation("download_drive_file")
-	op.WithDrive("", fileID, filepath.Base(destinationPath))
+	op.WithDrive("", fileInfo.ID, fileInfo.Name)
+	op.WithContext("folder_path", fileInfo.FolderPath)
 	op.WithContext("destination_path", destinationPath)
 
-	err := driveService.DownloadFile(fileID, destinationPath)
+	err := driveService.DownloadFile(fileInfo.ID, destinationPath)
 	if err != nil {
 		op.Fail("Failed to download file from Drive", err)
-		return fmt.Errorf("download failed: %v", err)
+		return &DriveDownloadError{FileID: fileInfo.ID, FileName: fileInfo.Name, FolderPath: fileInfo.FolderPath, Err: err}
 	}
 
 	op.Complete("Successfully downloaded file from Drive")
 	return nil
 }
 
+// isIgnoredFile reports whether a file name matches one of the ignore globs, case-insensitively
+func isIgnoredFile(name string, patterns []string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(strings.ToLower(pattern), name); matched {
+			return true
+		}
+	}
+	return false
+}
+
 // isAllowedFolder checks if a folder name matches one of the allowed folders
-// Allowed folders: Background, Featured Image, Stills, Dir
+// Allowed folders: Background, Featured Image, Poster(s), Stills, Dir
 // Uses lowercase comparison for better matching
 func isAllowedFolder(folderName string) bool {
 	if folderName == "" {
 		return false
 	}
 
-	allowedFolders := []string{"background", "featured image", "stills", "dir"}
+	allowedFolders := []string{"background", "featured image", "poster", "posters", "stills", "dir"}
 	folderLower := strings.ToLower(strings.TrimSpace(folderName))
 
 	for _, allowed := range allowedFolders {
@@ -116,12 +298,39 @@ func isAllowedFolder(folderName string) bool {
 	return false
 }
 
+// ClearDownloads deletes a film's downloaded originals and their optimized versions and
+// clears its recorded Drive metadata, so the next ProcessGoogleDriveFiles fetches every
+// file fresh. It returns the number of local files removed
+func ClearDownloads(filmDir string, imageService *services.ImageService, tursoService *services.TursoService, filmID string) (int, error) {
+	var existingFiles []*models.FileWithPath
+	if err := tursoService.GetDriveFilesMetadata(filmID, &existingFiles); err != nil && !errors.Is(err, services.ErrMetadataNotFound) {
+		return 0, fmt.Errorf("failed to load existing Drive metadata: %v", err)
+	}
+
+	removed := 0
+	for _, fileInfo := range existingFiles {
+		originalPath := filepath.Join(filmDir, fileInfo.FolderPath, fileInfo.Name)
+		for _, path := range []string{originalPath, imageService.OptimizedPath(filmDir, originalPath)} {
+			if err := os.Remove(path); err == nil {
+				removed++
+			} else if !os.IsNotExist(err) {
+				return removed, fmt.Errorf("failed to remove %s: %v", path, err)
+			}
+		}
+	}
+
+	if err := tursoService.DeleteDriveFilesMetadata(filmID); err != nil {
+		return removed, err
+	}
+	return removed, nil
+}
+
 // ProcessGoogleDriveFiles processes all files from a Google Drive folder
-func ProcessGoogleDriveFiles(filmDir string, driveService *services.GoogleDriveService, imageService *services.ImageService, tursoService *services.TursoService, filmName string, enlacesStr string) error {
+// Files that fail to download do not abort processing; they are returned alongside a nil error
+func ProcessGoogleDriveFiles(filmDir string, driveService *services.GoogleDriveService, imageService *services.ImageService, tursoService *services.TursoService, filmID string, filmName string, enlacesStr string, ignorePatterns []string) ([]*DriveDownloadError, error) {
 	l := logger.Get()
 	op := l.StartOperation("process_drive_files")
 	
-	filmID := utils.SanitizeFilename(filmName)
 	op.WithFilm(filmID, filmName, "", "")
 	op.WithContext("enlaces_url", enlacesStr)
 	op.WithContext("film_dir", filmDir)
@@ -129,15 +338,27 @@ func ProcessGoogleDriveFiles(filmDir string, driveService *services.GoogleDriveS
 	folderID := utils.ExtractFileIDFromURL(enlacesStr)
 	if folderID == "" {
 		op.Fail("Could not extract folder ID from ENLACES URL", fmt.Errorf("invalid URL format"))
-		return fmt.Errorf("could not extract folder ID from ENLACES URL")
+		return nil, fmt.Errorf("could not extract folder ID from ENLACES URL")
 	}
 
 	op.WithDrive(folderID, "", "")
 
-	allFiles, err := ListAllFilesRecursively(driveService, folderID)
+	allFiles, listingErrs, err := ListLinkedFiles(driveService, folderID)
 	if err != nil {
 		op.Fail("Failed to list files recursively in folder", err)
-		return fmt.Errorf("failed to list files recursively in folder: %v", err)
+		return nil, fmt.Errorf("failed to list files recursively in folder: %w", err)
+	}
+
+	if len(listingErrs) > 0 {
+		failedFolders := make([]string, 0, len(listingErrs))
+		for _, listingErr := range listingErrs {
+			failedFolders = append(failedFolders, listingErr.Error())
+		}
+		op.WithContext("listing_incomplete", true)
+		op.WithContext("failed_subfolders", failedFolders)
+		op.Warn(&logger.WideEvent{
+			Message: fmt.Sprintf("Drive listing incomplete: %d subfolder(s) could not be listed", len(listingErrs)),
+		})
 	}
 
 	var imageFileCount int
@@ -154,7 +375,12 @@ func ProcessGoogleDriveFiles(filmDir string, driveService *services.GoogleDriveS
 	// Filter files to only include images from allowed folders
 	var filteredFiles []*models.FileWithPath
 	skippedCount := 0
+	ignoredCount := 0
 	for _, fileInfo := range allFiles {
+		if isIgnoredFile(fileInfo.Name, ignorePatterns) {
+			ignoredCount++
+			continue
+		}
 		if !utils.IsImageFile(fileInfo.MimeType) {
 			continue
 		}
@@ -168,6 +394,7 @@ func ProcessGoogleDriveFiles(filmDir string, driveService *services.GoogleDriveS
 
 	op.WithContext("filtered_files", len(filteredFiles))
 	op.WithContext("skipped_files", skippedCount)
+	op.WithContext("ignored_files", ignoredCount)
 
 	var existingFiles []*models.FileWithPath
 	var filesToDownload []*models.FileWithPath
@@ -177,7 +404,7 @@ func ProcessGoogleDriveFiles(filmDir string, driveService *services.GoogleDriveS
 
 	err = tursoService.GetDriveFilesMetadata(filmID, &existingFiles)
 	if err != nil {
-		if strings.Contains(err.Error(), "metadata not found") {
+		if errors.Is(err, services.ErrMetadataNotFound) {
 			op.WithContext("existing_metadata", false)
 		} else {
 			op.Warn(&logger.WideEvent{
@@ -231,11 +458,13 @@ func ProcessGoogleDriveFiles(filmDir string, driveService *services.GoogleDriveS
 
 	downloadedCount := 0
 	failedDownloads := 0
+	var downloadErrs []*DriveDownloadError
 	for _, fileInfo := range filesToDownload {
 		var filePath string
 		if fileInfo.FolderPath != "" {
 			subDir := filepath.Join(filmDir, fileInfo.FolderPath)
 			if err := os.MkdirAll(subDir, 0755); err != nil {
+				downloadErrs = append(downloadErrs, &DriveDownloadError{FileID: fileInfo.ID, FileName: fileInfo.Name, FolderPath: fileInfo.FolderPath, Err: err})
 				failedDownloads++
 				continue
 			}
@@ -250,8 +479,12 @@ func ProcessGoogleDriveFiles(filmDir string, driveService *services.GoogleDriveS
 		downloadOp.WithContext("mime_type", fileInfo.MimeType)
 		downloadOp.WithContext("file_path", filePath)
 		
-		if err := DownloadFile(driveService, fileInfo.ID, filePath); err != nil {
+		if err := DownloadFile(driveService, fileInfo, filePath); err != nil {
 			downloadOp.Fail(fmt.Sprintf("Failed to download %s", fileInfo.Name), err)
+			var downloadErr *DriveDownloadError
+			if errors.As(err, &downloadErr) {
+				downloadErrs = append(downloadErrs, downloadErr)
+			}
 			failedDownloads++
 			continue
 		}
@@ -261,13 +494,31 @@ func ProcessGoogleDriveFiles(filmDir string, driveService *services.GoogleDriveS
 
 	op.WithContext("downloaded_count", downloadedCount)
 	op.WithContext("failed_downloads", failedDownloads)
+	if len(downloadErrs) > 0 {
+		failedFiles := make([]string, 0, len(downloadErrs))
+		for _, downloadErr := range downloadErrs {
+			failedFiles = append(failedFiles, downloadErr.Path())
+		}
+		op.WithContext("failed_download_files", failedFiles)
+	}
 
 	optimizeOp := l.StartOperation("optimize_images")
 	optimizeOp.WithFilm(filmID, filmName, "", "")
 	processedCount := 0
 	failedOptimizations := 0
+	filteredSmall := 0
 
-	for _, fileInfo := range filteredFiles {
+	// Images optimized on earlier runs keep the size flag recorded then
+	belowMinimum := make(map[string]bool)
+	for _, fileInfo := range existingFiles {
+		if fileInfo.BelowMinimum {
+			belowMinimum[fileInfo.ID] = true
+		}
+	}
+
+	// Images are optimized in parallel; the counters and size flags are shared under optimizeMu
+	var optimizeMu sync.Mutex
+	optimize := func(fileInfo *models.FileWithPath) {
 		var originalPath string
 		if fileInfo.FolderPath != "" {
 			originalPath = filepath.Join(filmDir, fileInfo.FolderPath, fileInfo.Name)
@@ -275,48 +526,91 @@ func ProcessGoogleDriveFiles(filmDir string, driveService *services.GoogleDriveS
 			originalPath = filepath.Join(filmDir, fileInfo.Name)
 		}
 
-		if _, err := os.Stat(originalPath); err == nil {
-			optimizedPath := utils.GetOptimizedImagePath(originalPath)
-
-			if _, err := os.Stat(optimizedPath); os.IsNotExist(err) {
-				imgOp := l.StartOperation("optimize_single_image")
-				imgOp.WithFilm(filmID, filmName, "", "")
-				imgOp.WithDrive(folderID, fileInfo.ID, fileInfo.Name)
-				
-				if err := imageService.ResizeImage(originalPath, optimizedPath); err != nil {
-					imgOp.Fail(fmt.Sprintf("Failed to optimize image %s", fileInfo.Name), err)
-					failedOptimizations++
-					continue
-				}
+		if _, err := os.Stat(originalPath); err != nil {
+			return
+		}
+		optimizedPath := imageService.OptimizedPath(filmDir, originalPath)
+		if _, err := os.Stat(optimizedPath); !os.IsNotExist(err) {
+			return
+		}
 
-				if originalInfo, err := os.Stat(originalPath); err == nil {
-					if optimizedInfo, err := os.Stat(optimizedPath); err == nil {
-						originalSize := originalInfo.Size()
-						optimizedSize := optimizedInfo.Size()
-						
-						imgOp.WithImage(originalPath, optimizedPath, originalSize, optimizedSize)
-						imgOp.Complete(fmt.Sprintf("Optimized image '%s'", fileInfo.Name))
-					}
-				}
+		imgOp := l.StartOperation("optimize_single_image")
+		imgOp.WithFilm(filmID, filmName, "", "")
+		imgOp.WithDrive(folderID, fileInfo.ID, fileInfo.Name)
+
+		result, err := imageService.ResizeImage(originalPath, optimizedPath)
+		if err != nil {
+			imgOp.Fail(fmt.Sprintf("Failed to optimize image %s", fileInfo.Name), err)
+			optimizeMu.Lock()
+			failedOptimizations++
+			optimizeMu.Unlock()
+			return
+		}
 
-				processedCount++
+		small := result.BelowMinimum
+		optimizeMu.Lock()
+		belowMinimum[fileInfo.ID] = small
+		if small {
+			filteredSmall++
+		}
+		optimizeMu.Unlock()
+		if small {
+			imgOp.WithContext("below_minimum", true)
+			if imageService.SkipsSmallImages() {
+				imgOp.Complete(fmt.Sprintf("Filtered image '%s': below the minimum dimensions", fileInfo.Name))
+				return
 			}
 		}
+
+		imgOp.WithImage(originalPath, optimizedPath, result.OriginalSize, result.OptimizedSize)
+		imgOp.WithContext("image_width", result.Width)
+		imgOp.WithContext("image_height", result.Height)
+		imgOp.Complete(fmt.Sprintf("Optimized image '%s'", fileInfo.Name))
+
+		optimizeMu.Lock()
+		processedCount++
+		optimizeMu.Unlock()
 	}
 
+	workers := imageService.Workers()
+	optimizeOp.WithContext("workers", workers)
+	slots := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for _, fileInfo := range filteredFiles {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(fileInfo *models.FileWithPath) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			optimize(fileInfo)
+		}(fileInfo)
+	}
+	wg.Wait()
+
 	optimizeOp.WithContext("processed_count", processedCount)
 	optimizeOp.WithContext("failed_optimizations", failedOptimizations)
+	optimizeOp.WithContext("filtered_small_images", filteredSmall)
 	optimizeOp.Complete(fmt.Sprintf("Image optimization completed: %d images processed", processedCount))
 
 	// Only save metadata for filtered files (from allowed folders)
 	var imageFiles []*models.FileWithPath
 	for _, fileInfo := range filteredFiles {
+		fileInfo.BelowMinimum = belowMinimum[fileInfo.ID]
 		imageFiles = append(imageFiles, fileInfo)
 	}
+	if len(listingErrs) > 0 {
+		// Files under subfolders that failed to list are unknown this run; keep their previous records
+		imageFiles = append(imageFiles, keepFilesFromFailedFolders(existingFiles, listingErrs)...)
+	}
 
 	if err := tursoService.SaveDriveFilesMetadata(filmID, imageFiles); err != nil {
-		op.Fail("Failed to save Drive media metadata", err)
-		return fmt.Errorf("failed to save Drive media metadata: %v", err)
+		if errors.Is(err, services.ErrEmptyMetadataOverwrite) {
+			// Keep the previously recorded files rather than wiping them
+			op.WithContext("drive_metadata_preserved", true)
+		} else {
+			op.Fail("Failed to save Drive media metadata", err)
+			return nil, fmt.Errorf("failed to save Drive media metadata: %v", err)
+		}
 	}
 
 	if len(imageFiles) == 0 {
@@ -327,5 +621,5 @@ func ProcessGoogleDriveFiles(filmDir string, driveService *services.GoogleDriveS
 
 	op.WithCounts(len(imageFiles), len(imageFiles), downloadedCount, skippedCount, 0, processedCount)
 	op.Complete(fmt.Sprintf("Successfully processed Drive files for '%s'", filmName))
-	return nil
+	return downloadErrs, nil
 }
@@ -0,0 +1,406 @@
+package drive
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+
+	"excentrico-tools-go/internal/config"
+	"excentrico-tools-go/internal/logger"
+	"excentrico-tools-go/internal/models"
+	"excentrico-tools-go/internal/services"
+	"excentrico-tools-go/internal/testutil/memsql"
+)
+
+func TestIsAllowedFolder(t *testing.T) {
+	tests := []struct {
+		folder string
+		want   bool
+	}{
+		{"Background", true},
+		{"Featured Image", true},
+		{"Poster", true},
+		{"Posters", true},
+		{" posters ", true},
+		{"Stills", true},
+		{"Dir", true},
+		{"Press", false},
+		{"Poster Drafts", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.folder, func(t *testing.T) {
+			if got := isAllowedFolder(tt.folder); got != tt.want {
+				t.Errorf("isAllowedFolder(%q) = %v, want %v", tt.folder, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClearDownloads(t *testing.T) {
+	var still bytes.Buffer
+	if err := png.Encode(&still, image.NewRGBA(image.Rect(0, 0, 32, 24))); err != nil {
+		t.Fatal(err)
+	}
+	const folderURL = "https://drive.google.com/drive/folders/root"
+
+	tests := []struct {
+		name          string
+		redownload    bool
+		wantRemoved   int
+		wantDownloads int // downloads per file after both runs
+	}{
+		{name: "kept downloads are reused", wantDownloads: 1},
+		{name: "redownload fetches every file again", redownload: true, wantRemoved: 4, wantDownloads: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fd, driveService := newFakeDrive(t, context.Background())
+			fd.addFolder("", "root", "Film")
+			fd.addFolder("root", "stills", "Stills")
+			fd.addFile("stills", "still-1", "still-1.jpg")
+			fd.addFile("stills", "still-2", "still-2.jpg")
+			fd.contents["still-1"] = still.Bytes()
+			fd.contents["still-2"] = still.Bytes()
+
+			db, err := memsql.Open()
+			if err != nil {
+				t.Fatalf("opening in-memory database: %v", err)
+			}
+			turso, err := services.NewTursoServiceWithDB(db)
+			if err != nil {
+				t.Fatalf("NewTursoServiceWithDB: %v", err)
+			}
+			t.Cleanup(func() { turso.Close() })
+			imageService := services.NewImageService(config.ImageConfig{MaxWidth: 1920, MaxHeight: 1080, Quality: 85})
+			otherFilm := []*models.FileWithPath{{ID: "poster-1", Name: "poster.jpg", FolderName: "Poster", FolderPath: "Poster"}}
+			if err := turso.SaveDriveFilesMetadata("film-2", otherFilm); err != nil {
+				t.Fatal(err)
+			}
+
+			filmDir := t.TempDir()
+			if _, err := ProcessGoogleDriveFiles(filmDir, driveService, imageService, turso, "film-1", "Film", folderURL, nil); err != nil {
+				t.Fatalf("first ProcessGoogleDriveFiles: %v", err)
+			}
+
+			if tt.redownload {
+				removed, err := ClearDownloads(filmDir, imageService, turso, "film-1")
+				if err != nil {
+					t.Fatalf("ClearDownloads: %v", err)
+				}
+				if removed != tt.wantRemoved {
+					t.Errorf("removed %d local files, want %d", removed, tt.wantRemoved)
+				}
+				var files []*models.FileWithPath
+				if err := turso.GetDriveFilesMetadata("film-1", &files); !errors.Is(err, services.ErrMetadataNotFound) {
+					t.Errorf("Drive metadata after clearing: %v, want ErrMetadataNotFound", err)
+				}
+				if err := turso.GetDriveFilesMetadata("film-2", &files); err != nil || len(files) != 1 {
+					t.Errorf("other film's Drive metadata = %d files, %v; want it kept", len(files), err)
+				}
+				for _, name := range []string{"still-1.jpg", "still-2.jpg"} {
+					original := filepath.Join(filmDir, "Stills", name)
+					for _, path := range []string{original, imageService.OptimizedPath(filmDir, original)} {
+						if _, err := os.Stat(path); !os.IsNotExist(err) {
+							t.Errorf("%s still exists after clearing", path)
+						}
+					}
+				}
+			}
+
+			if _, err := ProcessGoogleDriveFiles(filmDir, driveService, imageService, turso, "film-1", "Film", folderURL, nil); err != nil {
+				t.Fatalf("second ProcessGoogleDriveFiles: %v", err)
+			}
+			for _, id := range []string{"still-1", "still-2"} {
+				if got := fd.downloads[id]; got != tt.wantDownloads {
+					t.Errorf("%s downloaded %d times, want %d", id, got, tt.wantDownloads)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessLinkedFiles(t *testing.T) {
+	var still bytes.Buffer
+	if err := png.Encode(&still, image.NewRGBA(image.Rect(0, 0, 32, 24))); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		link      string
+		wantFiles []string // Folder/name of every file downloaded and recorded
+		wantLists int      // folder listings requested
+		wantErr   bool
+	}{
+		{
+			name:      "folder link",
+			link:      "https://drive.google.com/drive/folders/root",
+			wantFiles: []string{"Poster/poster.jpg", "Stills/still-1.jpg", "Stills/still-2.jpg"},
+			wantLists: 3,
+		},
+		{
+			name:      "file link",
+			link:      "https://drive.google.com/file/d/still-2/view?usp=sharing",
+			wantFiles: []string{"Stills/still-2.jpg"},
+		},
+		{
+			name:      "file link outside the Stills folder",
+			link:      "https://drive.google.com/file/d/poster-1/view",
+			wantFiles: []string{"Stills/poster.jpg"},
+		},
+		{
+			name:    "missing file",
+			link:    "https://drive.google.com/file/d/gone/view",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fd, driveService := newFakeDrive(t, context.Background())
+			fd.addFolder("", "root", "Film")
+			fd.addFolder("root", "poster", "Poster")
+			fd.addFolder("root", "stills", "Stills")
+			fd.addFile("poster", "poster-1", "poster.jpg")
+			fd.addFile("stills", "still-1", "still-1.jpg")
+			fd.addFile("stills", "still-2", "still-2.jpg")
+			for _, id := range []string{"poster-1", "still-1", "still-2"} {
+				fd.contents[id] = still.Bytes()
+			}
+
+			db, err := memsql.Open()
+			if err != nil {
+				t.Fatalf("opening in-memory database: %v", err)
+			}
+			turso, err := services.NewTursoServiceWithDB(db)
+			if err != nil {
+				t.Fatalf("NewTursoServiceWithDB: %v", err)
+			}
+			t.Cleanup(func() { turso.Close() })
+			imageService := services.NewImageService(config.ImageConfig{MaxWidth: 1920, MaxHeight: 1080, Quality: 85})
+
+			filmDir := t.TempDir()
+			_, err = ProcessGoogleDriveFiles(filmDir, driveService, imageService, turso, "film-1", "Film", tt.link, nil)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("ProcessGoogleDriveFiles succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ProcessGoogleDriveFiles: %v", err)
+			}
+
+			var files []*models.FileWithPath
+			if err := turso.GetDriveFilesMetadata("film-1", &files); err != nil {
+				t.Fatalf("GetDriveFilesMetadata: %v", err)
+			}
+			var got []string
+			for _, file := range files {
+				got = append(got, file.FolderName+"/"+file.Name)
+				if _, err := os.Stat(filepath.Join(filmDir, file.FolderPath, file.Name)); err != nil {
+					t.Errorf("%s/%s was not downloaded: %v", file.FolderPath, file.Name, err)
+				}
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.wantFiles) {
+				t.Errorf("recorded files %q, want %q", got, tt.wantFiles)
+			}
+
+			lists := 0
+			for _, count := range fd.lists {
+				lists += count
+			}
+			if lists != tt.wantLists {
+				t.Errorf("folder listings = %d, want %d", lists, tt.wantLists)
+			}
+		})
+	}
+}
+
+func TestOptimizeImagesConcurrently(t *testing.T) {
+	var still bytes.Buffer
+	if err := png.Encode(&still, image.NewRGBA(image.Rect(0, 0, 32, 24))); err != nil {
+		t.Fatal(err)
+	}
+	const stills, corrupt = 12, 2
+
+	tests := []struct {
+		name        string
+		workers     int
+		wantWorkers int
+	}{
+		{name: "serial", workers: 1, wantWorkers: 1},
+		{name: "several workers", workers: 4, wantWorkers: 4},
+		{name: "more workers than images", workers: 32, wantWorkers: 32},
+		{name: "default", wantWorkers: runtime.NumCPU()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fd, driveService := newFakeDrive(t, context.Background())
+			fd.addFolder("", "root", "Film")
+			fd.addFolder("root", "stills", "Stills")
+			for i := 1; i <= stills+corrupt; i++ {
+				id := fmt.Sprintf("still-%02d", i)
+				fd.addFile("stills", id, id+".jpg")
+				if i <= stills {
+					fd.contents[id] = still.Bytes()
+				}
+			}
+
+			db, err := memsql.Open()
+			if err != nil {
+				t.Fatalf("opening in-memory database: %v", err)
+			}
+			turso, err := services.NewTursoServiceWithDB(db)
+			if err != nil {
+				t.Fatalf("NewTursoServiceWithDB: %v", err)
+			}
+			t.Cleanup(func() { turso.Close() })
+			imageService := services.NewImageService(config.ImageConfig{MaxWidth: 1920, MaxHeight: 1080, Quality: 85, OptimizeWorkers: tt.workers})
+			events := captureEvents(t)
+
+			filmDir := t.TempDir()
+			if _, err := ProcessGoogleDriveFiles(filmDir, driveService, imageService, turso, "film-1", "Film", "https://drive.google.com/drive/folders/root", nil); err != nil {
+				t.Fatalf("ProcessGoogleDriveFiles: %v", err)
+			}
+
+			for i := 1; i <= stills+corrupt; i++ {
+				original := filepath.Join(filmDir, "Stills", fmt.Sprintf("still-%02d.jpg", i))
+				_, err := os.Stat(imageService.OptimizedPath(filmDir, original))
+				if optimized := err == nil; optimized != (i <= stills) {
+					t.Errorf("still-%02d optimized = %v, want %v", i, optimized, i <= stills)
+				}
+			}
+
+			var summary *logger.WideEvent
+			singles := 0
+			for _, event := range events() {
+				switch event.Operation {
+				case "optimize_images":
+					summary = &event
+				case "optimize_single_image":
+					singles++
+				}
+			}
+			if summary == nil {
+				t.Fatal("no optimize_images event")
+			}
+			want := map[string]any{"processed_count": float64(stills), "failed_optimizations": float64(corrupt), "workers": float64(tt.wantWorkers)}
+			for key, value := range want {
+				if summary.Context[key] != value {
+					t.Errorf("optimize_images %s = %v, want %v", key, summary.Context[key], value)
+				}
+			}
+			if singles != stills+corrupt {
+				t.Errorf("%d optimize_single_image events, want %d", singles, stills+corrupt)
+			}
+		})
+	}
+}
+
+// defaultIgnorePatterns mirrors the drive_ignore_patterns default of config.Load
+var defaultIgnorePatterns = []string{"thumbs.db", ".ds_store", "desktop.ini", "._*", "*.pdf"}
+
+func TestIsIgnoredFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		want     bool
+	}{
+		{"Thumbs.db", defaultIgnorePatterns, true},
+		{".DS_Store", defaultIgnorePatterns, true},
+		{"desktop.ini", defaultIgnorePatterns, true},
+		{"._still-01.jpg", defaultIgnorePatterns, true},
+		{"Contact Sheet.PDF", defaultIgnorePatterns, true},
+		{"still-01.jpg", defaultIgnorePatterns, false},
+		{"pdf-cover.jpg", defaultIgnorePatterns, false},
+		{"Thumbs.db", nil, false},
+		{"still-01.JPG", []string{"STILL-*"}, true},
+		{"still-01.jpg", []string{"*.png", "still-0?.jpg"}, true},
+		{"still-10.jpg", []string{"*.png", "still-0?.jpg"}, false},
+	}
+	for _, tt := range tests {
+		if got := isIgnoredFile(tt.name, tt.patterns); got != tt.want {
+			t.Errorf("isIgnoredFile(%q, %q) = %v, want %v", tt.name, tt.patterns, got, tt.want)
+		}
+	}
+}
+
+func TestProcessIgnoredFiles(t *testing.T) {
+	var still bytes.Buffer
+	if err := png.Encode(&still, image.NewRGBA(image.Rect(0, 0, 32, 24))); err != nil {
+		t.Fatal(err)
+	}
+	names := []string{"still-1.jpg", "Thumbs.db", ".DS_Store", "._still-1.jpg", "contactos.PDF", "still-2.png"}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string // files downloaded and recorded
+	}{
+		{name: "default patterns", patterns: defaultIgnorePatterns, want: []string{"still-1.jpg", "still-2.png"}},
+		{name: "custom patterns", patterns: []string{"*.PNG", "*.db", ".*", "*.pdf"}, want: []string{"still-1.jpg"}},
+		{name: "nothing ignored", want: names},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fd, driveService := newFakeDrive(t, context.Background())
+			fd.addFolder("", "root", "Film")
+			fd.addFolder("root", "stills", "Stills")
+			for _, name := range names {
+				fd.addFile("stills", name, name)
+			}
+			fd.contents["still-1.jpg"] = still.Bytes()
+			fd.contents["still-2.png"] = still.Bytes()
+
+			db, err := memsql.Open()
+			if err != nil {
+				t.Fatalf("opening in-memory database: %v", err)
+			}
+			turso, err := services.NewTursoServiceWithDB(db)
+			if err != nil {
+				t.Fatalf("NewTursoServiceWithDB: %v", err)
+			}
+			t.Cleanup(func() { turso.Close() })
+			imageService := services.NewImageService(config.ImageConfig{MaxWidth: 1920, MaxHeight: 1080, Quality: 85})
+
+			filmDir := t.TempDir()
+			if _, err := ProcessGoogleDriveFiles(filmDir, driveService, imageService, turso, "film-1", "Film", "https://drive.google.com/drive/folders/root", tt.patterns); err != nil {
+				t.Fatalf("ProcessGoogleDriveFiles: %v", err)
+			}
+
+			var downloaded []string
+			for id, count := range fd.downloads {
+				if count > 0 {
+					downloaded = append(downloaded, id)
+				}
+			}
+			var files []*models.FileWithPath
+			if err := turso.GetDriveFilesMetadata("film-1", &files); err != nil {
+				t.Fatalf("GetDriveFilesMetadata: %v", err)
+			}
+			var recorded []string
+			for _, file := range files {
+				recorded = append(recorded, file.Name)
+			}
+			want := slices.Sorted(slices.Values(tt.want))
+			slices.Sort(downloaded)
+			slices.Sort(recorded)
+			if !slices.Equal(downloaded, want) {
+				t.Errorf("downloaded %q, want %q", downloaded, want)
+			}
+			if !slices.Equal(recorded, want) {
+				t.Errorf("recorded %q, want %q", recorded, want)
+			}
+		})
+	}
+}
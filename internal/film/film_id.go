@@ -0,0 +1,71 @@
+package film
+
+import (
+	"strings"
+
+	"excentrico-tools-go/internal/utils"
+)
+
+// IDs resolves the ID of each film, used as its directory under films/ and as its Turso
+// key. A title held by a single sheet row keeps the sanitized title as before; titles
+// shared by several rows add the section, then the edition, until they are unique
+type IDs struct {
+	titles        map[string]int // sanitized title -> rows holding it
+	titleSections map[string]int // sanitized title and section -> rows holding them
+}
+
+// NewIDs counts the titles of every sheet row. It must see the whole sheet, not only
+// the filtered films, so a film keeps the same ID whatever filters a run uses
+func NewIDs(rows []map[string]any) *IDs {
+	ids := &IDs{
+		titles:        make(map[string]int),
+		titleSections: make(map[string]int),
+	}
+	for _, obj := range rows {
+		title := rowTitle(obj)
+		if strings.TrimSpace(title) == "" {
+			continue
+		}
+		ids.titles[utils.SanitizeFilename(title)]++
+		ids.titleSections[utils.SanitizeFilename(title+" - "+rowSection(obj))]++
+	}
+	return ids
+}
+
+// For returns the ID of the film titled title in the given sheet row. A nil IDs
+// resolves every film to its sanitized title
+func (ids *IDs) For(title string, obj map[string]any) string {
+	id := utils.SanitizeFilename(title)
+	if ids == nil || ids.titles[id] < 2 {
+		return id
+	}
+
+	section := rowSection(obj)
+	withSection := utils.SanitizeFilename(title + " - " + section)
+	if ids.titleSections[withSection] < 2 {
+		return withSection
+	}
+
+	edition, _ := obj["EDICIÓN"].(string)
+	if year := utils.ExtractEditionYear(edition); year != "" {
+		return utils.SanitizeFilename(title + " - " + section + " - " + year)
+	}
+	return withSection
+}
+
+// rowSection returns the section a row is disambiguated with
+func rowSection(obj map[string]any) string {
+	if section, _ := obj["SECCIÓN"].(string); strings.TrimSpace(section) != "" {
+		return section
+	}
+	return "sin sección"
+}
+
+// rowTitle returns the title a sheet row is processed under
+func rowTitle(obj map[string]any) string {
+	if title, ok := obj["TÍTULO ORIGINAL"].(string); ok {
+		return title
+	}
+	title, _ := obj["Name"].(string)
+	return title
+}
@@ -0,0 +1,48 @@
+package film
+
+import "testing"
+
+func TestFilmIDs(t *testing.T) {
+	row := func(title, section, edition string) map[string]any {
+		return map[string]any{"TÍTULO ORIGINAL": title, "SECCIÓN": section, "EDICIÓN": edition}
+	}
+	rows := []map[string]any{
+		row("El faro", "Cortos", "Excéntrico 2025"),
+		row("La playa", "Cortos", "Excéntrico 2025"),
+		row("La playa", "Largos", "Excéntrico 2025"),
+		row("El río", "Cortos", "Excéntrico 2024"),
+		row("El río", "Cortos", "Excéntrico 2025"),
+		row("El río", "Largos", "Excéntrico 2025"),
+		row("Sin título?", "", "Excéntrico 2025"),
+		row("Sin título?", "Cortos", "Excéntrico 2025"),
+		row("", "Cortos", "Excéntrico 2025"),
+		{"Name": "Otra", "SECCIÓN": "Cortos"},
+	}
+	ids := NewIDs(rows)
+
+	tests := []struct {
+		name string
+		ids  *IDs
+		obj  map[string]any
+		want string
+	}{
+		{"unique title", ids, rows[0], "El faro"},
+		{"title in two sections", ids, rows[1], "La playa - Cortos"},
+		{"same title in the other section", ids, rows[2], "La playa - Largos"},
+		{"title and section shared across editions", ids, rows[3], "El río - Cortos - 2024"},
+		{"title and section in another edition", ids, rows[4], "El río - Cortos - 2025"},
+		{"title shared but section unique", ids, rows[5], "El río - Largos"},
+		{"row without a section", ids, rows[6], "Sin título_ - sin sección"},
+		{"sanitized title with a section", ids, rows[7], "Sin título_ - Cortos"},
+		{"title from the Name column", ids, rows[9], "Otra"},
+		{"film missing from the sheet", ids, row("Nueva", "Cortos", "Excéntrico 2025"), "Nueva"},
+		{"nil IDs keep the title", nil, rows[1], "La playa"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ids.For(rowTitle(tt.obj), tt.obj); got != tt.want {
+				t.Errorf("For(%q) = %q, want %q", rowTitle(tt.obj), got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package film
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"excentrico-tools-go/internal/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitWithWriter("excentrico-tools-go-test", io.Discard)
+	os.Exit(m.Run())
+}
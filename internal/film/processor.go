@@ -1,9 +1,14 @@
 package film
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"excentrico-tools-go/internal/drive"
 	"excentrico-tools-go/internal/logger"
@@ -19,6 +24,22 @@ type Processor struct {
 	wordpressService    *services.WordPressService
 	diviTemplateService *services.DiviTemplateService
 	tursoService        *services.TursoService
+
+	redownload             bool
+	imageFolderColumns     []string
+	driveIgnorePatterns    []string
+	blockIncompleteContent bool
+	filmRetries            int // Extra attempts of the whole pipeline after a transient failure
+	mediaNaming            wordpress.MediaNaming
+	projectOptions         wordpress.ProjectOptions
+	replaceChangedMedia    bool
+	filmIDs                *IDs
+	missingFolderPolicy    string
+
+	mu               sync.Mutex
+	downloadFailures map[string][]*drive.DriveDownloadError
+	missingContent   map[string][]string // film ID -> missing "synopsis" / "credits"
+	mediaTally       wordpress.MediaTally
 }
 
 // NewProcessor creates a new film processor with the required services
@@ -38,12 +59,245 @@ func NewProcessor(
 	}
 }
 
-// ProcessSingleFilm processes a single film from the Google Sheet data
-func (p *Processor) ProcessSingleFilm(obj map[string]any, baseDir string, year string, filmName string, templateConfig *services.TemplateData) error {
+// SetRedownload makes ProcessSingleFilm discard a film's local downloads and recorded
+// Drive metadata before processing, so every file is fetched again
+func (p *Processor) SetRedownload(redownload bool) {
+	p.redownload = redownload
+}
+
+// SetImageFolderColumns sets the sheet columns tried, in order, for the Drive image folder link
+func (p *Processor) SetImageFolderColumns(columns []string) {
+	p.imageFolderColumns = columns
+}
+
+// SetDriveIgnorePatterns sets the file name globs never downloaded from Drive
+func (p *Processor) SetDriveIgnorePatterns(patterns []string) {
+	p.driveIgnorePatterns = patterns
+}
+
+// imageFolderLink returns the first of the candidate columns whose value yields a Drive
+// folder ID, along with that value. ENLACES is used when no columns are configured
+func imageFolderLink(obj map[string]any, columns []string) (string, string) {
+	if len(columns) == 0 {
+		columns = []string{"ENLACES"}
+	}
+	for _, column := range columns {
+		value, _ := obj[column].(string)
+		value = strings.TrimSpace(value)
+		if value != "" && utils.ExtractFileIDFromURL(value) != "" {
+			return column, value
+		}
+	}
+	return "", ""
+}
+
+// Policies for films without a Drive folder link
+const (
+	MissingFolderWarn        = "warn"
+	MissingFolderCreateEmpty = "create-empty"
+	MissingFolderSkip        = "skip"
+)
+
+// ErrNoDriveFolder is returned for films skipped because they have no Drive folder link
+var ErrNoDriveFolder = errors.New("film has no Drive folder link")
+
+// SetMissingFolderPolicy sets how films without a Drive folder link are handled
+func (p *Processor) SetMissingFolderPolicy(policy string) {
+	p.missingFolderPolicy = policy
+}
+
+// SetFilmIDs sets how film IDs are resolved; without it every film uses its sanitized title
+func (p *Processor) SetFilmIDs(ids *IDs) {
+	p.filmIDs = ids
+}
+
+// SetMediaNaming sets the title and alt text templates of uploaded media
+func (p *Processor) SetMediaNaming(naming wordpress.MediaNaming) {
+	p.mediaNaming = naming
+}
+
+// SetProjectOptions sets the slug, builder check and director settings projects are saved
+// with; the permalink writer is kept, see SetPermalinkWriter
+func (p *Processor) SetProjectOptions(opts wordpress.ProjectOptions) {
+	opts.PermalinkWriter = p.projectOptions.PermalinkWriter
+	p.projectOptions = opts
+}
+
+// SetPermalinkWriter sets where the permalink of every saved project is recorded; nil disables it
+func (p *Processor) SetPermalinkWriter(writer wordpress.PermalinkWriter) {
+	p.projectOptions.PermalinkWriter = writer
+}
+
+// SetReplaceChangedMedia makes re-uploading a changed image delete its previous media
+func (p *Processor) SetReplaceChangedMedia(replace bool) {
+	p.replaceChangedMedia = replace
+}
+
+// SetFilmRetries sets how many times a film is retried after a transient failure
+func (p *Processor) SetFilmRetries(retries int) {
+	p.filmRetries = retries
+}
+
+// SetBlockIncompleteContent makes films without a synopsis or credits fail before
+// anything is downloaded or published
+func (p *Processor) SetBlockIncompleteContent(block bool) {
+	p.blockIncompleteContent = block
+}
+
+// MissingContent returns, per film ID, the content ("synopsis", "credits") found empty in this run
+func (p *Processor) MissingContent() map[string][]string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result := make(map[string][]string, len(p.missingContent))
+	for filmID, missing := range p.missingContent {
+		result[filmID] = append([]string(nil), missing...)
+	}
+	return result
+}
+
+// recordMissingContent stores the missing content of a film, clearing it when complete
+func (p *Processor) recordMissingContent(filmID string, missing []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(missing) == 0 {
+		delete(p.missingContent, filmID)
+		return
+	}
+	if p.missingContent == nil {
+		p.missingContent = make(map[string][]string)
+	}
+	p.missingContent[filmID] = missing
+}
+
+// MediaTally returns the run-wide count of reused and uploaded media
+func (p *Processor) MediaTally() *wordpress.MediaTally {
+	return &p.mediaTally
+}
+
+// DownloadFailures returns, per film ID, the Drive files that failed to download in this run
+func (p *Processor) DownloadFailures() map[string][]*drive.DriveDownloadError {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result := make(map[string][]*drive.DriveDownloadError, len(p.downloadFailures))
+	for filmID, failures := range p.downloadFailures {
+		result[filmID] = append([]*drive.DriveDownloadError(nil), failures...)
+	}
+	return result
+}
+
+// recordDownloadFailures stores the failed downloads of a film, replacing any from an earlier attempt
+func (p *Processor) recordDownloadFailures(filmID string, failures []*drive.DriveDownloadError) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(failures) == 0 {
+		delete(p.downloadFailures, filmID)
+		return
+	}
+	if p.downloadFailures == nil {
+		p.downloadFailures = make(map[string][]*drive.DriveDownloadError)
+	}
+	p.downloadFailures[filmID] = failures
+}
+
+// ProcessSingleFilm processes a single film from the Google Sheet data, retrying the
+// whole pipeline up to the configured count when a failure is transient. The outcome
+// is written to result.json in the film directory whether or not it succeeded.
+// Cancelling ctx stops the film between stages and skips any further attempt
+func (p *Processor) ProcessSingleFilm(ctx context.Context, obj map[string]any, baseDir string, year string, filmName string, templateConfig *services.TemplateData) error {
+	filmID := p.filmIDs.For(filmName, obj)
+	section, _ := obj["SECCIÓN"].(string)
+
+	// Every event of the film, down to its service calls, shares the film's request ID
+	l := logger.Get()
+	requestID := logger.NewRequestID()
+	l.BindRequestID(filmID, requestID)
+	defer l.UnbindRequestID(filmID)
+	ctx = logger.ContextWithRequestID(ctx, requestID)
+
+	startOp := l.StartOperation("film_process")
+	startOp.WithFilm(filmID, filmName, year, section).WithRequestID(requestID).KeepAlways()
+	startOp.WithContext("phase", "start")
+	startOp.Complete(fmt.Sprintf("Started processing '%s'", filmName))
+
+	finishOp := l.StartOperation("film_process")
+	finishOp.WithFilm(filmID, filmName, year, section).WithRequestID(requestID).KeepAlways()
+	finishOp.WithContext("phase", "finish")
+
+	var imageIds []int
+	var err error
+	state := &filmAttempt{}
+	for attempt := 1; attempt <= p.filmRetries+1; attempt++ {
+		imageIds, err = p.processSingleFilmOnce(ctx, obj, baseDir, year, filmName, templateConfig, state)
+		// A WordPress request that was already retried with backoff is not worth another
+		// pass of the whole pipeline; film retries cover failures the request layer does not
+		if err == nil || attempt > p.filmRetries || !services.IsTransientError(err) || services.WasRetried(err) || ctx.Err() != nil {
+			filmData := wordpress.ConvertObjToFilmData(obj)
+			filmData.DetectMultipleDirectors = p.projectOptions.DetectMultipleDirectors
+			directors := filmData.DirectorNames()
+			result := p.buildResult(filmID, filmName, year, section, attempt, imageIds, directors, err)
+			writeResult(filepath.Join(baseDir, filmID), result)
+
+			finishOp.WithContext("attempts", attempt)
+			finishOp.WithContext("media_count", result.MediaCount)
+			switch {
+			case result.Skipped:
+				finishOp.WithContext("skipped", true)
+				finishOp.Complete(fmt.Sprintf("Skipped '%s'", filmName))
+			case err != nil:
+				finishOp.Fail(fmt.Sprintf("Failed processing '%s'", filmName), err)
+			default:
+				finishOp.Complete(fmt.Sprintf("Finished processing '%s'", filmName))
+			}
+			return err
+		}
+
+		// Drop what the failed attempt recorded so the next one starts clean. Once the media
+		// are uploaded the Drive step does not run again, so its download failures stand
+		if !state.mediaUploaded {
+			p.recordDownloadFailures(filmID, nil)
+		}
+		p.recordMissingContent(filmID, nil)
+
+		backoff := filmRetryBackoff * time.Duration(1<<(attempt-1))
+		retryOp := logger.Get().StartOperation("retry_single_film")
+		retryOp.WithFilm(filmID, filmName, year, "")
+		retryOp.WithContext("attempt", attempt)
+		retryOp.WithContext("max_attempts", p.filmRetries+1)
+		retryOp.WithContext("backoff_ms", backoff.Milliseconds())
+		retryOp.WithError(err)
+		retryOp.Warn(&logger.WideEvent{
+			Message: fmt.Sprintf("Transient failure processing '%s' (attempt %d/%d), retrying in %v", filmName, attempt, p.filmRetries+1, backoff),
+		})
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return err
+}
+
+// filmRetryBackoff is the wait before the first retry of a film; it doubles per attempt
+var filmRetryBackoff = 2 * time.Second
+
+// filmAttempt is the state the attempts of a film share. Once an attempt has uploaded the
+// film's media, later attempts reuse its media IDs instead of processing the Drive files
+// and uploading the images again
+type filmAttempt struct {
+	mediaUploaded bool
+	imageIds      []int
+}
+
+// processSingleFilmOnce runs the pipeline for a film once and returns the media IDs uploaded
+func (p *Processor) processSingleFilmOnce(ctx context.Context, obj map[string]any, baseDir string, year string, filmName string, templateConfig *services.TemplateData, state *filmAttempt) ([]int, error) {
 	l := logger.Get()
 	op := l.StartOperation("process_single_film")
 	
-	filmID := utils.SanitizeFilename(filmName)
+	filmID := p.filmIDs.For(filmName, obj)
 	filmSection := ""
 	if sec, exists := obj["SECCIÓN"]; exists && sec != nil {
 		filmSection = sec.(string)
@@ -51,66 +305,131 @@ func (p *Processor) ProcessSingleFilm(obj map[string]any, baseDir string, year s
 	
 	op.WithFilm(filmID, filmName, year, filmSection)
 	
-	sanitizedName := utils.SanitizeFilename(filmName)
-	filmDir := filepath.Join(baseDir, sanitizedName)
+	filmDir := filepath.Join(baseDir, filmID)
 	op.WithContext("film_dir", filmDir)
 
+	// Events without a film ID, like HTTP requests and uploads, get the request ID from ctx
+	wordpressService := p.wordpressService.WithContext(ctx)
+	tursoService := p.tursoService.WithContext(ctx)
+
 	if err := os.MkdirAll(filmDir, 0755); err != nil {
 		op.Fail("Failed to create directory", err)
-		return fmt.Errorf("failed to create directory: %v", err)
-	}
-
-	// Process Google Drive files if available
-	if enlaces, exists := obj["ENLACES"]; exists && enlaces != nil {
-		enlacesStr := enlaces.(string)
-		if enlacesStr != "" {
-			driveOp := l.StartOperation("process_drive_files")
-			driveOp.WithFilm(filmID, filmName, year, filmSection)
-			driveOp.WithContext("enlaces_url", enlacesStr)
-			
-			if err := drive.ProcessGoogleDriveFiles(filmDir, p.driveService, p.imageService, p.tursoService, filmName, enlacesStr); err != nil {
-				driveOp.Fail("Failed to process Google Drive files", err)
-				return fmt.Errorf("failed to process Google Drive files: %v", err)
+		return nil, fmt.Errorf("failed to create directory: %v", err)
+	}
+
+	filmData := wordpress.ConvertObjToFilmData(obj)
+	filmData.DetectMultipleDirectors = p.projectOptions.DetectMultipleDirectors
+	missing := filmData.MissingContent()
+	p.recordMissingContent(filmID, missing)
+	if len(missing) > 0 {
+		op.WithContext("missing_content", missing)
+		if p.blockIncompleteContent {
+			err := fmt.Errorf("film has no %s", strings.Join(missing, " or "))
+			op.Fail("Film content is incomplete", err)
+			return nil, err
+		}
+		contentOp := l.StartOperation("check_film_content")
+		contentOp.WithFilm(filmID, filmName, year, filmSection)
+		contentOp.WithContext("missing_content", missing)
+		contentOp.Warn(&logger.WideEvent{
+			Message: fmt.Sprintf("Film '%s' has no %s", filmName, strings.Join(missing, " or ")),
+		})
+	}
+
+	if err := ctx.Err(); err != nil {
+		op.Fail("Interrupted before downloading Drive files", err)
+		return nil, err
+	}
+
+	// Process Google Drive files from the first column holding a usable folder link, unless
+	// an earlier attempt already uploaded the media
+	if state.mediaUploaded {
+		op.WithContext("reused_media_count", len(state.imageIds))
+	} else if column, enlacesStr := imageFolderLink(obj, p.imageFolderColumns); enlacesStr != "" {
+		driveOp := l.StartOperation("process_drive_files")
+		driveOp.WithFilm(filmID, filmName, year, filmSection)
+		driveOp.WithContext("enlaces_url", enlacesStr)
+		driveOp.WithContext("image_folder_column", column)
+
+		if p.redownload {
+			removed, err := drive.ClearDownloads(filmDir, p.imageService, tursoService, filmID)
+			if err != nil {
+				driveOp.Fail("Failed to clear previous downloads", err)
+				return nil, fmt.Errorf("failed to clear previous downloads: %v", err)
 			}
-			driveOp.Complete("Successfully processed Google Drive files")
-		} else {
-			driveOp := l.StartOperation("process_drive_files")
-			driveOp.WithFilm(filmID, filmName, year, filmSection)
-			driveOp.Warn(&logger.WideEvent{
-				Message: fmt.Sprintf("No ENLACES URL found for film '%s'", filmName),
-			})
+			driveOp.WithContext("redownload", true)
+			driveOp.WithContext("removed_local_files", removed)
 		}
+
+		downloadErrs, err := drive.ProcessGoogleDriveFiles(filmDir, p.driveService, p.imageService, tursoService, filmID, filmName, enlacesStr, p.driveIgnorePatterns)
+		if err != nil {
+			driveOp.Fail("Failed to process Google Drive files", err)
+			return nil, fmt.Errorf("failed to process Google Drive files: %w", err)
+		}
+		p.recordDownloadFailures(filmID, downloadErrs)
+		driveOp.WithContext("failed_downloads", len(downloadErrs))
+		driveOp.Complete(fmt.Sprintf("Successfully processed Google Drive files from column '%s'", column))
 	} else {
 		driveOp := l.StartOperation("process_drive_files")
 		driveOp.WithFilm(filmID, filmName, year, filmSection)
-		driveOp.Warn(&logger.WideEvent{
-			Message: fmt.Sprintf("No ENLACES property found for film '%s'", filmName),
-		})
+		driveOp.WithContext("image_folder_columns", p.imageFolderColumns)
+		driveOp.WithContext("missing_folder_policy", p.missingFolderPolicy)
+		switch p.missingFolderPolicy {
+		case MissingFolderSkip:
+			driveOp.Warn(&logger.WideEvent{
+				Message: fmt.Sprintf("Skipping film '%s': no Drive folder link in columns %s", filmName, strings.Join(p.imageFolderColumns, ", ")),
+			})
+			op.Complete(fmt.Sprintf("Skipped film '%s' without a Drive folder", filmName))
+			return nil, ErrNoDriveFolder
+		case MissingFolderCreateEmpty:
+			driveOp.Complete(fmt.Sprintf("No Drive folder for film '%s'; creating the post without images", filmName))
+		default:
+			driveOp.Warn(&logger.WideEvent{
+				Message: fmt.Sprintf("No Drive folder link found for film '%s' in columns %s", filmName, strings.Join(p.imageFolderColumns, ", ")),
+			})
+		}
 	}
 
-	// Upload media to WordPress
-	wpOp := l.StartOperation("upload_wordpress_media")
-	wpOp.WithFilm(filmID, filmName, year, filmSection)
-	
-	imageIds, err := wordpress.UploadMediaToWordPress(p.wordpressService, p.tursoService, filmDir, filmName)
-	if err != nil {
-		wpOp.Fail("Failed to upload media to WordPress", err)
-		return fmt.Errorf("failed to upload media to WordPress: %v", err)
+	if err := ctx.Err(); err != nil {
+		op.Fail("Interrupted before uploading media", err)
+		return nil, err
+	}
+
+	// Upload media to WordPress, unless an earlier attempt already did
+	imageIds := state.imageIds
+	if !state.mediaUploaded {
+		wpOp := l.StartOperation("upload_wordpress_media")
+		wpOp.WithFilm(filmID, filmName, year, filmSection)
+
+		// Optimized images live in the film folder or its configured output subdirectory
+		directors := filmData.DirectorNames()
+		var err error
+		imageIds, err = wordpress.UploadMediaToWordPress(wordpressService, tursoService, p.imageService.OptimizedDir(filmDir), p.imageService.Formats(), filmID, filmName, directors, p.mediaNaming, p.replaceChangedMedia, &p.mediaTally)
+		if err != nil {
+			wpOp.Fail("Failed to upload media to WordPress", err)
+			return nil, fmt.Errorf("failed to upload media to WordPress: %w", err)
+		}
+		wpOp.WithContext("image_count", len(imageIds))
+		wpOp.Complete(fmt.Sprintf("Successfully uploaded %d images to WordPress", len(imageIds)))
+		state.mediaUploaded, state.imageIds = true, imageIds
+	}
+
+	if err := ctx.Err(); err != nil {
+		op.Fail("Interrupted before saving the WordPress project", err)
+		return imageIds, err
 	}
-	wpOp.WithContext("image_count", len(imageIds))
-	wpOp.Complete(fmt.Sprintf("Successfully uploaded %d images to WordPress", len(imageIds)))
 
 	// Create or update WordPress project
 	projectOp := l.StartOperation("create_update_wordpress_project")
 	projectOp.WithFilm(filmID, filmName, year, filmSection)
 	projectOp.WithContext("image_count", len(imageIds))
 	
-	if err := wordpress.CreateOrUpdateWordPressProject(p.wordpressService, p.diviTemplateService, p.tursoService, filmDir, obj, year, imageIds, templateConfig); err != nil {
+	if err := wordpress.CreateOrUpdateWordPressProject(wordpressService, p.diviTemplateService, tursoService, filmDir, filmID, obj, year, imageIds, templateConfig, p.projectOptions); err != nil {
 		projectOp.Fail("Failed to create/update WordPress project", err)
-		return fmt.Errorf("failed to create/update WordPress project: %v", err)
+		return imageIds, fmt.Errorf("failed to create/update WordPress project: %w", err)
 	}
 	projectOp.Complete("Successfully created/updated WordPress project")
 
 	op.Complete(fmt.Sprintf("Successfully processed film '%s'", filmName))
-	return nil
+	return imageIds, nil
 }
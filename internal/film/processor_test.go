@@ -0,0 +1,412 @@
+package film
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"excentrico-tools-go/internal/config"
+	"excentrico-tools-go/internal/logger"
+	"excentrico-tools-go/internal/services"
+	"excentrico-tools-go/internal/testutil/memsql"
+)
+
+// captureEvents routes every logged event to a buffer until the test ends and returns a
+// function decoding the events logged so far
+func captureEvents(t *testing.T) func() []logger.WideEvent {
+	t.Helper()
+	var buf bytes.Buffer
+	logger.InitWithWriter("excentrico-tools-go-test", &buf)
+	logger.Get().SetSampleRate(1)
+	t.Cleanup(func() { logger.InitWithWriter("excentrico-tools-go-test", io.Discard) })
+
+	return func() []logger.WideEvent {
+		var events []logger.WideEvent
+		scanner := bufio.NewScanner(bytes.NewReader(buf.Bytes()))
+		scanner.Buffer(nil, 1<<20)
+		for scanner.Scan() {
+			var event logger.WideEvent
+			if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+				t.Fatalf("decoding event %s: %v", scanner.Text(), err)
+			}
+			events = append(events, event)
+		}
+		return events
+	}
+}
+
+// newTestProcessor returns a Processor whose WordPress site is never reached and whose
+// metadata lives in an in-memory database
+func newTestProcessor(t *testing.T) *Processor {
+	t.Helper()
+	db, err := memsql.Open()
+	if err != nil {
+		t.Fatalf("opening in-memory database: %v", err)
+	}
+	turso, err := services.NewTursoServiceWithDB(db)
+	if err != nil {
+		t.Fatalf("NewTursoServiceWithDB: %v", err)
+	}
+	t.Cleanup(func() { turso.Close() })
+	wp := services.NewWordPressService(context.Background(), config.WordPressConfig{BaseURL: "https://example.test"})
+	return NewProcessor(nil, services.NewImageServiceWithConfig(1920, 1080, 85), wp, services.NewDiviTemplateService(), turso)
+}
+
+func TestProcessSingleFilmCorrelatesEvents(t *testing.T) {
+	p := newTestProcessor(t)
+	p.SetMissingFolderPolicy(MissingFolderSkip)
+	events := captureEvents(t)
+
+	// Films processed at the same time each get their own request ID
+	films := []string{"La Película", "Otra Película"}
+	var wg sync.WaitGroup
+	for _, film := range films {
+		wg.Add(1)
+		go func(film string) {
+			defer wg.Done()
+			obj := map[string]any{"TÍTULO ORIGINAL": film, "SECCIÓN": "Oficial"}
+			err := p.ProcessSingleFilm(context.Background(), obj, t.TempDir(), "2025", film, nil)
+			if !errors.Is(err, ErrNoDriveFolder) {
+				t.Errorf("ProcessSingleFilm(%s) error %v, want ErrNoDriveFolder", film, err)
+			}
+		}(film)
+	}
+	wg.Wait()
+
+	type phases struct{ start, finish string }
+	byFilm := make(map[string]*phases)
+	filmEvents := make(map[string][]logger.WideEvent)
+	for _, event := range events() {
+		if event.FilmID == "" {
+			continue
+		}
+		filmEvents[event.FilmID] = append(filmEvents[event.FilmID], event)
+		if event.Operation != "film_process" {
+			continue
+		}
+		if byFilm[event.FilmID] == nil {
+			byFilm[event.FilmID] = &phases{}
+		}
+		switch event.Context["phase"] {
+		case "start":
+			byFilm[event.FilmID].start = event.RequestID
+		case "finish":
+			byFilm[event.FilmID].finish = event.RequestID
+		}
+	}
+
+	seen := make(map[string]string)
+	for filmID, ids := range byFilm {
+		if ids.start == "" || ids.start != ids.finish {
+			t.Errorf("%s: start request ID %q, finish request ID %q, want the same non-empty ID", filmID, ids.start, ids.finish)
+		}
+		if other, ok := seen[ids.start]; ok {
+			t.Errorf("%s and %s share request ID %q", filmID, other, ids.start)
+		}
+		seen[ids.start] = filmID
+		for _, event := range filmEvents[filmID] {
+			if event.RequestID != ids.start {
+				t.Errorf("%s: %s event has request ID %q, want %q", filmID, event.Operation, event.RequestID, ids.start)
+			}
+		}
+	}
+	if len(byFilm) != len(films) {
+		t.Errorf("film_process events for %d films, want %d", len(byFilm), len(films))
+	}
+}
+
+func TestImageFolderLink(t *testing.T) {
+	const folder = "https://drive.google.com/drive/folders/1AbC-d_E"
+	columns := []string{"ENLACES", "Web Excentrico", "Drive"}
+	tests := []struct {
+		name       string
+		row        map[string]any
+		columns    []string
+		wantColumn string
+		wantLink   string
+	}{
+		{name: "first column", row: map[string]any{"ENLACES": folder, "Drive": "https://drive.google.com/drive/folders/other"}, columns: columns, wantColumn: "ENLACES", wantLink: folder},
+		{name: "falls back past a blank column", row: map[string]any{"ENLACES": "  ", "Web Excentrico": folder}, columns: columns, wantColumn: "Web Excentrico", wantLink: folder},
+		{name: "falls back past a link without a folder ID", row: map[string]any{"ENLACES": "https://vimeo.com/12345", "Web Excentrico": "pendiente", "Drive": " " + folder + " "}, columns: columns, wantColumn: "Drive", wantLink: folder},
+		{name: "missing and non-text cells", row: map[string]any{"ENLACES": 42, "Drive": folder}, columns: columns, wantColumn: "Drive", wantLink: folder},
+		{name: "no usable column", row: map[string]any{"ENLACES": "https://vimeo.com/12345"}, columns: columns},
+		{name: "configured order wins", row: map[string]any{"ENLACES": folder, "Drive": "https://drive.google.com/file/d/2XyZ/view"}, columns: []string{"Drive", "ENLACES"}, wantColumn: "Drive", wantLink: "https://drive.google.com/file/d/2XyZ/view"},
+		{name: "ENLACES when none configured", row: map[string]any{"ENLACES": folder, "Drive": folder}, wantColumn: "ENLACES", wantLink: folder},
+		{name: "other columns ignored when none configured", row: map[string]any{"Drive": folder}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			column, link := imageFolderLink(tt.row, tt.columns)
+			if column != tt.wantColumn || link != tt.wantLink {
+				t.Errorf("imageFolderLink() = (%q, %q), want (%q, %q)", column, link, tt.wantColumn, tt.wantLink)
+			}
+		})
+	}
+}
+
+func TestProcessSingleFilmMissingContent(t *testing.T) {
+	tests := []struct {
+		name        string
+		block       bool
+		obj         map[string]any
+		wantErr     string
+		wantMissing []string
+	}{
+		{
+			name:    "complete film",
+			obj:     map[string]any{"Sinopsis extendida (máximo 70 palabras)": "Una historia.", "Guión": "Ana Pérez"},
+			wantErr: ErrNoDriveFolder.Error(),
+		},
+		{
+			name:        "incomplete film is reported",
+			obj:         map[string]any{"Guión": "Ana Pérez"},
+			wantErr:     ErrNoDriveFolder.Error(),
+			wantMissing: []string{"synopsis"},
+		},
+		{
+			name:        "incomplete film is blocked",
+			block:       true,
+			obj:         map[string]any{},
+			wantErr:     "film has no synopsis or credits",
+			wantMissing: []string{"synopsis", "credits"},
+		},
+		{
+			name:    "complete film is not blocked",
+			block:   true,
+			obj:     map[string]any{"Sinopsis extendida (máximo 70 palabras)": "Una historia.", "Otros créditos / Other credits": "Vestuario: Luis Gil"},
+			wantErr: ErrNoDriveFolder.Error(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestProcessor(t)
+			p.SetMissingFolderPolicy(MissingFolderSkip)
+			p.SetBlockIncompleteContent(tt.block)
+
+			film := "La Película"
+			tt.obj["TÍTULO ORIGINAL"] = film
+			err := p.ProcessSingleFilm(context.Background(), tt.obj, t.TempDir(), "2025", film, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ProcessSingleFilm() error %v, want %q", err, tt.wantErr)
+			}
+
+			missing := p.MissingContent()
+			filmID := p.filmIDs.For(film, tt.obj)
+			if got := missing[filmID]; !reflect.DeepEqual(got, tt.wantMissing) {
+				t.Errorf("MissingContent()[%s] = %q, want %q", filmID, got, tt.wantMissing)
+			}
+			if tt.wantMissing == nil && len(missing) != 0 {
+				t.Errorf("MissingContent() = %v, want no films", missing)
+			}
+		})
+	}
+}
+
+func TestProcessSingleFilmRetries(t *testing.T) {
+	defer func(backoff time.Duration) { filmRetryBackoff = backoff }(filmRetryBackoff)
+	filmRetryBackoff = time.Millisecond
+
+	tests := []struct {
+		name         string
+		filmRetries  int
+		maxRetries   int   // WordPress request retries
+		statuses     []int // Answers to the project POSTs before they succeed
+		wantErr      bool
+		wantAttempts int
+		wantPosts    int
+	}{
+		{"transient failure then success", 1, 0, []int{http.StatusServiceUnavailable}, false, 2, 2},
+		{"rate limited then success", 2, 0, []int{http.StatusTooManyRequests}, false, 2, 2},
+		{"non-retriable failure", 2, 0, []int{http.StatusBadRequest}, true, 1, 1},
+		{"retries exhausted", 1, 0, []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway}, true, 2, 2},
+		{"retries disabled", 0, 0, []int{http.StatusServiceUnavailable}, true, 1, 1},
+		{"already retried by the request layer", 2, 1, []int{http.StatusTooManyRequests, http.StatusTooManyRequests}, true, 1, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			posts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					w.Write([]byte("[]"))
+					return
+				}
+				mu.Lock()
+				posts++
+				n := posts
+				mu.Unlock()
+				if n <= len(tt.statuses) {
+					http.Error(w, `{"code":"failed"}`, tt.statuses[n-1])
+					return
+				}
+				w.Write([]byte(`{"id": 7, "slug": "la-pelicula", "status": "draft"}`))
+			}))
+			defer server.Close()
+
+			p := newTestProcessor(t)
+			p.wordpressService = services.NewWordPressService(context.Background(), config.WordPressConfig{
+				BaseURL:        server.URL,
+				MaxRetries:     tt.maxRetries,
+				RetryBaseDelay: "1ms",
+			})
+			p.SetMissingFolderPolicy(MissingFolderCreateEmpty)
+			p.SetFilmRetries(tt.filmRetries)
+
+			film := "La Película"
+			obj := map[string]any{"TÍTULO ORIGINAL": film}
+			baseDir := t.TempDir()
+			err := p.ProcessSingleFilm(context.Background(), obj, baseDir, "2025", film, services.DefaultTemplateData())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ProcessSingleFilm() error %v, want error %v", err, tt.wantErr)
+			}
+			if posts != tt.wantPosts {
+				t.Errorf("project POSTs = %d, want %d", posts, tt.wantPosts)
+			}
+
+			data, err := os.ReadFile(filepath.Join(baseDir, p.filmIDs.For(film, obj), ResultFileName))
+			if err != nil {
+				t.Fatalf("reading result: %v", err)
+			}
+			var result FilmResult
+			if err := json.Unmarshal(data, &result); err != nil {
+				t.Fatalf("decoding result: %v", err)
+			}
+			if result.Attempts != tt.wantAttempts || result.Success == tt.wantErr {
+				t.Errorf("result attempts %d, success %v, want %d, %v", result.Attempts, result.Success, tt.wantAttempts, !tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProcessSingleFilmRetryReusesMedia(t *testing.T) {
+	defer func(backoff time.Duration) { filmRetryBackoff = backoff }(filmRetryBackoff)
+	filmRetryBackoff = time.Millisecond
+
+	tests := []struct {
+		name         string
+		statuses     []int // Answers to the project POSTs before they succeed
+		wantProjects int32
+	}{
+		{name: "first attempt succeeds", wantProjects: 1},
+		{name: "project fails transiently", statuses: []int{http.StatusServiceUnavailable}, wantProjects: 2},
+		{name: "project fails twice", statuses: []int{http.StatusBadGateway, http.StatusServiceUnavailable}, wantProjects: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var uploads, projects atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodGet:
+					w.Write([]byte("[]"))
+				case strings.HasSuffix(r.URL.Path, "/wp/v2/media"):
+					uploads.Add(1)
+					w.WriteHeader(http.StatusCreated)
+					w.Write([]byte(`{"id": 11, "source_url": "https://example.test/uploads/still_01_web.jpg"}`))
+				default:
+					if n := int(projects.Add(1)); n <= len(tt.statuses) {
+						http.Error(w, `{"code":"failed"}`, tt.statuses[n-1])
+						return
+					}
+					w.Write([]byte(`{"id": 7, "slug": "la-pelicula", "status": "draft"}`))
+				}
+			}))
+			defer server.Close()
+
+			p := newTestProcessor(t)
+			p.wordpressService = services.NewWordPressService(context.Background(), config.WordPressConfig{BaseURL: server.URL, RetryBaseDelay: "1ms"})
+			p.SetMissingFolderPolicy(MissingFolderCreateEmpty)
+			p.SetFilmRetries(2)
+
+			film := "La Película"
+			obj := map[string]any{"TÍTULO ORIGINAL": film}
+			baseDir := t.TempDir()
+			filmDir := filepath.Join(baseDir, p.filmIDs.For(film, obj))
+			if err := os.MkdirAll(filmDir, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(filmDir, "still_01_web.jpg"), []byte("still"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := p.ProcessSingleFilm(context.Background(), obj, baseDir, "2025", film, services.DefaultTemplateData()); err != nil {
+				t.Fatalf("ProcessSingleFilm: %v", err)
+			}
+			if got := projects.Load(); got != tt.wantProjects {
+				t.Errorf("project POSTs = %d, want %d", got, tt.wantProjects)
+			}
+			// Retries reuse the media of the first attempt: one upload, and nothing counted as
+			// reused by a later attempt
+			if got := uploads.Load(); got != 1 {
+				t.Errorf("media uploads = %d, want 1", got)
+			}
+			if reused, uploaded, _ := p.MediaTally().Totals(); reused != 0 || uploaded != 1 {
+				t.Errorf("media tally %d reused, %d uploaded, want 0 and 1", reused, uploaded)
+			}
+		})
+	}
+}
+
+func TestMissingFolderPolicy(t *testing.T) {
+	tests := []struct {
+		policy    string
+		wantErr   error
+		wantPosts int
+		wantLevel string // level of the process_drive_files event
+	}{
+		{policy: MissingFolderWarn, wantPosts: 1, wantLevel: "warn"},
+		{policy: MissingFolderCreateEmpty, wantPosts: 1, wantLevel: "info"},
+		{policy: MissingFolderSkip, wantErr: ErrNoDriveFolder, wantLevel: "warn"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			var posts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					w.Write([]byte("[]"))
+					return
+				}
+				posts.Add(1)
+				w.Write([]byte(`{"id": 7, "slug": "la-pelicula", "status": "draft"}`))
+			}))
+			defer server.Close()
+
+			p := newTestProcessor(t)
+			p.wordpressService = services.NewWordPressService(context.Background(), config.WordPressConfig{BaseURL: server.URL})
+			p.SetMissingFolderPolicy(tt.policy)
+			events := captureEvents(t)
+
+			film := "La Película"
+			obj := map[string]any{"TÍTULO ORIGINAL": film, "ENLACES": "", "Sinopsis extendida (máximo 70 palabras)": "Una historia.", "Guión": "Ana Pérez"}
+			err := p.ProcessSingleFilm(context.Background(), obj, t.TempDir(), "2025", film, services.DefaultTemplateData())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ProcessSingleFilm() error %v, want %v", err, tt.wantErr)
+			}
+			if got := int(posts.Load()); got != tt.wantPosts {
+				t.Errorf("project POSTs = %d, want %d", got, tt.wantPosts)
+			}
+
+			var levels []string
+			for _, event := range events() {
+				if event.Operation == "process_drive_files" {
+					levels = append(levels, event.Level)
+				}
+			}
+			if len(levels) != 1 || levels[0] != tt.wantLevel {
+				t.Errorf("process_drive_files event levels %q, want [%q]", levels, tt.wantLevel)
+			}
+		})
+	}
+}
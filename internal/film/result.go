@@ -0,0 +1,131 @@
+package film
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"excentrico-tools-go/internal/logger"
+	"excentrico-tools-go/internal/models"
+)
+
+// ResultFileName is the per-film outcome file written next to metadata.json
+const ResultFileName = "result.json"
+
+// FilmResult is the structured outcome of processing a film, for external tooling
+type FilmResult struct {
+	Film     string `json:"film"`
+	FilmID   string `json:"film_id"`
+	Year     string `json:"year,omitempty"`
+	Section  string `json:"section,omitempty"`
+	Success  bool   `json:"success"`
+	Skipped  bool   `json:"skipped,omitempty"`
+	Attempts int    `json:"attempts"`
+
+	PostID    int    `json:"post_id,omitempty"`
+	PostSlug  string `json:"post_slug,omitempty"`
+	PostState string `json:"post_status,omitempty"`
+	MediaIDs  []int  `json:"media_ids"`
+
+	PosterMediaID int `json:"poster_media_id,omitempty"`
+
+	BackgroundMediaID int    `json:"background_media_id,omitempty"`
+	BackgroundURL     string `json:"background_url,omitempty"`
+
+	// Director image matches are only known once the template was generated
+	DirectorsWithImages    []string `json:"directors_with_images,omitempty"`
+	DirectorsWithoutImages []string `json:"directors_without_images,omitempty"`
+
+	MediaCount      int      `json:"media_count"`
+	FailedDownloads []string `json:"failed_downloads,omitempty"`
+	MissingContent  []string `json:"missing_content,omitempty"`
+	Errors          []string `json:"errors,omitempty"`
+	ProcessedAt     string   `json:"processed_at"`
+}
+
+// buildResult collects the outcome of the last attempt at a film from what the
+// processor, template service and Turso recorded for it
+func (p *Processor) buildResult(filmID, filmName, year, section string, attempts int, imageIds []int, directors []string, err error) *FilmResult {
+	result := &FilmResult{
+		Film:        filmName,
+		FilmID:      filmID,
+		Year:        year,
+		Section:     section,
+		Success:     err == nil,
+		Attempts:    attempts,
+		MediaIDs:    imageIds,
+		MediaCount:  len(imageIds),
+		ProcessedAt: time.Now().Format(time.RFC3339),
+	}
+	if result.MediaIDs == nil {
+		result.MediaIDs = []int{}
+	}
+	if errors.Is(err, ErrNoDriveFolder) {
+		result.Skipped = true
+	} else if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+	}
+
+	p.mu.Lock()
+	for _, failure := range p.downloadFailures[filmID] {
+		result.FailedDownloads = append(result.FailedDownloads, failure.Path())
+	}
+	result.MissingContent = append(result.MissingContent, p.missingContent[filmID]...)
+	p.mu.Unlock()
+
+	if p.tursoService != nil {
+		metadata := &models.WordPressMetadata{}
+		if p.tursoService.GetWordPressMetadata(filmID, metadata) == nil {
+			result.PostID = metadata.PostID
+			result.PostSlug = metadata.Slug
+			result.PostState = metadata.Status
+		}
+		poster := &models.PosterMetadata{}
+		if p.tursoService.GetPosterMetadata(filmID, poster) == nil {
+			result.PosterMediaID = poster.MediaID
+		}
+		background := &models.BackgroundImageMetadata{}
+		if p.tursoService.GetBackgroundImageMetadata(filmID, background) == nil {
+			result.BackgroundMediaID = background.MediaID
+			result.BackgroundURL = background.URL
+		}
+	}
+
+	if err == nil {
+		missing := p.diviTemplateService.MissingDirectorImages()[filmID]
+		result.DirectorsWithoutImages = missing
+		for _, director := range directors {
+			if !slices.Contains(missing, director) {
+				result.DirectorsWithImages = append(result.DirectorsWithImages, director)
+			}
+		}
+	}
+
+	return result
+}
+
+// writeResult saves the result as result.json in the film directory. A failure is only
+// logged so it never changes the outcome of the film
+func writeResult(filmDir string, result *FilmResult) {
+	op := logger.Get().StartOperation("write_film_result")
+	op.WithFilm(result.FilmID, result.Film, result.Year, result.Section)
+
+	path := filepath.Join(filmDir, ResultFileName)
+	op.WithContext("result_path", path)
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err == nil {
+		if err = os.MkdirAll(filmDir, 0755); err == nil {
+			err = os.WriteFile(path, resultJSON, 0644)
+		}
+	}
+	if err != nil {
+		op.Fail("Failed to write film result", fmt.Errorf("failed to write %s: %v", path, err))
+		return
+	}
+	op.Complete(fmt.Sprintf("Wrote result for '%s'", result.Film))
+}
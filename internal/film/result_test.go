@@ -0,0 +1,120 @@
+package film
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"excentrico-tools-go/internal/config"
+	"excentrico-tools-go/internal/services"
+)
+
+func TestProcessSingleFilmWritesResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int // Answer to the project POST
+		policy  string
+		obj     map[string]any
+		want    FilmResult
+		wantErr bool
+	}{
+		{
+			name:   "success",
+			status: http.StatusCreated,
+			policy: MissingFolderCreateEmpty,
+			obj: map[string]any{
+				"DIRECCIÓN": "Ana Pérez",
+				"Sinopsis extendida (máximo 70 palabras)": "Una historia.",
+				"Guión": "Ana Pérez",
+			},
+			want: FilmResult{
+				Success:                true,
+				Attempts:               1,
+				PostID:                 7,
+				PostSlug:               "la-pelicula",
+				PostState:              "draft",
+				MediaIDs:               []int{},
+				DirectorsWithoutImages: []string{"Ana Pérez"},
+			},
+		},
+		{
+			name:   "failure",
+			status: http.StatusBadRequest,
+			policy: MissingFolderCreateEmpty,
+			obj:    map[string]any{},
+			want: FilmResult{
+				Attempts:       1,
+				MediaIDs:       []int{},
+				MissingContent: []string{"synopsis", "credits"},
+				Errors:         []string{`failed to create/update WordPress project: failed to create WordPress post: API request failed with status 400: {"code":"rest_invalid_param"}`},
+			},
+			wantErr: true,
+		},
+		{
+			name:   "skipped without a Drive folder",
+			policy: MissingFolderSkip,
+			obj:    map[string]any{"Sinopsis extendida (máximo 70 palabras)": "Una historia.", "Guión": "Ana Pérez"},
+			want: FilmResult{
+				Skipped:  true,
+				Attempts: 1,
+				MediaIDs: []int{},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					w.Write([]byte("[]"))
+					return
+				}
+				w.WriteHeader(tt.status)
+				if tt.status >= http.StatusBadRequest {
+					w.Write([]byte(`{"code":"rest_invalid_param"}`))
+					return
+				}
+				w.Write([]byte(`{"id": 7, "slug": "la-pelicula", "status": "draft"}`))
+			}))
+			defer server.Close()
+
+			p := newTestProcessor(t)
+			p.wordpressService = services.NewWordPressService(context.Background(), config.WordPressConfig{BaseURL: server.URL})
+			p.SetMissingFolderPolicy(tt.policy)
+
+			film := "La Película"
+			tt.obj["TÍTULO ORIGINAL"] = film
+			tt.obj["SECCIÓN"] = "Oficial"
+			baseDir := t.TempDir()
+			err := p.ProcessSingleFilm(context.Background(), tt.obj, baseDir, "2025", film, services.DefaultTemplateData())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ProcessSingleFilm() error %v, want error %v", err, tt.wantErr)
+			}
+
+			filmID := p.filmIDs.For(film, tt.obj)
+			data, err := os.ReadFile(filepath.Join(baseDir, filmID, ResultFileName))
+			if err != nil {
+				t.Fatalf("reading result: %v", err)
+			}
+			var got FilmResult
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("decoding result: %v", err)
+			}
+			if got.ProcessedAt == "" {
+				t.Error("result has no processed_at time")
+			}
+			got.ProcessedAt = ""
+
+			want := tt.want
+			want.Film, want.FilmID, want.Year, want.Section = film, filmID, "2025", "Oficial"
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("result = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
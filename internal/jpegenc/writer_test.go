@@ -0,0 +1,58 @@
+package jpegenc
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+func colorful(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 7), G: uint8(y * 5), B: uint8((x ^ y) * 3), A: 255})
+		}
+	}
+	return img
+}
+
+func TestEncodeSubsampling(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     *Options
+		img         image.Image
+		wantYCbCr   bool
+		wantSubsamp image.YCbCrSubsampleRatio
+	}{
+		{"nil options", nil, colorful(40, 24), true, image.YCbCrSubsampleRatio420},
+		{"4:2:0", &Options{Quality: 90, Subsampling: Subsampling420}, colorful(40, 24), true, image.YCbCrSubsampleRatio420},
+		{"4:4:4", &Options{Quality: 90, Subsampling: Subsampling444}, colorful(40, 24), true, image.YCbCrSubsampleRatio444},
+		{"4:4:4 odd size", &Options{Quality: 90, Subsampling: Subsampling444}, colorful(17, 9), true, image.YCbCrSubsampleRatio444},
+		{"4:2:0 odd size", &Options{Quality: 90, Subsampling: Subsampling420}, colorful(17, 9), true, image.YCbCrSubsampleRatio420},
+		{"gray ignores subsampling", &Options{Quality: 90, Subsampling: Subsampling444}, image.NewGray(image.Rect(0, 0, 16, 16)), false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Encode(&buf, tt.img, tt.options); err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			decoded, err := jpeg.Decode(&buf)
+			if err != nil {
+				t.Fatalf("decoding the encoded image: %v", err)
+			}
+			if decoded.Bounds() != tt.img.Bounds() {
+				t.Fatalf("decoded bounds %v, want %v", decoded.Bounds(), tt.img.Bounds())
+			}
+			ycbcr, ok := decoded.(*image.YCbCr)
+			if ok != tt.wantYCbCr {
+				t.Fatalf("decoded %T, want *image.YCbCr: %v", decoded, tt.wantYCbCr)
+			}
+			if ok && ycbcr.SubsampleRatio != tt.wantSubsamp {
+				t.Errorf("SubsampleRatio %v, want %v", ycbcr.SubsampleRatio, tt.wantSubsamp)
+			}
+		})
+	}
+}
@@ -1,8 +1,12 @@
 package logger
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
@@ -64,6 +68,9 @@ type WideEvent struct {
 	
 	// Additional context (flexible)
 	Context         map[string]any    `json:"context,omitempty"`
+
+	// alwaysKeep bypasses sampling for summary events that must always be recorded
+	alwaysKeep bool
 }
 
 // ErrorContext provides detailed error information
@@ -85,6 +92,10 @@ type Logger struct {
 	logFile     *os.File // File handle for log output
 	logFilePath string   // Path to the log file
 	mu          sync.Mutex // Mutex for thread-safe file writes
+	output      io.Writer  // Replaces stdout and the log file when set; see InitWithWriter
+
+	requestMu  sync.Mutex
+	requestIDs map[string]string // Film ID -> request ID of the film being processed
 }
 
 // NewLogger creates a new logger instance
@@ -133,6 +144,58 @@ func (l *Logger) initLogFile() {
 	fmt.Fprintf(logFile, "=== Log run started at %s ===\n", now.Format(time.RFC3339Nano))
 }
 
+// NewRequestID generates a random ID correlating the events of one film's processing
+func NewRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%016x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// requestIDKey is the context key of the request ID set by ContextWithRequestID
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID, for services that
+// log events without a film ID (HTTP requests, uploads) to tag them with it
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID set by ContextWithRequestID, or "" if none
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// BindRequestID tags every following event carrying the film ID with the request ID,
+// so sub-operations of the film's services are correlated without passing the ID along
+func (l *Logger) BindRequestID(filmID, requestID string) {
+	l.requestMu.Lock()
+	defer l.requestMu.Unlock()
+	if l.requestIDs == nil {
+		l.requestIDs = make(map[string]string)
+	}
+	l.requestIDs[filmID] = requestID
+}
+
+// UnbindRequestID stops tagging the film's events with its request ID
+func (l *Logger) UnbindRequestID(filmID string) {
+	l.requestMu.Lock()
+	defer l.requestMu.Unlock()
+	delete(l.requestIDs, filmID)
+}
+
+// requestIDFor returns the request ID bound to a film, if any
+func (l *Logger) requestIDFor(filmID string) string {
+	l.requestMu.Lock()
+	defer l.requestMu.Unlock()
+	return l.requestIDs[filmID]
+}
+
 // SetSampleRate sets the sampling rate for successful operations (0.0 to 1.0)
 func (l *Logger) SetSampleRate(rate float64) {
 	if rate < 0 {
@@ -154,6 +217,45 @@ func (l *Logger) GetLogFilePath() string {
 	return l.logFilePath
 }
 
+// PruneLogs removes run logs in the logs directory last modified more than maxAgeDays ago.
+// The active log file is never removed. It returns the number of files deleted
+func (l *Logger) PruneLogs(maxAgeDays int) (int, error) {
+	if maxAgeDays <= 0 {
+		return 0, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	logsDir := "logs"
+	if l.logFilePath != "" {
+		logsDir = filepath.Dir(l.logFilePath)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(logsDir, "run-*.log"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list log files: %v", err)
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -maxAgeDays)
+	removed := 0
+	for _, path := range matches {
+		if path == l.logFilePath {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return removed, fmt.Errorf("failed to remove log file %s: %v", path, err)
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
 // Close closes the log file
 func (l *Logger) Close() error {
 	l.mu.Lock()
@@ -185,6 +287,11 @@ func (l *Logger) shouldSample(event *WideEvent) bool {
 	if event.Level == "warn" {
 		return true
 	}
+
+	// Always keep events explicitly marked as summaries
+	if event.alwaysKeep {
+		return true
+	}
 	
 	// For successful operations, use sampling
 	if event.Outcome == "success" {
@@ -227,6 +334,10 @@ func (l *Logger) emit(event *WideEvent) {
 		event.Version = l.version
 	}
 	
+	if event.RequestID == "" && event.FilmID != "" {
+		event.RequestID = l.requestIDFor(event.FilmID)
+	}
+
 	// Ensure timestamp is set
 	if event.Timestamp == "" {
 		event.Timestamp = time.Now().UTC().Format(time.RFC3339Nano)
@@ -242,6 +353,12 @@ func (l *Logger) emit(event *WideEvent) {
 	
 	// Output as single line JSON (structured logging)
 	jsonLine := string(jsonBytes) + "\n"
+	if l.output != nil {
+		l.mu.Lock()
+		io.WriteString(l.output, jsonLine)
+		l.mu.Unlock()
+		return
+	}
 	fmt.Fprint(os.Stdout, jsonLine)
 	
 	// Also write to log file if available
@@ -315,6 +432,12 @@ func (ot *OperationTracker) WithFilm(filmID, filmName, year, section string) *Op
 	return ot
 }
 
+// WithRequestID sets the request ID correlating the event with the rest of its film's events
+func (ot *OperationTracker) WithRequestID(requestID string) *OperationTracker {
+	ot.event.RequestID = requestID
+	return ot
+}
+
 // WithWordPress adds WordPress context
 func (ot *OperationTracker) WithWordPress(postID, mediaID int, slug string) *OperationTracker {
 	ot.event.WordPressPostID = postID
@@ -354,6 +477,13 @@ func (ot *OperationTracker) WithCounts(totalFiles, imageFiles, downloaded, skipp
 	return ot
 }
 
+// KeepAlways marks the event to bypass sampling, for per-film summaries
+// whose counts must always reach the log
+func (ot *OperationTracker) KeepAlways() *OperationTracker {
+	ot.event.alwaysKeep = true
+	return ot
+}
+
 // WithError adds error context
 func (ot *OperationTracker) WithError(err error) *OperationTracker {
 	if err != nil {
@@ -422,6 +552,18 @@ func Init(service string) {
 	defaultLogger = NewLogger(service)
 }
 
+// InitWithWriter initializes the global logger to write its events to w only, without a
+// run log file; tests use it to capture or discard events
+func InitWithWriter(service string, w io.Writer) {
+	defaultLogger = &Logger{
+		service:         service,
+		sampleRate:      0.1,
+		errorRate:       1.0,
+		slowThresholdMs: 5000,
+		output:          w,
+	}
+}
+
 // Get returns the global logger instance
 func Get() *Logger {
 	if defaultLogger == nil {
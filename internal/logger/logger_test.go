@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPruneLogs(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxAgeDays  int
+		files       map[string]int // file name -> age in days
+		wantRemoved []string
+	}{
+		{
+			name:        "older than the cutoff",
+			maxAgeDays:  7,
+			files:       map[string]int{"run-old.log": 30, "run-week.log": 8, "run-recent.log": 2},
+			wantRemoved: []string{"run-old.log", "run-week.log"},
+		},
+		{
+			name:       "all recent",
+			maxAgeDays: 7,
+			files:      map[string]int{"run-a.log": 1, "run-b.log": 6},
+		},
+		{
+			name:        "active log is kept",
+			maxAgeDays:  1,
+			files:       map[string]int{"run-active.log": 10, "run-old.log": 10},
+			wantRemoved: []string{"run-old.log"},
+		},
+		{
+			name:       "other files are kept",
+			maxAgeDays: 1,
+			files:      map[string]int{"notes.txt": 10, "run-old.json": 10},
+		},
+		{
+			name:       "zero keeps everything",
+			maxAgeDays: 0,
+			files:      map[string]int{"run-old.log": 400},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, age := range tt.files {
+				path := filepath.Join(dir, name)
+				if err := os.WriteFile(path, []byte("{}\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+				modTime := time.Now().AddDate(0, 0, -age)
+				if err := os.Chtimes(path, modTime, modTime); err != nil {
+					t.Fatal(err)
+				}
+			}
+			l := &Logger{logFilePath: filepath.Join(dir, "run-active.log")}
+
+			removed, err := l.PruneLogs(tt.maxAgeDays)
+			if err != nil {
+				t.Fatalf("PruneLogs: %v", err)
+			}
+			if removed != len(tt.wantRemoved) {
+				t.Errorf("PruneLogs removed %d files, want %d", removed, len(tt.wantRemoved))
+			}
+			for name := range tt.files {
+				_, statErr := os.Stat(filepath.Join(dir, name))
+				if gone := os.IsNotExist(statErr); gone != slices.Contains(tt.wantRemoved, name) {
+					t.Errorf("%s removed: %v, want %v", name, gone, !gone)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// maxLogLineSize bounds a single event line read back from a run log; request payloads
+// logged by the WordPress service can make lines far longer than bufio's default
+const maxLogLineSize = 64 * 1024 * 1024
+
+// FailedFilm identifies a film whose processing failed in a previous run
+type FailedFilm struct {
+	FilmID   string
+	FilmName string
+	FilmYear string
+}
+
+// ReadFailedFilms parses a run log and returns the films whose last "film_process"
+// finish event was an error, in the order they first appear. Films that failed and then
+// succeeded within the same log, as well as skipped films, are not returned
+func ReadFailedFilms(path string) ([]FailedFilm, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open run log: %v", err)
+	}
+	defer file.Close()
+
+	var order []string
+	films := make(map[string]FailedFilm)
+	failed := make(map[string]bool)
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogLineSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 || line[0] != '{' {
+			// Run start and end markers are plain text
+			continue
+		}
+		var event WideEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			continue
+		}
+		if event.Operation != "film_process" || event.FilmID == "" || event.Context["phase"] != "finish" {
+			continue
+		}
+		if _, seen := films[event.FilmID]; !seen {
+			order = append(order, event.FilmID)
+		}
+		films[event.FilmID] = FailedFilm{FilmID: event.FilmID, FilmName: event.FilmName, FilmYear: event.FilmYear}
+		failed[event.FilmID] = event.Outcome == "error"
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read run log: %v", err)
+	}
+
+	var result []FailedFilm
+	for _, filmID := range order {
+		if failed[filmID] {
+			result = append(result, films[filmID])
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sampleEvent returns a film_process event line as a run log records it
+func sampleEvent(filmID, filmName, year, phase, outcome string) string {
+	level := "info"
+	if outcome == "error" {
+		level = "error"
+	}
+	return `{"timestamp":"2025-03-01T10:00:00Z","level":"` + level + `","message":"film","service":"excentrico-tools-go","operation":"film_process",` +
+		`"outcome":"` + outcome + `","film_id":"` + filmID + `","film_name":"` + filmName + `","film_year":"` + year + `","context":{"phase":"` + phase + `"}}`
+}
+
+func TestReadFailedFilms(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  []FailedFilm
+	}{
+		{
+			name: "failed films in order",
+			lines: []string{
+				"=== Log run started at 2025-03-01T10:00:00Z ===",
+				sampleEvent("el-faro", "El faro", "2025", "start", "success"),
+				sampleEvent("el-faro", "El faro", "2025", "finish", "error"),
+				sampleEvent("la-playa", "La playa", "2025", "start", "success"),
+				sampleEvent("la-playa", "La playa", "2025", "finish", "success"),
+				sampleEvent("el-rio", "El río", "2024", "finish", "error"),
+				"=== Log run ended at 2025-03-01T10:05:00Z ===",
+			},
+			want: []FailedFilm{
+				{FilmID: "el-faro", FilmName: "El faro", FilmYear: "2025"},
+				{FilmID: "el-rio", FilmName: "El río", FilmYear: "2024"},
+			},
+		},
+		{
+			name: "failure then success",
+			lines: []string{
+				sampleEvent("el-faro", "El faro", "2025", "finish", "error"),
+				sampleEvent("el-faro", "El faro", "2025", "finish", "success"),
+			},
+		},
+		{
+			name: "success then failure",
+			lines: []string{
+				sampleEvent("el-faro", "El faro", "2025", "finish", "success"),
+				sampleEvent("el-faro", "El faro", "2025", "finish", "error"),
+			},
+			want: []FailedFilm{{FilmID: "el-faro", FilmName: "El faro", FilmYear: "2025"}},
+		},
+		{
+			name: "only finish events count",
+			lines: []string{
+				sampleEvent("el-faro", "El faro", "2025", "start", "error"),
+				`{"level":"error","operation":"wordpress_create_post","outcome":"error","film_id":"la-playa","film_name":"La playa"}`,
+				`{"level":"error","operation":"film_process","outcome":"error","film_name":"Sin ID","context":{"phase":"finish"}}`,
+			},
+		},
+		{
+			name: "unreadable lines are skipped",
+			lines: []string{
+				"",
+				"{not json",
+				`{"operation":"upload_media","context":{"payload":"` + strings.Repeat("x", 128*1024) + `"}}`,
+				sampleEvent("el-faro", "El faro", "2025", "finish", "error"),
+			},
+			want: []FailedFilm{{FilmID: "el-faro", FilmName: "El faro", FilmYear: "2025"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "run.log")
+			if err := os.WriteFile(path, []byte(strings.Join(tt.lines, "\n")+"\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ReadFailedFilms(path)
+			if err != nil {
+				t.Fatalf("ReadFailedFilms: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadFailedFilms() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ReadFailedFilms(filepath.Join(t.TempDir(), "missing.log")); err == nil {
+		t.Error("ReadFailedFilms of a missing log returned no error")
+	}
+}
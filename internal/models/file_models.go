@@ -10,6 +10,8 @@ type FileWithPath struct {
 	ModifiedTime string `json:"modifiedTime"`
 	FolderPath   string `json:"folder_path"`
 	FolderName   string `json:"folder_name"`
+	// BelowMinimum marks images smaller than the configured minimum dimensions, kept out of the gallery
+	BelowMinimum bool `json:"below_minimum,omitempty"`
 }
 
 // WordPressMetadata represents metadata for WordPress posts
@@ -20,4 +22,18 @@ type WordPressMetadata struct {
 	Status    string `json:"status"`
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
-}
\ No newline at end of file
+	// FeaturedMediaID is the featured image last set on the post, kept on later updates
+	FeaturedMediaID int `json:"featured_media_id,omitempty"`
+}
+
+// PosterMetadata represents the uploaded poster of a film, its featured image
+type PosterMetadata struct {
+	MediaID  int    `json:"media_id"`
+	FileName string `json:"file_name"`
+}
+
+// BackgroundImageMetadata represents the header background image chosen for a film
+type BackgroundImageMetadata struct {
+	MediaID int    `json:"media_id"`
+	URL     string `json:"url"`
+}
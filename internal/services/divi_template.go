@@ -3,7 +3,9 @@ package services
 import (
 	"encoding/base64"
 	"encoding/json"
+	"excentrico-tools-go/internal/logger"
 	"excentrico-tools-go/internal/models"
+	"excentrico-tools-go/internal/utils"
 	"fmt"
 	"html"
 	"io"
@@ -11,7 +13,10 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 )
 
 // Template constants - Colors
@@ -40,7 +45,9 @@ const (
 
 // Template constants - Builder version
 const (
-	BuilderVersion = "4.27.4"
+	// DefaultBuilderVersion is the Divi builder version written into shortcodes when the
+	// template file sets no builder_version
+	DefaultBuilderVersion = "4.27.4"
 )
 
 // Template constants - Font settings
@@ -68,12 +75,121 @@ const (
 	PaddingNotes    = "1%||1%|2%|false|false"
 )
 
-type DiviTemplateService struct{}
+type DiviTemplateService struct {
+	refreshBackground bool
+	imageURLsOnly     bool // Reference images by URL and ID only instead of embedding base64 data
+	templateOutput    string // One of the TemplateOutput targets; empty means WordPress
+	templateJSON      string // One of the TemplateJSON layouts; empty means indented
+	language          string // Site language of generated templates; empty means Spanish
+
+	mu                    sync.Mutex
+	missingDirectorImages map[string][]string // film ID -> directors without a matching image
+
+	fallbackMu    sync.Mutex
+	fallbackImage *FallbackImage  // Stands in for the images of films without any; nil disables it
+	fallbackMedia *WordPressMedia // Resolved fallback media, once looked up or uploaded
+	fallbackErr   error           // Failure resolving the fallback, kept so it is not retried per film
+}
 
 func NewDiviTemplateService() *DiviTemplateService {
 	return &DiviTemplateService{}
 }
 
+// MissingDirectorImages returns, per film ID, the directors for which no
+// matching image was found during template generation in this run
+func (s *DiviTemplateService) MissingDirectorImages() map[string][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make(map[string][]string, len(s.missingDirectorImages))
+	for filmID, directors := range s.missingDirectorImages {
+		result[filmID] = append([]string(nil), directors...)
+	}
+	return result
+}
+
+// recordMissingDirectorImages stores the directors without a photo for a film and
+// emits a structured warning the first time a given set is seen
+func (s *DiviTemplateService) recordMissingDirectorImages(filmID string, filmData *FilmData, directors []DirectorInfo) {
+	var missing []string
+	for _, director := range directors {
+		if director.ImageURL == "" {
+			missing = append(missing, director.Name)
+		}
+	}
+
+	s.mu.Lock()
+	previous, seen := s.missingDirectorImages[filmID]
+	if len(missing) == 0 {
+		delete(s.missingDirectorImages, filmID)
+		s.mu.Unlock()
+		return
+	}
+	if s.missingDirectorImages == nil {
+		s.missingDirectorImages = make(map[string][]string)
+	}
+	s.missingDirectorImages[filmID] = missing
+	s.mu.Unlock()
+
+	if seen && strings.Join(previous, "\x00") == strings.Join(missing, "\x00") {
+		return
+	}
+
+	op := logger.Get().StartOperation("director_images_missing")
+	op.WithFilm(filmID, filmData.TituloOriginal, "", filmData.Seccion)
+	op.WithContext("missing_directors", missing)
+	op.WithContext("director_count", len(directors))
+	op.Warn(&logger.WideEvent{
+		Message: fmt.Sprintf("No image found for %d of %d directors of '%s'", len(missing), len(directors), filmData.TituloOriginal),
+	})
+}
+
+// SetImageURLsOnly makes saved Divi template files reference images by URL and ID
+// only, leaving WordPress to resolve the media on import, instead of embedding base64 data
+func (s *DiviTemplateService) SetImageURLsOnly(urlsOnly bool) {
+	s.imageURLsOnly = urlsOnly
+}
+
+// SetTemplateOutput selects where generated templates go: WordPress (post and file),
+// the file only, or stdout
+func (s *DiviTemplateService) SetTemplateOutput(output string) {
+	s.templateOutput = output
+}
+
+// SetTemplateJSON selects how divi_template.json is laid out: indented, minified,
+// or both as divi_template.json and divi_template.min.json
+func (s *DiviTemplateService) SetTemplateJSON(layout string) {
+	s.templateJSON = layout
+}
+
+// TemplateOutput returns the selected template output target
+func (s *DiviTemplateService) TemplateOutput() string {
+	if s.templateOutput == "" {
+		return TemplateOutputWordPress
+	}
+	return s.templateOutput
+}
+
+// SetLanguage selects the language of the template's labels, synopsis and button
+func (s *DiviTemplateService) SetLanguage(lang string) {
+	s.language = lang
+}
+
+// TemplateLanguage returns the language of a template: the template file's language when
+// set, otherwise the site language
+func (s *DiviTemplateService) TemplateLanguage(templateConfig *TemplateData) string {
+	if templateConfig != nil && templateConfig.Language != "" {
+		return strings.ToLower(strings.TrimSpace(templateConfig.Language))
+	}
+	return s.language
+}
+
+// SetRefreshBackground forces the background image to be re-evaluated instead of
+// reusing the choice stored in Turso from a previous run
+func (s *DiviTemplateService) SetRefreshBackground(refresh bool) {
+	s.refreshBackground = refresh
+}
+
 func escapeHtml(text string) string {
 	return html.EscapeString(text)
 }
@@ -104,6 +220,51 @@ func parseDuration(duration string) string {
 	return "0´0"
 }
 
+// Duration display formats, shown here for a running time of 1:30:00
+const (
+	DurationFormatAcute   = "acute"   // 90´00, the festival's house style
+	DurationFormatMinutes = "minutes" // 90 min
+	DurationFormatHours   = "hours"   // 1h30
+	DurationFormatPrime   = "prime"   // 90'
+)
+
+// IsValidDurationFormat reports whether format names a duration display format
+func IsValidDurationFormat(format string) bool {
+	switch format {
+	case DurationFormatAcute, DurationFormatMinutes, DurationFormatHours, DurationFormatPrime:
+		return true
+	}
+	return false
+}
+
+// formatDuration renders a sheet duration (h:mm:ss or mm:ss) in the given format;
+// empty or acute keeps the parseDuration style
+func formatDuration(duration, format string) string {
+	if format == "" || format == DurationFormatAcute {
+		return parseDuration(duration)
+	}
+
+	minutes := 0
+	parts := strings.Split(duration, ":")
+	if len(parts) == 3 {
+		hours := 0
+		fmt.Sscanf(strings.TrimSpace(parts[0]), "%d", &hours)
+		fmt.Sscanf(strings.TrimSpace(parts[1]), "%d", &minutes)
+		minutes += hours * 60
+	} else if len(parts) == 2 {
+		fmt.Sscanf(strings.TrimSpace(parts[0]), "%d", &minutes)
+	}
+
+	switch format {
+	case DurationFormatMinutes:
+		return fmt.Sprintf("%d min", minutes)
+	case DurationFormatHours:
+		return fmt.Sprintf("%dh%02d", minutes/60, minutes%60)
+	default:
+		return fmt.Sprintf("%d'", minutes)
+	}
+}
+
 type DirectorInfo struct {
 	Name     string `json:"name"`
 	ImageURL string `json:"image_url,omitempty"`
@@ -132,6 +293,9 @@ type Header struct {
 }
 
 type Menu struct {
+	// Enabled renders the in-page menu below the header; unset leaves it on. The menu is
+	// omitted either way while MenuId is empty, as Divi renders an empty menu bar for it
+	Enabled         *bool  `json:"enabled,omitempty"`
 	MenuId          string `json:"menu_id,omitempty"`
 	ActiveLinkColor string `json:"active_link_color,omitempty"`
 	MenuTextColor   string `json:"menu_text_color,omitempty"`
@@ -139,6 +303,14 @@ type Menu struct {
 	BackgroundImage string `json:"background_image,omitempty"`
 }
 
+// Rendered reports whether the menu is enabled and has a menu to show
+func (m Menu) Rendered() bool {
+	if m.Enabled != nil && !*m.Enabled {
+		return false
+	}
+	return strings.TrimSpace(m.MenuId) != ""
+}
+
 type Section struct {
 	Background                   string `json:"background_color,omitempty"`
 	BackgroundColorGradientStops string `json:"background_color_gradient_stops,omitempty"`
@@ -158,8 +330,78 @@ type TemplateData struct {
 	Texto    		Text    `json:"texto"`
 	Ndc         Ndc     `json:"ndc"`
 	Footer			Footer  `json:"footer"`
+	// MaxDirectorsFull is the number of directors above which the director section
+	// switches to a compact names-only layout; 0 uses DefaultMaxDirectorsFull
+	MaxDirectorsFull int `json:"max_directors_full,omitempty"`
+	// SynopsisSource selects the synopsis shown in the main content (extended, compact, english)
+	SynopsisSource string `json:"synopsis_source,omitempty"`
+	// ExcerptSource selects the synopsis used as the post excerpt; empty leaves the excerpt unset
+	ExcerptSource string `json:"excerpt_source,omitempty"`
+	// GalleryCaptions sets gallery image captions: "filename" derives them from the
+	// still's file name (overridable per film in captions.json); empty leaves them unset
+	GalleryCaptions string `json:"gallery_captions,omitempty"`
+	// MaxGalleryImages caps the gallery to the first N stills by file name; 0 is unlimited
+	MaxGalleryImages int `json:"max_gallery_images,omitempty"`
+	// DurationFormat selects how the running time is shown; see the DurationFormat constants
+	DurationFormat string `json:"duration_format,omitempty"`
+	// BuilderVersion is the Divi builder version written into every shortcode; empty uses DefaultBuilderVersion
+	BuilderVersion string `json:"builder_version,omitempty"`
+	// Language overrides the site language (-lang) for this edition's templates: es or en
+	Language string `json:"language,omitempty"`
+}
+
+// ResolvedBuilderVersion returns the configured Divi builder version or DefaultBuilderVersion
+func (t *TemplateData) ResolvedBuilderVersion() string {
+	if t == nil || t.BuilderVersion == "" {
+		return DefaultBuilderVersion
+	}
+	return t.BuilderVersion
 }
 
+var builderVersionPattern = regexp.MustCompile(`^\d+(\.\d+)*$`)
+
+// GalleryCaptionsFilename derives gallery captions from the stills' file names
+const GalleryCaptionsFilename = "filename"
+
+// Validate checks the values of a loaded template configuration
+func (t *TemplateData) Validate() error {
+	if t.SynopsisSource != "" && !IsValidSynopsisSource(t.SynopsisSource) {
+		return fmt.Errorf("invalid synopsis_source %q: must be one of %s, %s, %s", t.SynopsisSource, SynopsisSourceExtended, SynopsisSourceCompact, SynopsisSourceEnglish)
+	}
+	if t.ExcerptSource != "" && !IsValidSynopsisSource(t.ExcerptSource) {
+		return fmt.Errorf("invalid excerpt_source %q: must be one of %s, %s, %s", t.ExcerptSource, SynopsisSourceExtended, SynopsisSourceCompact, SynopsisSourceEnglish)
+	}
+	if t.MaxGalleryImages < 0 {
+		return fmt.Errorf("invalid max_gallery_images %d: must be 0 (unlimited) or positive", t.MaxGalleryImages)
+	}
+	if t.DurationFormat != "" && !IsValidDurationFormat(t.DurationFormat) {
+		return fmt.Errorf("invalid duration_format %q: must be one of %s, %s, %s, %s", t.DurationFormat, DurationFormatAcute, DurationFormatMinutes, DurationFormatHours, DurationFormatPrime)
+	}
+	if t.BuilderVersion != "" && !builderVersionPattern.MatchString(t.BuilderVersion) {
+		return fmt.Errorf("invalid builder_version %q: must be a dotted version such as %s", t.BuilderVersion, DefaultBuilderVersion)
+	}
+	if t.Language != "" {
+		if _, err := ParseLanguage(t.Language); err != nil {
+			return fmt.Errorf("invalid language %q: %v", t.Language, err)
+		}
+	}
+	if t.GalleryCaptions != "" && t.GalleryCaptions != GalleryCaptionsFilename {
+		return fmt.Errorf("invalid gallery_captions %q: must be empty or %s", t.GalleryCaptions, GalleryCaptionsFilename)
+	}
+	if t.Menu.Enabled != nil && *t.Menu.Enabled && strings.TrimSpace(t.Menu.MenuId) == "" {
+		return fmt.Errorf("invalid menu: enabled requires menu_id")
+	}
+	for i, network := range t.Footer.SocialNetworks {
+		if strings.TrimSpace(network.Network) == "" || strings.TrimSpace(network.URL) == "" {
+			return fmt.Errorf("invalid footer.social_networks[%d]: network and url are required", i)
+		}
+	}
+	return nil
+}
+
+// DefaultMaxDirectorsFull is the director count above which the compact layout is used
+const DefaultMaxDirectorsFull = 6
+
 type Footer struct {
 	Section struct {
 		BackgroundImage string `json:"background_image"`
@@ -171,7 +413,64 @@ type Footer struct {
 		ButtonIconColor string `json:"button_icon_color"`
 		ButtonBorderColor string `json:"button_border_color"`
 		ButtonTextColor string `json:"button_text_color"`
+		// Label is the button text; "{year}" is replaced with the edition year
+		Label string `json:"label,omitempty"`
+		// URL is a fixed link target; when empty the button links dynamically to PostID
+		URL string `json:"url,omitempty"`
+		// PostID is the WordPress page the button links to when URL is empty
+		PostID int `json:"post_id,omitempty"`
+	} `json:"button"`
+	// SocialNetworks are the follow icons of the footer in order; unset uses
+	// DefaultSocialNetworks and an empty list shows none
+	SocialNetworks []SocialNetwork `json:"social_networks,omitempty"`
+}
+
+// SocialNetwork is a footer follow icon: a Divi social_network name such as "facebook",
+// "instagram", "tiktok" or "youtube", and the profile it links to
+type SocialNetwork struct {
+	Network string `json:"network"`
+	URL     string `json:"url"`
+}
+
+// DefaultSocialNetworks returns the festival profiles the footer links to by default
+func DefaultSocialNetworks() []SocialNetwork {
+	return []SocialNetwork{
+		{Network: "facebook", URL: URLFacebook},
+		{Network: "instagram", URL: URLInstagram},
+		{Network: "twitter", URL: URLTwitter},
+	}
+}
+
+// Defaults for the footer "convocatoria" button
+const (
+	DefaultFooterButtonLabel  = "convocatoria {year}"
+	DefaultFooterButtonPostID = 10424
+)
+
+// footerButtonLabel expands the configured label for the given year, falling back to
+// DefaultFooterButtonLabel; without a year the placeholder and surrounding spaces are dropped
+func footerButtonLabel(label, year string) string {
+	if label == "" {
+		label = DefaultFooterButtonLabel
+	}
+	return strings.TrimSpace(strings.ReplaceAll(label, "{year}", year))
+}
+
+// footerButtonLink returns the button_url value and, for dynamic post links, the
+// _dynamic_attributes marker Divi needs to resolve it
+func footerButtonLink(url string, postID int) (string, string) {
+	if url != "" {
+		return url, ""
+	}
+	if postID <= 0 {
+		postID = DefaultFooterButtonPostID
 	}
+	dynamic, _ := json.Marshal(struct {
+		Dynamic  bool              `json:"dynamic"`
+		Content  string            `json:"content"`
+		Settings map[string]string `json:"settings"`
+	}{true, "post_link_url_page", map[string]string{"post_id": strconv.Itoa(postID)}})
+	return "@ET-DC@" + base64.StdEncoding.EncodeToString(dynamic) + "@", `_dynamic_attributes="button_url"`
 }
 
 type Credits struct {
@@ -185,6 +484,16 @@ type Credits struct {
 	OtherCredits string `json:"other_credits,omitempty"`
 }
 
+// IsEmpty reports whether every credit field is blank
+func (c Credits) IsEmpty() bool {
+	for _, value := range []string{c.Production, c.Script, c.Photography, c.ArtDesign, c.SoundMusic, c.Editing, c.Cast, c.OtherCredits} {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
 type Ndc struct {
 	Text struct {
 		DisabledOn      string `json:"disabled_on"`
@@ -213,7 +522,7 @@ func (s *DiviTemplateService) GenerateDiviTemplateDataWithWordPress(filmData *Fi
 	// Parse directors with bio information
 	var directors []DirectorInfo
 	if filmData.Direccion != "" {
-		if strings.ToUpper(filmData.MultiDir) == "SI" {
+		if filmData.HasMultipleDirectors() {
 			directorNames := parseDirectors(filmData.Direccion)
 			for _, name := range directorNames {
 				directors = append(directors, DirectorInfo{
@@ -231,6 +540,10 @@ func (s *DiviTemplateService) GenerateDiviTemplateDataWithWordPress(filmData *Fi
 		}
 	}
 
+	if filmID != "" {
+		s.recordMissingDirectorImages(filmID, filmData, directors)
+	}
+
 	// Format duration
 	formattedDuration := ""
 	if filmData.Duracion != "" {
@@ -238,16 +551,7 @@ func (s *DiviTemplateService) GenerateDiviTemplateDataWithWordPress(filmData *Fi
 	}
 
 	// Build credits structure
-	credits := Credits{
-		Production:   filmData.Produccion,
-		Script:       filmData.Guion,
-		Photography:  filmData.CamaraFoto,
-		ArtDesign:    filmData.ArteDiseno,
-		SoundMusic:   filmData.SonidoMusica,
-		Editing:      filmData.EdicionCredits,
-		Cast:         filmData.Interpretes,
-		OtherCredits: filmData.OtrosCreditos,
-	}
+	credits := filmData.Credits()
 
 	// Filter to only include stills images for the gallery
 	stillsImageIds := s.filterStillsImages(imageIds, tursoService, filmID)
@@ -259,11 +563,8 @@ func (s *DiviTemplateService) GenerateDiviTemplateDataWithWordPress(filmData *Fi
 	}
 	galleryMediaIds := strings.Join(galleryIds, ",")
 
-	var backgroundImage string
-	// Try to infer a background image from uploaded media
-	if url := s.selectBackgroundImageURL(imageIds, wordpressService); url != "" {
-		backgroundImage = url
-	}
+	// Reuse the stored background choice or infer one from uploaded media
+	backgroundImage := s.resolveBackgroundImageURL(imageIds, wordpressService, tursoService, filmID)
 	
 	// Create the complete template data
 	template := &DiviFilmTemplate{
@@ -280,45 +581,27 @@ func (s *DiviTemplateService) GenerateDiviTemplateDataWithWordPress(filmData *Fi
 		GalleryMediaIds: galleryMediaIds,
 	}
 
+	if len(imageIds) == 0 {
+		s.applyFallbackImage(template, wordpressService, filmID)
+	}
+
 	return template
 }
 
 // filterStillsImages filters imageIds to only include images from the Stills folder
-// Uses drive metadata to determine which images are from the Stills folder
+// Uses drive metadata to determine which images are from the Stills folder; the poster is left out
 func (s *DiviTemplateService) filterStillsImages(imageIds []int, tursoService *TursoService, filmID string) []int {
 	if tursoService == nil || len(imageIds) == 0 || filmID == "" {
 		return []int{}
 	}
 
 	// Get WordPress media metadata to map image IDs to file paths
-	var wpMediaMetadata []map[string]any
-	err := tursoService.GetMetadata(filmID, "wordpress_media", &wpMediaMetadata)
+	mediaIDToFilePath, err := mediaFilePaths(tursoService, filmID)
 	if err != nil {
 		// If no WordPress media metadata, return empty
 		return []int{}
 	}
 
-	// Create a map from WordPress media ID to file path
-	mediaIDToFilePath := make(map[int]string)
-	for _, media := range wpMediaMetadata {
-		if idValue, exists := media["id"]; exists {
-			var mediaID int
-			switch v := idValue.(type) {
-			case int:
-				mediaID = v
-			case float64:
-				mediaID = int(v)
-			default:
-				continue
-			}
-			if filePathValue, exists := media["file_path"]; exists {
-				if filePath, ok := filePathValue.(string); ok {
-					mediaIDToFilePath[mediaID] = filePath
-				}
-			}
-		}
-	}
-
 	// Get drive files metadata
 	var driveFiles []*models.FileWithPath
 	err = tursoService.GetDriveFilesMetadata(filmID, &driveFiles)
@@ -327,11 +610,15 @@ func (s *DiviTemplateService) filterStillsImages(imageIds []int, tursoService *T
 		return []int{}
 	}
 
-	// Create a map from filename (without _web.jpg) to FolderName
+	// Create a map from filename (without the optimized suffix) to FolderName
 	filenameToFolder := make(map[string]string)
 	for _, file := range driveFiles {
-		// Remove _web.jpg suffix if present, and normalize
-		filename := strings.TrimSuffix(strings.ToLower(file.Name), "_web.jpg")
+		if file.BelowMinimum {
+			// Too small for the gallery (icons, avatars)
+			continue
+		}
+		// Remove the optimized suffix (_web.jpg) if present, and normalize
+		filename := utils.TrimOptimizedSuffix(strings.ToLower(file.Name))
 		// Also try without any extension
 		if dot := strings.LastIndex(filename, "."); dot != -1 {
 			filenameWithoutExt := filename[:dot]
@@ -340,9 +627,18 @@ func (s *DiviTemplateService) filterStillsImages(imageIds []int, tursoService *T
 		filenameToFolder[filename] = strings.ToLower(file.FolderName)
 	}
 
+	// The poster is the featured image even when it sits in the Stills folder
+	poster := &models.PosterMetadata{}
+	if tursoService.GetPosterMetadata(filmID, poster) != nil {
+		poster.MediaID = 0
+	}
+
 	// Filter imageIds to only include those from Stills folder
 	var stillsIds []int
 	for _, id := range imageIds {
+		if id == poster.MediaID {
+			continue
+		}
 		filePath, exists := mediaIDToFilePath[id]
 		if !exists {
 			continue
@@ -350,8 +646,8 @@ func (s *DiviTemplateService) filterStillsImages(imageIds []int, tursoService *T
 
 		// Extract filename from file path
 		fileName := filepath.Base(filePath)
-		// Remove _web.jpg suffix
-		fileName = strings.TrimSuffix(strings.ToLower(fileName), "_web.jpg")
+		// Remove the optimized suffix
+		fileName = utils.TrimOptimizedSuffix(strings.ToLower(fileName))
 		// Remove extension
 		if dot := strings.LastIndex(fileName, "."); dot != -1 {
 			fileName = fileName[:dot]
@@ -368,12 +664,105 @@ func (s *DiviTemplateService) filterStillsImages(imageIds []int, tursoService *T
 	return stillsIds
 }
 
-// selectBackgroundImageURL attempts to pick a background image URL from media
+// mediaFilePaths maps the film's uploaded WordPress media IDs to their local file paths
+func mediaFilePaths(tursoService *TursoService, filmID string) (map[int]string, error) {
+	var wpMediaMetadata []map[string]any
+	if err := tursoService.GetMetadata(filmID, "wordpress_media", &wpMediaMetadata); err != nil {
+		return nil, err
+	}
+
+	mediaIDToFilePath := make(map[int]string)
+	for _, media := range wpMediaMetadata {
+		if idValue, exists := media["id"]; exists {
+			var mediaID int
+			switch v := idValue.(type) {
+			case int:
+				mediaID = v
+			case float64:
+				mediaID = int(v)
+			default:
+				continue
+			}
+			if filePathValue, exists := media["file_path"]; exists {
+				if filePath, ok := filePathValue.(string); ok {
+					mediaIDToFilePath[mediaID] = filePath
+				}
+			}
+		}
+	}
+	return mediaIDToFilePath, nil
+}
+
+// capGallery keeps the first maxImages gallery stills in file name order and returns
+// how many were omitted
+func capGallery(templateData *DiviFilmTemplate, maxImages int, tursoService *TursoService, filmID string) int {
+	if maxImages <= 0 || len(templateData.ImageGalleryIds) <= maxImages {
+		return 0
+	}
+
+	fileNames := make(map[int]string)
+	if tursoService != nil {
+		if paths, err := mediaFilePaths(tursoService, filmID); err == nil {
+			for id, path := range paths {
+				fileNames[id] = strings.ToLower(filepath.Base(path))
+			}
+		}
+	}
+
+	ids := append([]int(nil), templateData.ImageGalleryIds...)
+	sort.SliceStable(ids, func(i, j int) bool {
+		return fileNames[ids[i]] < fileNames[ids[j]]
+	})
+
+	omitted := len(ids) - maxImages
+	templateData.ImageGalleryIds = ids[:maxImages]
+
+	galleryIds := make([]string, 0, maxImages)
+	for _, id := range templateData.ImageGalleryIds {
+		galleryIds = append(galleryIds, fmt.Sprintf("%d", id))
+	}
+	templateData.GalleryMediaIds = strings.Join(galleryIds, ",")
+	return omitted
+}
+
+// resolveBackgroundImageURL returns the background image URL for a film
+// The stored choice is reused while its media is still attached to the film,
+// otherwise a new one is selected and persisted so the header stays stable across runs
+func (s *DiviTemplateService) resolveBackgroundImageURL(imageIds []int, wordpressService *WordPressService, tursoService *TursoService, filmID string) string {
+	if tursoService == nil || filmID == "" {
+		_, url := s.selectBackgroundImage(imageIds, wordpressService)
+		return url
+	}
+
+	if !s.refreshBackground {
+		stored := &models.BackgroundImageMetadata{}
+		if err := tursoService.GetBackgroundImageMetadata(filmID, stored); err == nil && stored.URL != "" {
+			for _, id := range imageIds {
+				if id == stored.MediaID {
+					return stored.URL
+				}
+			}
+		}
+	}
+
+	mediaID, url := s.selectBackgroundImage(imageIds, wordpressService)
+	if url == "" {
+		return ""
+	}
+
+	if err := tursoService.SaveBackgroundImageMetadata(filmID, &models.BackgroundImageMetadata{MediaID: mediaID, URL: url}); err != nil {
+		fmt.Printf("Warning: Failed to save background image choice for '%s': %v\n", filmID, err)
+	}
+
+	return url
+}
+
+// selectBackgroundImage attempts to pick a background image from media
 // Preference order by media title/filename/alt text contains: background, header, fondo, bg
-// Falls back to the first media URL if any
-func (s *DiviTemplateService) selectBackgroundImageURL(imageIds []int, wordpressService *WordPressService) string {
+// Falls back to the first media if any
+func (s *DiviTemplateService) selectBackgroundImage(imageIds []int, wordpressService *WordPressService) (int, string) {
 	if wordpressService == nil || len(imageIds) == 0 {
-		return ""
+		return 0, ""
 	}
 
 	keywords := []string{"background", "header", "fondo", "bg"}
@@ -401,7 +790,7 @@ func (s *DiviTemplateService) selectBackgroundImageURL(imageIds []int, wordpress
 
 		for _, kw := range keywords {
 			if strings.Contains(title, kw) || strings.Contains(filename, kw) || strings.Contains(alt, kw) {
-				return media.SourceURL
+				return id, media.SourceURL
 			}
 		}
 	}
@@ -409,10 +798,10 @@ func (s *DiviTemplateService) selectBackgroundImageURL(imageIds []int, wordpress
 	// Second pass: fallback to first media URL
 	first, err := wordpressService.GetMedia(imageIds[0])
 	if err == nil {
-		return first.SourceURL
+		return imageIds[0], first.SourceURL
 	}
 
-	return ""
+	return 0, ""
 }
 
 func (s *DiviTemplateService) GenerateDiviShortcodeTemplate(templateData *DiviFilmTemplate, year string, templateConfig *TemplateData) string {
@@ -423,6 +812,28 @@ func (s *DiviTemplateService) GenerateDiviShortcodeTemplate(templateData *DiviFi
 
 func (s *DiviTemplateService) GenerateCompleteTemplate(filmData *FilmData, imageIds []int, wordpressService *WordPressService, tursoService *TursoService, filmID string, year string, templateConfig *TemplateData) (*DiviFilmTemplate, string) {
 	templateData := s.GenerateDiviTemplateDataWithWordPress(filmData, imageIds, wordpressService, tursoService, filmID)
+	synopsisSource := ""
+	if templateConfig != nil {
+		synopsisSource = templateConfig.SynopsisSource
+	}
+	// The English edition shows the English synopsis in place of the extended one
+	if s.TemplateLanguage(templateConfig) == LanguageEnglish && (synopsisSource == "" || synopsisSource == SynopsisSourceExtended) && filmData.ExtendedSynopsis != "" {
+		templateData.Synopsis = filmData.ExtendedSynopsis
+	} else if synopsisSource != "" {
+		templateData.Synopsis = filmData.Synopsis(synopsisSource)
+	}
+	if templateConfig != nil && templateConfig.DurationFormat != "" && filmData.Duracion != "" {
+		templateData.Duration = formatDuration(filmData.Duracion, templateConfig.DurationFormat)
+	}
+	if templateConfig != nil && templateConfig.MaxGalleryImages > 0 {
+		if omitted := capGallery(templateData, templateConfig.MaxGalleryImages, tursoService, filmID); omitted > 0 {
+			op := logger.Get().StartOperation("cap_gallery_images")
+			op.WithFilm(filmID, filmData.TituloOriginal, year, filmData.Seccion)
+			op.WithContext("max_gallery_images", templateConfig.MaxGalleryImages)
+			op.WithContext("omitted_count", omitted)
+			op.Complete(fmt.Sprintf("Gallery capped at %d images, %d omitted", templateConfig.MaxGalleryImages, omitted))
+		}
+	}
 
 	shortcodes := s.GenerateDiviShortcodeTemplate(templateData, year, templateConfig)
 
@@ -434,30 +845,48 @@ func (s *DiviTemplateService) CreateStandardFilmTemplate(templateData *DiviFilmT
 	// Create subhead with country, year, and duration
 	subhead := fmt.Sprintf("%s · %s · %s", templateData.Country, templateData.Year, templateData.Duration)
 
-	// Build button text
-	buttonText := "convocatoria"
-	if year != "" {
-		buttonText = fmt.Sprintf("convocatoria %s", year)
+	labels := LabelsFor(s.TemplateLanguage(templateConfig))
+	builderVersion := templateConfig.ResolvedBuilderVersion()
+
+	// Build button text; the built-in label follows the site language
+	buttonLabel := templateConfig.Footer.Button.Label
+	if buttonLabel == "" || buttonLabel == DefaultFooterButtonLabel {
+		buttonLabel = labels.FooterButton
 	}
+	buttonText := footerButtonLabel(buttonLabel, year)
 
 	// Create reusable components
 	creditsComponent := &CreditsComponent{
 		Directors: templateData.Directors,
 		Credits:   templateData.Credits,
+		Labels:    labels,
 	}
 
 	contentNotesComponent := &ContentNotesComponent{
 		ContentNotes: templateData.ContentNotes,
 		NdcProps:     templateConfig.Ndc,
+		Labels:       labels,
+
+		BuilderVersion: builderVersion,
+	}
+
+	maxDirectorsFull := templateConfig.MaxDirectorsFull
+	if maxDirectorsFull <= 0 {
+		maxDirectorsFull = DefaultMaxDirectorsFull
 	}
 
 	directorComponent := &DirectorComponent{
 		Directors: templateData.Directors,
 		TextProps: templateConfig.Texto,
+		Compact:   len(templateData.Directors) > maxDirectorsFull,
+		Labels:    labels,
+
+		BuilderVersion: builderVersion,
 	}
 
 	galleryComponent := &GalleryComponent{
-		MediaIds: templateData.GalleryMediaIds,
+		MediaIds:       templateData.GalleryMediaIds,
+		BuilderVersion: builderVersion,
 	}
 
 	// Build standard template composition
@@ -467,9 +896,11 @@ func (s *DiviTemplateService) CreateStandardFilmTemplate(templateData *DiviFilmT
 			Subhead:         subhead,
 			HeaderProps:     templateConfig.Header,
 			BackgroundImage: templateData.BackgroundImage,
+			BuilderVersion:  builderVersion,
 		}).
 		AddComponent(&MenuComponent{
-			MenuProps: templateConfig.Menu,
+			MenuProps:      templateConfig.Menu,
+			BuilderVersion: builderVersion,
 		}).
 		AddComponent(&MainContentComponent{
 			CreditsComponent:      creditsComponent,
@@ -479,15 +910,18 @@ func (s *DiviTemplateService) CreateStandardFilmTemplate(templateData *DiviFilmT
 			GalleryComponent:      galleryComponent,
 			SectionProps:          templateConfig.Contenido,
 			TextProps:             templateConfig.Texto,
+			Labels:                labels,
+			BuilderVersion:        builderVersion,
 		}).
 		AddComponent(&FooterComponent{
 			ButtonText: buttonText,
 			FooterProps: templateConfig.Footer,
+			BuilderVersion: builderVersion,
 		})
 }
 
 type DiviImageData struct {
-	Encoded string `json:"encoded"`
+	Encoded string `json:"encoded,omitempty"`
 	URL     string `json:"url"`
 	ID      int    `json:"id"`
 }
@@ -504,10 +938,26 @@ type DiviTemplateFile struct {
 func (s *DiviTemplateService) SaveDiviTemplateToFile(filmData *FilmData, imageIds []int, wordpressService *WordPressService, tursoService *TursoService, filmID string, filmDir string, year string, wordpressPostID int, templateConfig *TemplateData) error {
 	_, shortcodes := s.GenerateCompleteTemplate(filmData, imageIds, wordpressService, tursoService, filmID, year, templateConfig)
 
+	return s.writeTemplateFile(&RenderedTemplate{
+		FilmID:         filmID,
+		FilmDir:        filmDir,
+		PostID:         wordpressPostID,
+		ImageIds:       imageIds,
+		Shortcodes:     shortcodes,
+		BuilderVersion: templateConfig.ResolvedBuilderVersion(),
+	}, wordpressService)
+}
+
+// writeTemplateFile saves a rendered template as divi_template.json in its film directory
+func (s *DiviTemplateService) writeTemplateFile(rendered *RenderedTemplate, wordpressService *WordPressService) error {
 	// Use WordPress Post ID instead of film title for better consistency
-	projectID := fmt.Sprintf("%d", wordpressPostID)
+	projectID := fmt.Sprintf("%d", rendered.PostID)
+	builderVersion := rendered.BuilderVersion
+	if builderVersion == "" {
+		builderVersion = DefaultBuilderVersion
+	}
 
-	images, err := s.fetchWordPressImagesData(imageIds, wordpressService)
+	images, err := s.fetchWordPressImagesData(rendered.ImageIds, wordpressService)
 	if err != nil {
 		return fmt.Errorf("failed to fetch WordPress images data: %v", err)
 	}
@@ -515,14 +965,14 @@ func (s *DiviTemplateService) SaveDiviTemplateToFile(filmData *FilmData, imageId
 	templateFile := &DiviTemplateFile{
 		Context: "et_builder",
 		Data: map[string]string{
-			projectID: shortcodes,
+			projectID: rendered.Shortcodes,
 		},
 		Presets: map[string]any{
 			"et_pb_row": map[string]any{
 				"presets": map[string]any{
 					"_initial": map[string]any{
 						"name":    "Fila Preset 1",
-						"version": BuilderVersion,
+						"version": builderVersion,
 						"settings": map[string]any{
 							"use_custom_gutter": "off",
 							"gutter_width":      "1",
@@ -544,17 +994,38 @@ func (s *DiviTemplateService) SaveDiviTemplateToFile(filmData *FilmData, imageId
 		Thumbnails: []any{},
 	}
 
-	templatePath := filepath.Join(filmDir, "divi_template.json")
-	templateJSON, err := json.MarshalIndent(templateFile, "", "  ")
+	templatePath := filepath.Join(rendered.FilmDir, "divi_template.json")
+	switch s.templateJSON {
+	case TemplateJSONMinified:
+		return writeTemplateJSON(templatePath, templateFile, false)
+	case TemplateJSONBoth:
+		if err := writeTemplateJSON(templatePath, templateFile, true); err != nil {
+			return err
+		}
+		return writeTemplateJSON(filepath.Join(rendered.FilmDir, "divi_template.min.json"), templateFile, false)
+	default:
+		return writeTemplateJSON(templatePath, templateFile, true)
+	}
+}
+
+// writeTemplateJSON saves a template file at path, indented or minified
+func writeTemplateJSON(path string, templateFile *DiviTemplateFile, indent bool) error {
+	var templateJSON []byte
+	var err error
+	if indent {
+		templateJSON, err = json.MarshalIndent(templateFile, "", "  ")
+	} else {
+		templateJSON, err = json.Marshal(templateFile)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to marshal template file: %v", err)
 	}
 
-	if err := os.WriteFile(templatePath, templateJSON, 0o644); err != nil {
+	if err := os.WriteFile(path, templateJSON, 0o644); err != nil {
 		return fmt.Errorf("failed to write template file: %v", err)
 	}
 
-	fmt.Printf("Saved Divi template to: %s\n", templatePath)
+	fmt.Printf("Saved Divi template to: %s\n", path)
 
 	return nil
 }
@@ -569,10 +1040,13 @@ func (s *DiviTemplateService) fetchWordPressImagesData(imageIds []int, wordpress
 			continue
 		}
 
-		encoded, err := s.downloadAndEncodeImage(media.SourceURL)
-		if err != nil {
-			fmt.Printf("Warning: Failed to encode image %d: %v\n", imageID, err)
-			encoded = ""
+		encoded := ""
+		if !s.imageURLsOnly {
+			encoded, err = s.downloadAndEncodeImage(media.SourceURL)
+			if err != nil {
+				fmt.Printf("Warning: Failed to encode image %d: %v\n", imageID, err)
+				encoded = ""
+			}
 		}
 
 		imageData := DiviImageData{
@@ -588,7 +1062,16 @@ func (s *DiviTemplateService) fetchWordPressImagesData(imageIds []int, wordpress
 }
 
 func (s *DiviTemplateService) downloadAndEncodeImage(url string) (string, error) {
-	resp, err := http.Get(url)
+	// Dry-run media points at the local optimized file
+	if path, ok := strings.CutPrefix(url, "file://"); ok {
+		imageData, err := os.ReadFile(filepath.FromSlash(path))
+		if err != nil {
+			return "", fmt.Errorf("failed to read image data: %v", err)
+		}
+		return base64.StdEncoding.EncodeToString(imageData), nil
+	}
+
+	resp, err := newHTTPClient().Get(url)
 	if err != nil {
 		return "", fmt.Errorf("failed to download image: %v", err)
 	}
@@ -688,17 +1171,19 @@ type TemplateComponent interface {
 type CreditsComponent struct {
 	Directors []DirectorInfo
 	Credits   Credits
+	Labels    Labels
 }
 
 func (c *CreditsComponent) Render() string {
 	var creditsHTML strings.Builder
+	labels := resolveLabels(c.Labels)
 
 	if len(c.Directors) > 0 {
 		var directorNames []string
 		for _, director := range c.Directors {
 			directorNames = append(directorNames, director.Name)
 		}
-		creditsHTML.WriteString(fmt.Sprintf(`<p><strong>Dirección:</strong> %s</p>`, escapeHtml(strings.Join(directorNames, ", "))))
+		creditsHTML.WriteString(fmt.Sprintf(`<p><strong>%s:</strong> %s</p>`, labels.Direction, escapeHtml(strings.Join(directorNames, ", "))))
 	}
 
 	// If OtherCredits is present, use it instead of individual credit fields
@@ -718,25 +1203,25 @@ func (c *CreditsComponent) Render() string {
 	} else {
 		// Fall back to individual credit fields if OtherCredits is not present
 		if c.Credits.Production != "" {
-			creditsHTML.WriteString(fmt.Sprintf(`<p><strong>Producción:</strong> %s</p>`, escapeHtml(c.Credits.Production)))
+			creditsHTML.WriteString(fmt.Sprintf(`<p><strong>%s:</strong> %s</p>`, labels.Production, escapeHtml(c.Credits.Production)))
 		}
 		if c.Credits.Script != "" {
-			creditsHTML.WriteString(fmt.Sprintf(`<p><strong>Guión:</strong> %s</p>`, escapeHtml(c.Credits.Script)))
+			creditsHTML.WriteString(fmt.Sprintf(`<p><strong>%s:</strong> %s</p>`, labels.Script, escapeHtml(c.Credits.Script)))
 		}
 		if c.Credits.Photography != "" {
-			creditsHTML.WriteString(fmt.Sprintf(`<p><strong>Cámara - Foto:</strong> %s</p>`, escapeHtml(c.Credits.Photography)))
+			creditsHTML.WriteString(fmt.Sprintf(`<p><strong>%s:</strong> %s</p>`, labels.Photography, escapeHtml(c.Credits.Photography)))
 		}
 		if c.Credits.ArtDesign != "" {
-			creditsHTML.WriteString(fmt.Sprintf(`<p><strong>Arte - Diseño:</strong> %s</p>`, escapeHtml(c.Credits.ArtDesign)))
+			creditsHTML.WriteString(fmt.Sprintf(`<p><strong>%s:</strong> %s</p>`, labels.ArtDesign, escapeHtml(c.Credits.ArtDesign)))
 		}
 		if c.Credits.SoundMusic != "" {
-			creditsHTML.WriteString(fmt.Sprintf(`<p><strong>Sonido - Música:</strong> %s</p>`, escapeHtml(c.Credits.SoundMusic)))
+			creditsHTML.WriteString(fmt.Sprintf(`<p><strong>%s:</strong> %s</p>`, labels.SoundMusic, escapeHtml(c.Credits.SoundMusic)))
 		}
 		if c.Credits.Editing != "" {
-			creditsHTML.WriteString(fmt.Sprintf(`<p><strong>Edición:</strong> %s</p>`, escapeHtml(c.Credits.Editing)))
+			creditsHTML.WriteString(fmt.Sprintf(`<p><strong>%s:</strong> %s</p>`, labels.Editing, escapeHtml(c.Credits.Editing)))
 		}
 		if c.Credits.Cast != "" {
-			creditsHTML.WriteString(fmt.Sprintf(`<p><strong>Intérpretes (especificar pronombres para subtítulos):</strong> %s</p>`, escapeHtml(c.Credits.Cast)))
+			creditsHTML.WriteString(fmt.Sprintf(`<p><strong>%s:</strong> %s</p>`, labels.Cast, escapeHtml(c.Credits.Cast)))
 		}
 	}
 
@@ -747,9 +1232,17 @@ func (c *CreditsComponent) Render() string {
 type DirectorComponent struct {
 	Directors []DirectorInfo
 	TextProps Text
+	Compact   bool // Render a names-only list instead of a photo and bio row per director
+	Labels    Labels
+
+	BuilderVersion string
 }
 
 func (d *DirectorComponent) Render() string {
+	if d.Compact {
+		return d.renderCompact()
+	}
+
 	var sections strings.Builder
 
 	for _, director := range d.Directors {
@@ -763,11 +1256,11 @@ func (d *DirectorComponent) Render() string {
 
 		sections.WriteString(fmt.Sprintf(`[et_pb_row column_structure="1_2,1_2" _builder_version="%s" %s %s][et_pb_column type="1_2" _builder_version="%s" %s %s][et_pb_image src="%s" alt="%s" title_text="%s" _builder_version="%s" %s %s][/et_pb_image][/et_pb_column][et_pb_column type="1_2" _builder_version="%s" %s %s][et_pb_text _builder_version="%s" text_font_size="15px" link_font="%s" link_text_color="%s" header_4_font="%s" header_4_text_color="%s" header_4_font_size="19px" background_color="%s" max_height_tablet="" max_height_phone="" max_height_last_edited="on|desktop" custom_padding="%s" %s box_shadow_color="%s" %s]<h4><span>%s</span></h4>
 <p><span data-sheets-root="1">%s</span></p>[/et_pb_text][/et_pb_column][/et_pb_row]`,
-			BuilderVersion, ModulePresetDefault, GlobalColorsInfo, BuilderVersion, ModulePresetDefault, GlobalColorsInfo,
+			d.BuilderVersion, ModulePresetDefault, GlobalColorsInfo, d.BuilderVersion, ModulePresetDefault, GlobalColorsInfo,
 			directorImage,
 			escapedName,
 			escapedName,
-			BuilderVersion, ModulePresetDefault, GlobalColorsInfo, BuilderVersion, ModulePresetDefault, GlobalColorsInfo, BuilderVersion, FontBold, ColorCoral, FontBoldCaps, d.TextProps.Header4TextColor, ColorWhite, PaddingDirector, BoxShadowPreset3, d.TextProps.BoxShadowColor, GlobalColorsInfo,
+			d.BuilderVersion, ModulePresetDefault, GlobalColorsInfo, d.BuilderVersion, ModulePresetDefault, GlobalColorsInfo, d.BuilderVersion, FontBold, ColorCoral, FontBoldCaps, d.TextProps.Header4TextColor, ColorWhite, PaddingDirector, BoxShadowPreset3, d.TextProps.BoxShadowColor, GlobalColorsInfo,
 			escapedName,
 			escapedBio,
 		))
@@ -776,10 +1269,40 @@ func (d *DirectorComponent) Render() string {
 	return sections.String()
 }
 
+// renderCompact renders all directors as a single names-only block followed by the
+// shared bio, used for collective films with many directors
+func (d *DirectorComponent) renderCompact() string {
+	if len(d.Directors) == 0 {
+		return ""
+	}
+
+	var names []string
+	for _, director := range d.Directors {
+		names = append(names, escapeHtml(director.Name))
+	}
+
+	bio := ""
+	if d.Directors[0].Bio != "" {
+		bio = fmt.Sprintf(`
+<p><span data-sheets-root="1">%s</span></p>`, escapeHtml(d.Directors[0].Bio))
+	}
+
+	return fmt.Sprintf(`[et_pb_row _builder_version="%s" %s %s][et_pb_column type="4_4" _builder_version="%s" %s %s][et_pb_text _builder_version="%s" text_font_size="15px" link_font="%s" link_text_color="%s" header_4_font="%s" header_4_text_color="%s" header_4_font_size="19px" background_color="%s" custom_padding="%s" %s box_shadow_color="%s" %s]<h4><span>%s</span></h4>
+<p>%s</p>%s[/et_pb_text][/et_pb_column][/et_pb_row]`,
+		d.BuilderVersion, ModulePresetDefault, GlobalColorsInfo, d.BuilderVersion, ModulePresetDefault, GlobalColorsInfo, d.BuilderVersion, FontBold, ColorCoral, FontBoldCaps, d.TextProps.Header4TextColor, ColorWhite, PaddingDirector, BoxShadowPreset3, d.TextProps.BoxShadowColor, GlobalColorsInfo,
+		resolveLabels(d.Labels).DirectorHeader,
+		strings.Join(names, " · "),
+		bio,
+	)
+}
+
 // Content notes component
 type ContentNotesComponent struct {
 	ContentNotes string
 	NdcProps     Ndc
+	Labels       Labels
+
+	BuilderVersion string
 }
 
 func (c *ContentNotesComponent) Render() string {
@@ -791,18 +1314,20 @@ func (c *ContentNotesComponent) Render() string {
 	return fmt.Sprintf(`
 	[et_pb_text disabled_on="%s" _builder_version="%s" %s text_font="%s" text_text_color="%s" background_color="%s" custom_margin="%s" custom_padding="%s" %s box_shadow_color="%s" locked="off" %s]
 		<p>
-			<strong>NdC: <span data-sheets-root="1">%s</span><br />
+			<strong>%s: <span data-sheets-root="1">%s</span><br />
 			</strong>
 		</p>
 	[/et_pb_text]`,
-		c.NdcProps.Text.DisabledOn, BuilderVersion, ModulePresetDefault, FontBold, c.NdcProps.Text.Color, c.NdcProps.Text.BackgroundColor, MarginStandard, PaddingNotes, BoxShadowPreset3, c.NdcProps.Text.BoxShadowColor, GlobalColorsInfo,
+		c.NdcProps.Text.DisabledOn, c.BuilderVersion, ModulePresetDefault, FontBold, c.NdcProps.Text.Color, c.NdcProps.Text.BackgroundColor, MarginStandard, PaddingNotes, BoxShadowPreset3, c.NdcProps.Text.BoxShadowColor, GlobalColorsInfo,
+		resolveLabels(c.Labels).ContentNotes,
 		escapedNdc,
 	)
 }
 
 // Gallery component
 type GalleryComponent struct {
-	MediaIds string
+	MediaIds       string
+	BuilderVersion string
 }
 
 func (g *GalleryComponent) Render() string {
@@ -813,7 +1338,7 @@ func (g *GalleryComponent) Render() string {
 			[/et_pb_gallery]
 		[/et_pb_column]
 	[/et_pb_row]`,
-		BuilderVersion, GlobalColorsInfo, BuilderVersion, GlobalColorsInfo, g.MediaIds, BuilderVersion, ModulePresetDefault, GlobalColorsInfo,
+		g.BuilderVersion, GlobalColorsInfo, g.BuilderVersion, GlobalColorsInfo, g.MediaIds, g.BuilderVersion, ModulePresetDefault, GlobalColorsInfo,
 	)
 }
 
@@ -823,6 +1348,7 @@ type HeaderComponent struct {
 	Subhead         string
 	BackgroundImage string
 	HeaderProps     Header
+	BuilderVersion  string
 }
 
 func (h *HeaderComponent) Render() string {
@@ -831,18 +1357,23 @@ func (h *HeaderComponent) Render() string {
 		[et_pb_fullwidth_header title="%s" subhead="%s" _builder_version="%s" title_font="%s" title_text_color="%s" subhead_text_color="%s"  background_enable_color="off" use_background_color_gradient="on" background_color_gradient_stops="%s 0%%|#82d0d9 50%%|%s 100%%" background_image="%s" background_blend="multiply" width="99.9%%" custom_padding="20%%||2%%||false|false" custom_padding_tablet="" custom_padding_phone="" custom_padding_last_edited="on|desktop" %s]
 		[/et_pb_fullwidth_header]
 	[/et_pb_section]`,
-		BuilderVersion, GlobalColorsInfo, h.Title, h.Subhead, BuilderVersion, FontBoldCaps, h.HeaderProps.TitleTextColor, h.HeaderProps.SubHeadTextColor, ColorPrimary, ColorSecondary, h.BackgroundImage, GlobalColorsInfo,
+		h.BuilderVersion, GlobalColorsInfo, h.Title, h.Subhead, h.BuilderVersion, FontBoldCaps, h.HeaderProps.TitleTextColor, h.HeaderProps.SubHeadTextColor, ColorPrimary, ColorSecondary, h.BackgroundImage, GlobalColorsInfo,
 	)
 }
 
 // Menu component
 type MenuComponent struct {
-	MenuProps Menu
+	MenuProps      Menu
+	BuilderVersion string
 }
 
+// Render returns nothing when the menu is disabled or has no menu ID
 func (m *MenuComponent) Render() string {
+	if !m.MenuProps.Rendered() {
+		return ""
+	}
 	return fmt.Sprintf(`[et_pb_section fb_built="1" fullwidth="on" _builder_version="%s" %s %s][et_pb_fullwidth_menu menu_id="%s" active_link_color="%s" dropdown_menu_text_color="#ffcccc" mobile_menu_text_color="#ffcccc" cart_icon_color="#ffcccc" search_icon_color="#ffcccc" menu_icon_color="#ffcccc" _builder_version="%s" menu_font="Montserrat|700||on|||||" menu_text_color="%s" menu_font_size="12px" background_color="%s" background_image="%s" background_blend="overlay" text_orientation="right" menu_text_color_tablet="%s" menu_text_color_phone="%s" menu_text_color_last_edited="on|desktop" %s menu_text_color__hover_enabled="on|desktop" menu_text_color__hover="%s"][/et_pb_fullwidth_menu][/et_pb_section]`,
-		BuilderVersion, ModulePresetDefault, GlobalColorsInfo, m.MenuProps.MenuId, m.MenuProps.ActiveLinkColor, BuilderVersion, m.MenuProps.MenuTextColor, m.MenuProps.BackgroundColor, m.MenuProps.BackgroundImage, ColorSecondary, ColorSecondary, GlobalColorsInfo, ColorLightGreen,
+		m.BuilderVersion, ModulePresetDefault, GlobalColorsInfo, m.MenuProps.MenuId, m.MenuProps.ActiveLinkColor, m.BuilderVersion, m.MenuProps.MenuTextColor, m.MenuProps.BackgroundColor, m.MenuProps.BackgroundImage, ColorSecondary, ColorSecondary, GlobalColorsInfo, ColorLightGreen,
 	)
 }
 
@@ -855,6 +1386,8 @@ type MainContentComponent struct {
 	Synopsis              string
 	DirectorComponent     *DirectorComponent
 	GalleryComponent      *GalleryComponent
+	Labels                Labels
+	BuilderVersion        string
 }
 
 type RowComponent struct {
@@ -864,6 +1397,7 @@ type RowComponent struct {
 
 func (m *MainContentComponent) Render() string {
 	escapedSinopsis := escapeHtml(m.Synopsis)
+	labels := resolveLabels(m.Labels)
 	creditsSection := m.CreditsComponent.Render()
 	contentNotesSection := m.ContentNotesComponent.Render()
 	directorSection := m.DirectorComponent.Render()
@@ -874,13 +1408,13 @@ func (m *MainContentComponent) Render() string {
 		[et_pb_row column_structure="1_2,1_2" _builder_version="%s" %s]	
 			[et_pb_column type="1_2" _builder_version="%s" %s]
 				[et_pb_text _builder_version="%s" text_font_size="15px" header_4_font="%s" header_4_text_color="%s" header_4_font_size="19px" background_color="%s" max_height_tablet="" max_height_phone="" max_height_last_edited="on|desktop" custom_padding="%s" %s box_shadow_color="%s" %s]
-					<h4><strong>FICHA TÉCNICA:</strong></h4>
+					<h4><strong>%s:</strong></h4>
 					%s
 				[/et_pb_text]
 			[/et_pb_column]
 			[et_pb_column type="1_2" _builder_version="%s" %s]
 				[et_pb_text _builder_version="%s" text_font_size="15px" header_4_font="%s" header_4_text_color="%s" header_4_font_size="19px" background_color="%s" custom_padding="%s" %s box_shadow_color="%s" %s]
-					<h4><strong>SINOPSIS:</strong></h4>
+					<h4><strong>%s:</strong></h4>
 					<p class="p1">
 						<span data-sheets-root="1">%s</span>
 					</p>
@@ -891,10 +1425,10 @@ func (m *MainContentComponent) Render() string {
 		%s
 		%s
 	[/et_pb_section]`,
-		BuilderVersion, m.SectionProps.Background, m.SectionProps.BackgroundColorGradientStops, m.SectionProps.BackgroundColorGradientStart, m.SectionProps.BackgroundColorGradientEnd, BuilderVersion, GlobalColorsInfo, BuilderVersion, GlobalColorsInfo, BuilderVersion, FontBoldCaps, m.TextProps.Header4TextColor, ColorWhite, PaddingStandard, BoxShadowPreset3, m.TextProps.BoxShadowColor, GlobalColorsInfo,
-		creditsSection,
-		BuilderVersion, GlobalColorsInfo, BuilderVersion, FontBoldCaps, m.TextProps.Header4TextColor, ColorWhite, PaddingStandard, BoxShadowPreset3, m.TextProps.BoxShadowColor, GlobalColorsInfo,
-		escapedSinopsis, contentNotesSection, directorSection, galleryComponent,
+		m.BuilderVersion, m.SectionProps.Background, m.SectionProps.BackgroundColorGradientStops, m.SectionProps.BackgroundColorGradientStart, m.SectionProps.BackgroundColorGradientEnd, m.BuilderVersion, GlobalColorsInfo, m.BuilderVersion, GlobalColorsInfo, m.BuilderVersion, FontBoldCaps, m.TextProps.Header4TextColor, ColorWhite, PaddingStandard, BoxShadowPreset3, m.TextProps.BoxShadowColor, GlobalColorsInfo,
+		labels.TechnicalSheet, creditsSection,
+		m.BuilderVersion, GlobalColorsInfo, m.BuilderVersion, FontBoldCaps, m.TextProps.Header4TextColor, ColorWhite, PaddingStandard, BoxShadowPreset3, m.TextProps.BoxShadowColor, GlobalColorsInfo,
+		labels.Synopsis, escapedSinopsis, contentNotesSection, directorSection, galleryComponent,
 	)
 }
 
@@ -902,29 +1436,23 @@ func (m *MainContentComponent) Render() string {
 type FooterComponent struct {
 	ButtonText string
 	FooterProps Footer
+	BuilderVersion string
 }
 
 func (f *FooterComponent) Render() string {
+	buttonURL, dynamicAttributes := footerButtonLink(f.FooterProps.Button.URL, f.FooterProps.Button.PostID)
+
 	return fmt.Sprintf(`
 	[et_pb_section fb_built="1" admin_label="Section" _builder_version="%s" background_image="%s" background_position="%s" min_height="294.8px" custom_margin="||||false|false" custom_padding="||||false|false" global_module="%s" saved_tabs="all" %s]
-		[et_pb_row disabled_on="off|off|off" _builder_version="4.23.2" %s min_height="164.4px" %s]
-			[et_pb_column type="4_4" _builder_version="4.17.4" %s %s]
-				[et_pb_button button_url="@ET-DC@eyJkeW5hbWljIjp0cnVlLCJjb250ZW50IjoicG9zdF9saW5rX3VybF9wYWdlIiwic2V0dGluZ3MiOnsicG9zdF9pZCI6IjEwNDI0In19@" button_text="%s" button_alignment="center" disabled_on="on|on|on" module_class="popmake-6500" _builder_version="%s" _dynamic_attributes="button_url" %s %s button_text_color="%s" button_bg_color="%s" button_border_color="%s" button_font="%s" button_icon_color="%s" %s box_shadow_color="%s" disabled="on" %s button_text_color__hover_enabled="on|desktop" button_text_color__hover="%s" button_bg_color__hover_enabled="on|hover" button_bg_color__hover="%s" button_bg_enable_color__hover="on" button_border_color__hover_enabled="on|hover" button_border_color__hover="%s"]
+		[et_pb_row disabled_on="off|off|off" _builder_version="%s" %s min_height="164.4px" %s]
+			[et_pb_column type="4_4" _builder_version="%s" %s %s]
+				[et_pb_button button_url="%s" button_text="%s" button_alignment="center" disabled_on="on|on|on" module_class="popmake-6500" _builder_version="%s" %s %s %s button_text_color="%s" button_bg_color="%s" button_border_color="%s" button_font="%s" button_icon_color="%s" %s box_shadow_color="%s" disabled="on" %s button_text_color__hover_enabled="on|desktop" button_text_color__hover="%s" button_bg_color__hover_enabled="on|hover" button_bg_color__hover="%s" button_bg_enable_color__hover="on" button_border_color__hover_enabled="on|hover" button_border_color__hover="%s"]
 				[/et_pb_button]
 			[/et_pb_column]
 		[/et_pb_row]
 		[et_pb_row column_structure="1_3,1_3,1_3" _builder_version="%s" %s]\
 			[et_pb_column type="1_3" _builder_version="%s" %s]
-				[et_pb_social_media_follow icon_color="%s" icon_color_tablet="%s" icon_color_phone="%s" icon_color_last_edited="on|tablet" _builder_version="%s" background_color="RGBA(255,255,255,0)" %s button_text_color="%s" button_bg_color="%s" button_border_color="%s" text_orientation="center" custom_margin="||||false|false" %s]
-					[et_pb_social_media_follow_network social_network="facebook" url="%s" icon_color="%s" _builder_version="%s" background_color="%s" %s %s]
-						facebook
-					[/et_pb_social_media_follow_network]
-					[et_pb_social_media_follow_network social_network="instagram" url="%s" icon_color="%s" _builder_version="%s" background_color="%s" %s %s]
-						instagram
-					[/et_pb_social_media_follow_network]
-					[et_pb_social_media_follow_network social_network="twitter" url="%s" icon_color="%s" _builder_version="%s" background_color="%s" %s %s]
-						twitter
-					[/et_pb_social_media_follow_network]
+				[et_pb_social_media_follow icon_color="%s" icon_color_tablet="%s" icon_color_phone="%s" icon_color_last_edited="on|tablet" _builder_version="%s" background_color="RGBA(255,255,255,0)" %s button_text_color="%s" button_bg_color="%s" button_border_color="%s" text_orientation="center" custom_margin="||||false|false" %s]%s
 				[/et_pb_social_media_follow]
 			[/et_pb_column]
 			[et_pb_column type="1_3" _builder_version="%s" %s]
@@ -938,13 +1466,31 @@ func (f *FooterComponent) Render() string {
 			[/et_pb_column]
 		[/et_pb_row]
 	[/et_pb_section]`,
-		BuilderVersion, f.FooterProps.Section.BackgroundImage, f.FooterProps.Section.BackgroundPosition, f.FooterProps.Section.GlobalModule, GlobalColorsInfo, ModulePresetDefault, GlobalColorsInfo, ModulePresetDefault, GlobalColorsInfo, f.ButtonText, BuilderVersion, ModulePresetDefault, CustomButtonOn, f.FooterProps.Button.ButtonTextColor, ColorSecondary, f.FooterProps.Button.ButtonBorderColor, FontBold, f.FooterProps.Button.ButtonIconColor, BoxShadowPreset3, f.FooterProps.Button.BoxShadowColor, GlobalColorsInfo, ColorYellow, ColorCoral, ColorCoral,
-		BuilderVersion, GlobalColorsInfo, BuilderVersion, GlobalColorsInfo, ColorPrimary, ColorPink, ColorPink, BuilderVersion, CustomButtonOn, ColorPrimary, ColorSecondary, ColorSecondary, GlobalColorsInfo, URLFacebook, ColorPrimary, BuilderVersion, ColorSecondary, BackgroundColorOn, GlobalColorsInfo, URLInstagram, ColorPrimary, BuilderVersion, ColorSecondary, BackgroundColorOn, GlobalColorsInfo, URLTwitter, ColorPrimary, BuilderVersion, ColorSecondary, BackgroundColorOn, GlobalColorsInfo,
-		BuilderVersion, GlobalColorsInfo, BuilderVersion, ColorYellow, FontBold, ColorDark, ColorDark, GlobalColorsInfo, ColorDark, EmailContact, ColorDark, EmailContact,
-		BuilderVersion, GlobalColorsInfo, ColorPrimary, ColorPrimary, BuilderVersion, ColorDark, ColorDark, FontExtraBold, ColorSecondary, ColorPrimary, GlobalColorsInfo,
+		f.BuilderVersion, f.FooterProps.Section.BackgroundImage, f.FooterProps.Section.BackgroundPosition, f.FooterProps.Section.GlobalModule, GlobalColorsInfo, f.BuilderVersion, ModulePresetDefault, GlobalColorsInfo, f.BuilderVersion, ModulePresetDefault, GlobalColorsInfo, buttonURL, f.ButtonText, f.BuilderVersion, dynamicAttributes, ModulePresetDefault, CustomButtonOn, f.FooterProps.Button.ButtonTextColor, ColorSecondary, f.FooterProps.Button.ButtonBorderColor, FontBold, f.FooterProps.Button.ButtonIconColor, BoxShadowPreset3, f.FooterProps.Button.BoxShadowColor, GlobalColorsInfo, ColorYellow, ColorCoral, ColorCoral,
+		f.BuilderVersion, GlobalColorsInfo, f.BuilderVersion, GlobalColorsInfo, ColorPrimary, ColorPink, ColorPink, f.BuilderVersion, CustomButtonOn, ColorPrimary, ColorSecondary, ColorSecondary, GlobalColorsInfo, f.renderSocialNetworks(),
+		f.BuilderVersion, GlobalColorsInfo, f.BuilderVersion, ColorYellow, FontBold, ColorDark, ColorDark, GlobalColorsInfo, ColorDark, EmailContact, ColorDark, EmailContact,
+		f.BuilderVersion, GlobalColorsInfo, ColorPrimary, ColorPrimary, f.BuilderVersion, ColorDark, ColorDark, FontExtraBold, ColorSecondary, ColorPrimary, GlobalColorsInfo,
 	)
 }
 
+// renderSocialNetworks renders a follow network module per configured social network
+func (f *FooterComponent) renderSocialNetworks() string {
+	networks := f.FooterProps.SocialNetworks
+	if networks == nil {
+		networks = DefaultSocialNetworks()
+	}
+	var result strings.Builder
+	for _, network := range networks {
+		name := strings.ToLower(strings.TrimSpace(network.Network))
+		fmt.Fprintf(&result, `
+					[et_pb_social_media_follow_network social_network="%s" url="%s" icon_color="%s" _builder_version="%s" background_color="%s" %s %s]
+						%s
+					[/et_pb_social_media_follow_network]`,
+			name, strings.TrimSpace(network.URL), ColorPrimary, f.BuilderVersion, ColorSecondary, BackgroundColorOn, GlobalColorsInfo, name)
+	}
+	return result.String()
+}
+
 // Template composer - coordinates all components
 type DiviTemplateComposer struct {
 	components []TemplateComponent
@@ -0,0 +1,799 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"excentrico-tools-go/internal/config"
+	"excentrico-tools-go/internal/logger"
+	"excentrico-tools-go/internal/models"
+)
+
+// newMediaServer starts a WordPress site serving GET /wp/v2/media/{id} from media and
+// returns a service talking to it with the number of media lookups it received
+func newMediaServer(t *testing.T, media map[int]*WordPressMedia) (*WordPressService, *atomic.Int32) {
+	t.Helper()
+	var lookups atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := strings.TrimPrefix(r.URL.Path, "/wp-json")
+		id, err := strconv.Atoi(strings.TrimPrefix(route, "/wp/v2/media/"))
+		if !strings.HasPrefix(route, "/wp/v2/media/") || err != nil || media[id] == nil {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":"rest_post_invalid_id"}`))
+			return
+		}
+		lookups.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(media[id])
+	}))
+	t.Cleanup(srv.Close)
+	return NewWordPressService(context.Background(), config.WordPressConfig{BaseURL: srv.URL}), &lookups
+}
+
+func TestResolveBackgroundImageURL(t *testing.T) {
+	media := map[int]*WordPressMedia{
+		1: {ID: 1, SourceURL: "https://example.test/uploads/still-1.jpg"},
+		2: {ID: 2, SourceURL: "https://example.test/uploads/fondo.jpg"},
+		3: {ID: 3, SourceURL: "https://example.test/uploads/still-3.jpg"},
+	}
+	tests := []struct {
+		name        string
+		stored      *models.BackgroundImageMetadata
+		imageIds    []int
+		refresh     bool
+		want        string
+		wantLookups bool // whether media had to be fetched to choose
+		wantStored  int  // media ID recorded afterwards
+	}{
+		{
+			name:        "first run persists the choice",
+			imageIds:    []int{1, 2, 3},
+			want:        media[2].SourceURL,
+			wantLookups: true,
+			wantStored:  2,
+		},
+		{
+			name:       "stored choice is reused",
+			stored:     &models.BackgroundImageMetadata{MediaID: 3, URL: media[3].SourceURL},
+			imageIds:   []int{1, 2, 3},
+			want:       media[3].SourceURL,
+			wantStored: 3,
+		},
+		{
+			name:        "stored media no longer attached",
+			stored:      &models.BackgroundImageMetadata{MediaID: 9, URL: "https://example.test/uploads/gone.jpg"},
+			imageIds:    []int{1, 2, 3},
+			want:        media[2].SourceURL,
+			wantLookups: true,
+			wantStored:  2,
+		},
+		{
+			name:        "refresh re-evaluates",
+			stored:      &models.BackgroundImageMetadata{MediaID: 3, URL: media[3].SourceURL},
+			imageIds:    []int{1, 2, 3},
+			refresh:     true,
+			want:        media[2].SourceURL,
+			wantLookups: true,
+			wantStored:  2,
+		},
+		{
+			name:        "no keyword falls back to the first image",
+			imageIds:    []int{3, 1},
+			want:        media[3].SourceURL,
+			wantLookups: true,
+			wantStored:  3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wp, lookups := newMediaServer(t, media)
+			turso := newTestTurso(t)
+			if tt.stored != nil {
+				if err := turso.SaveBackgroundImageMetadata("film-1", tt.stored); err != nil {
+					t.Fatalf("SaveBackgroundImageMetadata: %v", err)
+				}
+			}
+			s := NewDiviTemplateService()
+			s.SetRefreshBackground(tt.refresh)
+
+			if got := s.resolveBackgroundImageURL(tt.imageIds, wp, turso, "film-1"); got != tt.want {
+				t.Errorf("background %q, want %q", got, tt.want)
+			}
+			if got := lookups.Load() > 0; got != tt.wantLookups {
+				t.Errorf("media fetched: %v, want %v", got, tt.wantLookups)
+			}
+			stored := &models.BackgroundImageMetadata{}
+			if err := turso.GetBackgroundImageMetadata("film-1", stored); err != nil {
+				t.Fatalf("GetBackgroundImageMetadata: %v", err)
+			}
+			if stored.MediaID != tt.wantStored || stored.URL != media[tt.wantStored].SourceURL {
+				t.Errorf("stored choice %+v, want media %d", stored, tt.wantStored)
+			}
+		})
+	}
+}
+
+func TestMissingDirectorImageWarning(t *testing.T) {
+	media := map[int]*WordPressMedia{
+		1: {ID: 1, SourceURL: "https://example.test/uploads/ana-garcia.jpg"},
+		2: {ID: 2, SourceURL: "https://example.test/uploads/still-1.jpg"},
+	}
+	tests := []struct {
+		name         string
+		direccion    string
+		multiDir     string
+		wantMissing  []string // directors listed in the warning; nil when none is emitted
+		wantDirector string   // director whose image URL is checked
+		wantImage    string
+	}{
+		{name: "matched director", direccion: "Ana García", wantDirector: "Ana García", wantImage: media[1].SourceURL},
+		{name: "unmatched director", direccion: "Luis Pérez", wantMissing: []string{"Luis Pérez"}, wantDirector: "Luis Pérez"},
+		{
+			name:         "one of several directors unmatched",
+			direccion:    "Ana García, Luis Pérez",
+			multiDir:     "SI",
+			wantMissing:  []string{"Luis Pérez"},
+			wantDirector: "Ana García",
+			wantImage:    media[1].SourceURL,
+		},
+		{name: "no director", direccion: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wp, _ := newMediaServer(t, media)
+			service := NewDiviTemplateService()
+			filmData := &FilmData{TituloOriginal: "La película", Seccion: "Cortos", Direccion: tt.direccion, MultiDir: tt.multiDir}
+			events := captureEvents(t)
+
+			template := service.GenerateDiviTemplateDataWithWordPress(filmData, []int{1, 2}, wp, nil, "film-1")
+			for _, director := range template.Directors {
+				if director.Name == tt.wantDirector && director.ImageURL != tt.wantImage {
+					t.Errorf("director %s has image %q, want %q", director.Name, director.ImageURL, tt.wantImage)
+				}
+			}
+
+			var warnings []logger.WideEvent
+			for _, event := range events() {
+				if event.Operation == "director_images_missing" {
+					warnings = append(warnings, event)
+				}
+			}
+			if tt.wantMissing == nil {
+				if len(warnings) != 0 {
+					t.Errorf("got %d warnings, want none", len(warnings))
+				}
+				if missing := service.MissingDirectorImages(); len(missing) != 0 {
+					t.Errorf("MissingDirectorImages = %v, want none", missing)
+				}
+				return
+			}
+
+			if len(warnings) != 1 {
+				t.Fatalf("got %d warnings, want 1", len(warnings))
+			}
+			warning := warnings[0]
+			if warning.Level != "warn" || warning.FilmID != "film-1" || warning.FilmSection != "Cortos" {
+				t.Errorf("warning %+v, want a warn event for film-1 in Cortos", warning)
+			}
+			if got := fmt.Sprint(warning.Context["missing_directors"]); got != fmt.Sprint(tt.wantMissing) {
+				t.Errorf("missing_directors = %s, want %v", got, tt.wantMissing)
+			}
+			if got := service.MissingDirectorImages()["film-1"]; !reflect.DeepEqual(got, tt.wantMissing) {
+				t.Errorf("MissingDirectorImages[film-1] = %v, want %v", got, tt.wantMissing)
+			}
+
+			// The same gap on a later pass is already reported
+			service.GenerateDiviTemplateDataWithWordPress(filmData, []int{1, 2}, wp, nil, "film-1")
+			repeated := 0
+			for _, event := range events() {
+				if event.Operation == "director_images_missing" {
+					repeated++
+				}
+			}
+			if repeated != 1 {
+				t.Errorf("got %d warnings after regenerating, want the first one only", repeated)
+			}
+		})
+	}
+}
+
+func TestDirectorSectionThreshold(t *testing.T) {
+	directors := func(n int) []DirectorInfo {
+		var list []DirectorInfo
+		for i := 1; i <= n; i++ {
+			list = append(list, DirectorInfo{
+				Name:     fmt.Sprintf("Directora %d", i),
+				ImageURL: fmt.Sprintf("https://example.test/uploads/directora-%d.jpg", i),
+				Bio:      "Colectivo de cine.",
+			})
+		}
+		return list
+	}
+	tests := []struct {
+		name        string
+		directors   int
+		threshold   int // template's max_directors_full; 0 uses the default
+		wantCompact bool
+	}{
+		{name: "single director", directors: 1},
+		{name: "at the default threshold", directors: DefaultMaxDirectorsFull},
+		{name: "above the default threshold", directors: DefaultMaxDirectorsFull + 1, wantCompact: true},
+		{name: "at a configured threshold", directors: 3, threshold: 3},
+		{name: "above a configured threshold", directors: 4, threshold: 3, wantCompact: true},
+		{name: "configured threshold above the default", directors: 12, threshold: 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templateData := &DiviFilmTemplate{Title: "Película colectiva", Directors: directors(tt.directors)}
+			templateConfig := DefaultTemplateData()
+			templateConfig.MaxDirectorsFull = tt.threshold
+
+			rendered := NewDiviTemplateService().CreateStandardFilmTemplate(templateData, "2025", templateConfig).Compose()
+
+			photos := strings.Count(rendered, `[et_pb_image src="https://example.test/uploads/directora-`)
+			namesList := strings.Contains(rendered, "Directora 1 · Directora 2")
+			if tt.wantCompact {
+				if photos != 0 || !namesList {
+					t.Errorf("got %d director photos and names list %v, want the compact names-only list", photos, namesList)
+				}
+				if bios := strings.Count(rendered, "Colectivo de cine."); bios != 1 {
+					t.Errorf("bio rendered %d times, want once", bios)
+				}
+				return
+			}
+			if photos != tt.directors || namesList {
+				t.Errorf("got %d director photos and names list %v, want a photo row per director", photos, namesList)
+			}
+		})
+	}
+}
+
+func TestSynopsisSource(t *testing.T) {
+	filmData := &FilmData{
+		TituloOriginal:    "La película",
+		SinopsisExtendida: "Sinopsis extendida.",
+		SinopsisCompacta:  "Sinopsis compacta.",
+		ExtendedSynopsis:  "Extended synopsis.",
+	}
+	synopses := []string{filmData.SinopsisExtendida, filmData.SinopsisCompacta, filmData.ExtendedSynopsis}
+	tests := []struct {
+		name    string
+		source  string
+		excerpt string
+		want    string
+		wantErr bool
+	}{
+		{name: "default", want: "Sinopsis extendida."},
+		{name: "extended", source: SynopsisSourceExtended, want: "Sinopsis extendida."},
+		{name: "compact", source: SynopsisSourceCompact, want: "Sinopsis compacta."},
+		{name: "english", source: SynopsisSourceEnglish, want: "Extended synopsis."},
+		{name: "excerpt leaves the content alone", excerpt: SynopsisSourceCompact, want: "Sinopsis extendida."},
+		{name: "unknown content source", source: "sinopsis", wantErr: true},
+		{name: "unknown excerpt source", excerpt: "short", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templateConfig := DefaultTemplateData()
+			templateConfig.SynopsisSource = tt.source
+			templateConfig.ExcerptSource = tt.excerpt
+			if err := templateConfig.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v, want error: %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			_, shortcodes := NewDiviTemplateService().GenerateCompleteTemplate(filmData, nil, nil, nil, "", "2025", templateConfig)
+			for _, synopsis := range synopses {
+				if got := strings.Contains(shortcodes, synopsis); got != (synopsis == tt.want) {
+					t.Errorf("rendered content contains %q: %v, want only %q", synopsis, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFooterButton(t *testing.T) {
+	buttonPattern := regexp.MustCompile(`\[et_pb_button button_url="([^"]*)" button_text="([^"]*)"([^\]]*)\]`)
+	tests := []struct {
+		name       string
+		label      string
+		url        string
+		postID     int
+		wantText   string
+		wantURL    string // fixed link; empty expects a dynamic link to wantPostID
+		wantPostID string
+	}{
+		{name: "defaults", wantText: "convocatoria 2025", wantPostID: "10424"},
+		{name: "configured label", label: "Envía tu película {year}", wantText: "Envía tu película 2025", wantPostID: "10424"},
+		{name: "label without a year", label: "Participa", wantText: "Participa", wantPostID: "10424"},
+		{name: "configured page", postID: 321, wantText: "convocatoria 2025", wantPostID: "321"},
+		{name: "configured URL", url: "https://example.test/convocatoria", postID: 321, wantText: "convocatoria 2025", wantURL: "https://example.test/convocatoria"},
+		{name: "label and URL", label: "Call for entries {year}", url: "https://example.test/call", wantText: "Call for entries 2025", wantURL: "https://example.test/call"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templateConfig := DefaultTemplateData()
+			templateConfig.Footer.Button.Label = tt.label
+			templateConfig.Footer.Button.URL = tt.url
+			templateConfig.Footer.Button.PostID = tt.postID
+
+			rendered := NewDiviTemplateService().CreateStandardFilmTemplate(&DiviFilmTemplate{Title: "La película"}, "2025", templateConfig).Compose()
+			match := buttonPattern.FindStringSubmatch(rendered)
+			if match == nil {
+				t.Fatal("no footer button in the rendered template")
+			}
+			buttonURL, buttonText := match[1], match[2]
+			dynamic := strings.Contains(match[3], `_dynamic_attributes="button_url"`)
+
+			if buttonText != tt.wantText {
+				t.Errorf("button_text = %q, want %q", buttonText, tt.wantText)
+			}
+			if tt.wantURL != "" {
+				if buttonURL != tt.wantURL || dynamic {
+					t.Errorf("button_url = %q (dynamic %v), want the fixed %q", buttonURL, dynamic, tt.wantURL)
+				}
+				return
+			}
+
+			encoded := strings.TrimSuffix(strings.TrimPrefix(buttonURL, "@ET-DC@"), "@")
+			decoded, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil || !dynamic {
+				t.Fatalf("button_url = %q (dynamic %v), want a dynamic post link", buttonURL, dynamic)
+			}
+			var link struct {
+				Content  string            `json:"content"`
+				Settings map[string]string `json:"settings"`
+			}
+			if err := json.Unmarshal(decoded, &link); err != nil {
+				t.Fatalf("decoding the dynamic link %s: %v", decoded, err)
+			}
+			if link.Content != "post_link_url_page" || link.Settings["post_id"] != tt.wantPostID {
+				t.Errorf("dynamic link %s, want a page link to post %s", decoded, tt.wantPostID)
+			}
+		})
+	}
+}
+
+func TestImageURLsOnly(t *testing.T) {
+	dir := t.TempDir()
+	media := make(map[int]*WordPressMedia)
+	contents := make(map[int][]byte)
+	for id := 1; id <= 3; id++ {
+		path := filepath.Join(dir, fmt.Sprintf("still_%d_web.jpg", id))
+		contents[id] = []byte(strings.Repeat(fmt.Sprintf("still %d ", id), 1000))
+		if err := os.WriteFile(path, contents[id], 0o644); err != nil {
+			t.Fatal(err)
+		}
+		media[id] = &WordPressMedia{ID: id, SourceURL: "file://" + filepath.ToSlash(path)}
+	}
+	wp, _ := newMediaServer(t, media)
+
+	tests := []struct {
+		name     string
+		urlsOnly bool
+	}{
+		{name: "base64 by default", urlsOnly: false},
+		{name: "URLs only", urlsOnly: true},
+	}
+	sizes := make(map[bool]int)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewDiviTemplateService()
+			s.SetImageURLsOnly(tt.urlsOnly)
+			images, err := s.fetchWordPressImagesData([]int{1, 2, 3}, wp)
+			if err != nil {
+				t.Fatalf("fetchWordPressImagesData: %v", err)
+			}
+			if len(images) != 3 {
+				t.Fatalf("%d images, want 3", len(images))
+			}
+			for id, m := range media {
+				image := images[m.SourceURL]
+				if image.ID != id || image.URL != m.SourceURL {
+					t.Errorf("image %d = {ID: %d, URL: %q}, want {ID: %d, URL: %q}", id, image.ID, image.URL, id, m.SourceURL)
+				}
+				wantEncoded := base64.StdEncoding.EncodeToString(contents[id])
+				if tt.urlsOnly {
+					wantEncoded = ""
+				}
+				if image.Encoded != wantEncoded {
+					t.Errorf("image %d encoded %d bytes, want %d", id, len(image.Encoded), len(wantEncoded))
+				}
+			}
+
+			data, err := json.Marshal(images)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded map[string]map[string]any
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			for url, fields := range decoded {
+				if _, ok := fields["encoded"]; ok == tt.urlsOnly {
+					t.Errorf("%s has an encoded field: %v, want %v", url, ok, !tt.urlsOnly)
+				}
+			}
+			sizes[tt.urlsOnly] = len(data)
+		})
+	}
+	if sizes[true] >= sizes[false]/10 {
+		t.Errorf("URL-only images take %d bytes, want far less than the %d embedded", sizes[true], sizes[false])
+	}
+}
+
+func TestCapGallery(t *testing.T) {
+	turso := newTestTurso(t)
+	media := []map[string]any{
+		{"id": 14, "file_path": "films/la-playa/optimized/Stills/04_web.jpg"},
+		{"id": 11, "file_path": "films/la-playa/optimized/Stills/01_web.jpg"},
+		{"id": 13, "file_path": "films/la-playa/optimized/Stills/03_web.jpg"},
+		{"id": 12, "file_path": "films/la-playa/optimized/Stills/02_Web.jpg"},
+	}
+	if err := turso.SaveMetadata("film-1", "wordpress_media", media); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		maxImages   int
+		turso       *TursoService
+		wantIds     []int
+		wantOmitted int
+	}{
+		{name: "unlimited", maxImages: 0, turso: turso, wantIds: []int{14, 11, 13, 12}},
+		{name: "cap above the count", maxImages: 10, turso: turso, wantIds: []int{14, 11, 13, 12}},
+		{name: "cap at the count", maxImages: 4, turso: turso, wantIds: []int{14, 11, 13, 12}},
+		{name: "first by file name", maxImages: 2, turso: turso, wantIds: []int{11, 12}, wantOmitted: 2},
+		{name: "single image", maxImages: 1, turso: turso, wantIds: []int{11}, wantOmitted: 3},
+		{name: "gallery order without file names", maxImages: 3, wantIds: []int{14, 11, 13}, wantOmitted: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templateData := &DiviFilmTemplate{ImageGalleryIds: []int{14, 11, 13, 12}, GalleryMediaIds: "14,11,13,12"}
+			omitted := capGallery(templateData, tt.maxImages, tt.turso, "film-1")
+			if omitted != tt.wantOmitted {
+				t.Errorf("omitted %d, want %d", omitted, tt.wantOmitted)
+			}
+			if !reflect.DeepEqual(templateData.ImageGalleryIds, tt.wantIds) {
+				t.Errorf("gallery IDs %v, want %v", templateData.ImageGalleryIds, tt.wantIds)
+			}
+			wantMediaIds := make([]string, len(tt.wantIds))
+			for i, id := range tt.wantIds {
+				wantMediaIds[i] = strconv.Itoa(id)
+			}
+			if got, want := templateData.GalleryMediaIds, strings.Join(wantMediaIds, ","); got != want {
+				t.Errorf("gallery media IDs %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestFilterStillsImagesBelowMinimum(t *testing.T) {
+	turso := newTestTurso(t)
+	media := []map[string]any{
+		{"id": 11, "file_path": "films/la-playa/optimized/Stills/01_web.jpg"},
+		{"id": 12, "file_path": "films/la-playa/optimized/Stills/icon_web.jpg"},
+		{"id": 13, "file_path": "films/la-playa/optimized/Poster/poster_web.jpg"},
+	}
+	if err := turso.SaveMetadata("film-1", "wordpress_media", media); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		belowMinimum map[string]bool
+		want         []int
+	}{
+		{name: "all above the minimum", want: []int{11, 12}},
+		{name: "small still left out", belowMinimum: map[string]bool{"icon.png": true}, want: []int{11}},
+		{name: "every still too small", belowMinimum: map[string]bool{"01.jpg": true, "icon.png": true}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var files []*models.FileWithPath
+			for _, file := range []struct{ name, folder string }{{"01.jpg", "Stills"}, {"icon.png", "Stills"}, {"poster.jpg", "Poster"}} {
+				files = append(files, &models.FileWithPath{Name: file.name, FolderName: file.folder, BelowMinimum: tt.belowMinimum[file.name]})
+			}
+			if err := turso.SaveDriveFilesMetadata("film-1", files); err != nil {
+				t.Fatal(err)
+			}
+
+			got := NewDiviTemplateService().filterStillsImages([]int{11, 12, 13}, turso, "film-1")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterStillsImages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateLanguage(t *testing.T) {
+	filmData := &FilmData{
+		TituloOriginal:    "La playa",
+		Direccion:         "Ana Ruiz",
+		Pais:              "España",
+		Ano:               "2024",
+		Duracion:          "12'",
+		Produccion:        "Marta Gil",
+		Guion:             "Ana Ruiz",
+		EdicionCredits:    "Pablo Sanz",
+		Interpretes:       "Lucía Ramos",
+		NotasContenido:    "Violencia.",
+		SinopsisExtendida: "Una playa al amanecer.",
+		ExtendedSynopsis:  "A beach at dawn.",
+	}
+	localized := func(labels Labels, synopsis, button string) []string {
+		return []string{
+			labels.Direction + ":", labels.Production + ":", labels.Script + ":", labels.Editing + ":", labels.Cast + ":",
+			labels.TechnicalSheet, labels.Synopsis, labels.ContentNotes,
+			synopsis, `button_text="` + button + `"`,
+		}
+	}
+	spanish := localized(SpanishLabels, "Una playa al amanecer.", "convocatoria 2025")
+	english := localized(EnglishLabels, "A beach at dawn.", "call for entries 2025")
+
+	tests := []struct {
+		name        string
+		lang        string
+		buttonLabel string
+		want        []string
+		notWant     []string
+	}{
+		{name: "spanish by default", want: spanish, notWant: english},
+		{name: "spanish", lang: LanguageSpanish, want: spanish, notWant: english},
+		{name: "english", lang: LanguageEnglish, want: english, notWant: spanish},
+		{name: "english keeps a custom button label", lang: LanguageEnglish, buttonLabel: "festival {year}", want: []string{`button_text="festival 2025"`}, notWant: []string{`button_text="call for entries 2025"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewDiviTemplateService()
+			s.SetLanguage(tt.lang)
+			templateConfig := DefaultTemplateData()
+			if tt.buttonLabel != "" {
+				templateConfig.Footer.Button.Label = tt.buttonLabel
+			}
+
+			_, shortcodes := s.GenerateCompleteTemplate(filmData, nil, nil, nil, "", "2025", templateConfig)
+			for _, text := range tt.want {
+				if !strings.Contains(shortcodes, text) {
+					t.Errorf("template lacks %q", text)
+				}
+			}
+			for _, text := range tt.notWant {
+				if strings.Contains(shortcodes, text) {
+					t.Errorf("template contains %q", text)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		duration string
+		want     map[string]string // format -> rendered duration
+	}{
+		{"1:30:00", map[string]string{"": "90´00", DurationFormatAcute: "90´00", DurationFormatMinutes: "90 min", DurationFormatHours: "1h30", DurationFormatPrime: "90'"}},
+		{"2:05:10", map[string]string{"": "125´10", DurationFormatAcute: "125´10", DurationFormatMinutes: "125 min", DurationFormatHours: "2h05", DurationFormatPrime: "125'"}},
+		{"12:45", map[string]string{"": "12´45", DurationFormatAcute: "12´45", DurationFormatMinutes: "12 min", DurationFormatHours: "0h12", DurationFormatPrime: "12'"}},
+		{"", map[string]string{"": "0´0", DurationFormatAcute: "0´0", DurationFormatMinutes: "0 min", DurationFormatHours: "0h00", DurationFormatPrime: "0'"}},
+	}
+	for _, tt := range tests {
+		for format, want := range tt.want {
+			if got := formatDuration(tt.duration, format); got != want {
+				t.Errorf("formatDuration(%q, %q) = %q, want %q", tt.duration, format, got, want)
+			}
+		}
+	}
+}
+
+func TestDurationFormatValidation(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantErr bool
+	}{
+		{"", false},
+		{DurationFormatAcute, false},
+		{DurationFormatMinutes, false},
+		{DurationFormatHours, false},
+		{DurationFormatPrime, false},
+		{"seconds", true},
+		{"Minutes", true},
+	}
+	for _, tt := range tests {
+		templateData := DefaultTemplateData()
+		templateData.DurationFormat = tt.format
+		if err := templateData.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate() with duration_format %q: error %v, want error %v", tt.format, err, tt.wantErr)
+		}
+	}
+}
+
+func TestBuilderVersion(t *testing.T) {
+	versionPattern := regexp.MustCompile(`_builder_version="([^"]*)"`)
+	tests := []struct {
+		name    string
+		version string
+		want    string
+	}{
+		{name: "default", want: DefaultBuilderVersion},
+		{name: "configured", version: "4.28.0", want: "4.28.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templateConfig := DefaultTemplateData()
+			templateConfig.BuilderVersion = tt.version
+			templateConfig.Menu.MenuId = "7"
+			film := &DiviFilmTemplate{
+				Title:           "La película",
+				Directors:       []DirectorInfo{{Name: "Ana", ImageURL: "https://example.test/ana.jpg", Bio: "Bio"}},
+				Synopsis:        "Sinopsis",
+				GalleryMediaIds: "1,2",
+			}
+
+			rendered := NewDiviTemplateService().CreateStandardFilmTemplate(film, "2025", templateConfig).Compose()
+			matches := versionPattern.FindAllStringSubmatch(rendered, -1)
+			if len(matches) == 0 {
+				t.Fatal("no _builder_version in the rendered template")
+			}
+			for _, match := range matches {
+				if match[1] != tt.want {
+					t.Errorf("_builder_version = %q, want %q", match[1], tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestBuilderVersionValidation(t *testing.T) {
+	tests := []struct {
+		version string
+		wantErr bool
+	}{
+		{"", false},
+		{"4.27.4", false},
+		{"5", false},
+		{"v4.27", true},
+		{"4.27.", true},
+		{"latest", true},
+	}
+	for _, tt := range tests {
+		templateData := DefaultTemplateData()
+		templateData.BuilderVersion = tt.version
+		if err := templateData.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate() with builder_version %q: error %v, want error %v", tt.version, err, tt.wantErr)
+		}
+	}
+}
+
+func TestFooterSocialNetworks(t *testing.T) {
+	networkPattern := regexp.MustCompile(`\[et_pb_social_media_follow_network social_network="([^"]*)" url="([^"]*)"`)
+	tests := []struct {
+		name     string
+		networks []SocialNetwork
+		want     []SocialNetwork
+	}{
+		{name: "defaults", want: DefaultSocialNetworks()},
+		{
+			name:     "custom list",
+			networks: []SocialNetwork{{Network: "TikTok", URL: " https://tiktok.test/@excentrico "}, {Network: "youtube", URL: "https://youtube.test/excentrico"}},
+			want:     []SocialNetwork{{Network: "tiktok", URL: "https://tiktok.test/@excentrico"}, {Network: "youtube", URL: "https://youtube.test/excentrico"}},
+		},
+		{name: "no networks", networks: []SocialNetwork{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templateConfig := DefaultTemplateData()
+			templateConfig.Footer.SocialNetworks = tt.networks
+
+			rendered := NewDiviTemplateService().CreateStandardFilmTemplate(&DiviFilmTemplate{Title: "La película"}, "2025", templateConfig).Compose()
+			var got []SocialNetwork
+			for _, match := range networkPattern.FindAllStringSubmatch(rendered, -1) {
+				got = append(got, SocialNetwork{Network: match[1], URL: match[2]})
+			}
+			if len(got) != len(tt.want) || (len(got) > 0 && !reflect.DeepEqual(got, tt.want)) {
+				t.Errorf("footer networks %v, want %v", got, tt.want)
+			}
+			if !strings.Contains(rendered, "[et_pb_social_media_follow ") {
+				t.Error("no social media follow module in the footer")
+			}
+		})
+	}
+}
+
+func TestSocialNetworksValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		networks []SocialNetwork
+		wantErr  bool
+	}{
+		{name: "unset"},
+		{name: "empty list", networks: []SocialNetwork{}},
+		{name: "complete entries", networks: []SocialNetwork{{Network: "tiktok", URL: "https://tiktok.test/@excentrico"}}},
+		{name: "missing URL", networks: []SocialNetwork{{Network: "tiktok", URL: " "}}, wantErr: true},
+		{name: "missing network", networks: []SocialNetwork{{URL: "https://youtube.test/excentrico"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		templateData := DefaultTemplateData()
+		templateData.Footer.SocialNetworks = tt.networks
+		if err := templateData.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestMenuRendering(t *testing.T) {
+	enabled, disabled := true, false
+	tests := []struct {
+		name     string
+		enabled  *bool
+		menuId   string
+		wantMenu bool
+	}{
+		{name: "unset with menu", menuId: "7", wantMenu: true},
+		{name: "enabled with menu", enabled: &enabled, menuId: "7", wantMenu: true},
+		{name: "disabled with menu", enabled: &disabled, menuId: "7"},
+		{name: "unset without menu"},
+		{name: "blank menu ID", menuId: "  "},
+		{name: "disabled without menu", enabled: &disabled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templateConfig := DefaultTemplateData()
+			templateConfig.Menu.Enabled = tt.enabled
+			templateConfig.Menu.MenuId = tt.menuId
+
+			rendered := NewDiviTemplateService().CreateStandardFilmTemplate(&DiviFilmTemplate{Title: "La película"}, "2025", templateConfig).Compose()
+			wantCount := 0
+			if tt.wantMenu {
+				wantCount = 1
+			}
+			if got := strings.Count(rendered, "[et_pb_fullwidth_menu "); got != wantCount {
+				t.Errorf("rendered %d menus, want %d", got, wantCount)
+			}
+			if tt.wantMenu && !strings.Contains(rendered, `menu_id="`+tt.menuId+`"`) {
+				t.Errorf("menu does not use menu_id %q", tt.menuId)
+			}
+			if strings.Contains(rendered, `menu_id=""`) {
+				t.Error("rendered an empty menu")
+			}
+		})
+	}
+}
+
+func TestMenuValidation(t *testing.T) {
+	enabled, disabled := true, false
+	tests := []struct {
+		name    string
+		enabled *bool
+		menuId  string
+		wantErr bool
+	}{
+		{name: "unset without menu"},
+		{name: "disabled without menu", enabled: &disabled},
+		{name: "enabled with menu", enabled: &enabled, menuId: "7"},
+		{name: "enabled without menu", enabled: &enabled, wantErr: true},
+		{name: "enabled with blank menu", enabled: &enabled, menuId: " ", wantErr: true},
+	}
+	for _, tt := range tests {
+		templateData := DefaultTemplateData()
+		templateData.Menu.Enabled = tt.enabled
+		templateData.Menu.MenuId = tt.menuId
+		if err := templateData.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
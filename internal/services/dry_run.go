@@ -0,0 +1,163 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"excentrico-tools-go/internal/logger"
+)
+
+// ErrDryRun is returned for WordPress writes that have no dry-run stand-in
+var ErrDryRun = errors.New("write skipped in dry-run mode")
+
+// dryRunState holds the synthetic WordPress objects "created" during a dry run. Their IDs
+// are negative so they can never be mistaken for, or collide with, real WordPress IDs
+type dryRunState struct {
+	enabled bool
+	lastID  int
+	media   map[int]*WordPressMedia
+}
+
+// SetDryRun makes the service skip every write: uploads and post saves log what they
+// would send and return synthetic objects, other writes fail with ErrDryRun. Reads still
+// reach WordPress
+func (s *WordPressService) SetDryRun(dryRun bool) {
+	s.uploadMu.Lock()
+	defer s.uploadMu.Unlock()
+	s.dryRun.enabled = dryRun
+}
+
+// DryRun reports whether the service skips writes
+func (s *WordPressService) DryRun() bool {
+	s.uploadMu.Lock()
+	defer s.uploadMu.Unlock()
+	return s.dryRun.enabled
+}
+
+// nextDryRunID returns a new synthetic ID: -1, -2, ...
+func (s *WordPressService) nextDryRunID() int {
+	s.uploadMu.Lock()
+	defer s.uploadMu.Unlock()
+	s.dryRun.lastID--
+	return s.dryRun.lastID
+}
+
+// dryRunUpload stands in for uploading filePath: the synthetic media points at the local
+// file, so templates can still embed or reference it
+func (s *WordPressService) dryRunUpload(filePath, title, altText string) (*WordPressMedia, error) {
+	op := logger.Get().StartOperation("wordpress_upload_media_from_file")
+	op.WithContext("dry_run", true)
+	op.WithContext("http_request_payload_file_path", filePath)
+	op.WithContext("http_request_payload_title", title)
+	op.WithContext("http_request_payload_alt_text", altText)
+
+	absPath, err := filepath.Abs(filePath)
+	if err == nil {
+		_, err = os.Stat(absPath)
+	}
+	if err != nil {
+		op.Fail("Failed to open file", err)
+		return nil, fmt.Errorf("failed to open file: %v", err)
+	}
+
+	return s.storeDryRunMedia(op, filepath.Base(filePath), title, altText, "file://"+filepath.ToSlash(absPath)), nil
+}
+
+// storeDryRunMedia records a synthetic media for a dry-run upload of fileName, so that
+// GetMedia finds it later in the run, and completes op with what would have been uploaded
+func (s *WordPressService) storeDryRunMedia(op *logger.OperationTracker, fileName, title, altText, sourceURL string) *WordPressMedia {
+	media := &WordPressMedia{
+		ID:        s.nextDryRunID(),
+		Title:     WordPressRenderedField{Rendered: title},
+		SourceURL: sourceURL,
+		AltText:   altText,
+		MediaType: "image",
+	}
+	s.uploadMu.Lock()
+	if s.dryRun.media == nil {
+		s.dryRun.media = make(map[int]*WordPressMedia)
+	}
+	s.dryRun.media[media.ID] = media
+	s.uploadMu.Unlock()
+
+	op.WithWordPress(0, media.ID, "")
+	op.Complete(fmt.Sprintf("Dry run: would upload %s as '%s' (synthetic ID %d)", fileName, title, media.ID))
+	return media
+}
+
+// dryRunMedia returns the synthetic media of a dry-run upload
+func (s *WordPressService) dryRunMedia(mediaID int) (*WordPressMedia, bool) {
+	s.uploadMu.Lock()
+	defer s.uploadMu.Unlock()
+	media, ok := s.dryRun.media[mediaID]
+	return media, ok
+}
+
+// dryRunSavePost stands in for creating (postID 0) or updating a post and returns the
+// post as WordPress would echo it, with a synthetic ID when it is new
+func (s *WordPressService) dryRunSavePost(postID int, post *WordPressPost) *WordPressPost {
+	saved := *post
+	saved.ID = postID
+	if postID == 0 {
+		saved.ID = s.nextDryRunID()
+	}
+	now := time.Now().Format("2006-01-02T15:04:05")
+	if saved.Date == "" {
+		saved.Date = now
+	}
+	saved.Modified = now
+
+	op := logger.Get().StartOperation("wordpress_save_post")
+	op.WithContext("dry_run", true)
+	op.WithWordPress(saved.ID, saved.FeaturedMedia, saved.Slug)
+	op.WithContext("post_title", saved.Title.String())
+	op.WithContext("post_status", saved.Status)
+	op.WithContext("post_categories", saved.Categories)
+	op.KeepAlways()
+	if postID == 0 {
+		op.Complete(fmt.Sprintf("Dry run: would create post '%s' with slug %s (synthetic ID %d)", saved.Title.String(), saved.Slug, saved.ID))
+	} else {
+		op.Complete(fmt.Sprintf("Dry run: would update post %d '%s'", postID, saved.Title.String()))
+	}
+	return &saved
+}
+
+// SetDryRun makes the service keep every write in memory for the rest of the run instead
+// of sending it to the database. Reads see those writes over the stored data
+func (s *TursoService) SetDryRun(dryRun bool) {
+	s.dryRunMu.Lock()
+	defer s.dryRunMu.Unlock()
+	s.dryRun = dryRun
+	if dryRun && s.dryRunData == nil {
+		s.dryRunData = make(map[string]*string)
+	}
+}
+
+// dryRunKey identifies a metadata row in the dry-run overlay
+func dryRunKey(filmID, metadataType string) string {
+	return filmID + "\x00" + metadataType
+}
+
+// dryRunWrite records a write in the overlay and reports whether the service is in dry-run
+// mode; a nil data records a deletion
+func (s *TursoService) dryRunWrite(filmID, metadataType string, data *string) bool {
+	s.dryRunMu.Lock()
+	defer s.dryRunMu.Unlock()
+	if !s.dryRun {
+		return false
+	}
+	s.dryRunData[dryRunKey(filmID, metadataType)] = data
+	return true
+}
+
+// dryRunRead returns the overlay entry of a metadata row: found is false when the row was
+// never written in this dry run, and data is nil when it was deleted
+func (s *TursoService) dryRunRead(filmID, metadataType string) (data *string, found bool) {
+	s.dryRunMu.Lock()
+	defer s.dryRunMu.Unlock()
+	data, found = s.dryRunData[dryRunKey(filmID, metadataType)]
+	return data, found
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"excentrico-tools-go/internal/config"
+)
+
+func TestWordPressDryRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "still_01_web.jpg")
+	if err := os.WriteFile(path, []byte("still"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		write   func(wp *WordPressService) (int, error) // returns the ID of the written object
+		wantID  int
+		wantErr error
+	}{
+		{
+			name: "upload",
+			write: func(wp *WordPressService) (int, error) {
+				media, err := wp.UploadMediaFromFile(path, "Still 01", "")
+				if err != nil {
+					return 0, err
+				}
+				// The synthetic media can be read back without WordPress
+				found, err := wp.GetMedia(media.ID)
+				if err != nil || !strings.HasPrefix(found.SourceURL, "file://") {
+					t.Errorf("GetMedia(%d) = %+v, %v, want the local file", media.ID, found, err)
+				}
+				return media.ID, nil
+			},
+			wantID: -1,
+		},
+		{
+			name: "multipart upload",
+			write: func(wp *WordPressService) (int, error) {
+				media, err := wp.UploadMedia(multipartFile(t, "still_02_web.jpg"), "Still 02", "")
+				if err != nil {
+					return 0, err
+				}
+				if found, err := wp.GetMedia(media.ID); err != nil || found.Title.String() != "Still 02" {
+					t.Errorf("GetMedia(%d) = %+v, %v, want the synthetic media", media.ID, found, err)
+				}
+				return media.ID, nil
+			},
+			wantID: -1,
+		},
+		{
+			name: "create post",
+			write: func(wp *WordPressService) (int, error) {
+				post, err := wp.CreatePost(&WordPressPost{Title: WordPressRenderedField{Raw: "La playa"}, Slug: "la-playa"})
+				if err != nil {
+					return 0, err
+				}
+				return post.ID, nil
+			},
+			wantID: -1,
+		},
+		{
+			name: "update post",
+			write: func(wp *WordPressService) (int, error) {
+				post, err := wp.UpdatePost(42, &WordPressPost{Title: WordPressRenderedField{Raw: "La playa"}})
+				if err != nil {
+					return 0, err
+				}
+				return post.ID, nil
+			},
+			wantID: 42,
+		},
+		{
+			name: "other writes",
+			write: func(wp *WordPressService) (int, error) {
+				return 0, wp.DeleteMedia(7)
+			},
+			wantErr: ErrDryRun,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var writes atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet {
+					writes.Add(1)
+				}
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+			defer srv.Close()
+			wp := NewWordPressService(context.Background(), config.WordPressConfig{BaseURL: srv.URL})
+			wp.SetDryRun(true)
+
+			id, err := tt.write(wp)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error %v, want %v", err, tt.wantErr)
+			}
+			if id != tt.wantID {
+				t.Errorf("ID %d, want %d", id, tt.wantID)
+			}
+			if got := writes.Load(); got != 0 {
+				t.Errorf("%d write requests reached WordPress, want none", got)
+			}
+		})
+	}
+}
+
+// multipartFile returns the header of a file named name posted in a multipart form
+func multipartFile(t *testing.T, name string) *multipart.FileHeader {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte("still"))
+	writer.Close()
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { form.RemoveAll() })
+	return form.File["file"][0]
+}
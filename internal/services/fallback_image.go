@@ -0,0 +1,79 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+
+	"excentrico-tools-go/internal/logger"
+)
+
+// FallbackImage stands in for the images of a film that yields none, so its header and
+// gallery are not left empty
+type FallbackImage struct {
+	MediaID    int    // Existing WordPress media; takes precedence over Path
+	Path       string // Local image uploaded on first use in the run when MediaID is 0
+	Background bool   // Use it as the header background
+	Gallery    bool   // Use it as the single gallery item
+}
+
+// SetFallbackImage configures the image used for films without any; nil disables it
+func (s *DiviTemplateService) SetFallbackImage(fallback *FallbackImage) {
+	s.fallbackMu.Lock()
+	defer s.fallbackMu.Unlock()
+	s.fallbackImage = fallback
+	s.fallbackMedia = nil
+	s.fallbackErr = nil
+}
+
+// resolveFallbackMedia returns the fallback media, looking it up or uploading it the
+// first time it is needed. The outcome, failure included, is kept for the rest of the
+// run so the image is uploaded at most once
+func (s *DiviTemplateService) resolveFallbackMedia(wordpressService *WordPressService) (*WordPressMedia, error) {
+	s.fallbackMu.Lock()
+	defer s.fallbackMu.Unlock()
+
+	if s.fallbackMedia != nil || s.fallbackErr != nil {
+		return s.fallbackMedia, s.fallbackErr
+	}
+	if s.fallbackImage.MediaID > 0 {
+		s.fallbackMedia, s.fallbackErr = wordpressService.GetMedia(s.fallbackImage.MediaID)
+	} else {
+		s.fallbackMedia, s.fallbackErr = wordpressService.UploadMediaFromFile(s.fallbackImage.Path, "Fallback image", "")
+	}
+	return s.fallbackMedia, s.fallbackErr
+}
+
+// applyFallbackImage fills the background and gallery of a film without images with
+// the configured fallback image. It does nothing when no fallback is configured
+func (s *DiviTemplateService) applyFallbackImage(template *DiviFilmTemplate, wordpressService *WordPressService, filmID string) {
+	s.fallbackMu.Lock()
+	fallback := s.fallbackImage
+	s.fallbackMu.Unlock()
+	if fallback == nil || wordpressService == nil {
+		return
+	}
+
+	op := logger.Get().StartOperation("apply_fallback_image")
+	op.WithFilm(filmID, template.Title, template.Year, "")
+
+	media, err := s.resolveFallbackMedia(wordpressService)
+	if err != nil {
+		op.WithError(err)
+		op.Warn(&logger.WideEvent{
+			Message: fmt.Sprintf("Film '%s' has no images and the fallback image is unavailable", template.Title),
+		})
+		return
+	}
+	op.WithWordPress(0, media.ID, "")
+
+	if fallback.Background {
+		template.BackgroundImage = media.SourceURL
+	}
+	if fallback.Gallery {
+		template.ImageGalleryIds = []int{media.ID}
+		template.GalleryMediaIds = strconv.Itoa(media.ID)
+	}
+	op.WithContext("fallback_background", fallback.Background)
+	op.WithContext("fallback_gallery", fallback.Gallery)
+	op.Complete(fmt.Sprintf("Film '%s' has no images; using fallback media %d", template.Title, media.ID))
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"excentrico-tools-go/internal/config"
+)
+
+func TestFallbackImage(t *testing.T) {
+	media := map[int]*WordPressMedia{
+		1:  {ID: 1, SourceURL: "https://example.test/uploads/still-1.jpg"},
+		50: {ID: 50, SourceURL: "https://example.test/uploads/placeholder.jpg"},
+	}
+	tests := []struct {
+		name           string
+		fallback       *FallbackImage
+		imageIds       []int
+		wantBackground string
+		wantGallery    []int
+	}{
+		{name: "not configured", wantGallery: []int{}},
+		{
+			name:           "background and gallery",
+			fallback:       &FallbackImage{MediaID: 50, Background: true, Gallery: true},
+			wantBackground: media[50].SourceURL,
+			wantGallery:    []int{50},
+		},
+		{
+			name:           "background only",
+			fallback:       &FallbackImage{MediaID: 50, Background: true},
+			wantBackground: media[50].SourceURL,
+			wantGallery:    []int{},
+		},
+		{
+			name:        "gallery only",
+			fallback:    &FallbackImage{MediaID: 50, Gallery: true},
+			wantGallery: []int{50},
+		},
+		{
+			name:           "film with images",
+			fallback:       &FallbackImage{MediaID: 50, Background: true, Gallery: true},
+			imageIds:       []int{1},
+			wantBackground: media[1].SourceURL,
+			wantGallery:    []int{},
+		},
+		{
+			name:        "unavailable fallback media",
+			fallback:    &FallbackImage{MediaID: 404, Background: true, Gallery: true},
+			wantGallery: []int{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wp, _ := newMediaServer(t, media)
+			s := NewDiviTemplateService()
+			s.SetFallbackImage(tt.fallback)
+
+			template := s.GenerateDiviTemplateDataWithWordPress(&FilmData{TituloOriginal: "La playa"}, tt.imageIds, wp, nil, "")
+			if template.BackgroundImage != tt.wantBackground {
+				t.Errorf("background %q, want %q", template.BackgroundImage, tt.wantBackground)
+			}
+			if !reflect.DeepEqual(template.ImageGalleryIds, tt.wantGallery) {
+				t.Errorf("gallery IDs %v, want %v", template.ImageGalleryIds, tt.wantGallery)
+			}
+			wantMediaIds := ""
+			if len(tt.wantGallery) > 0 {
+				wantMediaIds = "50"
+			}
+			if template.GalleryMediaIds != wantMediaIds {
+				t.Errorf("gallery media IDs %q, want %q", template.GalleryMediaIds, wantMediaIds)
+			}
+		})
+	}
+}
+
+func TestFallbackImageUploadedOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "placeholder.jpg")
+	if err := os.WriteFile(path, []byte("placeholder"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var uploads atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/wp/v2/media") {
+			http.NotFound(w, r)
+			return
+		}
+		uploads.Add(1)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": 60, "source_url": "https://example.test/uploads/placeholder.jpg"}`))
+	}))
+	defer srv.Close()
+	wp := NewWordPressService(context.Background(), config.WordPressConfig{BaseURL: srv.URL})
+
+	s := NewDiviTemplateService()
+	s.SetFallbackImage(&FallbackImage{Path: path, Background: true, Gallery: true})
+	for _, title := range []string{"La playa", "El faro", "El bosque"} {
+		template := s.GenerateDiviTemplateDataWithWordPress(&FilmData{TituloOriginal: title}, nil, wp, nil, "")
+		if template.GalleryMediaIds != "60" || template.BackgroundImage != "https://example.test/uploads/placeholder.jpg" {
+			t.Errorf("%s: gallery %q and background %q, want the uploaded fallback", title, template.GalleryMediaIds, template.BackgroundImage)
+		}
+	}
+	if got := uploads.Load(); got != 1 {
+		t.Errorf("fallback uploaded %d times, want once", got)
+	}
+}
@@ -1,5 +1,13 @@
 package services
 
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"excentrico-tools-go/internal/utils"
+)
+
 type FilmData struct {
 	TituloOriginal      string `json:"titulo_original"`
 	Direccion           string `json:"direccion"`
@@ -39,4 +47,106 @@ type FilmData struct {
 	MultiDir            string `json:"multi_dir"`
 
 	AdditionalFields map[string]string `json:"additional_fields,omitempty"`
+
+	// DetectMultipleDirectors splits DIRECCIÓN on its separators when Multi Dir is blank
+	DetectMultipleDirectors bool `json:"-"`
+}
+
+// Synopsis sources selectable from the per-year template file
+const (
+	SynopsisSourceExtended = "extended" // Sinopsis extendida
+	SynopsisSourceCompact  = "compact"  // Sinopsis compacta
+	SynopsisSourceEnglish  = "english"  // Extended synopsis (english)
+)
+
+// IsValidSynopsisSource reports whether source names a known synopsis field
+func IsValidSynopsisSource(source string) bool {
+	switch source {
+	case SynopsisSourceExtended, SynopsisSourceCompact, SynopsisSourceEnglish:
+		return true
+	}
+	return false
+}
+
+// Synopsis returns the synopsis field selected by source, defaulting to the extended synopsis
+func (f *FilmData) Synopsis(source string) string {
+	switch source {
+	case SynopsisSourceCompact:
+		return f.SinopsisCompacta
+	case SynopsisSourceEnglish:
+		return f.ExtendedSynopsis
+	default:
+		return f.SinopsisExtendida
+	}
+}
+
+// Validate reports the data problems that would leave the film's page incomplete;
+// an empty result means the row is usable as is
+func (f *FilmData) Validate() []string {
+	var issues []string
+
+	for _, field := range SheetFields {
+		if field.Required && strings.TrimSpace(field.Value(f)) == "" {
+			issues = append(issues, fmt.Sprintf("missing %s", field.Header))
+		}
+	}
+
+	if ano := strings.TrimSpace(f.Ano); ano != "" && !yearPattern.MatchString(ano) {
+		issues = append(issues, fmt.Sprintf("AÑO %q is not a four-digit year", ano))
+	}
+	if edicion := strings.TrimSpace(f.Edicion); edicion != "" && utils.ExtractEditionYear(edicion) == "" {
+		issues = append(issues, fmt.Sprintf("EDICIÓN %q has no year", edicion))
+	}
+
+	return issues
+}
+
+var yearPattern = regexp.MustCompile(`^\d{4}$`)
+
+// HasMultipleDirectors reports whether DIRECCIÓN lists several directors: Multi Dir is
+// "SI", or it is blank, detection is enabled and the separators yield several names
+func (f *FilmData) HasMultipleDirectors() bool {
+	if strings.ToUpper(f.MultiDir) == "SI" {
+		return true
+	}
+	return f.DetectMultipleDirectors && strings.TrimSpace(f.MultiDir) == "" && len(parseDirectors(f.Direccion)) > 1
+}
+
+// DirectorNames returns the film's directors, split into individual names when
+// it has several, as the template's director section lists them
+func (f *FilmData) DirectorNames() []string {
+	if strings.TrimSpace(f.Direccion) == "" {
+		return nil
+	}
+	if f.HasMultipleDirectors() {
+		return parseDirectors(f.Direccion)
+	}
+	return []string{strings.TrimSpace(f.Direccion)}
+}
+
+// Credits returns the film's credit fields as rendered in the technical sheet
+func (f *FilmData) Credits() Credits {
+	return Credits{
+		Production:   f.Produccion,
+		Script:       f.Guion,
+		Photography:  f.CamaraFoto,
+		ArtDesign:    f.ArteDiseno,
+		SoundMusic:   f.SonidoMusica,
+		Editing:      f.EdicionCredits,
+		Cast:         f.Interpretes,
+		OtherCredits: f.OtrosCreditos,
+	}
+}
+
+// MissingContent lists the publishable content the film lacks: "synopsis" when the
+// extended synopsis is empty and "credits" when every credit field is empty
+func (f *FilmData) MissingContent() []string {
+	var missing []string
+	if strings.TrimSpace(f.SinopsisExtendida) == "" {
+		missing = append(missing, "synopsis")
+	}
+	if f.Credits().IsEmpty() {
+		missing = append(missing, "credits")
+	}
+	return missing
 }
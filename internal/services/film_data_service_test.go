@@ -0,0 +1,55 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDirectorNames(t *testing.T) {
+	tests := []struct {
+		name      string
+		film      FilmData
+		wantMulti bool
+		want      []string
+	}{
+		{"single director", FilmData{Direccion: "Ana Pérez"}, false, []string{"Ana Pérez"}},
+		{"blank Multi Dir without detection", FilmData{Direccion: "Ana Pérez y Luis Gil"}, false, []string{"Ana Pérez y Luis Gil"}},
+		{"blank Multi Dir with detection", FilmData{Direccion: "Ana Pérez y Luis Gil", DetectMultipleDirectors: true}, true, []string{"Ana Pérez", "Luis Gil"}},
+		{"blank Multi Dir with comma-separated names", FilmData{Direccion: "Ana Pérez, Luis Gil, Marta Ruiz", DetectMultipleDirectors: true}, true, []string{"Ana Pérez", "Luis Gil", "Marta Ruiz"}},
+		{"blank Multi Dir with a comma but no detection", FilmData{Direccion: "Ana Pérez, Luis Gil"}, false, []string{"Ana Pérez, Luis Gil"}},
+		{"Multi Dir SI", FilmData{Direccion: "Ana Pérez, Luis Gil", MultiDir: "si"}, true, []string{"Ana Pérez", "Luis Gil"}},
+		{"Multi Dir NO overrides detection", FilmData{Direccion: "Ana Pérez & Luis Gil", MultiDir: "NO", DetectMultipleDirectors: true}, false, []string{"Ana Pérez & Luis Gil"}},
+		{"no director", FilmData{DetectMultipleDirectors: true}, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.film.HasMultipleDirectors(); got != tt.wantMulti {
+				t.Errorf("HasMultipleDirectors() = %v, want %v", got, tt.wantMulti)
+			}
+			if got := tt.film.DirectorNames(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DirectorNames() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMissingContent(t *testing.T) {
+	tests := []struct {
+		name string
+		film FilmData
+		want []string
+	}{
+		{"complete", FilmData{SinopsisExtendida: "Una historia.", Guion: "Ana Pérez"}, nil},
+		{"blank synopsis", FilmData{SinopsisExtendida: "  \n", Guion: "Ana Pérez"}, []string{"synopsis"}},
+		{"no credits", FilmData{SinopsisExtendida: "Una historia.", Interpretes: " "}, []string{"credits"}},
+		{"only other credits", FilmData{SinopsisExtendida: "Una historia.", OtrosCreditos: "Vestuario: Luis Gil"}, nil},
+		{"both missing", FilmData{ExtendedSynopsis: "A story."}, []string{"synopsis", "credits"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.film.MissingContent(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MissingContent() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
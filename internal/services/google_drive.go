@@ -14,36 +14,78 @@ import (
 )
 
 type GoogleDriveService struct {
-	service *drive.Service
+	service  *drive.Service
+	ctx      context.Context // Cancels in-flight Drive requests when the run is interrupted
+	pageSize int64           // Files requested per ListFiles page; 0 uses the Drive API default
+	driveID  string          // Shared drive listings are scoped to; empty searches every drive
+	// listSlots bounds the ListFiles calls running at once across every film; nil means unlimited
+	listSlots chan struct{}
 }
 
-func NewGoogleDriveService(ctx context.Context, credentialsPath string) (*GoogleDriveService, error) {
+// DefaultListConcurrency is how many folder listings run at once unless configured otherwise
+const DefaultListConcurrency = 4
 
-	data, err := os.ReadFile(credentialsPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read credentials file: %v", err)
-	}
-	credentials, err := google.CredentialsFromJSON(ctx, data, drive.DriveScope)
+func NewGoogleDriveService(ctx context.Context, credentialsJSON []byte) (*GoogleDriveService, error) {
+
+	credentials, err := google.CredentialsFromJSON(ctx, credentialsJSON, drive.DriveScope)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load credentials: %v", err)
 	}
 
-	service, err := drive.NewService(ctx, option.WithCredentials(credentials))
+	return NewGoogleDriveServiceWithOptions(ctx, option.WithHTTPClient(newGoogleHTTPClient(ctx, credentials)))
+}
+
+// NewGoogleDriveServiceWithOptions creates a GoogleDriveService from Google API client
+// options, e.g. to talk to another endpoint
+func NewGoogleDriveServiceWithOptions(ctx context.Context, opts ...option.ClientOption) (*GoogleDriveService, error) {
+	service, err := drive.NewService(ctx, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create drive service: %v", err)
 	}
 
 	return &GoogleDriveService{
-		service: service,
+		service:   service,
+		ctx:       ctx,
+		listSlots: make(chan struct{}, DefaultListConcurrency),
 	}, nil
 }
 
+// Context returns the context the service's requests run under
+func (s *GoogleDriveService) Context() context.Context {
+	return s.ctx
+}
+
+// openDownload starts the download of a file's content; the caller closes the body
+func (s *GoogleDriveService) openDownload(fileID string) (io.ReadCloser, error) {
+	resp, err := s.service.Files.Get(fileID).SupportsAllDrives(true).Context(s.ctx).Download()
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	return resp.Body, nil
+}
+
+// DownloadFileBytes returns the content of a file in memory, for callers that do not
+// need it on disk
+func (s *GoogleDriveService) DownloadFileBytes(fileID string) ([]byte, error) {
+	body, err := s.openDownload(fileID)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file content: %v", err)
+	}
+	return data, nil
+}
+
 func (s *GoogleDriveService) DownloadFile(fileID, destinationPath string) error {
-	resp, err := s.service.Files.Get(fileID).Download()
+	body, err := s.openDownload(fileID)
 	if err != nil {
-		return fmt.Errorf("failed to download file: %v", err)
+		return err
 	}
-	defer resp.Body.Close()
+	defer body.Close()
 
 	dir := filepath.Dir(destinationPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -56,8 +98,13 @@ func (s *GoogleDriveService) DownloadFile(fileID, destinationPath string) error
 	}
 	defer dst.Close()
 
-	_, err = io.Copy(dst, resp.Body)
+	// A partial file would pass for a complete download on the next run or film retry
+	_, err = io.Copy(dst, body)
+	if err == nil {
+		err = dst.Close()
+	}
 	if err != nil {
+		os.Remove(destinationPath)
 		return fmt.Errorf("failed to copy file content: %v", err)
 	}
 
@@ -65,16 +112,79 @@ func (s *GoogleDriveService) DownloadFile(fileID, destinationPath string) error
 	return nil
 }
 
-func (s *GoogleDriveService) ListFiles(folderID string) ([]*drive.File, error) {
-	query := fmt.Sprintf("'%s' in parents and trashed=false", folderID)
-
-	files, err := s.service.Files.List().
-		Q(query).
-		Fields("files(id, name, mimeType, size, createdTime)").
+// GetFile returns the metadata of a single file or folder
+func (s *GoogleDriveService) GetFile(fileID string) (*drive.File, error) {
+	file, err := s.service.Files.Get(fileID).
+		SupportsAllDrives(true).
+		Fields("id, name, mimeType, size, createdTime, modifiedTime").
+		Context(s.ctx).
 		Do()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list files: %v", err)
+		return nil, fmt.Errorf("failed to get file metadata: %w", err)
 	}
+	return file, nil
+}
+
+// SetPageSize sets how many files ListFiles requests per page (at most 1000); 0 uses the API default
+func (s *GoogleDriveService) SetPageSize(pageSize int) {
+	s.pageSize = int64(pageSize)
+}
+
+// SetDriveID scopes ListFiles to a shared drive; empty lists from every drive the account can see
+func (s *GoogleDriveService) SetDriveID(driveID string) {
+	s.driveID = driveID
+}
 
-	return files.Files, nil
+// SetListConcurrency sets how many ListFiles calls may run at once; 0 or less removes the limit
+func (s *GoogleDriveService) SetListConcurrency(n int) {
+	if n <= 0 {
+		s.listSlots = nil
+		return
+	}
+	s.listSlots = make(chan struct{}, n)
+}
+
+// ListFiles returns every file directly inside a folder, following nextPageToken.
+// A listing holds one of the service's list slots until its last page has been read
+func (s *GoogleDriveService) ListFiles(folderID string) ([]*drive.File, error) {
+	if s.listSlots != nil {
+		select {
+		case s.listSlots <- struct{}{}:
+		case <-s.ctx.Done():
+			return nil, fmt.Errorf("failed to list files: %w", s.ctx.Err())
+		}
+		defer func() { <-s.listSlots }()
+	}
+
+	query := fmt.Sprintf("'%s' in parents and trashed=false", folderID)
+
+	var allFiles []*drive.File
+	pageToken := ""
+	for {
+		call := s.service.Files.List().
+			Q(query).
+			Fields("nextPageToken, files(id, name, mimeType, size, createdTime)").
+			SupportsAllDrives(true).
+			IncludeItemsFromAllDrives(true)
+		if s.driveID != "" {
+			call = call.Corpora("drive").DriveId(s.driveID)
+		}
+		if s.pageSize > 0 {
+			call = call.PageSize(s.pageSize)
+		}
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+
+		files, err := call.Context(s.ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files: %w", err)
+		}
+
+		allFiles = append(allFiles, files.Files...)
+		if files.NextPageToken == "" {
+			return allFiles, nil
+		}
+		pageToken = files.NextPageToken
+	}
 }
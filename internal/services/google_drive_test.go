@@ -0,0 +1,180 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+)
+
+func TestDownloadFileBytes(t *testing.T) {
+	contents := map[string][]byte{
+		"poster":  []byte("poster image"),
+		"binary":  {0xff, 0xd8, 0x00, 0x01, 0xff, 0xd9},
+		"empty":   {},
+		"unicode": []byte("Película de prueba"),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fileID := strings.TrimPrefix(r.URL.Path, "/drive/v3/files/")
+		if fileID == "truncated" {
+			// The connection drops after part of the declared content
+			w.Header().Set("Content-Length", "100")
+			w.Write([]byte("poster"))
+			return
+		}
+		content, ok := contents[fileID]
+		if !ok || r.URL.Query().Get("alt") != "media" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"code": 404, "message": "File not found"}}`))
+			return
+		}
+		w.Write(content)
+	}))
+	t.Cleanup(srv.Close)
+
+	service, err := NewGoogleDriveServiceWithOptions(context.Background(),
+		option.WithEndpoint(srv.URL+"/drive/v3/"),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatalf("NewGoogleDriveServiceWithOptions: %v", err)
+	}
+
+	tests := []struct {
+		fileID  string
+		want    []byte
+		wantErr bool
+	}{
+		{fileID: "poster", want: contents["poster"]},
+		{fileID: "binary", want: contents["binary"]},
+		{fileID: "empty", want: []byte{}},
+		{fileID: "unicode", want: contents["unicode"]},
+		{fileID: "missing", wantErr: true},
+		{fileID: "truncated", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fileID, func(t *testing.T) {
+			got, err := service.DownloadFileBytes(tt.fileID)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("DownloadFileBytes(%q) = %q, want an error", tt.fileID, got)
+				}
+				// A failed download leaves no partial file behind
+				path := filepath.Join(t.TempDir(), tt.fileID)
+				if err := service.DownloadFile(tt.fileID, path); err == nil {
+					t.Fatalf("DownloadFile(%q) succeeded, want an error", tt.fileID)
+				}
+				if _, err := os.Stat(path); !os.IsNotExist(err) {
+					t.Errorf("DownloadFile(%q) left %s behind (%v)", tt.fileID, path, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DownloadFileBytes(%q): %v", tt.fileID, err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("DownloadFileBytes(%q) = %q, want %q", tt.fileID, got, tt.want)
+			}
+
+			// The bytes match what DownloadFile writes to disk
+			path := filepath.Join(t.TempDir(), tt.fileID)
+			if err := service.DownloadFile(tt.fileID, path); err != nil {
+				t.Fatalf("DownloadFile(%q): %v", tt.fileID, err)
+			}
+			if onDisk, err := os.ReadFile(path); err != nil || !bytes.Equal(onDisk, got) {
+				t.Errorf("DownloadFile(%q) wrote %q (%v), want %q", tt.fileID, onDisk, err, got)
+			}
+		})
+	}
+}
+
+func TestListFilesPages(t *testing.T) {
+	const defaultPageSize = 2 // Page size the fake uses when none is requested
+	tests := []struct {
+		name      string
+		files     int
+		pageSize  int
+		wantPages int
+	}{
+		{name: "two default pages", files: 3, wantPages: 2},
+		{name: "exact pages", files: 4, wantPages: 2},
+		{name: "configured page size", files: 7, pageSize: 3, wantPages: 3},
+		{name: "single page", files: 7, pageSize: 100, wantPages: 1},
+		{name: "empty folder", wantPages: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var want []string
+			for i := range tt.files {
+				want = append(want, fmt.Sprintf("still-%d.jpg", i))
+			}
+
+			var pages int
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				query := r.URL.Query()
+				if r.URL.Path != "/drive/v3/files" || query.Get("q") != "'folder' in parents and trashed=false" {
+					http.NotFound(w, r)
+					return
+				}
+				pages++
+				requested, _ := strconv.Atoi(query.Get("pageSize"))
+				if requested != tt.pageSize {
+					t.Errorf("pageSize = %d, want %d", requested, tt.pageSize)
+				}
+				pageSize := defaultPageSize
+				if requested > 0 {
+					pageSize = requested
+				}
+				start, _ := strconv.Atoi(query.Get("pageToken"))
+				end := min(start+pageSize, len(want))
+
+				list := &drive.FileList{Files: []*drive.File{}}
+				for _, name := range want[start:end] {
+					list.Files = append(list.Files, &drive.File{Id: name, Name: name})
+				}
+				if end < len(want) {
+					list.NextPageToken = strconv.Itoa(end)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(list)
+			}))
+			t.Cleanup(srv.Close)
+
+			service, err := NewGoogleDriveServiceWithOptions(context.Background(),
+				option.WithEndpoint(srv.URL+"/drive/v3/"),
+				option.WithoutAuthentication(),
+				option.WithHTTPClient(srv.Client()))
+			if err != nil {
+				t.Fatalf("NewGoogleDriveServiceWithOptions: %v", err)
+			}
+			service.SetPageSize(tt.pageSize)
+
+			files, err := service.ListFiles("folder")
+			if err != nil {
+				t.Fatalf("ListFiles: %v", err)
+			}
+			var got []string
+			for _, file := range files {
+				got = append(got, file.Name)
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("ListFiles returned %q, want %q", got, want)
+			}
+			if pages != tt.wantPages {
+				t.Errorf("pages requested = %d, want %d", pages, tt.wantPages)
+			}
+		})
+	}
+}
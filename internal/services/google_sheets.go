@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"os"
 
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/option"
@@ -13,31 +12,35 @@ import (
 
 type GoogleSheetsService struct {
 	service *sheets.Service
+	ctx     context.Context // Cancels in-flight Sheets requests when the run is interrupted
 }
 
-func NewGoogleSheetsService(ctx context.Context, credentialsPath string) (*GoogleSheetsService, error) {
+func NewGoogleSheetsService(ctx context.Context, credentialsJSON []byte) (*GoogleSheetsService, error) {
 
-	data, err := os.ReadFile(credentialsPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read credentials file: %v", err)
-	}
-	credentials, err := google.CredentialsFromJSON(ctx, data, sheets.SpreadsheetsScope)
+	credentials, err := google.CredentialsFromJSON(ctx, credentialsJSON, sheets.SpreadsheetsScope)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load credentials: %v", err)
 	}
 
-	service, err := sheets.NewService(ctx, option.WithCredentials(credentials))
+	return NewGoogleSheetsServiceWithOptions(ctx, option.WithHTTPClient(newGoogleHTTPClient(ctx, credentials)))
+}
+
+// NewGoogleSheetsServiceWithOptions creates a GoogleSheetsService from Google API client
+// options, e.g. to talk to another endpoint
+func NewGoogleSheetsServiceWithOptions(ctx context.Context, opts ...option.ClientOption) (*GoogleSheetsService, error) {
+	service, err := sheets.NewService(ctx, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create sheets service: %v", err)
 	}
 
 	return &GoogleSheetsService{
 		service: service,
+		ctx:     ctx,
 	}, nil
 }
 
 func (s *GoogleSheetsService) ReadRange(spreadsheetID, rangeStr string) ([][]interface{}, error) {
-	resp, err := s.service.Spreadsheets.Values.Get(spreadsheetID, rangeStr).Do()
+	resp, err := s.service.Spreadsheets.Values.Get(spreadsheetID, rangeStr).Context(s.ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to read range: %v", err)
 	}
@@ -52,6 +55,7 @@ func (s *GoogleSheetsService) WriteRange(spreadsheetID, rangeStr string, values
 
 	_, err := s.service.Spreadsheets.Values.Update(spreadsheetID, rangeStr, valueRange).
 		ValueInputOption("RAW").
+		Context(s.ctx).
 		Do()
 	if err != nil {
 		return fmt.Errorf("failed to write range: %v", err)
@@ -60,6 +64,22 @@ func (s *GoogleSheetsService) WriteRange(spreadsheetID, rangeStr string, values
 	return nil
 }
 
+// WriteCell writes a single value to the 0-based column of a 1-based row of a sheet tab
+func (s *GoogleSheetsService) WriteCell(spreadsheetID, tab string, row, column int, value interface{}) error {
+	cell := fmt.Sprintf("%s!%s%d", tab, ColumnLetter(column), row)
+	return s.WriteRange(spreadsheetID, cell, [][]interface{}{{value}})
+}
+
+// ColumnLetter converts a 0-based column index to its A1 notation letters (0 -> A, 26 -> AA)
+func ColumnLetter(index int) string {
+	letters := ""
+	for index >= 0 {
+		letters = string(rune('A'+index%26)) + letters
+		index = index/26 - 1
+	}
+	return letters
+}
+
 func (s *GoogleSheetsService) AppendRow(spreadsheetID, rangeStr string, values []interface{}) error {
 	valueRange := &sheets.ValueRange{
 		Values: [][]interface{}{values},
@@ -68,6 +88,7 @@ func (s *GoogleSheetsService) AppendRow(spreadsheetID, rangeStr string, values [
 	_, err := s.service.Spreadsheets.Values.Append(spreadsheetID, rangeStr, valueRange).
 		ValueInputOption("RAW").
 		InsertDataOption("INSERT_ROWS").
+		Context(s.ctx).
 		Do()
 	if err != nil {
 		return fmt.Errorf("failed to append row: %v", err)
@@ -83,7 +104,7 @@ func (s *GoogleSheetsService) CreateSpreadsheet(title string) (*sheets.Spreadshe
 		},
 	}
 
-	resp, err := s.service.Spreadsheets.Create(spreadsheet).Do()
+	resp, err := s.service.Spreadsheets.Create(spreadsheet).Context(s.ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create spreadsheet: %v", err)
 	}
@@ -93,7 +114,7 @@ func (s *GoogleSheetsService) CreateSpreadsheet(title string) (*sheets.Spreadshe
 }
 
 func (s *GoogleSheetsService) GetSpreadsheet(spreadsheetID string) (*sheets.Spreadsheet, error) {
-	resp, err := s.service.Spreadsheets.Get(spreadsheetID).Do()
+	resp, err := s.service.Spreadsheets.Get(spreadsheetID).Context(s.ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get spreadsheet: %v", err)
 	}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// sharedTransport is used by every HTTP client in the application so keep-alive
+// connections are pooled and reused across services and concurrent requests
+var sharedTransport = &http.Transport{
+	Proxy: http.ProxyFromEnvironment,
+	DialContext: (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}).DialContext,
+	ForceAttemptHTTP2:     true,
+	MaxIdleConns:          100,
+	MaxIdleConnsPerHost:   16,
+	IdleConnTimeout:       90 * time.Second,
+	TLSHandshakeTimeout:   10 * time.Second,
+	ExpectContinueTimeout: 1 * time.Second,
+}
+
+// SharedTransport returns the pooled transport shared by all services
+func SharedTransport() *http.Transport {
+	return sharedTransport
+}
+
+// newHTTPClient returns a client backed by the shared transport
+func newHTTPClient() *http.Client {
+	return &http.Client{Transport: sharedTransport}
+}
+
+// newGoogleHTTPClient returns an OAuth2 client for the given credentials that
+// sends its requests through the shared transport
+func newGoogleHTTPClient(ctx context.Context, credentials *google.Credentials) *http.Client {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, newHTTPClient())
+	return oauth2.NewClient(ctx, credentials.TokenSource)
+}
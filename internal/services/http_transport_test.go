@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"excentrico-tools-go/internal/config"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+func TestSharedTransportReusesConnections(t *testing.T) {
+	tests := []struct {
+		name      string
+		rounds    int // batches of requests, each waiting for the previous one
+		parallel  int // requests per batch
+		maxConns  int32
+		useGoogle bool // send through the OAuth2 client built for the Google APIs
+	}{
+		{name: "sequential WordPress requests", rounds: 10, parallel: 1, maxConns: 1},
+		{name: "concurrent WordPress requests", rounds: 5, parallel: 8, maxConns: 8},
+		{name: "sequential Google requests", rounds: 10, parallel: 1, maxConns: 1, useGoogle: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var conns atomic.Int32
+			srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, `{"id":1}`)
+			}))
+			srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+				if state == http.StateNew {
+					conns.Add(1)
+				}
+			}
+			srv.Start()
+			defer srv.Close()
+
+			// Every request builds a new client, as each service does, so only the
+			// shared transport can carry connections from one to the next
+			get := func() error {
+				if tt.useGoogle {
+					client := newGoogleHTTPClient(context.Background(), &google.Credentials{
+						TokenSource: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"}),
+					})
+					resp, err := client.Get(srv.URL + "/drive/v3/files")
+					if err != nil {
+						return err
+					}
+					io.Copy(io.Discard, resp.Body)
+					return resp.Body.Close()
+				}
+				wp := NewWordPressService(context.Background(), config.WordPressConfig{BaseURL: srv.URL})
+				resp, err := wp.makeRequest("GET", "/wp/v2/users/me", nil)
+				if err != nil {
+					return err
+				}
+				io.Copy(io.Discard, resp.Body)
+				return resp.Body.Close()
+			}
+
+			for round := 0; round < tt.rounds; round++ {
+				var wg sync.WaitGroup
+				errs := make(chan error, tt.parallel)
+				for i := 0; i < tt.parallel; i++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						errs <- get()
+					}()
+				}
+				wg.Wait()
+				close(errs)
+				for err := range errs {
+					if err != nil {
+						t.Fatalf("request failed: %v", err)
+					}
+				}
+			}
+
+			if got := conns.Load(); got > tt.maxConns {
+				t.Errorf("%d requests opened %d connections, want at most %d", tt.rounds*tt.parallel, got, tt.maxConns)
+			}
+		})
+	}
+}
@@ -3,13 +3,20 @@ package services
 import (
 	"fmt"
 	"image"
-	"image/jpeg"
+	"image/color"
 	"image/png"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
 
+	"excentrico-tools-go/internal/config"
+	"excentrico-tools-go/internal/jpegenc"
+	"excentrico-tools-go/internal/utils"
+
 	"github.com/disintegration/imaging"
 )
 
@@ -17,8 +24,32 @@ type ImageService struct {
 	maxWidth  int
 	maxHeight int
 	quality   int
+	outputDir string // Subdirectory of the film folder for optimized images; empty writes next to originals
+	// subsampling is the JPEG chroma subsampling applied to optimized images
+	subsampling jpegenc.Subsampling
+	// maxPixels caps width*height read from the image header; 0 disables the check
+	maxPixels int64
+	// minWidth and minHeight flag smaller images as below minimum; 0 disables the check
+	minWidth  int
+	minHeight int
+	// skipSmallImages leaves images below the minimum unoptimized instead of only flagging them
+	skipSmallImages bool
+	// resizeMode is fit, fill or pad; see config.ImageConfig.ResizeMode
+	resizeMode string
+	padColor   color.NRGBA
+	// workers is how many images a film optimizes at once
+	workers int
+	// formats selects the format and file suffix of each optimized image
+	formats utils.OutputFormats
 }
 
+// Resize modes
+const (
+	ResizeModeFit  = "fit"
+	ResizeModeFill = "fill"
+	ResizeModePad  = "pad"
+)
+
 func NewImageServiceWithConfig(maxWidth, maxHeight, quality int) *ImageService {
 	return &ImageService{
 		maxWidth:  maxWidth,
@@ -27,44 +58,234 @@ func NewImageServiceWithConfig(maxWidth, maxHeight, quality int) *ImageService {
 	}
 }
 
-func (s *ImageService) ResizeImage(inputPath, outputPath string) error {
+// NewImageService creates an image service from the image configuration
+func NewImageService(cfg config.ImageConfig) *ImageService {
+	return &ImageService{
+		maxWidth:        cfg.MaxWidth,
+		maxHeight:       cfg.MaxHeight,
+		quality:         cfg.Quality,
+		outputDir:       cfg.OutputDir,
+		subsampling:     chromaSubsampling(cfg.ChromaSubsampling),
+		maxPixels:       int64(cfg.MaxMegapixels * 1000000),
+		minWidth:        cfg.MinWidth,
+		minHeight:       cfg.MinHeight,
+		skipSmallImages: cfg.SkipSmallImages,
+		resizeMode:      cfg.ResizeMode,
+		padColor:        parseHexColor(cfg.PadColor),
+		workers:         cfg.OptimizeWorkers,
+		formats:         utils.OutputFormats{Format: cfg.Format, Rules: cfg.FormatRules},
+	}
+}
+
+// chromaSubsampling maps the configured subsampling name to the encoder setting,
+// falling back to 4:2:0 for anything unrecognized
+func chromaSubsampling(name string) jpegenc.Subsampling {
+	if name == "4:4:4" {
+		return jpegenc.Subsampling444
+	}
+	return jpegenc.Subsampling420
+}
+
+// parseHexColor parses a #rrggbb color, falling back to opaque black
+func parseHexColor(hex string) color.NRGBA {
+	value, err := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	if err != nil || len(hex) != 7 {
+		return color.NRGBA{A: 255}
+	}
+	return color.NRGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 255}
+}
+
+// OptimizedDir returns the directory that holds the optimized images of a film
+func (s *ImageService) OptimizedDir(filmDir string) string {
+	if s.outputDir == "" {
+		return filmDir
+	}
+	return filepath.Join(filmDir, s.outputDir)
+}
+
+// Formats returns the formats optimized images are written in
+func (s *ImageService) Formats() utils.OutputFormats {
+	return s.formats
+}
+
+// CheckEncoders returns an error when optimized images are configured to be written as
+// WebP but no WebP encoder is available, so a run fails before downloading anything
+func (s *ImageService) CheckEncoders() error {
+	usesWebP := s.formats.Format == "webp"
+	for _, format := range s.formats.Rules {
+		usesWebP = usesWebP || format == "webp"
+	}
+	if !usesWebP {
+		return nil
+	}
+	return webpEncoderAvailable()
+}
+
+// OptimizedPath returns where the optimized version of an original inside filmDir is written,
+// mirroring the original's folder structure when a dedicated output directory is configured
+func (s *ImageService) OptimizedPath(filmDir, originalPath string) string {
+	if s.outputDir == "" {
+		return s.formats.OptimizedImagePath(originalPath)
+	}
+
+	relPath, err := filepath.Rel(filmDir, originalPath)
+	if err != nil || strings.HasPrefix(relPath, "..") {
+		return s.formats.OptimizedImagePath(originalPath)
+	}
+	return filepath.Join(s.OptimizedDir(filmDir), s.formats.OptimizedImagePath(relPath))
+}
+
+// Workers returns how many images are optimized at once, defaulting to the CPU count
+func (s *ImageService) Workers() int {
+	if s.workers <= 0 {
+		return runtime.NumCPU()
+	}
+	return s.workers
+}
+
+// SkipsSmallImages reports whether images below the minimum dimensions are left unoptimized
+func (s *ImageService) SkipsSmallImages() bool {
+	return s.skipSmallImages
+}
+
+// checkDimensions reads only the image header, once, and rejects images whose declared
+// dimensions exceed the configured pixel limit before they are fully decoded. It reports
+// whether the image is below the configured minimum dimensions
+func (s *ImageService) checkDimensions(inputPath string) (bool, error) {
+	if s.maxPixels <= 0 && s.minWidth <= 0 && s.minHeight <= 0 {
+		return false, nil
+	}
 
-	src, err := imaging.Open(inputPath)
+	file, err := os.Open(inputPath)
 	if err != nil {
-		return fmt.Errorf("failed to open image: %v", err)
+		return false, fmt.Errorf("failed to open image: %v", err)
+	}
+	defer file.Close()
+
+	cfg, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return false, fmt.Errorf("failed to read image header: %v", err)
+	}
+
+	pixels := int64(cfg.Width) * int64(cfg.Height)
+	if s.maxPixels > 0 && pixels > s.maxPixels {
+		return false, fmt.Errorf("image dimensions %dx%d (%.1f MP) exceed the limit of %.1f MP",
+			cfg.Width, cfg.Height, float64(pixels)/1000000, float64(s.maxPixels)/1000000)
+	}
+	return cfg.Width < s.minWidth || cfg.Height < s.minHeight, nil
+}
+
+// ImageResult describes an optimized image. Sizes are in bytes; Width and Height are the
+// dimensions of the optimized image
+type ImageResult struct {
+	OriginalSize  int64
+	OptimizedSize int64
+	Width         int64
+	Height        int64
+	// BelowMinimum is set when the original is smaller than the minimum dimensions; such an
+	// image is not written, and only OriginalSize is known, when small images are skipped
+	BelowMinimum bool
+}
+
+// ResizeImage writes an optimized copy of inputPath to outputPath and returns its sizes
+// and dimensions, including whether the original is below the minimum dimensions. Such
+// images are not written when small images are skipped
+func (s *ImageService) ResizeImage(inputPath, outputPath string) (*ImageResult, error) {
+
+	belowMinimum, err := s.checkDimensions(inputPath)
+	if err != nil {
+		log.Printf("Rejected image %s: %v", inputPath, err)
+		return nil, err
+	}
+
+	file, err := os.Open(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image: %v", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image: %v", err)
+	}
+	result := &ImageResult{OriginalSize: info.Size(), BelowMinimum: belowMinimum}
+	if belowMinimum && s.skipSmallImages {
+		log.Printf("Skipped image %s: below the minimum dimensions of %dx%d", inputPath, s.minWidth, s.minHeight)
+		return result, nil
+	}
+
+	src, err := imaging.Decode(file)
+	if err != nil {
+		return result, fmt.Errorf("failed to open image: %v", err)
 	}
 
-	resized := imaging.Fit(src, s.maxWidth, s.maxHeight, imaging.Lanczos)
+	resized := s.resize(src)
 
-	err = s.saveImage(resized, outputPath)
+	written, err := s.saveImage(resized, outputPath)
 	if err != nil {
-		return fmt.Errorf("failed to save resized image: %v", err)
+		return result, fmt.Errorf("failed to save resized image: %v", err)
 	}
+	bounds := resized.Bounds()
+	result.OptimizedSize = written
+	result.Width = int64(bounds.Dx())
+	result.Height = int64(bounds.Dy())
 
 	log.Printf("Resized image: %s -> %s", inputPath, outputPath)
-	return nil
+	return result, nil
 }
 
-func (s *ImageService) saveImage(img image.Image, outputPath string) error {
+// resize scales an image to the maximum dimensions according to the resize mode
+func (s *ImageService) resize(src image.Image) image.Image {
+	switch s.resizeMode {
+	case ResizeModeFill:
+		return imaging.Fill(src, s.maxWidth, s.maxHeight, imaging.Center, imaging.Lanczos)
+	case ResizeModePad:
+		fitted := imaging.Fit(src, s.maxWidth, s.maxHeight, imaging.Lanczos)
+		canvas := imaging.New(s.maxWidth, s.maxHeight, s.padColor)
+		return imaging.PasteCenter(canvas, fitted)
+	default:
+		return imaging.Fit(src, s.maxWidth, s.maxHeight, imaging.Lanczos)
+	}
+}
+
+// saveImage encodes img to outputPath in the format of its extension and returns the
+// number of bytes written
+func (s *ImageService) saveImage(img image.Image, outputPath string) (int64, error) {
 
 	dir := filepath.Dir(outputPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %v", err)
+		return 0, fmt.Errorf("failed to create directory: %v", err)
 	}
 
 	file, err := os.Create(outputPath)
 	if err != nil {
-		return fmt.Errorf("failed to create output file: %v", err)
+		return 0, fmt.Errorf("failed to create output file: %v", err)
 	}
 	defer file.Close()
 
+	out := &countingWriter{w: file}
 	ext := strings.ToLower(filepath.Ext(outputPath))
 	switch ext {
 	case ".jpg", ".jpeg":
-		return jpeg.Encode(file, img, &jpeg.Options{Quality: s.quality})
+		err = jpegenc.Encode(out, img, &jpegenc.Options{Quality: s.quality, Subsampling: s.subsampling})
 	case ".png":
-		return png.Encode(file, img)
+		err = png.Encode(out, img)
+	case ".webp":
+		err = encodeWebP(out, img, s.quality)
 	default:
-		return fmt.Errorf("unsupported image format: %s", ext)
+		err = fmt.Errorf("unsupported image format: %s", ext)
 	}
+	return out.n, err
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
 }
@@ -0,0 +1,437 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"excentrico-tools-go/internal/config"
+	"excentrico-tools-go/internal/jpegenc"
+)
+
+// writeTestPNG writes a colorful w x h PNG into dir and returns its path
+func writeTestPNG(t *testing.T, dir, name string, w, h int) string {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: uint8(x + y), A: 255})
+		}
+	}
+	path := filepath.Join(dir, name)
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := png.Encode(file, img); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestChromaSubsampling(t *testing.T) {
+	tests := []struct {
+		name string
+		want jpegenc.Subsampling
+	}{
+		{"4:4:4", jpegenc.Subsampling444},
+		{"4:2:0", jpegenc.Subsampling420},
+		{"", jpegenc.Subsampling420},
+		{"4:2:2", jpegenc.Subsampling420},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chromaSubsampling(tt.name); got != tt.want {
+				t.Errorf("chromaSubsampling(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResizeImageChromaSubsampling(t *testing.T) {
+	tests := []struct {
+		setting string
+		want    image.YCbCrSubsampleRatio
+	}{
+		{"4:4:4", image.YCbCrSubsampleRatio444},
+		{"4:2:0", image.YCbCrSubsampleRatio420},
+	}
+	for _, tt := range tests {
+		t.Run(tt.setting, func(t *testing.T) {
+			dir := t.TempDir()
+			input := writeTestPNG(t, dir, "still.png", 64, 48)
+			output := filepath.Join(dir, "still_optimized.jpg")
+
+			service := NewImageService(config.ImageConfig{MaxWidth: 1920, MaxHeight: 1080, Quality: 85, ChromaSubsampling: tt.setting})
+			if _, err := service.ResizeImage(input, output); err != nil {
+				t.Fatalf("ResizeImage: %v", err)
+			}
+
+			file, err := os.Open(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			decoded, err := jpeg.Decode(file)
+			if err != nil {
+				t.Fatalf("decoding the optimized image: %v", err)
+			}
+			ycbcr, ok := decoded.(*image.YCbCr)
+			if !ok {
+				t.Fatalf("decoded %T, want *image.YCbCr", decoded)
+			}
+			if ycbcr.SubsampleRatio != tt.want {
+				t.Errorf("SubsampleRatio %v, want %v", ycbcr.SubsampleRatio, tt.want)
+			}
+		})
+	}
+}
+
+// writeHeaderOnlyPNG writes a PNG that declares w x h pixels but holds no image data,
+// so only its header can be read, and returns its path
+func writeHeaderOnlyPNG(t *testing.T, dir, name string, w, h uint32) string {
+	t.Helper()
+	chunk := func(kind string, data []byte) []byte {
+		out := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+		out = append(out, kind...)
+		out = append(out, data...)
+		return binary.BigEndian.AppendUint32(out, crc32.ChecksumIEEE(append([]byte(kind), data...)))
+	}
+	ihdr := binary.BigEndian.AppendUint32(nil, w)
+	ihdr = binary.BigEndian.AppendUint32(ihdr, h)
+	ihdr = append(ihdr, 8, 6, 0, 0, 0) // 8-bit RGBA, no interlacing
+
+	data := []byte("\x89PNG\r\n\x1a\n")
+	data = append(data, chunk("IHDR", ihdr)...)
+	data = append(data, chunk("IEND", nil)...)
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestResizeImageModes(t *testing.T) {
+	tests := []struct {
+		name                  string
+		mode                  string
+		width, height         int
+		wantWidth, wantHeight int
+		wantPadding           bool // top-left pixel is the pad color
+	}{
+		{name: "default fits", width: 64, height: 48, wantWidth: 32, wantHeight: 24},
+		{name: "fit landscape", mode: ResizeModeFit, width: 64, height: 48, wantWidth: 32, wantHeight: 24},
+		{name: "fit portrait", mode: ResizeModeFit, width: 30, height: 60, wantWidth: 16, wantHeight: 32},
+		{name: "fit never upscales", mode: ResizeModeFit, width: 16, height: 16, wantWidth: 16, wantHeight: 16},
+		{name: "fill crops landscape", mode: ResizeModeFill, width: 64, height: 48, wantWidth: 32, wantHeight: 32},
+		{name: "fill upscales small images", mode: ResizeModeFill, width: 16, height: 16, wantWidth: 32, wantHeight: 32},
+		{name: "pad letterboxes landscape", mode: ResizeModePad, width: 64, height: 48, wantWidth: 32, wantHeight: 32, wantPadding: true},
+		{name: "pad pillarboxes portrait", mode: ResizeModePad, width: 30, height: 60, wantWidth: 32, wantHeight: 32, wantPadding: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			input := writeTestPNG(t, dir, "still.png", tt.width, tt.height)
+			output := filepath.Join(dir, "still_optimized.jpg")
+
+			service := NewImageService(config.ImageConfig{MaxWidth: 32, MaxHeight: 32, Quality: 95, ResizeMode: tt.mode, PadColor: "#ffffff"})
+			result, err := service.ResizeImage(input, output)
+			if err != nil {
+				t.Fatalf("ResizeImage: %v", err)
+			}
+			if result.Width != int64(tt.wantWidth) || result.Height != int64(tt.wantHeight) {
+				t.Errorf("result is %dx%d, want %dx%d", result.Width, result.Height, tt.wantWidth, tt.wantHeight)
+			}
+
+			file, err := os.Open(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			img, err := jpeg.Decode(file)
+			if err != nil {
+				t.Fatalf("decoding output: %v", err)
+			}
+			if got := img.Bounds().Size(); got.X != tt.wantWidth || got.Y != tt.wantHeight {
+				t.Errorf("output is %dx%d, want %dx%d", got.X, got.Y, tt.wantWidth, tt.wantHeight)
+			}
+			r, g, b, _ := img.At(0, 0).RGBA()
+			if padded := r>>8 > 240 && g>>8 > 240 && b>>8 > 240; padded != tt.wantPadding {
+				t.Errorf("top-left pixel is (%d, %d, %d), want padding %v", r>>8, g>>8, b>>8, tt.wantPadding)
+			}
+		})
+	}
+}
+
+func TestParseHexColor(t *testing.T) {
+	tests := []struct {
+		hex  string
+		want color.NRGBA
+	}{
+		{"#ffffff", color.NRGBA{R: 255, G: 255, B: 255, A: 255}},
+		{"#1a2B3c", color.NRGBA{R: 0x1a, G: 0x2b, B: 0x3c, A: 255}},
+		{"", color.NRGBA{A: 255}},
+		{"ffffff", color.NRGBA{A: 255}},
+		{"#fff", color.NRGBA{A: 255}},
+		{"#gggggg", color.NRGBA{A: 255}},
+	}
+	for _, tt := range tests {
+		if got := parseHexColor(tt.hex); got != tt.want {
+			t.Errorf("parseHexColor(%q) = %v, want %v", tt.hex, got, tt.want)
+		}
+	}
+}
+
+func TestResizeImageMinimumDimensions(t *testing.T) {
+	tests := []struct {
+		name             string
+		minWidth         int
+		minHeight        int
+		skip             bool
+		width, height    int
+		wantBelowMinimum bool
+		wantWritten      bool
+	}{
+		{name: "above the minimum", minWidth: 32, minHeight: 32, width: 64, height: 48, wantWritten: true},
+		{name: "exactly at the minimum", minWidth: 64, minHeight: 48, width: 64, height: 48, wantWritten: true},
+		{name: "too narrow", minWidth: 100, width: 64, height: 48, wantBelowMinimum: true, wantWritten: true},
+		{name: "too short", minHeight: 100, width: 64, height: 48, wantBelowMinimum: true, wantWritten: true},
+		{name: "check disabled", width: 16, height: 16, wantWritten: true},
+		{name: "small image skipped", minWidth: 32, minHeight: 32, skip: true, width: 16, height: 16, wantBelowMinimum: true},
+		{name: "large image not skipped", minWidth: 32, minHeight: 32, skip: true, width: 64, height: 48, wantWritten: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			input := writeTestPNG(t, dir, "still.png", tt.width, tt.height)
+			output := filepath.Join(dir, "still_optimized.jpg")
+
+			service := NewImageService(config.ImageConfig{
+				MaxWidth:        1920,
+				MaxHeight:       1080,
+				Quality:         85,
+				MinWidth:        tt.minWidth,
+				MinHeight:       tt.minHeight,
+				SkipSmallImages: tt.skip,
+			})
+			result, err := service.ResizeImage(input, output)
+			if err != nil {
+				t.Fatalf("ResizeImage: %v", err)
+			}
+			if result.BelowMinimum != tt.wantBelowMinimum {
+				t.Errorf("BelowMinimum = %v, want %v", result.BelowMinimum, tt.wantBelowMinimum)
+			}
+			if _, err := os.Stat(output); (err == nil) != tt.wantWritten {
+				t.Errorf("optimized image written = %v, want %v", err == nil, tt.wantWritten)
+			}
+		})
+	}
+}
+
+func TestResizeImageMaxMegapixels(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxMegapixels float64
+		write         func(t *testing.T, dir string) string
+		wantErr       string // substring of the error; empty when the image is optimized
+	}{
+		{
+			name:          "header-only oversized image",
+			maxMegapixels: 100,
+			write:         func(t *testing.T, dir string) string { return writeHeaderOnlyPNG(t, dir, "bomb.png", 100000, 100000) },
+			wantErr:       "exceed the limit of 100.0 MP",
+		},
+		{
+			name:          "just over the limit",
+			maxMegapixels: 0.003,
+			write:         func(t *testing.T, dir string) string { return writeTestPNG(t, dir, "still.png", 64, 48) },
+			wantErr:       "exceed the limit",
+		},
+		{
+			name:          "normal image",
+			maxMegapixels: 100,
+			write:         func(t *testing.T, dir string) string { return writeTestPNG(t, dir, "still.png", 64, 48) },
+		},
+		{
+			name:          "exactly at the limit",
+			maxMegapixels: 0.003072,
+			write:         func(t *testing.T, dir string) string { return writeTestPNG(t, dir, "still.png", 64, 48) },
+		},
+		{
+			name:  "limit disabled",
+			write: func(t *testing.T, dir string) string { return writeTestPNG(t, dir, "still.png", 64, 48) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			input := tt.write(t, dir)
+			output := filepath.Join(dir, "still_optimized.jpg")
+
+			service := NewImageService(config.ImageConfig{MaxWidth: 1920, MaxHeight: 1080, Quality: 85, MaxMegapixels: tt.maxMegapixels})
+			result, err := service.ResizeImage(input, output)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ResizeImage() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				if _, err := os.Stat(output); !os.IsNotExist(err) {
+					t.Errorf("rejected image was written: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResizeImage: %v", err)
+			}
+			if result.Width != 64 || result.Height != 48 {
+				t.Errorf("optimized to %dx%d, want 64x48", result.Width, result.Height)
+			}
+		})
+	}
+}
+
+func TestResizeImageFormatRules(t *testing.T) {
+	// Leading bytes of each encoder's output
+	signatures := map[string]func([]byte) bool{
+		"jpeg": func(b []byte) bool { return bytes.HasPrefix(b, []byte{0xff, 0xd8}) },
+		"png":  func(b []byte) bool { return bytes.HasPrefix(b, []byte("\x89PNG")) },
+		"webp": func(b []byte) bool { return len(b) > 12 && string(b[:4]) == "RIFF" && string(b[8:12]) == "WEBP" },
+	}
+	tests := []struct {
+		name       string
+		rules      map[string]string
+		input      string
+		wantOutput string
+		wantFormat string
+	}{
+		{name: "PNG without rules", input: "logo.png", wantOutput: "logo_web.jpg", wantFormat: "jpeg"},
+		{name: "JPEG without rules", input: "still.jpg", wantOutput: "still_web.jpg", wantFormat: "jpeg"},
+		{name: "PNG stays PNG", rules: map[string]string{".png": "png"}, input: "logo.png", wantOutput: "logo_web.png", wantFormat: "png"},
+		{name: "PNG rule leaves JPEG", rules: map[string]string{".png": "png"}, input: "still.jpg", wantOutput: "still_web.jpg", wantFormat: "jpeg"},
+		{name: "JPEG to WebP", rules: map[string]string{".jpg": "webp", ".jpeg": "webp"}, input: "still.jpg", wantOutput: "still_web.webp", wantFormat: "webp"},
+		{name: "JPEG rule leaves PNG", rules: map[string]string{".jpg": "webp"}, input: "logo.png", wantOutput: "logo_web.jpg", wantFormat: "jpeg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := webpEncoderAvailable(); tt.wantFormat == "webp" && err != nil {
+				t.Skip(err)
+			}
+			dir := t.TempDir()
+			input := writeTestImage(t, filepath.Join(dir, tt.input), 48, 32)
+
+			service := NewImageService(config.ImageConfig{MaxWidth: 32, MaxHeight: 32, Quality: 85, FormatRules: tt.rules})
+			output := service.OptimizedPath(dir, input)
+			if want := filepath.Join(dir, tt.wantOutput); output != want {
+				t.Errorf("OptimizedPath(%q) = %q, want %q", tt.input, output, want)
+			}
+			if _, err := service.ResizeImage(input, output); err != nil {
+				t.Fatalf("ResizeImage: %v", err)
+			}
+			data, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !signatures[tt.wantFormat](data) {
+				t.Errorf("%s is not encoded as %s", filepath.Base(output), tt.wantFormat)
+			}
+		})
+	}
+}
+
+func TestResizeImageResult(t *testing.T) {
+	tests := []struct {
+		name                  string
+		input                 string
+		width, height         int
+		skipSmall             bool
+		wantWidth, wantHeight int64
+		wantWritten           bool
+	}{
+		{name: "JPEG downscaled", input: "still.jpg", width: 96, height: 64, wantWidth: 48, wantHeight: 32, wantWritten: true},
+		{name: "PNG downscaled", input: "logo.png", width: 64, height: 96, wantWidth: 32, wantHeight: 48, wantWritten: true},
+		{name: "within bounds", input: "still.jpg", width: 40, height: 30, wantWidth: 40, wantHeight: 30, wantWritten: true},
+		{name: "small image skipped", input: "thumb.jpg", width: 8, height: 8, skipSmall: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			input := writeTestImage(t, filepath.Join(dir, tt.input), tt.width, tt.height)
+			output := filepath.Join(dir, "optimized.jpg")
+			original, err := os.Stat(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			service := NewImageService(config.ImageConfig{MaxWidth: 48, MaxHeight: 48, Quality: 85, MinWidth: 16, MinHeight: 16, SkipSmallImages: tt.skipSmall})
+			result, err := service.ResizeImage(input, output)
+			if err != nil {
+				t.Fatalf("ResizeImage: %v", err)
+			}
+			if result.OriginalSize != original.Size() {
+				t.Errorf("OriginalSize = %d, want %d", result.OriginalSize, original.Size())
+			}
+			if result.Width != tt.wantWidth || result.Height != tt.wantHeight {
+				t.Errorf("result is %dx%d, want %dx%d", result.Width, result.Height, tt.wantWidth, tt.wantHeight)
+			}
+
+			optimized, err := os.Stat(output)
+			if (err == nil) != tt.wantWritten {
+				t.Fatalf("optimized image written = %v, want %v", err == nil, tt.wantWritten)
+			}
+			if !tt.wantWritten {
+				if result.OptimizedSize != 0 {
+					t.Errorf("OptimizedSize = %d for a skipped image, want 0", result.OptimizedSize)
+				}
+				return
+			}
+			if result.OptimizedSize != optimized.Size() {
+				t.Errorf("OptimizedSize = %d, want %d", result.OptimizedSize, optimized.Size())
+			}
+			file, err := os.Open(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			cfg, err := jpeg.DecodeConfig(file)
+			if err != nil {
+				t.Fatalf("decoding output: %v", err)
+			}
+			if int64(cfg.Width) != result.Width || int64(cfg.Height) != result.Height {
+				t.Errorf("output is %dx%d, result reports %dx%d", cfg.Width, cfg.Height, result.Width, result.Height)
+			}
+		})
+	}
+}
+
+// writeTestImage writes a w x h image to path, as JPEG or PNG after its extension
+func writeTestImage(t *testing.T, path string, w, h int) string {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: uint8(x + y), A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	var err error
+	if filepath.Ext(path) == ".png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Site languages selectable with -lang
+const (
+	LanguageSpanish = "es"
+	LanguageEnglish = "en"
+)
+
+// ParseLanguage validates a language code; empty selects Spanish
+func ParseLanguage(name string) (string, error) {
+	switch lang := strings.ToLower(strings.TrimSpace(name)); lang {
+	case "":
+		return LanguageSpanish, nil
+	case LanguageSpanish, LanguageEnglish:
+		return lang, nil
+	default:
+		return "", fmt.Errorf("language must be one of: %s, %s", LanguageSpanish, LanguageEnglish)
+	}
+}
+
+// Labels holds the fixed headings and credit labels rendered in a film's template
+type Labels struct {
+	Direction      string
+	Production     string
+	Script         string
+	Photography    string
+	ArtDesign      string
+	SoundMusic     string
+	Editing        string
+	Cast           string
+	TechnicalSheet string
+	Synopsis       string
+	DirectorHeader string
+	ContentNotes   string
+	FooterButton   string // Default convocatoria button label; "{year}" is replaced with the edition year
+}
+
+// SpanishLabels are the labels of the default Spanish site
+var SpanishLabels = Labels{
+	Direction:      "Dirección",
+	Production:     "Producción",
+	Script:         "Guión",
+	Photography:    "Cámara - Foto",
+	ArtDesign:      "Arte - Diseño",
+	SoundMusic:     "Sonido - Música",
+	Editing:        "Edición",
+	Cast:           "Intérpretes (especificar pronombres para subtítulos)",
+	TechnicalSheet: "FICHA TÉCNICA",
+	Synopsis:       "SINOPSIS",
+	DirectorHeader: "DIRECCIÓN",
+	ContentNotes:   "NdC",
+	FooterButton:   DefaultFooterButtonLabel,
+}
+
+// EnglishLabels are the labels of the English site variant
+var EnglishLabels = Labels{
+	Direction:      "Director",
+	Production:     "Producer",
+	Script:         "Screenplay",
+	Photography:    "Cinematography",
+	ArtDesign:      "Art Design",
+	SoundMusic:     "Sound - Music",
+	Editing:        "Editing",
+	Cast:           "Cast",
+	TechnicalSheet: "CREDITS",
+	Synopsis:       "SYNOPSIS",
+	DirectorHeader: "DIRECTOR",
+	ContentNotes:   "Content notes",
+	FooterButton:   "call for entries {year}",
+}
+
+// labelsByLanguage maps every site language to its labels
+var labelsByLanguage = map[string]Labels{
+	LanguageSpanish: SpanishLabels,
+	LanguageEnglish: EnglishLabels,
+}
+
+// LabelsFor returns the labels of a language, defaulting to Spanish
+func LabelsFor(lang string) Labels {
+	if labels, ok := labelsByLanguage[lang]; ok {
+		return labels
+	}
+	return SpanishLabels
+}
+
+// resolveLabels returns labels, or the Spanish labels when a component was built without any
+func resolveLabels(labels Labels) Labels {
+	if labels == (Labels{}) {
+		return SpanishLabels
+	}
+	return labels
+}
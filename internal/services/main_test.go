@@ -0,0 +1,41 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+
+	"excentrico-tools-go/internal/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitWithWriter("excentrico-tools-go-test", io.Discard)
+	os.Exit(m.Run())
+}
+
+// captureEvents routes the logger into a buffer for the rest of the test and
+// returns a function decoding the events written so far
+func captureEvents(t *testing.T) func() []logger.WideEvent {
+	t.Helper()
+	var buf bytes.Buffer
+	logger.InitWithWriter("excentrico-tools-go-test", &buf)
+	logger.Get().SetSampleRate(1)
+	t.Cleanup(func() { logger.InitWithWriter("excentrico-tools-go-test", io.Discard) })
+
+	return func() []logger.WideEvent {
+		var events []logger.WideEvent
+		scanner := bufio.NewScanner(bytes.NewReader(buf.Bytes()))
+		scanner.Buffer(nil, 1<<20)
+		for scanner.Scan() {
+			var event logger.WideEvent
+			if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+				t.Fatalf("decoding event %s: %v", scanner.Text(), err)
+			}
+			events = append(events, event)
+		}
+		return events
+	}
+}
@@ -0,0 +1,70 @@
+package services
+
+// SheetField is a sheet column read into FilmData. SheetFields is the single list
+// ConvertObjToFilmData, FilmData.Validate and -help-fields work from
+type SheetField struct {
+	Header   string // Exact header of the column in the TODO sheet
+	Name     string // FilmData JSON name
+	Required bool   // Reported as missing by FilmData.Validate when empty
+	field    func(f *FilmData) *string
+}
+
+// Value returns the field's value in f
+func (s SheetField) Value(f *FilmData) string {
+	return *s.field(f)
+}
+
+// Set stores value in the field of f
+func (s SheetField) Set(f *FilmData, value string) {
+	*s.field(f) = value
+}
+
+// SheetFields lists every recognized sheet column in sheet order
+var SheetFields = []SheetField{
+	{Header: "TÍTULO ORIGINAL", Name: "titulo_original", Required: true, field: func(f *FilmData) *string { return &f.TituloOriginal }},
+	{Header: "DIRECCIÓN", Name: "direccion", Required: true, field: func(f *FilmData) *string { return &f.Direccion }},
+	{Header: "PAIS", Name: "pais", Required: true, field: func(f *FilmData) *string { return &f.Pais }},
+	{Header: "AÑO", Name: "ano", Required: true, field: func(f *FilmData) *string { return &f.Ano }},
+	{Header: "DURAC.", Name: "duracion", Required: true, field: func(f *FilmData) *string { return &f.Duracion }},
+	{Header: "EDICIÓN", Name: "edicion", Required: true, field: func(f *FilmData) *string { return &f.Edicion }},
+	{Header: "SECCIÓN", Name: "seccion", Required: true, field: func(f *FilmData) *string { return &f.Seccion }},
+	{Header: "TIPO", Name: "tipo", field: func(f *FilmData) *string { return &f.Tipo }},
+	{Header: "SOCIAL/ETIQUETAS", Name: "social_etiquetas", field: func(f *FilmData) *string { return &f.SocialEtiquetas }},
+	{Header: "Idioma(s) / Language(s)", Name: "idiomas", field: func(f *FilmData) *string { return &f.Idiomas }},
+	{Header: "Relación / Aspect Ratio (4:3, 16:9 u otro)", Name: "relacion_aspect_ratio", field: func(f *FilmData) *string { return &f.RelacionAspectRatio }},
+	{Header: "Sinopsis extendida (máximo 70 palabras)", Name: "sinopsis_extendida", Required: true, field: func(f *FilmData) *string { return &f.SinopsisExtendida }},
+	{Header: "Extended synopsis (english)", Name: "extended_synopsis", field: func(f *FilmData) *string { return &f.ExtendedSynopsis }},
+	{Header: "Sinopsis compacta  (máximo 10 palabras)", Name: "sinopsis_compacta", field: func(f *FilmData) *string { return &f.SinopsisCompacta }},
+	{Header: "Short Synopsis (log line - Uso Pink Label)", Name: "short_synopsis", field: func(f *FilmData) *string { return &f.ShortSynopsis }},
+	{Header: "Notas de contenido / Content notes (*)", Name: "notas_contenido", field: func(f *FilmData) *string { return &f.NotasContenido }},
+	{Header: "Nota de intención", Name: "nota_intencion", field: func(f *FilmData) *string { return &f.NotaIntencion }},
+	{Header: "Producción / Producer(s)", Name: "produccion", field: func(f *FilmData) *string { return &f.Produccion }},
+	{Header: "Guión", Name: "guion", field: func(f *FilmData) *string { return &f.Guion }},
+	{Header: "Cámara - Foto / Camera - Photography", Name: "camara_foto", field: func(f *FilmData) *string { return &f.CamaraFoto }},
+	{Header: "Arte - Diseño / Art/Design", Name: "arte_diseno", field: func(f *FilmData) *string { return &f.ArteDiseno }},
+	{Header: "Sonido - Música / Sound - Music", Name: "sonido_musica", field: func(f *FilmData) *string { return &f.SonidoMusica }},
+	{Header: "Edición / Editor(s)", Name: "edicion_credits", field: func(f *FilmData) *string { return &f.EdicionCredits }},
+	{Header: "Intérpretes (especificar pronombres para subtítulos)/ Cast (please specify pronouns for subtitles)", Name: "interpretes", field: func(f *FilmData) *string { return &f.Interpretes }},
+	{Header: "Otros créditos / Other credits", Name: "otros_creditos", field: func(f *FilmData) *string { return &f.OtrosCreditos }},
+	{Header: "Festivales y premios / Festivals & Awards", Name: "festivales_premios", field: func(f *FilmData) *string { return &f.FestivalesPremios }},
+	{Header: "Bio Realizadorxs / Filmaker's Bio (min 150 - max 1500 caracteres)", Name: "bio_realizadorxs", field: func(f *FilmData) *string { return &f.BioRealizadorxs }},
+	{Header: "Correo electrónico / Email", Name: "correo_electronico", field: func(f *FilmData) *string { return &f.CorreoElectronico }},
+	{Header: "Teléfono / Phone number", Name: "telefono", field: func(f *FilmData) *string { return &f.Telefono }},
+	{Header: "ENLACES", Name: "enlaces", field: func(f *FilmData) *string { return &f.Enlaces }},
+	{Header: "Web Excentrico", Name: "web_excentrico", field: func(f *FilmData) *string { return &f.WebExcentrico }},
+	{Header: "imágenes en baja", Name: "imagenes_baja", field: func(f *FilmData) *string { return &f.ImagenesBaja }},
+	{Header: "Obs. Subtitulos", Name: "obs_subtitulos", field: func(f *FilmData) *string { return &f.ObsSubtitulos }},
+	{Header: "Published Status", Name: "published_status", field: func(f *FilmData) *string { return &f.PublishedStatus }},
+	{Header: "Categoría", Name: "categoria", field: func(f *FilmData) *string { return &f.Categoria }},
+	{Header: "Multi Dir", Name: "multi_dir", field: func(f *FilmData) *string { return &f.MultiDir }},
+}
+
+// IsSheetField reports whether header is one of SheetFields
+func IsSheetField(header string) bool {
+	for _, field := range SheetFields {
+		if field.Header == header {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSheetFields(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, field := range SheetFields {
+		t.Run(field.Name, func(t *testing.T) {
+			if seen[field.Header] {
+				t.Errorf("header %q is listed twice", field.Header)
+			}
+			seen[field.Header] = true
+			if !IsSheetField(field.Header) {
+				t.Errorf("IsSheetField(%q) = false", field.Header)
+			}
+
+			// The field reads and writes the FilmData field with its JSON name
+			var f FilmData
+			field.Set(&f, "value of "+field.Name)
+			if got := field.Value(&f); got != "value of "+field.Name {
+				t.Errorf("Value() = %q after Set", got)
+			}
+			data, err := json.Marshal(&f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fields map[string]any
+			if err := json.Unmarshal(data, &fields); err != nil {
+				t.Fatal(err)
+			}
+			for name, value := range fields {
+				if want := name == field.Name; (value == "value of "+field.Name) != want {
+					t.Errorf("Set stored %q in %s", value, name)
+				}
+			}
+		})
+	}
+	if IsSheetField("Notas internas") {
+		t.Error(`IsSheetField("Notas internas") = true, want false`)
+	}
+}
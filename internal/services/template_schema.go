@@ -0,0 +1,172 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// DefaultTemplateData returns a TemplateData with every field populated using the
+// template's built-in colors, suitable as a starting point for templates/<year>.json
+func DefaultTemplateData() *TemplateData {
+	data := &TemplateData{
+		Header: Header{
+			TitleTextColor:        ColorSecondary,
+			SubHeadTextColor:      ColorSecondary,
+			BackgroundEnableColor: "off",
+		},
+		Menu: Menu{
+			ActiveLinkColor: ColorSecondary,
+			MenuTextColor:   ColorSecondary,
+			BackgroundColor: "RGBA(255,255,255,0)",
+			BackgroundImage: URLHeaderBackground,
+		},
+		Contenido: Section{
+			Background:                   ColorDark,
+			BackgroundColorGradientStops: ColorGradientStart + " 0%|" + ColorGradientEnd + " 100%",
+			BackgroundColorGradientStart: ColorGradientStart,
+			BackgroundColorGradientEnd:   ColorGradientEnd,
+		},
+		Texto: Text{
+			Header4TextColor: ColorDark,
+			BoxShadowColor:   ColorSecondary,
+		},
+		MaxDirectorsFull: DefaultMaxDirectorsFull,
+		SynopsisSource:   SynopsisSourceExtended,
+		DurationFormat:   DurationFormatAcute,
+		BuilderVersion:   DefaultBuilderVersion,
+	}
+
+	data.Ndc.Text.DisabledOn = "off|off|off"
+	data.Ndc.Text.Color = ColorDark
+	data.Ndc.Text.BackgroundColor = ColorPink
+	data.Ndc.Text.BoxShadowColor = ColorSecondary
+
+	data.Footer.Section.BackgroundImage = URLHeaderBackground
+	data.Footer.Section.BackgroundPosition = "top_center"
+	data.Footer.Button.BoxShadowColor = ColorSecondary
+	data.Footer.Button.ButtonIconColor = ColorPrimary
+	data.Footer.Button.ButtonBorderColor = ColorSecondary
+	data.Footer.Button.ButtonTextColor = ColorDark
+	data.Footer.Button.Label = DefaultFooterButtonLabel
+	data.Footer.Button.PostID = DefaultFooterButtonPostID
+	data.Footer.SocialNetworks = DefaultSocialNetworks()
+
+	return data
+}
+
+// TemplateDataFieldDocs describes every field of the per-year template file,
+// keyed by its dotted JSON path
+func TemplateDataFieldDocs() map[string]string {
+	return map[string]string{
+		"header.title_text_color":                   "Film title color in the fullwidth header",
+		"header.subhead_text_color":                 "Color of the country · year · duration subhead",
+		"header.background_enable_color":            "Whether the header uses a flat background color (on/off)",
+		"menu.enabled":                              "Whether the in-page menu is rendered below the header (default true); it is omitted while menu_id is empty",
+		"menu.menu_id":                              "WordPress navigation menu ID rendered below the header",
+		"menu.active_link_color":                    "Color of the active menu link",
+		"menu.menu_text_color":                      "Color of the menu links",
+		"menu.background_color":                     "Menu bar background color",
+		"menu.background_image":                     "Menu bar background image URL",
+		"contenido.background_color":                "Background color of the main content section",
+		"contenido.background_color_gradient_stops": "Divi gradient stops for the main content section",
+		"contenido.background_color_gradient_start": "Gradient start color of the main content section",
+		"contenido.background_color_gradient_end":   "Gradient end color of the main content section",
+		"texto.header_4_text_color":                 "Heading color of the credits, synopsis and director boxes",
+		"texto.box_shadow_color":                    "Box shadow color of the credits, synopsis and director boxes",
+		"ndc.text.disabled_on":                      "Devices on which the content notes box is hidden (phone|tablet|desktop)",
+		"ndc.text.color":                            "Content notes text color",
+		"ndc.text.background_color":                 "Content notes background color",
+		"ndc.text.box_shadow_color":                 "Content notes box shadow color",
+		"footer.section.background_image":           "Footer background image URL",
+		"footer.section.background_position":        "Footer background image position",
+		"footer.section.global_module":              "ID of the Divi global module used for the footer",
+		"footer.button.box_shadow_color":            "Box shadow color of the convocatoria button",
+		"footer.button.button_icon_color":           "Icon color of the convocatoria button",
+		"footer.button.button_border_color":         "Border color of the convocatoria button",
+		"footer.button.button_text_color":           "Text color of the convocatoria button",
+		"footer.button.label":                       "Convocatoria button text; {year} is replaced with the edition year",
+		"footer.button.url":                         "Fixed URL the convocatoria button links to; overrides post_id",
+		"footer.button.post_id":                     "WordPress page ID the convocatoria button links to when url is empty",
+		"footer.social_networks":                    "Footer follow icons in order, each {\"network\": \"tiktok\", \"url\": \"https://...\"}; unset uses facebook, instagram and twitter, [] shows none",
+		"max_directors_full":                        "Number of directors above which the director section becomes a compact names-only list",
+		"synopsis_source":                           "Synopsis shown in the main content: extended, compact or english (default extended; the English edition shows english for extended)",
+		"gallery_captions":                          "Set to filename to caption gallery stills from their file names; films/<film>/captions.json maps file names to custom captions",
+		"excerpt_source":                            "Synopsis used as the post excerpt: extended, compact or english; empty leaves the excerpt unset",
+		"duration_format":                           "Running time display: acute (90´00, default), minutes (90 min), hours (1h30) or prime (90')",
+		"max_gallery_images":                        "Maximum gallery stills per film, keeping the first by file name; 0 or unset is unlimited",
+		"builder_version":                           "Divi builder version written into every shortcode and preset (default 4.27.4)",
+		"language":                                  "Template language for this edition, es or en: selects the labels, button text and English synopsis; unset follows -lang",
+	}
+}
+
+// TemplateFieldChange is a field that differs between two template files, keyed by its
+// dotted JSON path as in TemplateDataFieldDocs. An empty From or To means unset
+type TemplateFieldChange struct {
+	Path string
+	From string
+	To   string
+}
+
+// DiffTemplateData lists the fields that differ between two template files, sorted by path
+func DiffTemplateData(from, to *TemplateData) ([]TemplateFieldChange, error) {
+	fromFields, err := flattenTemplateData(from)
+	if err != nil {
+		return nil, err
+	}
+	toFields, err := flattenTemplateData(to)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]bool)
+	for path := range fromFields {
+		paths[path] = true
+	}
+	for path := range toFields {
+		paths[path] = true
+	}
+
+	var changes []TemplateFieldChange
+	for path := range paths {
+		if fromFields[path] != toFields[path] {
+			changes = append(changes, TemplateFieldChange{Path: path, From: fromFields[path], To: toFields[path]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// flattenTemplateData maps the dotted JSON path of every set field to its JSON value
+func flattenTemplateData(data *TemplateData) (map[string]string, error) {
+	fields := make(map[string]string)
+	if data == nil {
+		return fields, nil
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal template data: %v", err)
+	}
+	var tree map[string]any
+	if err := json.Unmarshal(encoded, &tree); err != nil {
+		return nil, fmt.Errorf("failed to decode template data: %v", err)
+	}
+
+	var walk func(prefix string, value any)
+	walk = func(prefix string, value any) {
+		if object, ok := value.(map[string]any); ok {
+			for key, child := range object {
+				if prefix != "" {
+					key = prefix + "." + key
+				}
+				walk(key, child)
+			}
+			return
+		}
+		leaf, _ := json.Marshal(value)
+		fields[prefix] = string(leaf)
+	}
+	walk("", tree)
+	return fields, nil
+}
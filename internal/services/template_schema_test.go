@@ -0,0 +1,50 @@
+package services
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDefaultTemplateDataRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		marshal func(any) ([]byte, error)
+	}{
+		{"compact", json.Marshal},
+		{"indented as written by -dump-template-schema", func(v any) ([]byte, error) { return json.MarshalIndent(v, "", "  ") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			example := DefaultTemplateData()
+			data, err := tt.marshal(example)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+
+			var decoded TemplateData
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("json.Unmarshal: %v", err)
+			}
+			if !reflect.DeepEqual(&decoded, example) {
+				t.Errorf("decoded example differs from the original:\n got %+v\nwant %+v", decoded, *example)
+			}
+			if err := decoded.Validate(); err != nil {
+				t.Errorf("decoded example does not validate: %v", err)
+			}
+		})
+	}
+}
+
+func TestTemplateDataFieldDocsCoverExample(t *testing.T) {
+	fields, err := flattenTemplateData(DefaultTemplateData())
+	if err != nil {
+		t.Fatalf("flattenTemplateData: %v", err)
+	}
+	docs := TemplateDataFieldDocs()
+	for path := range fields {
+		if docs[path] == "" {
+			t.Errorf("example field %s is not documented", path)
+		}
+	}
+}
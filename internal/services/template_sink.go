@@ -0,0 +1,100 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Template output targets
+const (
+	// TemplateOutputWordPress creates or updates the WordPress post and saves divi_template.json
+	TemplateOutputWordPress = "wordpress"
+	// TemplateOutputFile only saves divi_template.json, leaving the WordPress post untouched
+	TemplateOutputFile = "file"
+	// TemplateOutputStdout only prints the generated shortcodes, for piping and debugging
+	TemplateOutputStdout = "stdout"
+)
+
+// ParseTemplateOutput validates a template output target name; empty selects WordPress
+func ParseTemplateOutput(name string) (string, error) {
+	switch output := strings.ToLower(strings.TrimSpace(name)); output {
+	case "":
+		return TemplateOutputWordPress, nil
+	case TemplateOutputWordPress, TemplateOutputFile, TemplateOutputStdout:
+		return output, nil
+	default:
+		return "", fmt.Errorf("template output must be one of: %s, %s, %s", TemplateOutputWordPress, TemplateOutputFile, TemplateOutputStdout)
+	}
+}
+
+// Template file JSON layouts
+const (
+	// TemplateJSONIndented writes divi_template.json indented for reading
+	TemplateJSONIndented = "indented"
+	// TemplateJSONMinified writes divi_template.json without whitespace, which matters
+	// when images are embedded as base64
+	TemplateJSONMinified = "minified"
+	// TemplateJSONBoth writes the indented divi_template.json and a minified divi_template.min.json
+	TemplateJSONBoth = "both"
+)
+
+// ParseTemplateJSON validates a template file JSON layout; empty selects indented
+func ParseTemplateJSON(name string) (string, error) {
+	switch layout := strings.ToLower(strings.TrimSpace(name)); layout {
+	case "":
+		return TemplateJSONIndented, nil
+	case TemplateJSONIndented, TemplateJSONMinified, TemplateJSONBoth:
+		return layout, nil
+	default:
+		return "", fmt.Errorf("template JSON must be one of: %s, %s, %s", TemplateJSONIndented, TemplateJSONMinified, TemplateJSONBoth)
+	}
+}
+
+// RenderedTemplate is the generated template of a film handed to a TemplateSink
+type RenderedTemplate struct {
+	FilmID     string
+	FilmDir    string
+	PostID     int // WordPress post the template belongs to; 0 when none exists yet
+	ImageIds   []int
+	Shortcodes string
+	// BuilderVersion is the Divi builder version of the presets; empty uses DefaultBuilderVersion
+	BuilderVersion string
+}
+
+// TemplateSink receives generated templates
+type TemplateSink interface {
+	WriteTemplate(rendered *RenderedTemplate) error
+}
+
+// StdoutTemplateSink writes the shortcodes of each template to Writer
+type StdoutTemplateSink struct {
+	Writer io.Writer
+}
+
+func (s *StdoutTemplateSink) WriteTemplate(rendered *RenderedTemplate) error {
+	if _, err := fmt.Fprintln(s.Writer, rendered.Shortcodes); err != nil {
+		return fmt.Errorf("failed to write template for '%s': %v", rendered.FilmID, err)
+	}
+	return nil
+}
+
+// FileTemplateSink saves each template as divi_template.json in the film directory
+type FileTemplateSink struct {
+	diviTemplateService *DiviTemplateService
+	wordpressService    *WordPressService
+}
+
+func (s *FileTemplateSink) WriteTemplate(rendered *RenderedTemplate) error {
+	return s.diviTemplateService.writeTemplateFile(rendered, s.wordpressService)
+}
+
+// TemplateSink returns the sink for the configured template output. The WordPress
+// target saves the file alongside the post update, so it shares the file sink
+func (s *DiviTemplateService) TemplateSink(wordpressService *WordPressService) TemplateSink {
+	if s.templateOutput == TemplateOutputStdout {
+		return &StdoutTemplateSink{Writer: os.Stdout}
+	}
+	return &FileTemplateSink{diviTemplateService: s, wordpressService: wordpressService}
+}
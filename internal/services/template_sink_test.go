@@ -0,0 +1,167 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseTemplateOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "", want: TemplateOutputWordPress},
+		{name: "wordpress", want: TemplateOutputWordPress},
+		{name: " File ", want: TemplateOutputFile},
+		{name: "STDOUT", want: TemplateOutputStdout},
+		{name: "post", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTemplateOutput(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTemplateOutput(%q) error = %v, want error: %v", tt.name, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTemplateOutput(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateSinks(t *testing.T) {
+	const shortcodes = `[et_pb_section][et_pb_text]La película[/et_pb_text][/et_pb_section]`
+	wp, _ := newMediaServer(t, map[int]*WordPressMedia{})
+
+	tests := []struct {
+		output   string
+		wantFile bool
+		wantOut  string
+	}{
+		{output: TemplateOutputWordPress, wantFile: true},
+		{output: TemplateOutputFile, wantFile: true},
+		{output: TemplateOutputStdout, wantOut: shortcodes + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			s := NewDiviTemplateService()
+			s.SetTemplateOutput(tt.output)
+			sink := s.TemplateSink(wp)
+			var out bytes.Buffer
+			if stdout, ok := sink.(*StdoutTemplateSink); ok {
+				stdout.Writer = &out
+			}
+
+			filmDir := t.TempDir()
+			if err := sink.WriteTemplate(&RenderedTemplate{FilmID: "film-1", FilmDir: filmDir, PostID: 12, Shortcodes: shortcodes}); err != nil {
+				t.Fatalf("WriteTemplate: %v", err)
+			}
+
+			if out.String() != tt.wantOut {
+				t.Errorf("printed %q, want %q", out.String(), tt.wantOut)
+			}
+			data, err := os.ReadFile(filepath.Join(filmDir, "divi_template.json"))
+			if !tt.wantFile {
+				if !os.IsNotExist(err) {
+					t.Errorf("divi_template.json written for the %s output", tt.output)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("reading divi_template.json: %v", err)
+			}
+			var file DiviTemplateFile
+			if err := json.Unmarshal(data, &file); err != nil {
+				t.Fatalf("decoding divi_template.json: %v", err)
+			}
+			if got := file.Data["12"]; got != shortcodes {
+				t.Errorf("template data for post 12 = %q, want %q", got, shortcodes)
+			}
+		})
+	}
+}
+
+func TestParseTemplateJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "", want: TemplateJSONIndented},
+		{name: "indented", want: TemplateJSONIndented},
+		{name: " Minified ", want: TemplateJSONMinified},
+		{name: "BOTH", want: TemplateJSONBoth},
+		{name: "compact", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTemplateJSON(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTemplateJSON(%q) error = %v, want error: %v", tt.name, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTemplateJSON(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateJSONLayouts(t *testing.T) {
+	const shortcodes = `[et_pb_section][et_pb_text]<h4>La película & "el faro"</h4>[/et_pb_text][/et_pb_section]`
+	wp, _ := newMediaServer(t, map[int]*WordPressMedia{})
+
+	tests := []struct {
+		layout    string
+		wantFiles map[string]bool // file name -> whether it is indented
+	}{
+		{layout: "", wantFiles: map[string]bool{"divi_template.json": true}},
+		{layout: TemplateJSONIndented, wantFiles: map[string]bool{"divi_template.json": true}},
+		{layout: TemplateJSONMinified, wantFiles: map[string]bool{"divi_template.json": false}},
+		{layout: TemplateJSONBoth, wantFiles: map[string]bool{"divi_template.json": true, "divi_template.min.json": false}},
+	}
+	var reference any // The default indented file, decoded
+	for _, tt := range tests {
+		t.Run(tt.layout, func(t *testing.T) {
+			s := NewDiviTemplateService()
+			s.SetTemplateOutput(TemplateOutputFile)
+			s.SetTemplateJSON(tt.layout)
+			filmDir := t.TempDir()
+			if err := s.TemplateSink(wp).WriteTemplate(&RenderedTemplate{FilmID: "film-1", FilmDir: filmDir, PostID: 12, Shortcodes: shortcodes}); err != nil {
+				t.Fatalf("WriteTemplate: %v", err)
+			}
+
+			entries, err := os.ReadDir(filmDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != len(tt.wantFiles) {
+				t.Errorf("%d files written, want %d", len(entries), len(tt.wantFiles))
+			}
+			for name, indented := range tt.wantFiles {
+				data, err := os.ReadFile(filepath.Join(filmDir, name))
+				if err != nil {
+					t.Fatalf("reading %s: %v", name, err)
+				}
+				if got := bytes.Contains(data, []byte("\n  ")); got != indented {
+					t.Errorf("%s indented: %v, want %v", name, got, indented)
+				}
+
+				var decoded any
+				if err := json.Unmarshal(data, &decoded); err != nil {
+					t.Fatalf("decoding %s: %v", name, err)
+				}
+				if reference == nil {
+					reference = decoded
+				}
+				if !reflect.DeepEqual(decoded, reference) {
+					t.Errorf("%s decodes to %v, want the indented template %v", name, decoded, reference)
+				}
+			}
+		})
+	}
+}
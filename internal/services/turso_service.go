@@ -1,41 +1,127 @@
 package services
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
 
 	"excentrico-tools-go/internal/config"
+	"excentrico-tools-go/internal/logger"
 
 	_ "github.com/tursodatabase/libsql-client-go/libsql"
 )
 
 type TursoService struct {
+	*tursoState
+	ctx context.Context // Carries the request ID of the film being processed in views made by WithContext
+}
+
+// tursoState is what a TursoService shares with the views WithContext makes of it
+type tursoState struct {
 	db *sql.DB
+
+	dryRunMu   sync.Mutex
+	dryRun     bool               // Keep writes in dryRunData instead of the database; see SetDryRun
+	dryRunData map[string]*string // Film ID and type -> JSON written in this dry run; nil when deleted
+}
+
+// DatabaseStatus summarizes the contents of the metadata table
+type DatabaseStatus struct {
+	TotalRows      int            `json:"total_rows"`
+	DistinctFilms  int            `json:"distinct_films"`
+	CountsByType   map[string]int `json:"counts_by_type"`
+	OldestUpdateAt string         `json:"oldest_updated_at,omitempty"`
+	NewestUpdateAt string         `json:"newest_updated_at,omitempty"`
 }
 
 func NewTursoService(cfg config.TursoConfig) (*TursoService, error) {
+	op := logger.Get().StartOperation("turso_connect")
 
-	db, err := sql.Open("libsql", cfg.DatabaseURL+"?authToken="+cfg.AuthToken)
+	dsn, err := tursoDSN(cfg.DatabaseURL, cfg.AuthToken)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to Turso database: %v", err)
+		op.Fail("Invalid Turso configuration", err)
+		return nil, err
+	}
+
+	db, err := sql.Open("libsql", dsn)
+	if err != nil {
+		err = fmt.Errorf("failed to connect to Turso database: %v", err)
+		op.Fail("Failed to connect to Turso database", err)
+		return nil, err
 	}
 
 	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("failed to ping Turso database: %v", err)
+		err = fmt.Errorf("failed to ping Turso database: %v", err)
+		op.Fail("Failed to ping Turso database", err)
+		return nil, err
 	}
 
-	service := &TursoService{db: db}
+	service := &TursoService{tursoState: &tursoState{db: db}, ctx: context.Background()}
 
 	if err := service.initializeTables(); err != nil {
-		return nil, fmt.Errorf("failed to initialize database tables: %v", err)
+		err = fmt.Errorf("failed to initialize database tables: %v", err)
+		op.Fail("Failed to initialize database tables", err)
+		return nil, err
 	}
 
-	log.Printf("Successfully connected to Turso database")
+	op.Complete("Connected to Turso database")
 	return service, nil
 }
 
+// NewTursoServiceWithDB creates a TursoService on an already opened database, creating
+// the metadata table if needed
+func NewTursoServiceWithDB(db *sql.DB) (*TursoService, error) {
+	service := &TursoService{tursoState: &tursoState{db: db}, ctx: context.Background()}
+	if err := service.initializeTables(); err != nil {
+		return nil, fmt.Errorf("failed to initialize database tables: %v", err)
+	}
+	return service, nil
+}
+
+// WithContext returns a view of the service whose events carry the request ID ctx holds
+// (see logger.ContextWithRequestID); it shares the database and dry-run state of s
+func (s *TursoService) WithContext(ctx context.Context) *TursoService {
+	return &TursoService{tursoState: s.tursoState, ctx: ctx}
+}
+
+// startOperation starts a logged operation tagged with the request ID of the service's context
+func (s *TursoService) startOperation(operation string) *logger.OperationTracker {
+	return logger.Get().StartOperation(operation).WithRequestID(logger.RequestIDFromContext(s.ctx))
+}
+
+// tursoDSN builds the libsql connection string, adding the auth token as an encoded
+// query parameter alongside any parameters already present in the database URL
+func tursoDSN(databaseURL, authToken string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(databaseURL))
+	if err != nil {
+		return "", fmt.Errorf("invalid turso database_url %q: %v", databaseURL, err)
+	}
+	switch u.Scheme {
+	case "libsql", "https", "http", "wss", "ws":
+	default:
+		return "", fmt.Errorf("invalid turso database_url %q: scheme must be libsql, https, http, wss or ws", databaseURL)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("invalid turso database_url %q: missing host", databaseURL)
+	}
+	if strings.ContainsAny(authToken, " \t\r\n") {
+		return "", fmt.Errorf("invalid turso auth_token: contains whitespace")
+	}
+
+	if authToken != "" {
+		query := u.Query()
+		query.Set("authToken", authToken)
+		u.RawQuery = query.Encode()
+	}
+	return u.String(), nil
+}
+
 func (s *TursoService) initializeTables() error {
 	query := `
 		CREATE TABLE IF NOT EXISTS metadata (
@@ -59,10 +145,54 @@ func (s *TursoService) initializeTables() error {
 	return nil
 }
 
+// ErrEmptyMetadataOverwrite is returned when saving an empty collection would
+// replace existing non-empty metadata
+var ErrEmptyMetadataOverwrite = errors.New("refusing to overwrite existing metadata with an empty collection")
+
+// SaveMetadata upserts the metadata of the given type for a film. Saving an empty
+// slice or map over existing non-empty data is rejected with ErrEmptyMetadataOverwrite;
+// use ReplaceMetadata to clear data intentionally
 func (s *TursoService) SaveMetadata(filmID, metadataType string, data interface{}) error {
+	if isEmptyCollection(data) {
+		var existing string
+		var err error
+		// A dry run checks what it has written itself before the stored data
+		if written, found := s.dryRunRead(filmID, metadataType); !found {
+			err = s.db.QueryRow(`SELECT data FROM metadata WHERE film_id = ? AND type = ?`, filmID, metadataType).Scan(&existing)
+		} else if written == nil {
+			err = sql.ErrNoRows
+		} else {
+			existing = *written
+		}
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("failed to check existing metadata: %v", err)
+		}
+		if err == nil && !isEmptyJSON(existing) {
+			return fmt.Errorf("%w (film '%s', type: %s)", ErrEmptyMetadataOverwrite, filmID, metadataType)
+		}
+	}
+
+	return s.ReplaceMetadata(filmID, metadataType, data)
+}
+
+// ReplaceMetadata upserts the metadata of the given type for a film unconditionally
+func (s *TursoService) ReplaceMetadata(filmID, metadataType string, data interface{}) error {
+	op := s.startOperation("turso_save_metadata")
+	op.WithFilm(filmID, "", "", "")
+	op.WithContext("metadata_type", metadataType)
+
 	jsonData, err := json.Marshal(data)
 	if err != nil {
-		return fmt.Errorf("failed to marshal data to JSON: %v", err)
+		err = fmt.Errorf("failed to marshal data to JSON: %v", err)
+		op.Fail("Failed to marshal metadata", err)
+		return err
+	}
+	op.WithContext("data_size", len(jsonData))
+
+	if dryRunJSON := string(jsonData); s.dryRunWrite(filmID, metadataType, &dryRunJSON) {
+		op.WithContext("dry_run", true)
+		op.Complete(fmt.Sprintf("Dry run: would save %s metadata for film '%s'", metadataType, filmID))
+		return nil
 	}
 
 	query := `
@@ -74,32 +204,231 @@ func (s *TursoService) SaveMetadata(filmID, metadataType string, data interface{
 	`
 
 	if _, err := s.db.Exec(query, filmID, metadataType, string(jsonData)); err != nil {
-		return fmt.Errorf("failed to save metadata: %v", err)
+		err = fmt.Errorf("failed to save metadata: %v", err)
+		op.Fail("Failed to save metadata", err)
+		return err
 	}
 
-	log.Printf("Saved metadata for film '%s' (type: %s)", filmID, metadataType)
+	op.Complete(fmt.Sprintf("Saved %s metadata for film '%s'", metadataType, filmID))
 	return nil
 }
 
+// MergeMetadata merges map-shaped metadata into the stored entries of the given type,
+// keeping existing keys that are not present in entries and overwriting those that are
+func (s *TursoService) MergeMetadata(filmID, metadataType string, entries interface{}) error {
+	jsonData, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data to JSON: %v", err)
+	}
+
+	var updates map[string]json.RawMessage
+	if err := json.Unmarshal(jsonData, &updates); err != nil {
+		return fmt.Errorf("metadata type '%s' is not map-shaped: %v", metadataType, err)
+	}
+
+	merged := make(map[string]json.RawMessage)
+	if err := s.GetMetadata(filmID, metadataType, &merged); err != nil && !errors.Is(err, ErrMetadataNotFound) {
+		return fmt.Errorf("failed to load existing metadata for merge: %v", err)
+	}
+
+	for key, value := range updates {
+		merged[key] = value
+	}
+
+	return s.SaveMetadata(filmID, metadataType, merged)
+}
+
+// isEmptyCollection reports whether data is nil or an empty slice, array or map
+func isEmptyCollection(data interface{}) bool {
+	v := reflect.ValueOf(data)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Slice, reflect.Map:
+		return v.IsNil() || v.Len() == 0
+	case reflect.Array:
+		return v.Len() == 0
+	}
+	return false
+}
+
+func isEmptyJSON(data string) bool {
+	switch strings.TrimSpace(data) {
+	case "", "null", "[]", "{}":
+		return true
+	}
+	return false
+}
+
+// DeleteMetadata removes the metadata of the given type for a film; missing rows are not an error
+func (s *TursoService) DeleteMetadata(filmID, metadataType string) error {
+	op := s.startOperation("turso_delete_metadata")
+	op.WithFilm(filmID, "", "", "")
+	op.WithContext("metadata_type", metadataType)
+
+	if s.dryRunWrite(filmID, metadataType, nil) {
+		op.WithContext("dry_run", true)
+		op.Complete(fmt.Sprintf("Dry run: would delete %s metadata for film '%s'", metadataType, filmID))
+		return nil
+	}
+
+	if _, err := s.db.Exec(`DELETE FROM metadata WHERE film_id = ? AND type = ?`, filmID, metadataType); err != nil {
+		err = fmt.Errorf("failed to delete metadata: %v", err)
+		op.Fail("Failed to delete metadata", err)
+		return err
+	}
+
+	op.Complete(fmt.Sprintf("Deleted %s metadata for film '%s'", metadataType, filmID))
+	return nil
+}
+
+// ErrMetadataNotFound is returned, wrapped, by GetMetadata and its typed variants when
+// a film has no metadata of the requested type
+var ErrMetadataNotFound = errors.New("metadata not found")
+
+// GetMetadata loads the metadata of the given type for a film into dest. A missing row
+// is reported as ErrMetadataNotFound but logged as a completed lookup, not a failure
 func (s *TursoService) GetMetadata(filmID, metadataType string, dest interface{}) error {
+	op := s.startOperation("turso_get_metadata")
+	op.WithFilm(filmID, "", "", "")
+	op.WithContext("metadata_type", metadataType)
+
 	query := `SELECT data FROM metadata WHERE film_id = ? AND type = ?`
 
 	var jsonData string
-	err := s.db.QueryRow(query, filmID, metadataType).Scan(&jsonData)
+	var err error
+	if data, found := s.dryRunRead(filmID, metadataType); !found {
+		err = s.db.QueryRow(query, filmID, metadataType).Scan(&jsonData)
+	} else if data == nil {
+		err = sql.ErrNoRows
+	} else {
+		jsonData = *data
+	}
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return fmt.Errorf("metadata not found for film '%s' type '%s'", filmID, metadataType)
+			op.WithContext("found", false)
+			op.Complete(fmt.Sprintf("No %s metadata for film '%s'", metadataType, filmID))
+			return fmt.Errorf("%w for film '%s' type '%s'", ErrMetadataNotFound, filmID, metadataType)
 		}
-		return fmt.Errorf("failed to get metadata: %v", err)
+		err = fmt.Errorf("failed to get metadata: %v", err)
+		op.Fail("Failed to get metadata", err)
+		return err
 	}
+	op.WithContext("found", true)
+	op.WithContext("data_size", len(jsonData))
 
 	if err := json.Unmarshal([]byte(jsonData), dest); err != nil {
-		return fmt.Errorf("failed to unmarshal JSON data: %v", err)
+		err = fmt.Errorf("failed to unmarshal JSON data: %v", err)
+		op.Fail("Failed to unmarshal metadata", err)
+		return err
 	}
 
+	op.Complete(fmt.Sprintf("Loaded %s metadata for film '%s'", metadataType, filmID))
 	return nil
 }
 
+// CountMetadataRows returns the total number of metadata rows
+func (s *TursoService) CountMetadataRows() (int, error) {
+	var count int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM metadata`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count metadata rows: %v", err)
+	}
+	return count, nil
+}
+
+// CountDistinctFilms returns the number of films with at least one metadata row
+func (s *TursoService) CountDistinctFilms() (int, error) {
+	var count int
+	if err := s.db.QueryRow(`SELECT COUNT(DISTINCT film_id) FROM metadata`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count films: %v", err)
+	}
+	return count, nil
+}
+
+// CountMetadataByType returns the number of rows per metadata type
+func (s *TursoService) CountMetadataByType() (map[string]int, error) {
+	rows, err := s.db.Query(`SELECT type, COUNT(*) FROM metadata GROUP BY type`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count metadata by type: %v", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var metadataType string
+		var count int
+		if err := rows.Scan(&metadataType, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan metadata count: %v", err)
+		}
+		counts[metadataType] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read metadata counts: %v", err)
+	}
+
+	return counts, nil
+}
+
+// MetadataUpdateRange returns the oldest and newest updated_at values, empty if the table is empty
+func (s *TursoService) MetadataUpdateRange() (string, string, error) {
+	var oldest, newest sql.NullString
+	if err := s.db.QueryRow(`SELECT MIN(updated_at), MAX(updated_at) FROM metadata`).Scan(&oldest, &newest); err != nil {
+		return "", "", fmt.Errorf("failed to read metadata update range: %v", err)
+	}
+	return oldest.String, newest.String, nil
+}
+
+// ListFilmIDs returns the IDs of the films that have metadata of the given type
+func (s *TursoService) ListFilmIDs(metadataType string) ([]string, error) {
+	rows, err := s.db.Query(`SELECT film_id FROM metadata WHERE type = ? ORDER BY film_id`, metadataType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list films: %v", err)
+	}
+	defer rows.Close()
+
+	var filmIDs []string
+	for rows.Next() {
+		var filmID string
+		if err := rows.Scan(&filmID); err != nil {
+			return nil, fmt.Errorf("failed to scan film ID: %v", err)
+		}
+		filmIDs = append(filmIDs, filmID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read film IDs: %v", err)
+	}
+
+	return filmIDs, nil
+}
+
+// Status gathers a summary of the metadata table
+func (s *TursoService) Status() (*DatabaseStatus, error) {
+	status := &DatabaseStatus{}
+	var err error
+
+	if status.TotalRows, err = s.CountMetadataRows(); err != nil {
+		return nil, err
+	}
+	if status.DistinctFilms, err = s.CountDistinctFilms(); err != nil {
+		return nil, err
+	}
+	if status.CountsByType, err = s.CountMetadataByType(); err != nil {
+		return nil, err
+	}
+	if status.OldestUpdateAt, status.NewestUpdateAt, err = s.MetadataUpdateRange(); err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
 func (s *TursoService) Close() error {
 	if s.db != nil {
 		return s.db.Close()
@@ -123,6 +452,10 @@ func (s *TursoService) GetDriveFilesMetadata(filmID string, dest interface{}) er
 	return s.GetMetadata(filmID, "drive_files", dest)
 }
 
+func (s *TursoService) DeleteDriveFilesMetadata(filmID string) error {
+	return s.DeleteMetadata(filmID, "drive_files")
+}
+
 func (s *TursoService) SaveWPImagesMetadata(filmID string, images interface{}) error {
 	return s.SaveMetadata(filmID, "wp_images", images)
 }
@@ -130,3 +463,43 @@ func (s *TursoService) SaveWPImagesMetadata(filmID string, images interface{}) e
 func (s *TursoService) GetWPImagesMetadata(filmID string, dest interface{}) error {
 	return s.GetMetadata(filmID, "wp_images", dest)
 }
+
+func (s *TursoService) SaveWPImageHashesMetadata(filmID string, hashes interface{}) error {
+	return s.SaveMetadata(filmID, "wp_image_hashes", hashes)
+}
+
+func (s *TursoService) GetWPImageHashesMetadata(filmID string, dest interface{}) error {
+	return s.GetMetadata(filmID, "wp_image_hashes", dest)
+}
+
+func (s *TursoService) SavePosterMetadata(filmID string, poster interface{}) error {
+	return s.SaveMetadata(filmID, "poster", poster)
+}
+
+func (s *TursoService) GetPosterMetadata(filmID string, dest interface{}) error {
+	return s.GetMetadata(filmID, "poster", dest)
+}
+
+func (s *TursoService) SaveBackgroundImageMetadata(filmID string, background interface{}) error {
+	return s.SaveMetadata(filmID, "background_image", background)
+}
+
+func (s *TursoService) GetBackgroundImageMetadata(filmID string, dest interface{}) error {
+	return s.GetMetadata(filmID, "background_image", dest)
+}
+
+func (s *TursoService) SaveSheetRowHash(filmID string, hash string) error {
+	return s.SaveMetadata(filmID, "sheet_row_hash", hash)
+}
+
+func (s *TursoService) GetSheetRowHash(filmID string, dest interface{}) error {
+	return s.GetMetadata(filmID, "sheet_row_hash", dest)
+}
+
+func (s *TursoService) SaveGalleryCaptionsMetadata(filmID string, captions interface{}) error {
+	return s.SaveMetadata(filmID, "gallery_captions", captions)
+}
+
+func (s *TursoService) GetGalleryCaptionsMetadata(filmID string, dest interface{}) error {
+	return s.GetMetadata(filmID, "gallery_captions", dest)
+}
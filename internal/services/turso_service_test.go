@@ -0,0 +1,369 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"excentrico-tools-go/internal/logger"
+	"excentrico-tools-go/internal/testutil/memsql"
+)
+
+// newTestTurso returns a TursoService on a fresh in-memory database
+func newTestTurso(t *testing.T) *TursoService {
+	t.Helper()
+	db, err := memsql.Open()
+	if err != nil {
+		t.Fatalf("opening in-memory database: %v", err)
+	}
+	service, err := NewTursoServiceWithDB(db)
+	if err != nil {
+		t.Fatalf("NewTursoServiceWithDB: %v", err)
+	}
+	t.Cleanup(func() { service.Close() })
+	return service
+}
+
+func TestGetMetadataNotFound(t *testing.T) {
+	s := newTestTurso(t)
+	var dest []string
+	err := s.GetMetadata("film-1", "drive_files", &dest)
+	if !errors.Is(err, ErrMetadataNotFound) {
+		t.Fatalf("GetMetadata error %v, want ErrMetadataNotFound", err)
+	}
+
+	if err := s.SaveMetadata("film-1", "drive_files", []string{"a"}); err != nil {
+		t.Fatalf("SaveMetadata: %v", err)
+	}
+	if err := s.DeleteMetadata("film-1", "drive_files"); err != nil {
+		t.Fatalf("DeleteMetadata: %v", err)
+	}
+	if err := s.GetMetadata("film-1", "drive_files", &dest); !errors.Is(err, ErrMetadataNotFound) {
+		t.Fatalf("GetMetadata after delete: error %v, want ErrMetadataNotFound", err)
+	}
+}
+
+func TestTursoOperationEvents(t *testing.T) {
+	tests := []struct {
+		name      string
+		call      func(s *TursoService) error
+		operation string
+		level     string
+		context   map[string]any
+	}{
+		{
+			name:      "save",
+			call:      func(s *TursoService) error { return s.SaveMetadata("film-1", "drive_files", []string{"a"}) },
+			operation: "turso_save_metadata",
+			level:     "info",
+			context:   map[string]any{"metadata_type": "drive_files", "data_size": float64(len(`["a"]`))},
+		},
+		{
+			name: "get",
+			call: func(s *TursoService) error {
+				var dest []string
+				return s.GetMetadata("film-1", "wp_images", &dest)
+			},
+			operation: "turso_get_metadata",
+			level:     "info",
+			context:   map[string]any{"metadata_type": "wp_images", "found": true},
+		},
+		{
+			name: "get missing",
+			call: func(s *TursoService) error {
+				var dest []string
+				if err := s.GetMetadata("film-1", "poster", &dest); !errors.Is(err, ErrMetadataNotFound) {
+					return err
+				}
+				return nil
+			},
+			operation: "turso_get_metadata",
+			level:     "info",
+			context:   map[string]any{"metadata_type": "poster", "found": false},
+		},
+		{
+			name:      "delete",
+			call:      func(s *TursoService) error { return s.DeleteMetadata("film-1", "wp_images") },
+			operation: "turso_delete_metadata",
+			level:     "info",
+			context:   map[string]any{"metadata_type": "wp_images"},
+		},
+		{
+			name: "unreadable metadata",
+			call: func(s *TursoService) error {
+				var dest int
+				if err := s.GetMetadata("film-1", "wp_images", &dest); err == nil {
+					return errors.New("GetMetadata decoded a list into an int")
+				}
+				return nil
+			},
+			operation: "turso_get_metadata",
+			level:     "error",
+			context:   map[string]any{"metadata_type": "wp_images", "found": true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := newTestTurso(t)
+			if err := base.SaveMetadata("film-1", "wp_images", []string{"still_web.jpg"}); err != nil {
+				t.Fatal(err)
+			}
+			requestID := logger.NewRequestID()
+			s := base.WithContext(logger.ContextWithRequestID(context.Background(), requestID))
+			events := captureEvents(t)
+
+			if err := tt.call(s); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []logger.WideEvent
+			for _, event := range events() {
+				if event.Operation == tt.operation {
+					got = append(got, event)
+				}
+			}
+			if len(got) != 1 {
+				t.Fatalf("%d %s events, want 1", len(got), tt.operation)
+			}
+			event := got[0]
+			if event.FilmID != "film-1" || event.RequestID != requestID || event.Level != tt.level {
+				t.Errorf("event film %q request %q level %q, want film-1, %q, %q", event.FilmID, event.RequestID, event.Level, requestID, tt.level)
+			}
+			for key, want := range tt.context {
+				if event.Context[key] != want {
+					t.Errorf("%s = %v, want %v", key, event.Context[key], want)
+				}
+			}
+		})
+	}
+}
+
+func TestSaveMetadataEmptyOverwrite(t *testing.T) {
+	tests := []struct {
+		name    string
+		dryRun  bool
+		stored  []string // saved before the dry run starts; nil saves nothing
+		written []string // saved during the test; nil saves nothing
+		deleted bool     // delete the metadata before the empty save
+		wantErr bool
+		want    []string // what GetMetadata returns afterwards
+	}{
+		{name: "no existing metadata", want: []string{}},
+		{name: "existing data is kept", stored: []string{"a", "b"}, wantErr: true, want: []string{"a", "b"}},
+		{name: "existing empty data", stored: []string{}, want: []string{}},
+		{name: "deleted data", stored: []string{"a"}, deleted: true, want: []string{}},
+		{name: "dry run keeps stored data", dryRun: true, stored: []string{"a"}, wantErr: true, want: []string{"a"}},
+		{name: "dry run keeps its own writes", dryRun: true, written: []string{"c"}, wantErr: true, want: []string{"c"}},
+		{name: "dry run after deleting", dryRun: true, stored: []string{"a"}, deleted: true, want: []string{}},
+		{name: "dry run over its own empty write", dryRun: true, stored: []string{"a"}, written: []string{}, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestTurso(t)
+			if tt.stored != nil {
+				if err := s.ReplaceMetadata("film-1", "drive_files", tt.stored); err != nil {
+					t.Fatalf("ReplaceMetadata: %v", err)
+				}
+			}
+			s.SetDryRun(tt.dryRun)
+			if tt.written != nil {
+				if err := s.ReplaceMetadata("film-1", "drive_files", tt.written); err != nil {
+					t.Fatalf("ReplaceMetadata: %v", err)
+				}
+			}
+			if tt.deleted {
+				if err := s.DeleteMetadata("film-1", "drive_files"); err != nil {
+					t.Fatalf("DeleteMetadata: %v", err)
+				}
+			}
+
+			err := s.SaveMetadata("film-1", "drive_files", []string{})
+			if tt.wantErr != errors.Is(err, ErrEmptyMetadataOverwrite) {
+				t.Fatalf("SaveMetadata error %v, want ErrEmptyMetadataOverwrite: %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("SaveMetadata: %v", err)
+			}
+
+			var got []string
+			if err := s.GetMetadata("film-1", "drive_files", &got); err != nil {
+				t.Fatalf("GetMetadata: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("metadata %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDryRunLeavesDatabaseUntouched(t *testing.T) {
+	s := newTestTurso(t)
+	if err := s.ReplaceMetadata("film-1", "drive_files", []string{"a"}); err != nil {
+		t.Fatalf("ReplaceMetadata: %v", err)
+	}
+	s.SetDryRun(true)
+	if err := s.ReplaceMetadata("film-1", "drive_files", []string{"b"}); err != nil {
+		t.Fatalf("ReplaceMetadata: %v", err)
+	}
+	if err := s.DeleteMetadata("film-1", "drive_files"); err != nil {
+		t.Fatalf("DeleteMetadata: %v", err)
+	}
+
+	// A service without the dry-run overlay sees what the database holds
+	stored, err := NewTursoServiceWithDB(s.db)
+	if err != nil {
+		t.Fatalf("NewTursoServiceWithDB: %v", err)
+	}
+	var got []string
+	if err := stored.GetMetadata("film-1", "drive_files", &got); err != nil {
+		t.Fatalf("GetMetadata: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("metadata %v after the dry run, want [a]", got)
+	}
+}
+
+func TestMergeMetadata(t *testing.T) {
+	tests := []struct {
+		name    string
+		stored  map[string]string
+		entries map[string]string
+		want    map[string]string
+	}{
+		{"no existing metadata", nil, map[string]string{"a": "1"}, map[string]string{"a": "1"}},
+		{"keeps other keys", map[string]string{"a": "1"}, map[string]string{"b": "2"}, map[string]string{"a": "1", "b": "2"}},
+		{"overwrites given keys", map[string]string{"a": "1", "b": "2"}, map[string]string{"b": "3"}, map[string]string{"a": "1", "b": "3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestTurso(t)
+			if tt.stored != nil {
+				if err := s.SaveMetadata("film-1", "gallery_captions", tt.stored); err != nil {
+					t.Fatalf("SaveMetadata: %v", err)
+				}
+			}
+			if err := s.MergeMetadata("film-1", "gallery_captions", tt.entries); err != nil {
+				t.Fatalf("MergeMetadata: %v", err)
+			}
+			got := make(map[string]string)
+			if err := s.GetMetadata("film-1", "gallery_captions", &got); err != nil {
+				t.Fatalf("GetMetadata: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("merged metadata %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	type seed struct{ filmID, metadataType string }
+	tests := []struct {
+		name  string
+		seeds []seed
+		want  DatabaseStatus
+	}{
+		{
+			name: "empty database",
+			want: DatabaseStatus{CountsByType: map[string]int{}},
+		},
+		{
+			name: "several films and types",
+			seeds: []seed{
+				{"film-1", "wordpress"},
+				{"film-1", "drive_files"},
+				{"film-2", "wordpress"},
+				{"film-3", "poster"},
+			},
+			want: DatabaseStatus{
+				TotalRows:      4,
+				DistinctFilms:  3,
+				CountsByType:   map[string]int{"wordpress": 2, "drive_files": 1, "poster": 1},
+				OldestUpdateAt: "2024-01-01 00:00:01",
+				NewestUpdateAt: "2024-01-01 00:00:04",
+			},
+		},
+		{
+			name: "resaving a row updates it in place",
+			seeds: []seed{
+				{"film-1", "wordpress"},
+				{"film-2", "wordpress"},
+				{"film-1", "wordpress"},
+			},
+			want: DatabaseStatus{
+				TotalRows:      2,
+				DistinctFilms:  2,
+				CountsByType:   map[string]int{"wordpress": 2},
+				OldestUpdateAt: "2024-01-01 00:00:02",
+				NewestUpdateAt: "2024-01-01 00:00:03",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestTurso(t)
+			for _, seed := range tt.seeds {
+				if err := s.SaveMetadata(seed.filmID, seed.metadataType, map[string]string{"seeded": "yes"}); err != nil {
+					t.Fatalf("SaveMetadata(%s, %s): %v", seed.filmID, seed.metadataType, err)
+				}
+			}
+
+			status, err := s.Status()
+			if err != nil {
+				t.Fatalf("Status: %v", err)
+			}
+			if !reflect.DeepEqual(*status, tt.want) {
+				t.Errorf("Status = %+v, want %+v", *status, tt.want)
+			}
+		})
+	}
+}
+
+func TestTursoDSN(t *testing.T) {
+	tests := []struct {
+		name        string
+		databaseURL string
+		authToken   string
+		want        string
+		wantErr     bool
+	}{
+		{name: "plain URL", databaseURL: "libsql://films-excentrico.turso.io", authToken: "eyJhbGciOi.abc", want: "libsql://films-excentrico.turso.io?authToken=eyJhbGciOi.abc"},
+		{name: "no token", databaseURL: "https://films-excentrico.turso.io", want: "https://films-excentrico.turso.io"},
+		{name: "surrounding space", databaseURL: "  libsql://films-excentrico.turso.io\n", authToken: "abc", want: "libsql://films-excentrico.turso.io?authToken=abc"},
+		{name: "existing query", databaseURL: "libsql://films-excentrico.turso.io?tls=1", authToken: "abc", want: "libsql://films-excentrico.turso.io?authToken=abc&tls=1"},
+		{name: "token with special characters", databaseURL: "libsql://films-excentrico.turso.io?tls=1", authToken: "a+b/c=&d?", want: "libsql://films-excentrico.turso.io?authToken=a%2Bb%2Fc%3D%26d%3F&tls=1"},
+		{name: "existing token replaced", databaseURL: "libsql://films-excentrico.turso.io?authToken=old", authToken: "new", want: "libsql://films-excentrico.turso.io?authToken=new"},
+		{name: "missing scheme", databaseURL: "films-excentrico.turso.io", authToken: "abc", wantErr: true},
+		{name: "unsupported scheme", databaseURL: "postgres://films-excentrico.turso.io", authToken: "abc", wantErr: true},
+		{name: "missing host", databaseURL: "libsql://", authToken: "abc", wantErr: true},
+		{name: "malformed URL", databaseURL: "libsql://films excentrico%zz", authToken: "abc", wantErr: true},
+		{name: "token with whitespace", databaseURL: "libsql://films-excentrico.turso.io", authToken: "abc def", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tursoDSN(tt.databaseURL, tt.authToken)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("tursoDSN() error = %v, want error: %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("tursoDSN() = %q, want %q", got, tt.want)
+			}
+			if tt.authToken == "" {
+				return
+			}
+			// The driver must read back the exact token
+			u, err := url.Parse(got)
+			if err != nil {
+				t.Fatalf("parsing the DSN: %v", err)
+			}
+			if token := u.Query().Get("authToken"); token != tt.authToken {
+				t.Errorf("DSN carries token %q, want %q", token, tt.authToken)
+			}
+		})
+	}
+}
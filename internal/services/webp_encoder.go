@@ -0,0 +1,78 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// encodeWebP writes img to w as lossy WebP at quality (1-100). It runs cwebp from libwebp;
+// builds with the webpenc tag use the in-tree encoder instead
+var encodeWebP = encodeWebPWithCwebp
+
+// webpEncoderAvailable returns why WebP images cannot be written, or nil when they can
+var webpEncoderAvailable = cwebpAvailable
+
+// cwebpAvailable reports whether cwebp is on the PATH
+func cwebpAvailable() error {
+	if _, err := exec.LookPath("cwebp"); err != nil {
+		return fmt.Errorf("webp output needs the cwebp tool from libwebp on the PATH: %v", err)
+	}
+	return nil
+}
+
+// encodeWebPWithCwebp hands img to cwebp as a lossless PNG and copies the WebP it writes to w
+func encodeWebPWithCwebp(w io.Writer, img image.Image, quality int) error {
+	cwebp, err := exec.LookPath("cwebp")
+	if err != nil {
+		return cwebpAvailable()
+	}
+	if quality <= 0 || quality > 100 {
+		quality = 75 // cwebp's default
+	}
+
+	dir, err := os.MkdirTemp("", "excentrico-webp-")
+	if err != nil {
+		return fmt.Errorf("failed to create cwebp work directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "input.png")
+	output := filepath.Join(dir, "output.webp")
+	file, err := os.Create(input)
+	if err != nil {
+		return fmt.Errorf("failed to create cwebp input: %v", err)
+	}
+	encoder := png.Encoder{CompressionLevel: png.BestSpeed}
+	err = encoder.Encode(file, img)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write cwebp input: %v", err)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(cwebp, "-quiet", "-q", strconv.Itoa(quality), input, "-o", output)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("cwebp failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	webp, err := os.Open(output)
+	if err != nil {
+		return fmt.Errorf("failed to read cwebp output: %v", err)
+	}
+	defer webp.Close()
+	if _, err := io.Copy(w, webp); err != nil {
+		return fmt.Errorf("failed to copy cwebp output: %v", err)
+	}
+	return nil
+}
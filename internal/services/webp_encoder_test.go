@@ -0,0 +1,82 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+
+	"excentrico-tools-go/internal/config"
+
+	"golang.org/x/image/webp"
+)
+
+func TestCheckEncoders(t *testing.T) {
+	defer func(available func() error) { webpEncoderAvailable = available }(webpEncoderAvailable)
+
+	tests := []struct {
+		name      string
+		format    string
+		rules     map[string]string
+		available bool // A WebP encoder is installed
+		wantErr   bool
+	}{
+		{name: "JPEG without an encoder", format: "jpeg"},
+		{name: "default format without an encoder"},
+		{name: "PNG rule without an encoder", rules: map[string]string{".png": "png"}},
+		{name: "WebP with an encoder", format: "webp", available: true},
+		{name: "WebP without an encoder", format: "webp", wantErr: true},
+		{name: "WebP rule without an encoder", format: "jpeg", rules: map[string]string{".jpg": "webp"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webpEncoderAvailable = func() error {
+				if tt.available {
+					return nil
+				}
+				return errors.New("no WebP encoder")
+			}
+			service := NewImageService(config.ImageConfig{Format: tt.format, FormatRules: tt.rules})
+			if err := service.CheckEncoders(); (err != nil) != tt.wantErr {
+				t.Errorf("CheckEncoders() error %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEncodeWebPWithCwebp(t *testing.T) {
+	if err := cwebpAvailable(); err != nil {
+		t.Skip(err)
+	}
+	tests := []struct {
+		name          string
+		width, height int
+		quality       int
+	}{
+		{name: "landscape", width: 48, height: 32, quality: 85},
+		{name: "portrait", width: 17, height: 31, quality: 50},
+		{name: "default quality", width: 16, height: 16},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := image.NewRGBA(image.Rect(0, 0, tt.width, tt.height))
+			for y := 0; y < tt.height; y++ {
+				for x := 0; x < tt.width; x++ {
+					img.Set(x, y, color.RGBA{R: uint8(x * 8), G: uint8(y * 8), B: 128, A: 255})
+				}
+			}
+			var out bytes.Buffer
+			if err := encodeWebPWithCwebp(&out, img, tt.quality); err != nil {
+				t.Fatalf("encodeWebPWithCwebp: %v", err)
+			}
+			decoded, err := webp.Decode(&out)
+			if err != nil {
+				t.Fatalf("decoding cwebp output: %v", err)
+			}
+			if got := decoded.Bounds().Size(); got.X != tt.width || got.Y != tt.height {
+				t.Errorf("decoded %dx%d, want %dx%d", got.X, got.Y, tt.width, tt.height)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+//go:build webpenc
+
+package services
+
+import (
+	"image"
+	"io"
+
+	"excentrico-tools-go/internal/webpenc"
+)
+
+// Builds with the webpenc tag write WebP with the in-tree encoder instead of cwebp
+func init() {
+	encodeWebP = func(w io.Writer, img image.Image, quality int) error {
+		return webpenc.Encode(w, img, &webpenc.Options{Quality: quality})
+	}
+	webpEncoderAvailable = func() error { return nil }
+}
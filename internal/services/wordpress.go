@@ -2,8 +2,10 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"excentrico-tools-go/internal/config"
 	"excentrico-tools-go/internal/debug"
 	"excentrico-tools-go/internal/logger"
@@ -11,18 +13,72 @@ import (
 	"io"
 	"log"
 	"mime/multipart"
+	"net"
 	"net/http"
+	"net/http/cookiejar"
 	"net/url"
 	"os"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/googleapi"
 )
 
 type WordPressService struct {
-	baseURL    string
-	authHeader string
-	client     *http.Client
+	*wordPressState
+	// ctx aborts in-flight requests and retry waits when the run is interrupted; in a view
+	// made by WithContext it also carries the request ID of the film being processed
+	ctx context.Context
+}
+
+// wordPressState is what a WordPressService shares with the views WithContext makes of it
+type wordPressState struct {
+	baseURL      string
+	authHeader   string
+	authMode     string
+	username     string
+	password     string
+	sessionToken string
+	authMu       sync.Mutex
+	requestSlots chan struct{} // Bounds requests whose response is still open; nil means unlimited
+	client       *http.Client
+
+	postType         string // Post type films are published as
+	postRestBase     string // REST route of the post type, confirmed by VerifyPostType
+	categoryTaxonomy string // Taxonomy slug used for film sections
+	categoryRestBase string // REST route of the taxonomy, confirmed by VerifyCategoryTaxonomy
+	tagTaxonomy      string // Taxonomy slug used for social tags
+	tagRestBase      string // REST route of the tag taxonomy, confirmed by VerifyTagTaxonomy
+	// sectionCategories maps lowercased sheet sections to a category ID or slug, bypassing the search
+	sectionCategories map[string]string
+	// createMissingCategories creates the category of a section the search does not find
+	createMissingCategories bool
+	categoryMu              sync.Mutex // Serializes category creation
+
+	authorID      int // Configured post author; 0 uses the authenticated user
+	userMu        sync.Mutex
+	currentUserID int // Cached ID of the authenticated user
+
+	maxRetries     int           // Extra attempts of a request answered with 429 or 5xx
+	retryBaseDelay time.Duration // Wait before the first of those retries, doubled for each further one
+
+	mediaLookupRetries int // Extra GetMedia attempts when media uploaded this run is not found yet
+	uploadMu           sync.Mutex
+	uploadedMedia      map[int]bool // Media IDs uploaded by this service
+	dryRun             dryRunState  // Skips writes when enabled; guarded by uploadMu
+
+	chunkedUploadEndpoint  string // REST route assembling ranged uploads; empty always uses a single POST
+	chunkedUploadThreshold int64  // Files larger than this many bytes are uploaded in parts
+	chunkSize              int64  // Bytes sent per part
 }
 
+// mediaLookupBackoff is the wait before the first GetMedia retry, doubled for each further attempt
+var mediaLookupBackoff = 500 * time.Millisecond
+
 type WordPressRenderedField struct {
 	Raw      string `json:"raw,omitempty"`
 	Rendered string `json:"rendered,omitempty"`
@@ -70,9 +126,14 @@ type WordPressPost struct {
 	Tags          []int                  `json:"tags,omitempty"`
 	FeaturedMedia int                    `json:"featured_media,omitempty"`
 	Slug          string                 `json:"slug,omitempty"`
+	Link          string                 `json:"link,omitempty"` // Permalink, a ?p= preview for drafts; set by WordPress
 	Date          string                 `json:"date,omitempty"`
 	Modified      string                 `json:"modified,omitempty"`
 	Meta          map[string]interface{} `json:"meta,omitempty"`
+
+	// PermalinkTemplate and GeneratedSlug are returned in the edit context; see Permalink
+	PermalinkTemplate string `json:"permalink_template,omitempty"`
+	GeneratedSlug     string `json:"generated_slug,omitempty"`
 }
 
 type WordPressMedia struct {
@@ -112,7 +173,7 @@ type WordPressMenu struct {
 	Slug string `json:"slug,omitempty"`
 }
 
-func NewWordPressService(config config.WordPressConfig) *WordPressService {
+func NewWordPressService(ctx context.Context, config config.WordPressConfig) *WordPressService {
 
 	authString := fmt.Sprintf("%s:%s", config.Username, config.ApplicationPassword)
 	encodedAuth := base64.StdEncoding.EncodeToString([]byte(authString))
@@ -120,13 +181,88 @@ func NewWordPressService(config config.WordPressConfig) *WordPressService {
 	baseURL := strings.TrimRight(config.BaseURL, "/")
 	authHeader := "Basic " + encodedAuth
 
-	debug.Printf("WordPress Service Initialized - Base URL: %s, Username: %s, Auth Header: %s", baseURL, config.Username, authHeader)
+	authMode := strings.ToLower(strings.TrimSpace(config.AuthMode))
+	if authMode == "" {
+		authMode = AuthModeBasic
+	}
 
-	return &WordPressService{
-		baseURL:    baseURL,
-		authHeader: authHeader,
-		client:     &http.Client{},
+	client := newHTTPClient()
+	if authMode == AuthModeCookie {
+		// The login session lives in cookies, so keep them between requests
+		jar, _ := cookiejar.New(nil)
+		client.Jar = jar
 	}
+
+	debug.Printf("WordPress Service Initialized - Base URL: %s, Username: %s, Auth Mode: %s, Auth Header: %s", baseURL, config.Username, authMode, authHeader)
+
+	var requestSlots chan struct{}
+	if config.MaxConcurrentRequests > 0 {
+		requestSlots = make(chan struct{}, config.MaxConcurrentRequests)
+	}
+
+	postType := strings.TrimSpace(config.PostType)
+	if postType == "" {
+		postType = DefaultPostType
+	}
+	categoryTaxonomy := strings.TrimSpace(config.CategoryTaxonomy)
+	if categoryTaxonomy == "" {
+		categoryTaxonomy = DefaultCategoryTaxonomy
+	}
+	tagTaxonomy := strings.TrimSpace(config.TagTaxonomy)
+	if tagTaxonomy == "" {
+		tagTaxonomy = DefaultTagTaxonomy
+	}
+
+	// The delay was validated when the configuration was loaded
+	retryBaseDelay, err := time.ParseDuration(config.RetryBaseDelay)
+	if err != nil {
+		retryBaseDelay = DefaultRetryBaseDelay
+	}
+
+	state := &wordPressState{
+		baseURL:      baseURL,
+		authHeader:   authHeader,
+		authMode:     authMode,
+		username:     config.Username,
+		password:     config.Password,
+		requestSlots: requestSlots,
+		client:       client,
+
+		postType:         postType,
+		postRestBase:     postType,
+		categoryTaxonomy: categoryTaxonomy,
+		categoryRestBase: categoryTaxonomy,
+		tagTaxonomy:      tagTaxonomy,
+		tagRestBase:      defaultRestBase(tagTaxonomy),
+
+		sectionCategories:       normalizeSectionCategories(config.SectionCategories),
+		createMissingCategories: config.CreateMissingCategories,
+
+		authorID: config.AuthorID,
+
+		maxRetries:     config.MaxRetries,
+		retryBaseDelay: retryBaseDelay,
+
+		mediaLookupRetries: config.MediaLookupRetries,
+		uploadedMedia:      make(map[int]bool),
+
+		chunkedUploadEndpoint:  normalizeChunkedUploadEndpoint(config.ChunkedUploadEndpoint),
+		chunkedUploadThreshold: int64(config.ChunkedUploadThresholdMB) << 20,
+		chunkSize:              int64(config.ChunkSizeMB) << 20,
+	}
+	return &WordPressService{wordPressState: state, ctx: ctx}
+}
+
+// WithContext returns a view of the service whose requests run under ctx and whose events
+// carry the request ID ctx holds (see logger.ContextWithRequestID). The view shares the
+// session, request limiter, caches and settings of s
+func (s *WordPressService) WithContext(ctx context.Context) *WordPressService {
+	return &WordPressService{wordPressState: s.wordPressState, ctx: ctx}
+}
+
+// startOperation starts a logged operation tagged with the request ID of the service's context
+func (s *WordPressService) startOperation(operation string) *logger.OperationTracker {
+	return logger.Get().StartOperation(operation).WithRequestID(logger.RequestIDFromContext(s.ctx))
 }
 
 // cleanCategories removes any 0 values from the Categories array
@@ -144,9 +280,25 @@ func cleanCategories(categories []int) []int {
 	return cleaned
 }
 
+// Permalink returns the public URL of the post. WordPress links an unpublished post as a
+// ?p= preview, so its URL is built from the permalink template and slug of the edit
+// context instead. It is empty when the site uses plain ?p= permalinks
+func (p *WordPressPost) Permalink() string {
+	if p.Status == "publish" {
+		return p.Link
+	}
+	slug := p.Slug
+	if slug == "" {
+		slug = p.GeneratedSlug
+	}
+	if slug == "" || !strings.Contains(p.PermalinkTemplate, "%postname%") && !strings.Contains(p.PermalinkTemplate, "%pagename%") {
+		return ""
+	}
+	return strings.NewReplacer("%postname%", slug, "%pagename%", slug).Replace(p.PermalinkTemplate)
+}
+
 func (s *WordPressService) CreatePost(post *WordPressPost) (*WordPressPost, error) {
-	l := logger.Get()
-	op := l.StartOperation("wordpress_create_post")
+	op := s.startOperation("wordpress_create_post")
 	op.WithContext("post_title", post.Title.String())
 	op.WithContext("post_slug", post.Slug)
 	op.WithContext("post_status", post.Status)
@@ -157,13 +309,18 @@ func (s *WordPressService) CreatePost(post *WordPressPost) (*WordPressPost, erro
 		post.Categories = nil // Set to nil if empty to use omitempty
 	}
 
-	jsonData, err := json.Marshal(post)
+	if s.DryRun() {
+		return s.dryRunSavePost(0, post), nil
+	}
+
+	jsonData, err := s.encodePost(post)
 	if err != nil {
 		op.Fail("Failed to marshal post", err)
 		return nil, fmt.Errorf("failed to marshal post: %v", err)
 	}
 
-	resp, err := s.makeRequest("POST", "/wp/v2/project", jsonData)
+	// The edit context adds the permalink template drafts need; see WordPressPost.Permalink
+	resp, err := s.makeRequest("POST", s.postEndpoint()+"?context=edit", jsonData)
 	if err != nil {
 		op.Fail("WordPress API request failed", err)
 		return nil, err
@@ -183,8 +340,7 @@ func (s *WordPressService) CreatePost(post *WordPressPost) (*WordPressPost, erro
 }
 
 func (s *WordPressService) UpdatePost(postID int, post *WordPressPost) (*WordPressPost, error) {
-	l := logger.Get()
-	op := l.StartOperation("wordpress_update_post")
+	op := s.startOperation("wordpress_update_post")
 	op.WithWordPress(postID, 0, post.Slug)
 	op.WithContext("post_title", post.Title.String())
 	op.WithContext("post_status", post.Status)
@@ -195,13 +351,17 @@ func (s *WordPressService) UpdatePost(postID int, post *WordPressPost) (*WordPre
 		post.Categories = nil // Set to nil if empty to use omitempty
 	}
 
-	jsonData, err := json.Marshal(post)
+	if s.DryRun() {
+		return s.dryRunSavePost(postID, post), nil
+	}
+
+	jsonData, err := s.encodePost(post)
 	if err != nil {
 		op.Fail("Failed to marshal post", err)
 		return nil, fmt.Errorf("failed to marshal post: %v", err)
 	}
 
-	resp, err := s.makeRequest("PUT", fmt.Sprintf("/wp/v2/project/%d", postID), jsonData)
+	resp, err := s.makeRequest("PUT", fmt.Sprintf("%s/%d?context=edit", s.postEndpoint(), postID), jsonData)
 	if err != nil {
 		op.Fail("WordPress API request failed", err)
 		return nil, err
@@ -221,7 +381,7 @@ func (s *WordPressService) UpdatePost(postID int, post *WordPressPost) (*WordPre
 }
 
 func (s *WordPressService) GetPost(postID int) (*WordPressPost, error) {
-	resp, err := s.makeRequest("GET", fmt.Sprintf("/wp/v2/project/%d", postID), nil)
+	resp, err := s.makeRequest("GET", fmt.Sprintf("%s/%d", s.postEndpoint(), postID), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -235,33 +395,50 @@ func (s *WordPressService) GetPost(postID int) (*WordPressPost, error) {
 	return &post, nil
 }
 
+// GetPosts lists every project matching params, following pagination
 func (s *WordPressService) GetPosts(params map[string]string) ([]*WordPressPost, error) {
 	query := url.Values{}
 	for key, value := range params {
 		query.Set(key, value)
 	}
+	return getAllPages[*WordPressPost](s, s.postEndpoint(), query)
+}
 
-	endpoint := "/wp/v2/project"
-	if len(query) > 0 {
-		endpoint += "?" + query.Encode()
-	}
+// getAllPages requests path with query one page of 100 items at a time, until the
+// X-WP-TotalPages response header or an empty page says the collection is exhausted,
+// and returns the items of every page
+func getAllPages[T any](s *WordPressService, path string, query url.Values) ([]T, error) {
+	var all []T
+	for page := 1; ; page++ {
+		pageQuery := url.Values{}
+		for key, values := range query {
+			pageQuery[key] = values
+		}
+		pageQuery.Set("per_page", "100")
+		pageQuery.Set("page", strconv.Itoa(page))
 
-	resp, err := s.makeRequest("GET", endpoint, nil)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+		resp, err := s.makeRequest("GET", path+"?"+pageQuery.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
 
-	var posts []*WordPressPost
-	if err := json.NewDecoder(resp.Body).Decode(&posts); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %v", err)
-	}
+		var items []T
+		err = json.NewDecoder(resp.Body).Decode(&items)
+		totalPages, _ := strconv.Atoi(resp.Header.Get("X-WP-TotalPages"))
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode response: %v", err)
+		}
 
-	return posts, nil
+		all = append(all, items...)
+		if len(items) == 0 || page >= totalPages {
+			return all, nil
+		}
+	}
 }
 
 func (s *WordPressService) DeletePost(postID int) error {
-	resp, err := s.makeRequest("DELETE", fmt.Sprintf("/wp/v2/project/%d", postID), nil)
+	resp, err := s.makeRequest("DELETE", fmt.Sprintf("%s/%d", s.postEndpoint(), postID), nil)
 	if err != nil {
 		return err
 	}
@@ -273,8 +450,7 @@ func (s *WordPressService) DeletePost(postID int) error {
 
 func (s *WordPressService) UploadMedia(file *multipart.FileHeader, title, altText string) (*WordPressMedia, error) {
 	// Log HTTP request with multipart payload info
-	l := logger.Get()
-	op := l.StartOperation("wordpress_upload_media")
+	op := s.startOperation("wordpress_upload_media")
 	op.WithContext("http_method", "POST")
 	op.WithContext("http_url", s.baseURL+"/wp-json/wp/v2/media")
 	op.WithContext("http_endpoint", "/wp/v2/media")
@@ -284,6 +460,12 @@ func (s *WordPressService) UploadMedia(file *multipart.FileHeader, title, altTex
 	op.WithContext("http_request_payload_title", title)
 	op.WithContext("http_request_payload_alt_text", altText)
 
+	if s.DryRun() {
+		// A multipart file has no local path, so the synthetic media has no source URL
+		op.WithContext("dry_run", true)
+		return s.storeDryRunMedia(op, file.Filename, title, altText, ""), nil
+	}
+
 	fileReader, err := file.Open()
 	if err != nil {
 		op.Fail("Failed to open file", err)
@@ -316,20 +498,11 @@ func (s *WordPressService) UploadMedia(file *multipart.FileHeader, title, altTex
 
 	writer.Close()
 
-	req, err := http.NewRequest("POST", s.baseURL+"/wp-json/wp/v2/media", &buf)
-	if err != nil {
-		op.Fail("Failed to create HTTP request", err)
-		return nil, fmt.Errorf("failed to create request: %v", err)
-	}
-
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	req.Header.Set("Authorization", s.authHeader)
-
-	resp, err := s.client.Do(req)
+	resp, err := s.doRequest("POST", s.baseURL+"/wp-json/wp/v2/media", writer.FormDataContentType(), buf.Bytes())
 	if err != nil {
 		op.WithContext("http_status_code", 0)
 		op.Fail("HTTP request failed", err)
-		return nil, fmt.Errorf("failed to make request: %v", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -348,6 +521,7 @@ func (s *WordPressService) UploadMedia(file *multipart.FileHeader, title, altTex
 		return nil, fmt.Errorf("failed to decode response: %v", err)
 	}
 
+	s.recordUpload(media.ID)
 	op.WithWordPress(0, media.ID, "")
 	op.WithContext("http_response_media_id", media.ID)
 	op.Complete(fmt.Sprintf("Uploaded media: %s (ID: %d)", media.Title.String(), media.ID))
@@ -355,9 +529,12 @@ func (s *WordPressService) UploadMedia(file *multipart.FileHeader, title, altTex
 }
 
 func (s *WordPressService) UploadMediaFromFile(filePath, title, altText string) (*WordPressMedia, error) {
+	if s.DryRun() {
+		return s.dryRunUpload(filePath, title, altText)
+	}
+
 	// Log HTTP request with multipart payload info
-	l := logger.Get()
-	op := l.StartOperation("wordpress_upload_media_from_file")
+	op := s.startOperation("wordpress_upload_media_from_file")
 	op.WithContext("http_method", "POST")
 	op.WithContext("http_url", s.baseURL+"/wp-json/wp/v2/media")
 	op.WithContext("http_endpoint", "/wp/v2/media")
@@ -382,6 +559,21 @@ func (s *WordPressService) UploadMediaFromFile(filePath, title, altText string)
 	op.WithContext("http_request_payload_file_name", fileInfo.Name())
 	op.WithContext("http_request_payload_file_size", fileInfo.Size())
 
+	// Files over the threshold would exceed PHP upload limits in a single POST
+	if s.usesChunkedUpload(fileInfo.Size()) {
+		op.WithContext("chunked_upload", true)
+		media, err := s.uploadMediaInChunks(file, fileInfo.Name(), fileInfo.Size(), title, altText)
+		if err != nil {
+			op.Fail("Chunked upload failed", err)
+			return nil, err
+		}
+		s.recordUpload(media.ID)
+		op.WithWordPress(0, media.ID, "")
+		op.WithContext("http_response_media_id", media.ID)
+		op.Complete(fmt.Sprintf("Uploaded media from file: %s (ID: %d)", media.Title.String(), media.ID))
+		return media, nil
+	}
+
 	var buf bytes.Buffer
 	writer := multipart.NewWriter(&buf)
 
@@ -407,20 +599,11 @@ func (s *WordPressService) UploadMediaFromFile(filePath, title, altText string)
 
 	writer.Close()
 
-	req, err := http.NewRequest("POST", s.baseURL+"/wp-json/wp/v2/media", &buf)
-	if err != nil {
-		op.Fail("Failed to create HTTP request", err)
-		return nil, fmt.Errorf("failed to create request: %v", err)
-	}
-
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	req.Header.Set("Authorization", s.authHeader)
-
-	resp, err := s.client.Do(req)
+	resp, err := s.doRequest("POST", s.baseURL+"/wp-json/wp/v2/media", writer.FormDataContentType(), buf.Bytes())
 	if err != nil {
 		op.WithContext("http_status_code", 0)
 		op.Fail("HTTP request failed", err)
-		return nil, fmt.Errorf("failed to make request: %v", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -439,34 +622,34 @@ func (s *WordPressService) UploadMediaFromFile(filePath, title, altText string)
 		return nil, fmt.Errorf("failed to decode response: %v", err)
 	}
 
+	s.recordUpload(media.ID)
 	op.WithWordPress(0, media.ID, "")
 	op.WithContext("http_response_media_id", media.ID)
 	op.Complete(fmt.Sprintf("Uploaded media from file: %s (ID: %d)", media.Title.String(), media.ID))
 	return &media, nil
 }
 
+// GetCategories lists every category of the film section taxonomy, following pagination
 func (s *WordPressService) GetCategories() ([]*WordPressCategory, error) {
-	resp, err := s.makeRequest("GET", "/wp/v2/project-category", nil)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	var categories []*WordPressCategory
-	if err := json.NewDecoder(resp.Body).Decode(&categories); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %v", err)
-	}
-
-	return categories, nil
+	return getAllPages[*WordPressCategory](s, s.categoryEndpoint(), nil)
 }
 
+// SearchCategories lists every category matching year, following pagination
 func (s *WordPressService) SearchCategories(year string) ([]*WordPressCategory, error) {
 
 	query := url.Values{}
 	query.Set("search", year)
-	query.Set("per_page", "100")
 
-	endpoint := "/wp/v2/project_category?" + query.Encode()
+	return getAllPages[*WordPressCategory](s, s.categoryEndpoint(), query)
+}
+
+func (s *WordPressService) SearchCategoriesWithParams(params map[string]string) ([]*WordPressCategory, error) {
+	query := url.Values{}
+	for key, value := range params {
+		query.Set(key, value)
+	}
+
+	endpoint := s.categoryQueryEndpoint(query)
 
 	resp, err := s.makeRequest("GET", endpoint, nil)
 	if err != nil {
@@ -482,33 +665,63 @@ func (s *WordPressService) SearchCategories(year string) ([]*WordPressCategory,
 	return categories, nil
 }
 
-func (s *WordPressService) SearchCategoriesWithParams(params map[string]string) ([]*WordPressCategory, error) {
-	query := url.Values{}
-	for key, value := range params {
-		query.Set(key, value)
-	}
+// CreateCategory adds a category to the film section taxonomy under parent (0 for a
+// top-level one). When WordPress reports the term already exists, that term is returned
+func (s *WordPressService) CreateCategory(name string, parent int) (*WordPressCategory, error) {
+	op := s.startOperation("wordpress_create_category")
+	op.WithContext("category_name", name)
+	op.WithContext("category_parent", parent)
 
-	endpoint := "/wp/v2/categories"
-	if len(query) > 0 {
-		endpoint += "?" + query.Encode()
+	jsonData, err := json.Marshal(map[string]any{"name": name, "parent": parent})
+	if err != nil {
+		op.Fail("Failed to marshal category", err)
+		return nil, fmt.Errorf("failed to marshal category: %v", err)
 	}
 
-	resp, err := s.makeRequest("GET", endpoint, nil)
+	resp, err := s.makeRequest("POST", s.categoryEndpoint(), jsonData)
 	if err != nil {
+		if existingID := existingTermID(err); existingID > 0 {
+			op.WithContext("category_id", existingID)
+			op.Complete(fmt.Sprintf("Category '%s' already exists (ID: %d)", name, existingID))
+			return &WordPressCategory{ID: existingID, Name: name, Parent: parent}, nil
+		}
+		op.Fail("WordPress API request failed", err)
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	var categories []*WordPressCategory
-	if err := json.NewDecoder(resp.Body).Decode(&categories); err != nil {
+	var category WordPressCategory
+	if err := json.NewDecoder(resp.Body).Decode(&category); err != nil {
+		op.Fail("Failed to decode response", err)
 		return nil, fmt.Errorf("failed to decode response: %v", err)
 	}
 
-	return categories, nil
+	op.WithContext("category_id", category.ID)
+	op.Complete(fmt.Sprintf("Created category '%s' (ID: %d)", category.Name, category.ID))
+	return &category, nil
+}
+
+// existingTermID returns the term ID of a "term_exists" error answered to a term
+// creation, or 0 for any other error
+func existingTermID(err error) int {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		return 0
+	}
+	var body struct {
+		Code string `json:"code"`
+		Data struct {
+			TermID int `json:"term_id"`
+		} `json:"data"`
+	}
+	if json.Unmarshal([]byte(apiErr.Body), &body) != nil || body.Code != "term_exists" {
+		return 0
+	}
+	return body.Data.TermID
 }
 
 func (s *WordPressService) GetTags() ([]*WordPressTag, error) {
-	resp, err := s.makeRequest("GET", "/wp/v2/tags", nil)
+	resp, err := s.makeRequest("GET", s.tagEndpoint(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -527,7 +740,7 @@ func (s *WordPressService) SearchTags(searchText string) ([]*WordPressTag, error
 	query := url.Values{}
 	query.Set("search", searchText)
 
-	endpoint := "/wp/v2/tags?" + query.Encode()
+	endpoint := s.tagEndpoint() + "?" + query.Encode()
 
 	resp, err := s.makeRequest("GET", endpoint, nil)
 	if err != nil {
@@ -543,8 +756,74 @@ func (s *WordPressService) SearchTags(searchText string) ([]*WordPressTag, error
 	return tags, nil
 }
 
+// UpdateMedia changes the given fields (e.g. "caption", "alt_text") of an existing media item
+func (s *WordPressService) UpdateMedia(mediaID int, fields map[string]any) (*WordPressMedia, error) {
+	jsonData, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal media update: %v", err)
+	}
+
+	resp, err := s.makeRequest("POST", fmt.Sprintf("/wp/v2/media/%d", mediaID), jsonData)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var media WordPressMedia
+	if err := json.NewDecoder(resp.Body).Decode(&media); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+
+	return &media, nil
+}
+
+// DeleteMedia permanently deletes a media item; WordPress does not trash attachments
+func (s *WordPressService) DeleteMedia(mediaID int) error {
+	resp, err := s.makeRequest("DELETE", fmt.Sprintf("/wp/v2/media/%d?force=true", mediaID), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// recordUpload remembers a media ID uploaded in this run, so GetMedia retries it on a 404
+func (s *WordPressService) recordUpload(mediaID int) {
+	s.uploadMu.Lock()
+	s.uploadedMedia[mediaID] = true
+	s.uploadMu.Unlock()
+}
+
+// GetMedia fetches a media item. Some hosts briefly answer 404 for media uploaded
+// moments ago, so media uploaded in this run is retried with backoff before failing
 func (s *WordPressService) GetMedia(mediaID int) (*WordPressMedia, error) {
+	if media, ok := s.dryRunMedia(mediaID); ok {
+		return media, nil
+	}
+
+	s.uploadMu.Lock()
+	retries := 0
+	if s.uploadedMedia[mediaID] {
+		retries = s.mediaLookupRetries
+	}
+	s.uploadMu.Unlock()
+
 	resp, err := s.makeRequest("GET", fmt.Sprintf("/wp/v2/media/%d", mediaID), nil)
+	for attempt := 1; attempt <= retries && IsNotFoundError(err); attempt++ {
+		op := s.startOperation("retry_get_media")
+		op.WithWordPress(0, mediaID, "")
+		op.WithContext("attempt", attempt)
+		if err = SleepContext(s.ctx, mediaLookupBackoff<<(attempt-1)); err != nil {
+			op.Fail("Interrupted while waiting to retry media lookup", err)
+			break
+		}
+		resp, err = s.makeRequest("GET", fmt.Sprintf("/wp/v2/media/%d", mediaID), nil)
+		if err != nil {
+			op.WithError(err)
+		}
+		op.Complete(fmt.Sprintf("Retried just-uploaded media %d (attempt %d of %d)", mediaID, attempt, retries))
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -558,12 +837,59 @@ func (s *WordPressService) GetMedia(mediaID int) (*WordPressMedia, error) {
 	return &media, nil
 }
 
+// GetMediaByParent lists every media item WordPress attaches to a post, following
+// pagination, so orphaned attachments can be found and pruned
+func (s *WordPressService) GetMediaByParent(postID int) ([]*WordPressMedia, error) {
+	query := url.Values{}
+	query.Set("parent", strconv.Itoa(postID))
+	return getAllPages[*WordPressMedia](s, "/wp/v2/media", query)
+}
+
+// GetCurrentUserID returns the ID of the authenticated user, fetched from
+// /wp/v2/users/me once and cached for the rest of the run
+func (s *WordPressService) GetCurrentUserID() (int, error) {
+	s.userMu.Lock()
+	defer s.userMu.Unlock()
+
+	if s.currentUserID > 0 {
+		return s.currentUserID, nil
+	}
+
+	resp, err := s.makeRequest("GET", "/wp/v2/users/me", nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch current user: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var user struct {
+		ID int `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return 0, fmt.Errorf("failed to decode user response: %v", err)
+	}
+	if user.ID == 0 {
+		return 0, fmt.Errorf("current user response did not contain an ID")
+	}
+
+	s.currentUserID = user.ID
+	return s.currentUserID, nil
+}
+
+// AuthorID returns the author to attribute posts to: the configured author_id,
+// or the authenticated user when none is configured
+func (s *WordPressService) AuthorID() (int, error) {
+	if s.authorID > 0 {
+		return s.authorID, nil
+	}
+	return s.GetCurrentUserID()
+}
+
 func (s *WordPressService) TestConnection() error {
 	log.Printf("Testing WordPress API connection to: %s", s.baseURL)
 
 	resp, err := s.makeRequest("GET", "/wp/v2/users/me", nil)
 	if err != nil {
-		return fmt.Errorf("connection test failed: %v", err)
+		return fmt.Errorf("connection test failed: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -634,12 +960,59 @@ func (s *WordPressService) GetNavMenus() ([]*WordPressMenu, error) {
 	return []*WordPressMenu{}, nil
 }
 
+// APIError is returned when the WordPress REST API responds with an error status
+type APIError struct {
+	StatusCode int
+	Body       string
+	Retries    int // Times the request was retried before this response
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// IsNotFoundError reports whether err is a WordPress API 404 response
+func IsNotFoundError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// ErrAuthenticationFailed ends a run when WordPress rejects the configured credentials
+var ErrAuthenticationFailed = errors.New("WordPress authentication failed — check the username and application password")
+
+// IsAuthError reports whether err, anywhere in its chain, is a WordPress API 401 response
+func IsAuthError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized
+}
+
+// IsTransientError reports whether err, anywhere in its chain, is a failure worth retrying:
+// a WordPress or Google API rate limit or server error, or a network error
+func IsTransientError(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	var googleErr *googleapi.Error
+	if errors.As(err, &googleErr) {
+		return googleErr.Code == http.StatusTooManyRequests || googleErr.Code >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// WasRetried reports whether err, anywhere in its chain, is a WordPress API error whose
+// request was already retried, so retrying the caller as well would only repeat the wait
+func WasRetried(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Retries > 0
+}
+
 func (s *WordPressService) makeRequest(method, endpoint string, body []byte) (*http.Response, error) {
 	url := s.baseURL + "/wp-json" + endpoint
 
 	// Log HTTP request with payload
-	l := logger.Get()
-	op := l.StartOperation("wordpress_http_request")
+	op := s.startOperation("wordpress_http_request")
 	op.WithContext("http_method", method)
 	op.WithContext("http_url", url)
 	op.WithContext("http_endpoint", endpoint)
@@ -650,30 +1023,39 @@ func (s *WordPressService) makeRequest(method, endpoint string, body []byte) (*h
 		op.WithContext("http_request_payload_size", len(body))
 	}
 
-	var bodyReader io.Reader
-	if body != nil {
-		bodyReader = bytes.NewReader(body)
-	}
+	debug.Printf("WordPress API Request - Method: %s, URL: %s, Auth Mode: %s", method, url, s.authMode)
 
-	req, err := http.NewRequest(method, url, bodyReader)
-	if err != nil {
-		op.Fail("Failed to create HTTP request", err)
-		return nil, fmt.Errorf("failed to create request: %v", err)
+	if method != "GET" && s.DryRun() {
+		op.WithContext("dry_run", true)
+		op.Complete(fmt.Sprintf("Dry run: skipped %s %s", method, endpoint))
+		return nil, ErrDryRun
 	}
 
-	req.Header.Set("Authorization", s.authHeader)
-
-	debug.Printf("WordPress API Request - Method: %s, URL: %s, Auth Header: %s", method, url, s.authHeader)
-
+	contentType := ""
 	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
+		contentType = "application/json"
+	}
+
+	resp, err := s.doRequest(method, url, contentType, body)
+	// Rate limits and server errors are retried with backoff; other failures are final.
+	// POSTs are only retried when the server refused them, see isRetriableRequest
+	retries := 0
+	for attempt := 1; err == nil && isRetriableRequest(method, resp) && attempt <= s.maxRetries; attempt++ {
+		retries = attempt
+		delay := retryDelay(resp, attempt, s.retryBaseDelay)
+		resp.Body.Close()
+		op.WithContext("retry_attempts", attempt)
+		op.WithContext("retry_last_status_code", resp.StatusCode)
+		debug.Printf("WordPress API %s %s answered %d, retry %d/%d in %v", method, endpoint, resp.StatusCode, attempt, s.maxRetries, delay)
+		if err = SleepContext(s.ctx, delay); err != nil {
+			break
+		}
+		resp, err = s.doRequest(method, url, contentType, body)
 	}
-
-	resp, err := s.client.Do(req)
 	if err != nil {
 		op.WithContext("http_status_code", 0)
 		op.Fail("HTTP request failed", err)
-		return nil, fmt.Errorf("failed to make request: %v", err)
+		return nil, err
 	}
 	
 	// Log response status
@@ -684,7 +1066,7 @@ func (s *WordPressService) makeRequest(method, endpoint string, body []byte) (*h
 		bodyBytes, _ := io.ReadAll(resp.Body)
 		op.WithContext("http_response_body", string(bodyBytes))
 		op.Fail(fmt.Sprintf("WordPress API Error - Status: %d", resp.StatusCode), fmt.Errorf("%s", string(bodyBytes)))
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(bodyBytes), Retries: retries}
 	}
 
 	// Complete the operation successfully
@@ -694,13 +1076,13 @@ func (s *WordPressService) makeRequest(method, endpoint string, body []byte) (*h
 }
 
 func (s *WordPressService) GetCategoryIDsByNames(year string,categoryNames []string) ([]int, error) {
-	l := logger.Get()
-	op := l.StartOperation("wordpress_get_category_ids")
+	op := s.startOperation("wordpress_get_category_ids")
 	op.WithContext("year", year)
 	op.WithContext("category_names", categoryNames)
 	
 	var categoryIDs []int
 	foundCount := 0
+	createdCount := 0
 	notFoundCount := 0
 
 	for _, categoryName := range categoryNames {
@@ -709,6 +1091,19 @@ func (s *WordPressService) GetCategoryIDsByNames(year string,categoryNames []str
 			continue
 		}
 
+		if mappedID, mapped, err := s.mappedCategoryID(categoryName); mapped {
+			if err != nil {
+				op.Warn(&logger.WideEvent{
+					Message: fmt.Sprintf("Failed to resolve mapped category for section '%s': %v", categoryName, err),
+				})
+				notFoundCount++
+				continue
+			}
+			categoryIDs = appendUniqueIDs(categoryIDs, mappedID)
+			foundCount++
+			continue
+		}
+
 		categories, err := s.SearchCategories(year)
 		if err != nil {
 			op.Warn(&logger.WideEvent{
@@ -718,26 +1113,20 @@ func (s *WordPressService) GetCategoryIDsByNames(year string,categoryNames []str
 			continue
 		}
 
-		var foundCategory *WordPressCategory
-		for _, category := range categories {
-			if strings.Contains(strings.ToLower(category.Name), strings.ReplaceAll(strings.ToLower(categoryName), "" , "-")) {
-				foundCategory = category
-				break
+		foundCategory := selectCategory(categories, categoryName, year)
+		if foundCategory == nil && s.createMissingCategories {
+			created, err := s.createMissingCategory(categoryName, year)
+			if err != nil {
+				op.Warn(&logger.WideEvent{
+					Message: fmt.Sprintf("Failed to create missing category '%s': %v", categoryName, err),
+				})
+			} else {
+				foundCategory = created
+				createdCount++
 			}
 		}
-
-		if foundCategory == nil {
-			for _, category := range categories {
-				if strings.Contains(strings.ToLower(category.Name), strings.ToLower(strings.TrimSpace(categoryName))) {
-					foundCategory = category
-					break
-				}
-			}
-		}
-
 		if foundCategory != nil {
-			categoryIDs = append(categoryIDs, foundCategory.ID)
-			categoryIDs = append(categoryIDs, foundCategory.Parent)
+			categoryIDs = appendUniqueIDs(categoryIDs, foundCategory.ID, foundCategory.Parent)
 			foundCount++
 		} else {
 			notFoundCount++
@@ -745,6 +1134,7 @@ func (s *WordPressService) GetCategoryIDsByNames(year string,categoryNames []str
 	}
 
 	op.WithContext("found_categories", foundCount)
+	op.WithContext("created_categories", createdCount)
 	op.WithContext("not_found_categories", notFoundCount)
 	op.WithContext("total_category_ids", len(categoryIDs))
 	op.Complete(fmt.Sprintf("Found %d categories, %d not found", foundCount, notFoundCount))
@@ -752,6 +1142,84 @@ func (s *WordPressService) GetCategoryIDsByNames(year string,categoryNames []str
 	return categoryIDs, nil
 }
 
+// createMissingCategory creates the category for a section not found for year, named
+// "<section> <year>" so the year search finds it next time and placed under the
+// category named after the year when one exists. Creation is serialized and the search
+// repeated first, so films processed concurrently do not create duplicates
+func (s *WordPressService) createMissingCategory(name, year string) (*WordPressCategory, error) {
+	s.categoryMu.Lock()
+	defer s.categoryMu.Unlock()
+
+	categories, err := s.SearchCategories(year)
+	if err != nil {
+		return nil, err
+	}
+	if category := selectCategory(categories, name, year); category != nil {
+		return category, nil
+	}
+
+	parent := 0
+	categoryName := strings.TrimSpace(name)
+	if year != "" {
+		categoryName += " " + year
+		for _, category := range categories {
+			if strings.TrimSpace(category.Name) == year {
+				parent = category.ID
+				break
+			}
+		}
+	}
+	return s.CreateCategory(categoryName, parent)
+}
+
+// selectCategory picks the category best matching name for the given year. An exact
+// name match wins, then "<name> <year>", then a name containing both; a loose
+// containment match is used last and never one that names a different year
+func selectCategory(categories []*WordPressCategory, name, year string) *WordPressCategory {
+	want := strings.ToLower(strings.TrimSpace(name))
+	if want == "" {
+		return nil
+	}
+
+	var best *WordPressCategory
+	bestScore := 0
+	for _, category := range categories {
+		have := strings.ToLower(strings.TrimSpace(category.Name))
+		score := 0
+		switch {
+		case have == want:
+			score = 4
+		case year != "" && have == want+" "+year:
+			score = 3
+		case year != "" && strings.Contains(have, want) && strings.Contains(have, year):
+			score = 2
+		case strings.Contains(have, want):
+			if otherYear := categoryYear.FindString(have); otherYear != "" && otherYear != year {
+				continue
+			}
+			score = 1
+		}
+		if score > bestScore {
+			best = category
+			bestScore = score
+		}
+	}
+	return best
+}
+
+var categoryYear = regexp.MustCompile(`\b(19|20)\d{2}\b`)
+
+// appendUniqueIDs appends the non-zero IDs not already present in ids
+func appendUniqueIDs(ids []int, newIDs ...int) []int {
+	for _, id := range newIDs {
+		if id == 0 || slices.Contains(ids, id) {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
 func ParseCategoryString(categoryString string) []string {
 	if categoryString == "" {
 		return []string{}
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"excentrico-tools-go/internal/debug"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// WordPress authentication modes
+const (
+	AuthModeBasic  = "basic"  // Application password sent as Basic auth (default)
+	AuthModeCookie = "cookie" // Login via wp-login.php and send the REST nonce
+	AuthModeJWT    = "jwt"    // Token from the JWT Authentication plugin
+)
+
+const jwtTokenEndpoint = "/jwt-auth/v1/token"
+
+// usesSessionAuth reports whether the service authenticates with a login session
+// that can expire and must be refreshed
+func (s *WordPressService) usesSessionAuth() bool {
+	return s.authMode == AuthModeCookie || s.authMode == AuthModeJWT
+}
+
+// applyAuth attaches the credentials for the configured auth mode to the request
+func (s *WordPressService) applyAuth(req *http.Request) {
+	s.authMu.Lock()
+	token := s.sessionToken
+	s.authMu.Unlock()
+
+	switch s.authMode {
+	case AuthModeCookie:
+		// Session cookies are attached by the client's cookie jar
+		req.Header.Set("X-WP-Nonce", token)
+	case AuthModeJWT:
+		req.Header.Set("Authorization", "Bearer "+token)
+	default:
+		req.Header.Set("Authorization", s.authHeader)
+	}
+}
+
+// ensureAuthenticated logs in if a session auth mode is in use and no session exists yet
+func (s *WordPressService) ensureAuthenticated() error {
+	if !s.usesSessionAuth() {
+		return nil
+	}
+
+	s.authMu.Lock()
+	hasToken := s.sessionToken != ""
+	s.authMu.Unlock()
+
+	if hasToken {
+		return nil
+	}
+	return s.authenticate()
+}
+
+// authenticate obtains a fresh session for the configured auth mode
+func (s *WordPressService) authenticate() error {
+	s.authMu.Lock()
+	defer s.authMu.Unlock()
+
+	var token string
+	var err error
+	switch s.authMode {
+	case AuthModeCookie:
+		token, err = s.loginWithCookie()
+	case AuthModeJWT:
+		token, err = s.loginWithJWT()
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	s.sessionToken = token
+	debug.Printf("WordPress %s authentication succeeded for user %s", s.authMode, s.username)
+	return nil
+}
+
+// loginWithCookie logs in through wp-login.php and returns the REST API nonce
+// that must accompany cookie-authenticated requests
+func (s *WordPressService) loginWithCookie() (string, error) {
+	form := url.Values{}
+	form.Set("log", s.username)
+	form.Set("pwd", s.password)
+	form.Set("rememberme", "forever")
+	form.Set("redirect_to", s.baseURL+"/wp-admin/")
+
+	req, err := http.NewRequestWithContext(s.ctx, "POST", s.baseURL+"/wp-login.php", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create login request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{Name: "wordpress_test_cookie", Value: "WP%20Cookie%20check"})
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("login request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	loggedIn := false
+	if baseURL, err := url.Parse(s.baseURL); err == nil {
+		for _, cookie := range s.client.Jar.Cookies(baseURL) {
+			if strings.HasPrefix(cookie.Name, "wordpress_logged_in_") {
+				loggedIn = true
+				break
+			}
+		}
+	}
+	if !loggedIn {
+		return "", fmt.Errorf("login failed for user %s: no session cookie returned", s.username)
+	}
+
+	nonceReq, err := http.NewRequestWithContext(s.ctx, "GET", s.baseURL+"/wp-admin/admin-ajax.php?action=rest-nonce", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create nonce request: %v", err)
+	}
+
+	nonceResp, err := s.client.Do(nonceReq)
+	if err != nil {
+		return "", fmt.Errorf("nonce request failed: %v", err)
+	}
+	defer nonceResp.Body.Close()
+
+	body, err := io.ReadAll(nonceResp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read nonce response: %v", err)
+	}
+
+	nonce := strings.TrimSpace(string(body))
+	if nonceResp.StatusCode != http.StatusOK || nonce == "" || nonce == "0" {
+		return "", fmt.Errorf("failed to obtain REST nonce (status %d)", nonceResp.StatusCode)
+	}
+
+	return nonce, nil
+}
+
+// loginWithJWT requests a bearer token from the JWT Authentication plugin
+func (s *WordPressService) loginWithJWT() (string, error) {
+	payload, err := json.Marshal(map[string]string{
+		"username": s.username,
+		"password": s.password,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal token request: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(s.ctx, "POST", s.baseURL+"/wp-json"+jwtTokenEndpoint, bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("token request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var tokenResp struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", fmt.Errorf("failed to decode token response: %v", err)
+	}
+	if tokenResp.Token == "" {
+		return "", fmt.Errorf("token response did not contain a token")
+	}
+
+	return tokenResp.Token, nil
+}
+
+// doRequest sends an authenticated request to the given URL
+// When a session auth mode is in use, a 401 triggers one re-login and retry
+func (s *WordPressService) doRequest(method, url, contentType string, body []byte) (*http.Response, error) {
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	return s.doRequestWithHeader(method, url, header, body)
+}
+
+// doRequestWithHeader is doRequest with arbitrary request headers
+func (s *WordPressService) doRequestWithHeader(method, url string, header http.Header, body []byte) (*http.Response, error) {
+	if err := s.ensureAuthenticated(); err != nil {
+		return nil, fmt.Errorf("authentication failed: %v", err)
+	}
+
+	resp, err := s.sendRequest(method, url, header, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized && s.usesSessionAuth() {
+		resp.Body.Close()
+		debug.Printf("WordPress session rejected for %s %s, re-authenticating", method, url)
+		if err := s.authenticate(); err != nil {
+			return nil, fmt.Errorf("re-authentication failed: %v", err)
+		}
+		return s.sendRequest(method, url, header, body)
+	}
+
+	return resp, nil
+}
+
+func (s *WordPressService) sendRequest(method, url string, header http.Header, body []byte) (*http.Response, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(s.ctx, method, url, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	s.applyAuth(req)
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	// The slot is held until the response body is closed, since the server is still
+	// sending it until then
+	if !s.acquireRequestSlot() {
+		return nil, fmt.Errorf("failed to make request: %v", s.ctx.Err())
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		s.releaseRequestSlot()
+		return nil, fmt.Errorf("failed to make request: %v", err)
+	}
+	if s.requestSlots != nil {
+		resp.Body = &slotBody{ReadCloser: resp.Body, release: s.releaseRequestSlot}
+	}
+
+	return resp, nil
+}
+
+// acquireRequestSlot blocks until the number of in-flight WordPress requests
+// is below the configured limit. It reports false when the context ended first
+func (s *WordPressService) acquireRequestSlot() bool {
+	if s.requestSlots == nil {
+		return true
+	}
+	select {
+	case s.requestSlots <- struct{}{}:
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+func (s *WordPressService) releaseRequestSlot() {
+	if s.requestSlots != nil {
+		<-s.requestSlots
+	}
+}
+
+// slotBody is a response body that gives its request slot back when closed
+type slotBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *slotBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"excentrico-tools-go/internal/config"
+)
+
+func TestRequestLimiterCeiling(t *testing.T) {
+	const requests = 8
+	tests := []struct {
+		name    string
+		limit   int
+		wantMax int32 // Most requests the server may see at once
+	}{
+		{"one at a time", 1, 1},
+		{"default", 4, 4},
+		{"unlimited", -1, requests},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var active, peak atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := active.Add(1)
+				defer active.Add(-1)
+				for {
+					old := peak.Load()
+					if n <= old || peak.CompareAndSwap(old, n) {
+						break
+					}
+				}
+				time.Sleep(30 * time.Millisecond)
+				w.Write([]byte(`{}`))
+			}))
+			defer srv.Close()
+
+			wp := NewWordPressService(context.Background(), config.WordPressConfig{
+				BaseURL:               srv.URL,
+				MaxConcurrentRequests: tt.limit,
+			})
+			var wg sync.WaitGroup
+			for i := 0; i < requests; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					resp, err := wp.makeRequest("GET", "/wp/v2/users/me", nil)
+					if err != nil {
+						t.Errorf("makeRequest: %v", err)
+						return
+					}
+					io.Copy(io.Discard, resp.Body)
+					resp.Body.Close()
+				}()
+			}
+			wg.Wait()
+
+			if got := peak.Load(); got > tt.wantMax {
+				t.Errorf("server saw %d requests at once, want at most %d", got, tt.wantMax)
+			}
+			if tt.limit < 0 && peak.Load() < 2 {
+				t.Errorf("server saw %d requests at once without a limit, want them in parallel", peak.Load())
+			}
+		})
+	}
+}
+
+func TestRequestSlotHeldUntilBodyClosed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	wp := NewWordPressService(context.Background(), config.WordPressConfig{
+		BaseURL:               srv.URL,
+		MaxConcurrentRequests: 1,
+	})
+	first, err := wp.makeRequest("GET", "/wp/v2/users/me", nil)
+	if err != nil {
+		t.Fatalf("makeRequest: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		resp, err := wp.makeRequest("GET", "/wp/v2/users/me", nil)
+		if err == nil {
+			resp.Body.Close()
+		}
+		done <- err
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("second request ran while the first response was still open")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	first.Body.Close()
+	// Closing twice must not free a second slot
+	first.Body.Close()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("second request: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("second request still blocked after the first response was closed")
+	}
+	if n := len(wp.requestSlots); n != 0 {
+		t.Errorf("%d request slots still held after every response was closed", n)
+	}
+}
+
+func TestRequestSlotWaitEndsWithContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wp := NewWordPressService(ctx, config.WordPressConfig{
+		BaseURL:               srv.URL,
+		MaxConcurrentRequests: 1,
+	})
+	first, err := wp.makeRequest("GET", "/wp/v2/users/me", nil)
+	if err != nil {
+		t.Fatalf("makeRequest: %v", err)
+	}
+	defer first.Body.Close()
+
+	cancel()
+	if _, err := wp.makeRequest("GET", "/wp/v2/users/me", nil); err == nil {
+		t.Fatal("request waiting for a slot succeeded after the context was cancelled")
+	}
+}
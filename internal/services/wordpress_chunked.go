@@ -0,0 +1,162 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// chunkAttempts is how many times a single part of a chunked upload is sent before
+// the upload fails; parts carry their byte range, so resending one is safe
+const chunkAttempts = 3
+
+// byteRange is an inclusive range of bytes of an uploaded file
+type byteRange struct {
+	Start, End int64
+}
+
+// chunkRanges splits a file of size bytes into consecutive parts of at most chunkSize
+// bytes. A non-positive chunkSize yields a single part
+func chunkRanges(size, chunkSize int64) []byteRange {
+	if size <= 0 {
+		return nil
+	}
+	if chunkSize <= 0 || chunkSize >= size {
+		return []byteRange{{Start: 0, End: size - 1}}
+	}
+	ranges := make([]byteRange, 0, (size+chunkSize-1)/chunkSize)
+	for start := int64(0); start < size; start += chunkSize {
+		end := min(start+chunkSize, size) - 1
+		ranges = append(ranges, byteRange{Start: start, End: end})
+	}
+	return ranges
+}
+
+// usesChunkedUpload reports whether a file of size bytes is sent in parts to the
+// chunked upload endpoint instead of a single POST to /wp/v2/media
+func (s *WordPressService) usesChunkedUpload(size int64) bool {
+	return s.chunkedUploadEndpoint != "" && size > s.chunkedUploadThreshold
+}
+
+// uploadMediaInChunks sends file to the chunked upload endpoint as ranged parts sharing
+// an upload ID. The endpoint assembles the parts and answers the last one with the
+// created media, as /wp/v2/media would; title and alt text are then set on it
+func (s *WordPressService) uploadMediaInChunks(file *os.File, fileName string, size int64, title, altText string) (*WordPressMedia, error) {
+	op := s.startOperation("wordpress_upload_media_chunked")
+	url := s.baseURL + "/wp-json" + s.chunkedUploadEndpoint
+	op.WithContext("http_url", url)
+	op.WithContext("http_request_payload_file_name", fileName)
+	op.WithContext("http_request_payload_file_size", size)
+
+	uploadID, err := newUploadID()
+	if err != nil {
+		op.Fail("Failed to create upload ID", err)
+		return nil, err
+	}
+	op.WithContext("upload_id", uploadID)
+
+	ranges := chunkRanges(size, s.chunkSize)
+	op.WithContext("chunk_count", len(ranges))
+
+	var media *WordPressMedia
+	for i, part := range ranges {
+		buf := make([]byte, part.End-part.Start+1)
+		if _, err := file.ReadAt(buf, part.Start); err != nil && err != io.EOF {
+			op.Fail("Failed to read file part", err)
+			return nil, fmt.Errorf("failed to read bytes %d-%d of %s: %v", part.Start, part.End, fileName, err)
+		}
+
+		header := http.Header{}
+		header.Set("Content-Type", "application/octet-stream")
+		header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+		header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", part.Start, part.End, size))
+		header.Set("X-Upload-ID", uploadID)
+
+		last := i == len(ranges)-1
+		var body []byte
+		var status int
+		for attempt := 1; attempt <= chunkAttempts; attempt++ {
+			body, status, err = s.sendChunk(url, header, buf)
+			if err == nil && (status == http.StatusCreated || (!last && status >= 200 && status < 300)) {
+				break
+			}
+			if err == nil {
+				err = fmt.Errorf("part %d/%d failed with status %d: %s", i+1, len(ranges), status, string(body))
+			}
+			op.WithContext("failed_part", i+1)
+			op.WithContext("part_attempts", attempt)
+		}
+		if err != nil {
+			op.Fail("Chunked upload failed", err)
+			return nil, err
+		}
+
+		if last {
+			media = &WordPressMedia{}
+			if err := json.Unmarshal(body, media); err != nil {
+				op.Fail("Failed to decode response", err)
+				return nil, fmt.Errorf("failed to decode response: %v", err)
+			}
+		}
+	}
+
+	fields := make(map[string]any)
+	if title != "" {
+		fields["title"] = title
+	}
+	if altText != "" {
+		fields["alt_text"] = altText
+	}
+	if len(fields) > 0 {
+		updated, err := s.UpdateMedia(media.ID, fields)
+		if err != nil {
+			op.Fail("Failed to set media title and alt text", err)
+			return nil, fmt.Errorf("uploaded media %d but failed to set its title and alt text: %v", media.ID, err)
+		}
+		media = updated
+	}
+
+	op.WithWordPress(0, media.ID, "")
+	op.Complete(fmt.Sprintf("Uploaded %s in %d parts (ID: %d)", fileName, len(ranges), media.ID))
+	return media, nil
+}
+
+// sendChunk posts one part and returns the response body and status code
+func (s *WordPressService) sendChunk(url string, header http.Header, part []byte) ([]byte, int, error) {
+	resp, err := s.doRequestWithHeader("POST", url, header, part)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to read response: %v", err)
+	}
+	return body, resp.StatusCode, nil
+}
+
+// newUploadID returns a random ID grouping the parts of one chunked upload
+func newUploadID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate upload ID: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// normalizeChunkedUploadEndpoint returns the REST route of the chunked upload endpoint
+// relative to /wp-json, with a leading slash; empty disables chunked uploads
+func normalizeChunkedUploadEndpoint(endpoint string) string {
+	endpoint = strings.Trim(strings.TrimSpace(endpoint), "/")
+	endpoint = strings.TrimPrefix(endpoint, "wp-json/")
+	if endpoint == "" {
+		return ""
+	}
+	return "/" + endpoint
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"excentrico-tools-go/internal/config"
+)
+
+func TestChunkRanges(t *testing.T) {
+	tests := []struct {
+		size, chunkSize int64
+		want            []byteRange
+	}{
+		{size: 0, chunkSize: 4, want: nil},
+		{size: 10, chunkSize: 0, want: []byteRange{{0, 9}}},
+		{size: 10, chunkSize: 10, want: []byteRange{{0, 9}}},
+		{size: 10, chunkSize: 20, want: []byteRange{{0, 9}}},
+		{size: 10, chunkSize: 5, want: []byteRange{{0, 4}, {5, 9}}},
+		{size: 10, chunkSize: 4, want: []byteRange{{0, 3}, {4, 7}, {8, 9}}},
+		{size: 10, chunkSize: 9, want: []byteRange{{0, 8}, {9, 9}}},
+	}
+	for _, tt := range tests {
+		if got := chunkRanges(tt.size, tt.chunkSize); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("chunkRanges(%d, %d) = %v, want %v", tt.size, tt.chunkSize, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeChunkedUploadEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint, want string
+	}{
+		{"", ""},
+		{" / ", ""},
+		{"excentrico/v1/media-chunks", "/excentrico/v1/media-chunks"},
+		{"/excentrico/v1/media-chunks/", "/excentrico/v1/media-chunks"},
+		{"/wp-json/excentrico/v1/media-chunks", "/excentrico/v1/media-chunks"},
+	}
+	for _, tt := range tests {
+		if got := normalizeChunkedUploadEndpoint(tt.endpoint); got != tt.want {
+			t.Errorf("normalizeChunkedUploadEndpoint(%q) = %q, want %q", tt.endpoint, got, tt.want)
+		}
+	}
+}
+
+// chunkServer assembles ranged parts posted to /wp-json/excentrico/v1/media-chunks and
+// accepts single multipart uploads to /wp-json/wp/v2/media
+type chunkServer struct {
+	mu          sync.Mutex
+	failParts   int              // parts answered with a 500 before any is accepted
+	ranges      []string         // Content-Range of every accepted part
+	uploadIDs   map[string]bool  // X-Upload-ID of every accepted part
+	assembled   []byte           // bytes of the accepted parts, in order
+	singlePosts int              // uploads to /wp/v2/media
+	updates     []map[string]any // bodies of media updates
+}
+
+func (cs *chunkServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+
+	switch r.URL.Path {
+	case "/wp-json/excentrico/v1/media-chunks":
+		if cs.failParts > 0 {
+			cs.failParts--
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var start, end, size int
+		fmt.Sscanf(r.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &size)
+		if start != len(cs.assembled) || end-start+1 != len(body) {
+			http.Error(w, "unexpected range "+r.Header.Get("Content-Range"), http.StatusBadRequest)
+			return
+		}
+		cs.ranges = append(cs.ranges, r.Header.Get("Content-Range"))
+		cs.uploadIDs[r.Header.Get("X-Upload-ID")] = true
+		cs.assembled = append(cs.assembled, body...)
+		if end < size-1 {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": 55}`))
+	case "/wp-json/wp/v2/media":
+		cs.singlePosts++
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": 77}`))
+	case "/wp-json/wp/v2/media/55":
+		var fields map[string]any
+		json.Unmarshal(body, &fields)
+		cs.updates = append(cs.updates, fields)
+		w.Write([]byte(`{"id": 55, "alt_text": "Cartel"}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestUploadMediaFromFileChunking(t *testing.T) {
+	const mb = 1 << 20
+	tests := []struct {
+		name       string
+		endpoint   string
+		size       int
+		failParts  int
+		wantID     int
+		wantRanges []string // nil expects a single POST
+		wantErr    bool
+	}{
+		{name: "small file", endpoint: "excentrico/v1/media-chunks", size: mb, wantID: 77},
+		{name: "no chunked endpoint", size: 3 * mb, wantID: 77},
+		{
+			name: "large file", endpoint: "excentrico/v1/media-chunks", size: 3*mb + 10, wantID: 55,
+			wantRanges: []string{
+				fmt.Sprintf("bytes 0-%d/%d", 2*mb-1, 3*mb+10),
+				fmt.Sprintf("bytes %d-%d/%d", 2*mb, 3*mb+9, 3*mb+10),
+			},
+		},
+		{
+			name: "failed part resent", endpoint: "excentrico/v1/media-chunks", size: 3 * mb, failParts: 2, wantID: 55,
+			wantRanges: []string{
+				fmt.Sprintf("bytes 0-%d/%d", 2*mb-1, 3*mb),
+				fmt.Sprintf("bytes %d-%d/%d", 2*mb, 3*mb-1, 3*mb),
+			},
+		},
+		{name: "part failing every attempt", endpoint: "excentrico/v1/media-chunks", size: 3 * mb, failParts: chunkAttempts, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := bytes.Repeat([]byte("cartel"), tt.size/6+1)[:tt.size]
+			path := filepath.Join(t.TempDir(), "cartel.jpg")
+			if err := os.WriteFile(path, content, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cs := &chunkServer{failParts: tt.failParts, uploadIDs: make(map[string]bool)}
+			srv := httptest.NewServer(http.HandlerFunc(cs.serveHTTP))
+			defer srv.Close()
+			wp := NewWordPressService(context.Background(), config.WordPressConfig{
+				BaseURL:                  srv.URL,
+				ChunkedUploadEndpoint:    tt.endpoint,
+				ChunkedUploadThresholdMB: 2,
+				ChunkSizeMB:              2,
+			})
+
+			media, err := wp.UploadMediaFromFile(path, "Cartel", "Cartel")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("UploadMediaFromFile() = %+v, want an error", media)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UploadMediaFromFile: %v", err)
+			}
+			if media.ID != tt.wantID {
+				t.Errorf("media ID = %d, want %d", media.ID, tt.wantID)
+			}
+
+			if tt.wantRanges == nil {
+				if cs.singlePosts != 1 || len(cs.ranges) != 0 {
+					t.Errorf("%d single uploads and %d parts, want one single upload", cs.singlePosts, len(cs.ranges))
+				}
+				return
+			}
+			if cs.singlePosts != 0 {
+				t.Errorf("single uploads = %d, want 0", cs.singlePosts)
+			}
+			if !reflect.DeepEqual(cs.ranges, tt.wantRanges) {
+				t.Errorf("parts %q, want %q", cs.ranges, tt.wantRanges)
+			}
+			if len(cs.uploadIDs) != 1 {
+				t.Errorf("parts used %d upload IDs, want 1", len(cs.uploadIDs))
+			}
+			if !bytes.Equal(cs.assembled, content) {
+				t.Errorf("assembled %d bytes differing from the %d-byte file", len(cs.assembled), len(content))
+			}
+			wantUpdates := []map[string]any{{"title": "Cartel", "alt_text": "Cartel"}}
+			if !reflect.DeepEqual(cs.updates, wantUpdates) {
+				t.Errorf("media updates = %v, want %v", cs.updates, wantUpdates)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"excentrico-tools-go/internal/debug"
+)
+
+// DefaultPostType is the Divi project post type films are published as
+const DefaultPostType = "project"
+
+// postEndpoint returns the REST route of the configured post type
+func (s *WordPressService) postEndpoint() string {
+	return "/wp/v2/" + s.postRestBase
+}
+
+// VerifyPostType looks up the configured post type through the types route and adopts its
+// REST base, so post requests use the route the site actually exposes
+func (s *WordPressService) VerifyPostType() error {
+	resp, err := s.makeRequest("GET", "/wp/v2/types/"+url.PathEscape(s.postType), nil)
+	if err != nil {
+		if IsNotFoundError(err) {
+			return fmt.Errorf("post type %q is not registered in the WordPress REST API", s.postType)
+		}
+		return fmt.Errorf("failed to verify post type %q: %v", s.postType, err)
+	}
+	defer resp.Body.Close()
+
+	var postType struct {
+		Slug     string `json:"slug"`
+		RestBase string `json:"rest_base"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&postType); err != nil {
+		return fmt.Errorf("failed to decode post type response: %v", err)
+	}
+	if postType.RestBase != "" && postType.RestBase != s.postRestBase {
+		debug.Printf("Post type %s uses REST base %s", s.postType, postType.RestBase)
+		s.postRestBase = postType.RestBase
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRetryBaseDelay is the wait before the first retry of a rate-limited or failed
+// WordPress API request when none is configured
+const DefaultRetryBaseDelay = time.Second
+
+// maxRetryAfter caps the wait requested by a Retry-After header, so a misconfigured
+// host cannot stall the run for hours
+const maxRetryAfter = 2 * time.Minute
+
+// isRetriableStatus reports whether a WordPress API response status is worth retrying:
+// a rate limit or a server error. Other 4xx responses fail immediately
+func isRetriableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// isRetriableRequest reports whether a request answered by resp may be sent again. A POST
+// may have created a post, term or media before the server failed, so sending it again
+// could duplicate it; it is only retried when the server refused it outright: a 429, or a
+// 503 with a Retry-After header. Other methods are retried on any retriable status
+func isRetriableRequest(method string, resp *http.Response) bool {
+	if method != http.MethodPost {
+		return isRetriableStatus(resp.StatusCode)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusServiceUnavailable:
+		_, ok := retryAfter(resp)
+		return ok
+	}
+	return false
+}
+
+// retryDelay returns the wait before retry number attempt (starting at 1) of a request
+// answered by resp. A Retry-After header, in seconds or as an HTTP date, is honored;
+// otherwise the base delay doubles per attempt, with jitter spreading the wait over
+// its upper half so concurrent films do not retry in lockstep
+func retryDelay(resp *http.Response, attempt int, base time.Duration) time.Duration {
+	if wait, ok := retryAfter(resp); ok {
+		return wait
+	}
+	backoff := base << (attempt - 1)
+	if backoff <= 0 {
+		return 0
+	}
+	half := backoff / 2
+	return half + rand.N(half+1)
+}
+
+// SleepContext waits for d, returning early with the context error when ctx is done
+func SleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryAfter parses the Retry-After header of resp
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(value); err == nil {
+		wait = time.Until(at)
+	} else {
+		return 0, false
+	}
+	return min(max(wait, 0), maxRetryAfter), true
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"excentrico-tools-go/internal/config"
+)
+
+func TestIsRetriableRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		status     int
+		retryAfter string
+		want       bool
+	}{
+		{"get rate limited", http.MethodGet, http.StatusTooManyRequests, "", true},
+		{"get server error", http.MethodGet, http.StatusInternalServerError, "", true},
+		{"get bad gateway", http.MethodGet, http.StatusBadGateway, "", true},
+		{"get not found", http.MethodGet, http.StatusNotFound, "", false},
+		{"delete server error", http.MethodDelete, http.StatusInternalServerError, "", true},
+		{"post rate limited", http.MethodPost, http.StatusTooManyRequests, "", true},
+		{"post unavailable with retry-after", http.MethodPost, http.StatusServiceUnavailable, "3", true},
+		{"post unavailable without retry-after", http.MethodPost, http.StatusServiceUnavailable, "", false},
+		{"post server error", http.MethodPost, http.StatusInternalServerError, "", false},
+		{"post gateway timeout", http.MethodPost, http.StatusGatewayTimeout, "", false},
+		{"post bad request", http.MethodPost, http.StatusBadRequest, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			if tt.retryAfter != "" {
+				resp.Header.Set("Retry-After", tt.retryAfter)
+			}
+			if got := isRetriableRequest(tt.method, resp); got != tt.want {
+				t.Errorf("isRetriableRequest(%s, %d) = %v, want %v", tt.method, tt.status, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMakeRequestRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		status       int // Answer to the first request, or to all of them when wantRetried
+		retryAfter   string
+		wantRequests int32
+		wantErr      bool
+		wantRetried  bool
+	}{
+		{"get recovers from a server error", http.MethodGet, http.StatusInternalServerError, "", 2, false, false},
+		{"post is not resent after a server error", http.MethodPost, http.StatusInternalServerError, "", 1, true, false},
+		{"post is resent after a rate limit", http.MethodPost, http.StatusTooManyRequests, "", 2, false, false},
+		{"post is resent when the server asks to", http.MethodPost, http.StatusServiceUnavailable, "0", 2, false, false},
+		{"get gives up after max retries", http.MethodGet, http.StatusBadGateway, "", 3, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// The second request succeeds, except when every attempt should fail
+				if n := requests.Add(1); n == 1 || tt.wantRetried {
+					if tt.retryAfter != "" {
+						w.Header().Set("Retry-After", tt.retryAfter)
+					}
+					w.WriteHeader(tt.status)
+					return
+				}
+				w.Write([]byte(`{}`))
+			}))
+			defer srv.Close()
+
+			wp := NewWordPressService(context.Background(), config.WordPressConfig{
+				BaseURL:        srv.URL,
+				MaxRetries:     2,
+				RetryBaseDelay: "1ms",
+			})
+			resp, err := wp.makeRequest(tt.method, "/wp/v2/media", []byte(`{}`))
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("makeRequest error %v, want error: %v", err, tt.wantErr)
+			}
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("server got %d requests, want %d", got, tt.wantRequests)
+			}
+			if got := WasRetried(err); got != tt.wantRetried {
+				t.Errorf("WasRetried = %v, want %v", got, tt.wantRetried)
+			}
+		})
+	}
+}
+
+func TestGetMediaRetriesUploadedMedia(t *testing.T) {
+	defer func(backoff time.Duration) { mediaLookupBackoff = backoff }(mediaLookupBackoff)
+	mediaLookupBackoff = time.Millisecond
+
+	tests := []struct {
+		name        string
+		uploaded    bool // the media was uploaded by this service
+		retries     int
+		notFound    int32 // 404s answered before the media is found
+		wantErr     bool
+		wantLookups int32
+	}{
+		{name: "found at once", uploaded: true, retries: 3, wantLookups: 1},
+		{name: "found after 404s", uploaded: true, retries: 3, notFound: 2, wantLookups: 3},
+		{name: "found on the last attempt", uploaded: true, retries: 3, notFound: 3, wantLookups: 4},
+		{name: "still missing after every retry", uploaded: true, retries: 3, notFound: 4, wantErr: true, wantLookups: 4},
+		{name: "retries disabled", uploaded: true, notFound: 1, wantErr: true, wantLookups: 1},
+		{name: "media not uploaded in this run", retries: 3, notFound: 1, wantErr: true, wantLookups: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lookups atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if lookups.Add(1) <= tt.notFound {
+					w.WriteHeader(http.StatusNotFound)
+					w.Write([]byte(`{"code":"rest_post_invalid_id"}`))
+					return
+				}
+				w.Write([]byte(`{"id": 5, "source_url": "https://example.test/uploads/still.jpg"}`))
+			}))
+			defer srv.Close()
+
+			wp := NewWordPressService(context.Background(), config.WordPressConfig{BaseURL: srv.URL, MediaLookupRetries: tt.retries})
+			if tt.uploaded {
+				wp.recordUpload(5)
+			}
+			media, err := wp.GetMedia(5)
+			if tt.wantErr {
+				if !IsNotFoundError(err) {
+					t.Errorf("GetMedia() error %v, want a 404", err)
+				}
+			} else if err != nil || media.ID != 5 {
+				t.Errorf("GetMedia() = %+v, %v, want media 5", media, err)
+			}
+			if got := lookups.Load(); got != tt.wantLookups {
+				t.Errorf("lookups = %d, want %d", got, tt.wantLookups)
+			}
+		})
+	}
+}
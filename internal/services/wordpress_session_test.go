@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"excentrico-tools-go/internal/config"
+)
+
+// sessionServer is a WordPress site that issues cookie and JWT sessions and accepts
+// only the latest one, so tests can expire a session between requests
+type sessionServer struct {
+	mu        sync.Mutex
+	logins    int
+	current   string // The only session value protected routes accept
+	failLogin bool
+	rejectAll bool // reject every session, as when the user lacks REST access
+}
+
+func (s *sessionServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/wp-login.php":
+		if s.failLogin || r.FormValue("pwd") != "secret" {
+			w.WriteHeader(http.StatusOK) // wp-login.php shows the form again on failure
+			return
+		}
+		s.logins++
+		s.current = fmt.Sprintf("nonce-%d", s.logins)
+		http.SetCookie(w, &http.Cookie{Name: "wordpress_logged_in_abc", Value: "editor", Path: "/"})
+		w.WriteHeader(http.StatusOK)
+	case r.URL.Path == "/wp-admin/admin-ajax.php" && r.URL.Query().Get("action") == "rest-nonce":
+		if s.failLogin {
+			io.WriteString(w, "0") // admin-ajax.php answers 0 without a valid session
+			return
+		}
+		io.WriteString(w, s.current)
+	case r.URL.Path == "/wp-json"+jwtTokenEndpoint:
+		var creds map[string]string
+		json.NewDecoder(r.Body).Decode(&creds)
+		if s.failLogin || creds["password"] != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			io.WriteString(w, `{"code":"jwt_auth_failed"}`)
+			return
+		}
+		s.logins++
+		s.current = fmt.Sprintf("token-%d", s.logins)
+		json.NewEncoder(w).Encode(map[string]string{"token": s.current})
+	case r.URL.Path == "/wp-json/wp/v2/users/me":
+		presented := r.Header.Get("X-WP-Nonce")
+		if bearer := r.Header.Get("Authorization"); strings.HasPrefix(bearer, "Bearer ") {
+			presented = strings.TrimPrefix(bearer, "Bearer ")
+		}
+		if s.rejectAll || presented == "" || presented != s.current {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"code":"rest_not_logged_in"}`)
+			return
+		}
+		io.WriteString(w, `{"id":7}`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// expire invalidates the current session, as when it times out on the server
+func (s *sessionServer) expire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.current = "expired"
+}
+
+func TestSessionRefreshOn401(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		expire     bool // expire the session between the two requests
+		failLogin  bool // fail logins after the first
+		rejectAll  bool // reject sessions after the first request
+		wantLogins int
+		wantErr    bool
+	}{
+		{name: "cookie session kept", mode: AuthModeCookie, wantLogins: 1},
+		{name: "cookie session refreshed", mode: AuthModeCookie, expire: true, wantLogins: 2},
+		{name: "cookie re-login fails", mode: AuthModeCookie, expire: true, failLogin: true, wantLogins: 1, wantErr: true},
+		{name: "cookie session rejected again", mode: AuthModeCookie, rejectAll: true, wantLogins: 2, wantErr: true},
+		{name: "jwt token kept", mode: AuthModeJWT, wantLogins: 1},
+		{name: "jwt token refreshed", mode: AuthModeJWT, expire: true, wantLogins: 2},
+		{name: "jwt re-login fails", mode: AuthModeJWT, expire: true, failLogin: true, wantLogins: 1, wantErr: true},
+		{name: "jwt token rejected again", mode: AuthModeJWT, rejectAll: true, wantLogins: 2, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &sessionServer{}
+			srv := httptest.NewServer(server)
+			defer srv.Close()
+			wp := NewWordPressService(context.Background(), config.WordPressConfig{
+				BaseURL:  srv.URL,
+				AuthMode: tt.mode,
+				Username: "editor",
+				Password: "secret",
+			})
+
+			get := func() error {
+				resp, err := wp.makeRequest("GET", "/wp/v2/users/me", nil)
+				if err != nil {
+					return err
+				}
+				resp.Body.Close()
+				return nil
+			}
+			if err := get(); err != nil {
+				t.Fatalf("first request: %v", err)
+			}
+			if tt.expire {
+				server.expire()
+			}
+			server.mu.Lock()
+			server.failLogin = tt.failLogin
+			server.rejectAll = tt.rejectAll
+			server.mu.Unlock()
+
+			err := get()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("second request error %v, want error: %v", err, tt.wantErr)
+			}
+			if server.logins != tt.wantLogins {
+				t.Errorf("%d logins, want %d", server.logins, tt.wantLogins)
+			}
+		})
+	}
+}
+
+func TestBasicAuthDoesNotRelogin(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if user, pass, ok := r.BasicAuth(); !ok || user != "editor" || pass != "app-password" {
+			t.Errorf("request without the application password: %q %q", user, pass)
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		io.WriteString(w, `{"code":"rest_not_logged_in"}`)
+	}))
+	defer srv.Close()
+	wp := NewWordPressService(context.Background(), config.WordPressConfig{
+		BaseURL:             srv.URL,
+		Username:            "editor",
+		ApplicationPassword: "app-password",
+	})
+
+	_, err := wp.makeRequest("GET", "/wp/v2/users/me", nil)
+	if !IsAuthError(err) {
+		t.Fatalf("makeRequest error %v, want a 401", err)
+	}
+	if requests != 1 {
+		t.Errorf("%d requests, want the 401 returned without a retry", requests)
+	}
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"excentrico-tools-go/internal/debug"
+)
+
+// DefaultCategoryTaxonomy is the Divi project category taxonomy
+const DefaultCategoryTaxonomy = "project_category"
+
+// DefaultTagTaxonomy is the WordPress core tag taxonomy
+const DefaultTagTaxonomy = "post_tag"
+
+// categoryPostField is the JSON key WordPressPost.Categories is marshalled under
+const categoryPostField = "project_category"
+
+// tagPostField is the JSON key WordPressPost.Tags is marshalled under
+const tagPostField = "tags"
+
+// defaultRestBase returns the REST base WordPress registers for a taxonomy slug until
+// the taxonomies route confirms it; core tags are the one taxonomy routed under another name
+func defaultRestBase(taxonomy string) string {
+	if taxonomy == DefaultTagTaxonomy {
+		return tagPostField
+	}
+	return taxonomy
+}
+
+// tagEndpoint returns the REST route of the configured tag taxonomy
+func (s *WordPressService) tagEndpoint() string {
+	return "/wp/v2/" + s.tagRestBase
+}
+
+// categoryEndpoint returns the REST route of the configured category taxonomy
+func (s *WordPressService) categoryEndpoint() string {
+	return "/wp/v2/" + s.categoryRestBase
+}
+
+// categoryQueryEndpoint returns the category route with the given query parameters
+func (s *WordPressService) categoryQueryEndpoint(query url.Values) string {
+	endpoint := s.categoryEndpoint()
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	return endpoint
+}
+
+// VerifyCategoryTaxonomy looks up the configured taxonomy through the taxonomies route
+// and adopts its REST base, so category requests and post payloads use the route the
+// site actually exposes
+func (s *WordPressService) VerifyCategoryTaxonomy() error {
+	restBase, err := s.taxonomyRestBase("category", s.categoryTaxonomy)
+	if err != nil {
+		return err
+	}
+	if restBase != "" && restBase != s.categoryRestBase {
+		debug.Printf("Category taxonomy %s uses REST base %s", s.categoryTaxonomy, restBase)
+		s.categoryRestBase = restBase
+	}
+	return nil
+}
+
+// VerifyTagTaxonomy does the same as VerifyCategoryTaxonomy for the tag taxonomy
+func (s *WordPressService) VerifyTagTaxonomy() error {
+	restBase, err := s.taxonomyRestBase("tag", s.tagTaxonomy)
+	if err != nil {
+		return err
+	}
+	if restBase != "" && restBase != s.tagRestBase {
+		debug.Printf("Tag taxonomy %s uses REST base %s", s.tagTaxonomy, restBase)
+		s.tagRestBase = restBase
+	}
+	return nil
+}
+
+// taxonomyRestBase fetches the REST base of a taxonomy from the taxonomies route;
+// kind names the taxonomy's role in error messages
+func (s *WordPressService) taxonomyRestBase(kind, taxonomySlug string) (string, error) {
+	resp, err := s.makeRequest("GET", "/wp/v2/taxonomies/"+url.PathEscape(taxonomySlug), nil)
+	if err != nil {
+		if IsNotFoundError(err) {
+			return "", fmt.Errorf("%s taxonomy %q is not registered in the WordPress REST API", kind, taxonomySlug)
+		}
+		return "", fmt.Errorf("failed to verify %s taxonomy %q: %v", kind, taxonomySlug, err)
+	}
+	defer resp.Body.Close()
+
+	var taxonomy struct {
+		Slug     string `json:"slug"`
+		RestBase string `json:"rest_base"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&taxonomy); err != nil {
+		return "", fmt.Errorf("failed to decode taxonomy response: %v", err)
+	}
+	return taxonomy.RestBase, nil
+}
+
+// encodePost marshals a post, sending its categories and tags under their taxonomies' REST bases
+func (s *WordPressService) encodePost(post *WordPressPost) ([]byte, error) {
+	jsonData, err := json.Marshal(post)
+	renameCategories := s.categoryRestBase != categoryPostField && post.Categories != nil
+	renameTags := s.tagRestBase != tagPostField && post.Tags != nil
+	if err != nil || (!renameCategories && !renameTags) {
+		return jsonData, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(jsonData, &fields); err != nil {
+		return nil, err
+	}
+	if renameCategories {
+		fields[s.categoryRestBase] = fields[categoryPostField]
+		delete(fields, categoryPostField)
+	}
+	if renameTags {
+		fields[s.tagRestBase] = fields[tagPostField]
+		delete(fields, tagPostField)
+	}
+	return json.Marshal(fields)
+}
+
+// normalizeSectionCategories keys the configured section mapping by trimmed, lowercased section
+func normalizeSectionCategories(mapping map[string]string) map[string]string {
+	if len(mapping) == 0 {
+		return nil
+	}
+	normalized := make(map[string]string, len(mapping))
+	for section, category := range mapping {
+		normalized[strings.ToLower(strings.TrimSpace(section))] = strings.TrimSpace(category)
+	}
+	return normalized
+}
+
+// mappedCategoryID resolves a section through the configured mapping. The mapped value
+// is a category ID or slug; mapped is false for sections left to the category search
+func (s *WordPressService) mappedCategoryID(section string) (id int, mapped bool, err error) {
+	category, mapped := s.sectionCategories[strings.ToLower(strings.TrimSpace(section))]
+	if !mapped {
+		return 0, false, nil
+	}
+	if id, err := strconv.Atoi(category); err == nil {
+		if id <= 0 {
+			return 0, true, fmt.Errorf("invalid category ID %d", id)
+		}
+		return id, true, nil
+	}
+
+	categories, err := s.SearchCategoriesWithParams(map[string]string{"slug": category})
+	if err != nil {
+		return 0, true, err
+	}
+	if len(categories) == 0 {
+		return 0, true, fmt.Errorf("no category with slug %q", category)
+	}
+	return categories[0].ID, true, nil
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"excentrico-tools-go/internal/config"
+)
+
+func TestCategoryMethodsShareEndpoint(t *testing.T) {
+	tests := []struct {
+		name      string
+		taxonomy  string // configured category taxonomy; empty uses the default
+		restBase  string // REST base the site reports for it; empty when not registered
+		verify    bool
+		wantRoute string
+		wantErr   bool // VerifyCategoryTaxonomy fails
+	}{
+		{name: "default taxonomy", wantRoute: "/wp/v2/project_category"},
+		{name: "configured taxonomy", taxonomy: "seccion", wantRoute: "/wp/v2/seccion"},
+		{name: "verified default taxonomy", restBase: "project_category", verify: true, wantRoute: "/wp/v2/project_category"},
+		{name: "verified REST base differs from the slug", taxonomy: "seccion", restBase: "secciones", verify: true, wantRoute: "/wp/v2/secciones"},
+		{name: "taxonomy not registered", taxonomy: "seccion", verify: true, wantRoute: "/wp/v2/seccion", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var routes []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				route := strings.TrimPrefix(r.URL.Path, "/wp-json")
+				w.Header().Set("Content-Type", "application/json")
+				if slug, ok := strings.CutPrefix(route, "/wp/v2/taxonomies/"); ok {
+					if tt.restBase == "" {
+						w.WriteHeader(http.StatusNotFound)
+						w.Write([]byte(`{"code":"rest_taxonomy_invalid"}`))
+						return
+					}
+					json.NewEncoder(w).Encode(map[string]string{"slug": slug, "rest_base": tt.restBase})
+					return
+				}
+				mu.Lock()
+				routes = append(routes, r.Method+" "+route)
+				mu.Unlock()
+				if r.Method == http.MethodPost {
+					w.WriteHeader(http.StatusCreated)
+					w.Write([]byte(`{"id":5,"name":"Cortos 2025"}`))
+					return
+				}
+				w.Header().Set("X-WP-TotalPages", "1")
+				w.Write([]byte(`[{"id":4,"name":"Largos 2025"}]`))
+			}))
+			defer srv.Close()
+			wp := NewWordPressService(context.Background(), config.WordPressConfig{BaseURL: srv.URL, CategoryTaxonomy: tt.taxonomy})
+
+			if tt.verify {
+				if err := wp.VerifyCategoryTaxonomy(); (err != nil) != tt.wantErr {
+					t.Fatalf("VerifyCategoryTaxonomy() = %v, want error: %v", err, tt.wantErr)
+				}
+			}
+
+			calls := map[string]func() error{
+				"GetCategories":    func() error { _, err := wp.GetCategories(); return err },
+				"SearchCategories": func() error { _, err := wp.SearchCategories("2025"); return err },
+				"SearchCategoriesWithParams": func() error {
+					_, err := wp.SearchCategoriesWithParams(map[string]string{"slug": "cortos-2025"})
+					return err
+				},
+				"CreateCategory": func() error { _, err := wp.CreateCategory("Cortos 2025", 0); return err },
+			}
+			for method, call := range calls {
+				mu.Lock()
+				routes = nil
+				mu.Unlock()
+				if err := call(); err != nil {
+					t.Fatalf("%s: %v", method, err)
+				}
+				mu.Lock()
+				for _, route := range routes {
+					if _, path, _ := strings.Cut(route, " "); path != tt.wantRoute {
+						t.Errorf("%s requested %s, want %s", method, route, tt.wantRoute)
+					}
+				}
+				if len(routes) == 0 {
+					t.Errorf("%s sent no request", method)
+				}
+				mu.Unlock()
+			}
+		})
+	}
+}
+
+func TestConfiguredTaxonomies(t *testing.T) {
+	tests := []struct {
+		name             string
+		categoryTaxonomy string
+		tagTaxonomy      string
+		verify           map[string]string // taxonomy slug -> REST base reported by the site; nil skips verification
+		wantCategoryKey  string            // route and payload field for categories
+		wantTagKey       string            // route and payload field for tags
+	}{
+		{name: "defaults", wantCategoryKey: "project_category", wantTagKey: "tags"},
+		{name: "core tags by slug", tagTaxonomy: "post_tag", wantCategoryKey: "project_category", wantTagKey: "tags"},
+		{name: "custom taxonomies", categoryTaxonomy: "seccion", tagTaxonomy: "etiqueta_social", wantCategoryKey: "seccion", wantTagKey: "etiqueta_social"},
+		{
+			name:             "verified REST bases",
+			categoryTaxonomy: "seccion",
+			tagTaxonomy:      "etiqueta_social",
+			verify:           map[string]string{"seccion": "secciones", "etiqueta_social": "etiquetas"},
+			wantCategoryKey:  "secciones",
+			wantTagKey:       "etiquetas",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var routes []string
+			var postBody map[string]any
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				route := strings.TrimPrefix(r.URL.Path, "/wp-json")
+				w.Header().Set("Content-Type", "application/json")
+				if slug, ok := strings.CutPrefix(route, "/wp/v2/taxonomies/"); ok {
+					json.NewEncoder(w).Encode(map[string]string{"slug": slug, "rest_base": tt.verify[slug]})
+					return
+				}
+				routes = append(routes, route)
+				if route == "/wp/v2/project" {
+					json.NewDecoder(r.Body).Decode(&postBody)
+					w.Write([]byte(`{"id": 12, "status": "draft"}`))
+					return
+				}
+				w.Write([]byte(`[]`))
+			}))
+			defer srv.Close()
+			wp := NewWordPressService(context.Background(), config.WordPressConfig{
+				BaseURL:          srv.URL,
+				CategoryTaxonomy: tt.categoryTaxonomy,
+				TagTaxonomy:      tt.tagTaxonomy,
+			})
+			if tt.verify != nil {
+				if err := wp.VerifyCategoryTaxonomy(); err != nil {
+					t.Fatalf("VerifyCategoryTaxonomy: %v", err)
+				}
+				if err := wp.VerifyTagTaxonomy(); err != nil {
+					t.Fatalf("VerifyTagTaxonomy: %v", err)
+				}
+			}
+
+			if _, err := wp.SearchTags("festival"); err != nil {
+				t.Fatalf("SearchTags: %v", err)
+			}
+			if _, err := wp.SearchCategoriesWithParams(map[string]string{"slug": "cortos"}); err != nil {
+				t.Fatalf("SearchCategoriesWithParams: %v", err)
+			}
+			if _, err := wp.CreatePost(&WordPressPost{Status: "draft", Categories: []int{3}, Tags: []int{4}}); err != nil {
+				t.Fatalf("CreatePost: %v", err)
+			}
+
+			wantRoutes := []string{"/wp/v2/" + tt.wantTagKey, "/wp/v2/" + tt.wantCategoryKey, "/wp/v2/project"}
+			if !reflect.DeepEqual(routes, wantRoutes) {
+				t.Errorf("requested %v, want %v", routes, wantRoutes)
+			}
+			wantTerms := map[string]any{tt.wantCategoryKey: []any{3.0}, tt.wantTagKey: []any{4.0}}
+			for key, want := range wantTerms {
+				if got := postBody[key]; !reflect.DeepEqual(got, want) {
+					t.Errorf("post payload %s = %v, want %v", key, got, want)
+				}
+			}
+			for _, key := range []string{"project_category", "tags"} {
+				if _, wanted := wantTerms[key]; !wanted {
+					if got, ok := postBody[key]; ok {
+						t.Errorf("post payload still has %s = %v", key, got)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+
+	"excentrico-tools-go/internal/config"
+)
+
+func TestPostPermalink(t *testing.T) {
+	tests := []struct {
+		name string
+		post WordPressPost
+		want string
+	}{
+		{
+			name: "published",
+			post: WordPressPost{Status: "publish", Slug: "film", Link: "https://example.test/project/film/", PermalinkTemplate: "https://example.test/project/%postname%/"},
+			want: "https://example.test/project/film/",
+		},
+		{
+			name: "draft",
+			post: WordPressPost{Status: "draft", Slug: "film", Link: "https://example.test/?post_type=project&p=12", PermalinkTemplate: "https://example.test/project/%postname%/"},
+			want: "https://example.test/project/film/",
+		},
+		{
+			name: "hierarchical draft",
+			post: WordPressPost{Status: "draft", Slug: "film", PermalinkTemplate: "https://example.test/project/%pagename%/"},
+			want: "https://example.test/project/film/",
+		},
+		{
+			name: "draft without a slug yet",
+			post: WordPressPost{Status: "draft", GeneratedSlug: "film-2", PermalinkTemplate: "https://example.test/project/%postname%/"},
+			want: "https://example.test/project/film-2/",
+		},
+		{
+			name: "draft on plain permalinks",
+			post: WordPressPost{Status: "draft", Slug: "film", Link: "https://example.test/?post_type=project&p=12", PermalinkTemplate: "https://example.test/?post_type=project&p=12"},
+			want: "",
+		},
+		{
+			name: "draft without the edit context",
+			post: WordPressPost{Status: "draft", Slug: "film", Link: "https://example.test/?post_type=project&p=12"},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.post.Permalink(); got != tt.want {
+				t.Errorf("Permalink() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreatePostRequestsEditContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("context") != "edit" {
+			w.Write([]byte(`{"id": 12, "status": "draft", "slug": "film", "link": "https://example.test/?post_type=project&p=12"}`))
+			return
+		}
+		w.Write([]byte(`{"id": 12, "status": "draft", "slug": "film", "link": "https://example.test/?post_type=project&p=12", "permalink_template": "https://example.test/project/%postname%/"}`))
+	}))
+	defer srv.Close()
+
+	wp := NewWordPressService(context.Background(), config.WordPressConfig{BaseURL: srv.URL})
+	post, err := wp.CreatePost(&WordPressPost{Title: WordPressRenderedField{Rendered: "Film"}, Status: "draft"})
+	if err != nil {
+		t.Fatalf("CreatePost: %v", err)
+	}
+	if got, want := post.Permalink(), "https://example.test/project/film/"; got != want {
+		t.Errorf("created post permalink %q, want %q", got, want)
+	}
+}
+
+func TestSelectCategory(t *testing.T) {
+	categories := []*WordPressCategory{
+		{ID: 1, Name: "2025"},
+		{ID: 2, Name: "Cortos 2024"},
+		{ID: 3, Name: "Cortos 2025"},
+		{ID: 4, Name: "Cortos"},
+		{ID: 5, Name: "Largos (2025)"},
+		{ID: 6, Name: "Cortos animados"},
+		{ID: 7, Name: "Documentales 2024"},
+		{ID: 8, Name: "Documentales clásicos"},
+	}
+	tests := []struct {
+		name    string
+		section string
+		year    string
+		want    int // 0 when no category matches
+	}{
+		{name: "exact name wins over the year", section: "Cortos", year: "2025", want: 4},
+		{name: "section and year", section: "Cortos 2025", year: "2025", want: 3},
+		{name: "case and space insensitive", section: " cortos 2025 ", year: "2025", want: 3},
+		{name: "name containing section and year", section: "Largos", year: "2025", want: 5},
+		{name: "loose match never names another year", section: "Documentales", year: "2025", want: 8},
+		{name: "loose match without a year filter", section: "animados", want: 6},
+		{name: "no match", section: "Videoclips", year: "2025", want: 0},
+		{name: "blank section", section: " ", year: "2025", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := 0
+			if category := selectCategory(categories, tt.section, tt.year); category != nil {
+				got = category.ID
+			}
+			if got != tt.want {
+				t.Errorf("selectCategory(%q, %q) = %d, want %d", tt.section, tt.year, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetCategoryIDsByNames(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-WP-TotalPages", "1")
+		w.Write([]byte(`[
+			{"id": 10, "name": "2025"},
+			{"id": 11, "name": "Cortos 2025", "parent": 10},
+			{"id": 12, "name": "Cortos 2024", "parent": 9},
+			{"id": 13, "name": "Largos 2025", "parent": 10}
+		]`))
+	}))
+	defer srv.Close()
+	wp := NewWordPressService(context.Background(), config.WordPressConfig{BaseURL: srv.URL})
+
+	tests := []struct {
+		name     string
+		sections []string
+		want     []int
+	}{
+		{name: "category and its parent", sections: []string{"Cortos"}, want: []int{11, 10}},
+		{name: "sections sharing a parent", sections: []string{"Cortos", "Largos"}, want: []int{11, 10, 13}},
+		{name: "repeated section", sections: []string{"Cortos", "cortos 2025"}, want: []int{11, 10}},
+		{name: "unknown and blank sections", sections: []string{"", "Videoclips", "Largos"}, want: []int{13, 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := wp.GetCategoryIDsByNames("2025", tt.sections)
+			if err != nil {
+				t.Fatalf("GetCategoryIDsByNames: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetCategoryIDsByNames(%q) = %v, want %v", tt.sections, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSectionCategories(t *testing.T) {
+	var mu sync.Mutex
+	var searches int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-WP-TotalPages", "1")
+		switch r.URL.Query().Get("slug") {
+		case "":
+			mu.Lock()
+			searches++
+			mu.Unlock()
+			w.Write([]byte(`[
+				{"id": 10, "name": "2025"},
+				{"id": 11, "name": "Cortos 2025", "parent": 10},
+				{"id": 14, "name": "Documentales 2025", "parent": 10}
+			]`))
+		case "largos-especial":
+			w.Write([]byte(`[{"id": 30, "name": "Largos especial", "slug": "largos-especial"}]`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}))
+	defer srv.Close()
+	wp := NewWordPressService(context.Background(), config.WordPressConfig{
+		BaseURL: srv.URL,
+		SectionCategories: map[string]string{
+			"Cortos":     "21",
+			" Largos ":   "largos-especial",
+			"Videoclips": "sin-categoria",
+			"Animación":  "0",
+		},
+	})
+
+	tests := []struct {
+		name         string
+		sections     []string
+		want         []int
+		wantSearches int
+	}{
+		{name: "mapped to an ID", sections: []string{"cortos"}, want: []int{21}},
+		{name: "mapped to a slug", sections: []string{"Largos"}, want: []int{30}},
+		{name: "unmapped section is searched", sections: []string{"Documentales"}, want: []int{14, 10}, wantSearches: 1},
+		{name: "mapped and unmapped sections", sections: []string{"Cortos", "Documentales"}, want: []int{21, 14, 10}, wantSearches: 1},
+		{name: "unknown slug", sections: []string{"Videoclips"}},
+		{name: "invalid ID", sections: []string{"Animación"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			searches = 0
+			got, err := wp.GetCategoryIDsByNames("2025", tt.sections)
+			if err != nil {
+				t.Fatalf("GetCategoryIDsByNames: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetCategoryIDsByNames(%q) = %v, want %v", tt.sections, got, tt.want)
+			}
+			if searches != tt.wantSearches {
+				t.Errorf("category searches = %d, want %d", searches, tt.wantSearches)
+			}
+		})
+	}
+}
+
+func TestGetMediaByParent(t *testing.T) {
+	// Post 7 has more attachments than fit on one page
+	parents := map[int]int{}
+	for id := 1; id <= 150; id++ {
+		parents[id] = 7
+	}
+	parents[200], parents[201] = 8, 8
+
+	var mu sync.Mutex
+	var pages int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path != "/wp-json/wp/v2/media" || query.Get("parent") == "" {
+			http.NotFound(w, r)
+			return
+		}
+		mu.Lock()
+		pages++
+		mu.Unlock()
+		parent, _ := strconv.Atoi(query.Get("parent"))
+		perPage, _ := strconv.Atoi(query.Get("per_page"))
+		page, _ := strconv.Atoi(query.Get("page"))
+
+		var ids []int
+		for id := 1; id <= 201; id++ {
+			if parents[id] == parent && parent != 0 {
+				ids = append(ids, id)
+			}
+		}
+		totalPages := (len(ids) + perPage - 1) / perPage
+		w.Header().Set("X-WP-TotalPages", strconv.Itoa(totalPages))
+		items := []*WordPressMedia{}
+		for i := (page - 1) * perPage; i < len(ids) && i < page*perPage; i++ {
+			items = append(items, &WordPressMedia{ID: ids[i]})
+		}
+		json.NewEncoder(w).Encode(items)
+	}))
+	defer srv.Close()
+	wp := NewWordPressService(context.Background(), config.WordPressConfig{BaseURL: srv.URL})
+
+	tests := []struct {
+		name      string
+		postID    int
+		wantCount int
+		wantFirst int
+		wantPages int
+	}{
+		{name: "several pages", postID: 7, wantCount: 150, wantFirst: 1, wantPages: 2},
+		{name: "single page", postID: 8, wantCount: 2, wantFirst: 200, wantPages: 1},
+		{name: "no attachments", postID: 9, wantPages: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages = 0
+			media, err := wp.GetMediaByParent(tt.postID)
+			if err != nil {
+				t.Fatalf("GetMediaByParent(%d): %v", tt.postID, err)
+			}
+			if len(media) != tt.wantCount {
+				t.Fatalf("GetMediaByParent(%d) returned %d media, want %d", tt.postID, len(media), tt.wantCount)
+			}
+			if tt.wantCount > 0 && media[0].ID != tt.wantFirst {
+				t.Errorf("first media ID %d, want %d", media[0].ID, tt.wantFirst)
+			}
+			for _, item := range media {
+				if parents[item.ID] != tt.postID {
+					t.Errorf("media %d is attached to post %d, not %d", item.ID, parents[item.ID], tt.postID)
+				}
+			}
+			if pages != tt.wantPages {
+				t.Errorf("pages requested = %d, want %d", pages, tt.wantPages)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+// Package memsql is an in-memory database/sql driver that understands the handful of
+// statements TursoService issues against its metadata table, so services can be tested
+// without a libsql server
+package memsql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DriverName is the name the driver is registered under
+const DriverName = "memsql"
+
+type row struct {
+	filmID, kind, data string
+	updatedAt          string
+}
+
+// store holds the metadata table of one database
+type store struct {
+	mu    sync.Mutex
+	rows  map[[2]string]*row
+	clock int
+}
+
+type memDriver struct {
+	mu     sync.Mutex
+	stores map[string]*store
+}
+
+var (
+	drv  = &memDriver{stores: make(map[string]*store)}
+	next atomic.Int64
+)
+
+func init() {
+	sql.Register(DriverName, drv)
+}
+
+// Open returns a handle to a new, empty database
+func Open() (*sql.DB, error) {
+	return sql.Open(DriverName, fmt.Sprintf("db%d", next.Add(1)))
+}
+
+func (d *memDriver) Open(name string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	s, ok := d.stores[name]
+	if !ok {
+		s = &store{rows: make(map[[2]string]*row)}
+		d.stores[name] = s
+	}
+	return &conn{store: s}, nil
+}
+
+type conn struct {
+	store *store
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{store: c.store, query: strings.Join(strings.Fields(query), " ")}, nil
+}
+
+func (c *conn) Close() error { return nil }
+func (c *conn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("memsql: transactions are not supported")
+}
+
+type stmt struct {
+	store *store
+	query string
+}
+
+func (s *stmt) Close() error  { return nil }
+func (s *stmt) NumInput() int { return -1 }
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(s.query, "CREATE "):
+		return driver.RowsAffected(0), nil
+	case strings.HasPrefix(s.query, "INSERT INTO metadata"):
+		if len(args) != 3 {
+			return nil, fmt.Errorf("memsql: insert takes 3 arguments, got %d", len(args))
+		}
+		key := [2]string{asString(args[0]), asString(args[1])}
+		st.clock++
+		stamp := time.Date(2024, 1, 1, 0, 0, st.clock, 0, time.UTC).Format("2006-01-02 15:04:05")
+		if existing, ok := st.rows[key]; ok {
+			existing.data = asString(args[2])
+			existing.updatedAt = stamp
+		} else {
+			st.rows[key] = &row{filmID: key[0], kind: key[1], data: asString(args[2]), updatedAt: stamp}
+		}
+		return driver.RowsAffected(1), nil
+	case s.query == "DELETE FROM metadata WHERE film_id = ? AND type = ?":
+		key := [2]string{asString(args[0]), asString(args[1])}
+		if _, ok := st.rows[key]; !ok {
+			return driver.RowsAffected(0), nil
+		}
+		delete(st.rows, key)
+		return driver.RowsAffected(1), nil
+	}
+	return nil, fmt.Errorf("memsql: unsupported statement %q", s.query)
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	switch s.query {
+	case "SELECT data FROM metadata WHERE film_id = ? AND type = ?":
+		r, ok := st.rows[[2]string{asString(args[0]), asString(args[1])}]
+		if !ok {
+			return &rows{columns: []string{"data"}}, nil
+		}
+		return &rows{columns: []string{"data"}, values: [][]driver.Value{{r.data}}}, nil
+	case "SELECT COUNT(*) FROM metadata":
+		return &rows{columns: []string{"count"}, values: [][]driver.Value{{int64(len(st.rows))}}}, nil
+	case "SELECT COUNT(DISTINCT film_id) FROM metadata":
+		films := make(map[string]bool)
+		for _, r := range st.rows {
+			films[r.filmID] = true
+		}
+		return &rows{columns: []string{"count"}, values: [][]driver.Value{{int64(len(films))}}}, nil
+	case "SELECT type, COUNT(*) FROM metadata GROUP BY type":
+		counts := make(map[string]int64)
+		for _, r := range st.rows {
+			counts[r.kind]++
+		}
+		result := &rows{columns: []string{"type", "count"}}
+		for _, kind := range sortedKeys(counts) {
+			result.values = append(result.values, []driver.Value{kind, counts[kind]})
+		}
+		return result, nil
+	case "SELECT MIN(updated_at), MAX(updated_at) FROM metadata":
+		var oldest, newest driver.Value
+		for _, r := range st.rows {
+			if oldest == nil || r.updatedAt < oldest.(string) {
+				oldest = r.updatedAt
+			}
+			if newest == nil || r.updatedAt > newest.(string) {
+				newest = r.updatedAt
+			}
+		}
+		return &rows{columns: []string{"min", "max"}, values: [][]driver.Value{{oldest, newest}}}, nil
+	case "SELECT film_id FROM metadata WHERE type = ? ORDER BY film_id":
+		var ids []string
+		for _, r := range st.rows {
+			if r.kind == asString(args[0]) {
+				ids = append(ids, r.filmID)
+			}
+		}
+		sort.Strings(ids)
+		result := &rows{columns: []string{"film_id"}}
+		for _, id := range ids {
+			result.values = append(result.values, []driver.Value{id})
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("memsql: unsupported query %q", s.query)
+}
+
+type rows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *rows) Columns() []string { return r.columns }
+func (r *rows) Close() error      { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func asString(v driver.Value) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return fmt.Sprint(v)
+}
+
+func sortedKeys(m map[string]int64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -3,7 +3,13 @@ package utils
 import (
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
 )
 
 // SanitizeFilename cleans a filename to be safe for filesystem use
@@ -28,17 +34,94 @@ func SanitizeFilename(name string) string {
 	return sanitized
 }
 
-// GetOptimizedImagePath returns the path for the optimized version of an image
-func GetOptimizedImagePath(originalPath string) string {
+// optimizedSuffixes maps an image_config.format to the suffix of its optimized files
+var optimizedSuffixes = map[string]string{
+	"jpeg": "_web.jpg",
+	"png":  "_web.png",
+	"webp": "_web.webp",
+}
+
+// OutputFormats selects the format optimized images are written in: Format for every
+// image, unless Rules maps its lowercase input extension (e.g. ".png") to another one so
+// PNG logos can stay lossless while photos are JPEG. The zero value writes every image as
+// JPEG; unknown formats are ignored, as the configuration is validated on load
+type OutputFormats struct {
+	Format string
+	Rules  map[string]string
+}
+
+// defaultFormat returns Format, or "jpeg" when it is unset or unknown
+func (f OutputFormats) defaultFormat() string {
+	if _, ok := optimizedSuffixes[f.Format]; ok {
+		return f.Format
+	}
+	return "jpeg"
+}
+
+// FormatFor returns the format the optimized version of originalPath is written in
+func (f OutputFormats) FormatFor(originalPath string) string {
+	if format, ok := f.Rules[strings.ToLower(filepath.Ext(originalPath))]; ok {
+		if _, known := optimizedSuffixes[format]; known {
+			return format
+		}
+	}
+	return f.defaultFormat()
+}
+
+// Suffixes returns the suffixes optimized images can be written with: the one of the
+// default format followed by those only format rules produce
+func (f OutputFormats) Suffixes() []string {
+	defaultFormat := f.defaultFormat()
+	suffixes := []string{optimizedSuffixes[defaultFormat]}
+	for _, format := range []string{"jpeg", "png", "webp"} {
+		suffix := optimizedSuffixes[format]
+		if format == defaultFormat || slices.Contains(suffixes, suffix) {
+			continue
+		}
+		for _, ruleFormat := range f.Rules {
+			if ruleFormat == format {
+				suffixes = append(suffixes, suffix)
+				break
+			}
+		}
+	}
+	return suffixes
+}
+
+// IsOptimizedImage reports whether a file name carries one of the suffixes optimized
+// images are written with, ignoring case
+func (f OutputFormats) IsOptimizedImage(name string) bool {
+	lower := strings.ToLower(name)
+	for _, suffix := range f.Suffixes() {
+		if strings.HasSuffix(lower, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// OptimizedImagePath returns the path for the optimized version of an image
+func (f OutputFormats) OptimizedImagePath(originalPath string) string {
 	dir := filepath.Dir(originalPath)
 	filename := filepath.Base(originalPath)
 	ext := filepath.Ext(filename)
 	nameWithoutExt := strings.TrimSuffix(filename, ext)
 
-	optimizedFilename := nameWithoutExt + "_web.jpg"
+	optimizedFilename := nameWithoutExt + optimizedSuffixes[f.FormatFor(originalPath)]
 	return filepath.Join(dir, optimizedFilename)
 }
 
+// TrimOptimizedSuffix removes the optimized suffix of any format from a file name, e.g.
+// "still_01_web.png" -> "still_01"; other names are returned unchanged
+func TrimOptimizedSuffix(name string) string {
+	for _, format := range []string{"jpeg", "png", "webp"} {
+		if suffix := optimizedSuffixes[format]; strings.HasSuffix(name, suffix) {
+			return strings.TrimSuffix(name, suffix)
+		}
+	}
+	return name
+}
+
 // IsImageFile checks if a MIME type represents an image file
 func IsImageFile(mimeType string) bool {
 	imageTypes := []string{
@@ -80,3 +163,20 @@ func ExtractFileIDFromURL(url string) string {
 
 	return ""
 }
+
+// ExtractEditionYear extracts the four-digit year from an edition label such as
+// "Excéntrico 2025" or "Excentrico 2025". Returns an empty string if none is found
+func ExtractEditionYear(edition string) string {
+	reg := regexp.MustCompile(`\b(19|20)\d{2}\b`)
+	return reg.FindString(edition)
+}
+
+// FoldAccents lowercases s and strips diacritics so that "Sección" and "SECCION" compare equal
+func FoldAccents(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		folded = s
+	}
+	return strings.ToLower(strings.TrimSpace(folded))
+}
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestOutputFormats(t *testing.T) {
+	tests := []struct {
+		name         string
+		formats      OutputFormats
+		original     string
+		wantPath     string
+		wantSuffixes []string
+	}{
+		{"zero value writes JPEG", OutputFormats{}, "stills/still.png", "stills/still_web.jpg", []string{"_web.jpg"}},
+		{"configured format", OutputFormats{Format: "webp"}, "still.jpg", "still_web.webp", []string{"_web.webp"}},
+		{"unknown format falls back to JPEG", OutputFormats{Format: "gif"}, "still.jpg", "still_web.jpg", []string{"_web.jpg"}},
+		{"rule applies to its extension", OutputFormats{Format: "webp", Rules: map[string]string{".png": "png"}}, "logo.PNG", "logo_web.png", []string{"_web.webp", "_web.png"}},
+		{"rule leaves other extensions", OutputFormats{Format: "webp", Rules: map[string]string{".png": "png"}}, "still.jpg", "still_web.webp", []string{"_web.webp", "_web.png"}},
+		{"JPEG to WebP rule", OutputFormats{Rules: map[string]string{".jpg": "webp"}}, "still.JPG", "still_web.webp", []string{"_web.jpg", "_web.webp"}},
+		{"rule with an unknown format", OutputFormats{Rules: map[string]string{".png": "gif"}}, "logo.png", "logo_web.jpg", []string{"_web.jpg"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.formats.OptimizedImagePath(tt.original); got != filepath.FromSlash(tt.wantPath) {
+				t.Errorf("OptimizedImagePath(%q) = %q, want %q", tt.original, got, tt.wantPath)
+			}
+			if got := tt.formats.Suffixes(); !reflect.DeepEqual(got, tt.wantSuffixes) {
+				t.Errorf("Suffixes() = %v, want %v", got, tt.wantSuffixes)
+			}
+			if !tt.formats.IsOptimizedImage(filepath.Base(tt.wantPath)) {
+				t.Errorf("IsOptimizedImage(%q) = false", filepath.Base(tt.wantPath))
+			}
+		})
+	}
+}
+
+func TestOutputFormatsAreIndependent(t *testing.T) {
+	// Two configurations in one process must not affect each other
+	jpeg := OutputFormats{Format: "jpeg"}
+	webp := OutputFormats{Format: "webp"}
+	if jpeg.IsOptimizedImage("still_web.webp") {
+		t.Error("a JPEG configuration treats _web.webp files as optimized")
+	}
+	if !webp.IsOptimizedImage("still_web.webp") || webp.IsOptimizedImage("still_web.jpg") {
+		t.Error("a WebP configuration does not select only _web.webp files")
+	}
+}
+
+func TestTrimOptimizedSuffix(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"still_01_web.jpg", "still_01"},
+		{"still_01_web.png", "still_01"},
+		{"still_01_web.webp", "still_01"},
+		{"still_01.jpg", "still_01.jpg"},
+		{"web.jpg", "web.jpg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TrimOptimizedSuffix(tt.name); got != tt.want {
+				t.Errorf("TrimOptimizedSuffix(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractEditionYear(t *testing.T) {
+	tests := []struct {
+		edition string
+		want    string
+	}{
+		{"Excéntrico 2025", "2025"},
+		{"Excentrico 2025", "2025"},
+		{"EXCÉNTRICO 2024", "2024"},
+		{"Exce\u0301ntrico 2023", "2023"}, // decomposed accent, as some sheets export it
+		{"  Excéntrico 2025 (online) ", "2025"},
+		{"2019", "2019"},
+		{"Excéntrico", ""},
+		{"Excéntrico 12025", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := ExtractEditionYear(tt.edition); got != tt.want {
+			t.Errorf("ExtractEditionYear(%q) = %q, want %q", tt.edition, got, tt.want)
+		}
+	}
+}
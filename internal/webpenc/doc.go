@@ -0,0 +1,13 @@
+// Package webpenc encodes images as lossy WebP: a single VP8 key frame in a
+// RIFF container. Macroblocks use 16x16 luma and 8x8 chroma prediction with
+// the default token probabilities.
+//
+// The prediction and inverse transforms in reconstruct.go and the tables in
+// tables.go are derived from golang.org/x/image/vp8, so the encoder
+// reconstructs exactly what a decoder sees. Those two files remain under the
+// Go Authors' BSD-style license, included in this directory's LICENSE file.
+//
+// The encoder is compiled only with the webpenc build tag until it has had a
+// review of its own. Without the tag the package is empty, and the image
+// service writes WebP with the cwebp tool from libwebp.
+package webpenc
@@ -0,0 +1,250 @@
+// Copyright 2011 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Derived from golang.org/x/image/vp8 (reconstruct.go, predfunc.go and idct.go),
+// adapted to the encoder's workspace.
+
+//go:build webpenc
+
+package webpenc
+
+// prepareYBR fills the top and left borders of the workspace from the
+// reconstructed planes, as golang.org/x/image/vp8 does.
+func (e *encoder) prepareYBR(mbx, mby int) {
+	if mbx == 0 {
+		for y := 0; y < 17; y++ {
+			e.ybr[y][7] = 0x81
+		}
+		for y := 17; y < 26; y++ {
+			e.ybr[y][7] = 0x81
+			e.ybr[y][23] = 0x81
+		}
+	} else {
+		for y := 0; y < 17; y++ {
+			e.ybr[y][7] = e.ybr[y][7+16]
+		}
+		for y := 17; y < 26; y++ {
+			e.ybr[y][7] = e.ybr[y][15]
+			e.ybr[y][23] = e.ybr[y][31]
+		}
+	}
+	if mby == 0 {
+		for x := 7; x < 28; x++ {
+			e.ybr[0][x] = 0x7f
+		}
+		for x := 7; x < 16; x++ {
+			e.ybr[17][x] = 0x7f
+		}
+		for x := 23; x < 32; x++ {
+			e.ybr[17][x] = 0x7f
+		}
+	} else {
+		for i := 0; i < 16; i++ {
+			e.ybr[0][8+i] = e.ry[(16*mby-1)*e.yStride+16*mbx+i]
+		}
+		for i := 0; i < 8; i++ {
+			e.ybr[17][8+i] = e.rcb[(8*mby-1)*e.cStride+8*mbx+i]
+			e.ybr[17][24+i] = e.rcr[(8*mby-1)*e.cStride+8*mbx+i]
+		}
+	}
+}
+
+func checkTopLeftPred(mbx, mby int, p uint8) uint8 {
+	if p != predDC {
+		return p
+	}
+	if mbx == 0 {
+		if mby == 0 {
+			return predDCTopLeft
+		}
+		return predDCLeft
+	}
+	if mby == 0 {
+		return predDCTop
+	}
+	return predDC
+}
+
+var predFunc8 = [13]func(*encoder, int, int){
+	predDC:        func(e *encoder, y, x int) { e.predDCN(y, x, 8, true, true) },
+	predTM:        func(e *encoder, y, x int) { e.predTMN(y, x, 8) },
+	predVE:        func(e *encoder, y, x int) { e.predVEN(y, x, 8) },
+	predHE:        func(e *encoder, y, x int) { e.predHEN(y, x, 8) },
+	predDCTop:     func(e *encoder, y, x int) { e.predDCN(y, x, 8, false, true) },
+	predDCLeft:    func(e *encoder, y, x int) { e.predDCN(y, x, 8, true, false) },
+	predDCTopLeft: func(e *encoder, y, x int) { e.predDCN(y, x, 8, false, false) },
+}
+
+var predFunc16 = [13]func(*encoder, int, int){
+	predDC:        func(e *encoder, y, x int) { e.predDCN(y, x, 16, true, true) },
+	predTM:        func(e *encoder, y, x int) { e.predTMN(y, x, 16) },
+	predVE:        func(e *encoder, y, x int) { e.predVEN(y, x, 16) },
+	predHE:        func(e *encoder, y, x int) { e.predHEN(y, x, 16) },
+	predDCTop:     func(e *encoder, y, x int) { e.predDCN(y, x, 16, false, true) },
+	predDCLeft:    func(e *encoder, y, x int) { e.predDCN(y, x, 16, true, false) },
+	predDCTopLeft: func(e *encoder, y, x int) { e.predDCN(y, x, 16, false, false) },
+}
+
+// predDCN fills an n x n region with the rounded average of the row above
+// and/or the column left, or 0x80 when neither is available. Like the decoder,
+// "DCTop" is the variant for the top macroblock row and so averages the left column.
+func (e *encoder) predDCN(y, x, n int, useTop, useLeft bool) {
+	sum, count := uint32(0), uint32(0)
+	if useTop {
+		for i := 0; i < n; i++ {
+			sum += uint32(e.ybr[y-1][x+i])
+		}
+		count += uint32(n)
+	}
+	if useLeft {
+		for j := 0; j < n; j++ {
+			sum += uint32(e.ybr[y+j][x-1])
+		}
+		count += uint32(n)
+	}
+	avg := uint8(0x80)
+	if count > 0 {
+		avg = uint8((sum + count/2) / count)
+	}
+	for j := 0; j < n; j++ {
+		for i := 0; i < n; i++ {
+			e.ybr[y+j][x+i] = avg
+		}
+	}
+}
+
+func (e *encoder) predTMN(y, x, n int) {
+	delta0 := -int32(e.ybr[y-1][x-1])
+	for j := 0; j < n; j++ {
+		delta1 := delta0 + int32(e.ybr[y+j][x-1])
+		for i := 0; i < n; i++ {
+			delta2 := delta1 + int32(e.ybr[y-1][x+i])
+			e.ybr[y+j][x+i] = clip8(delta2)
+		}
+	}
+}
+
+func (e *encoder) predVEN(y, x, n int) {
+	for j := 0; j < n; j++ {
+		for i := 0; i < n; i++ {
+			e.ybr[y+j][x+i] = e.ybr[y-1][x+i]
+		}
+	}
+}
+
+func (e *encoder) predHEN(y, x, n int) {
+	for j := 0; j < n; j++ {
+		for i := 0; i < n; i++ {
+			e.ybr[y+j][x+i] = e.ybr[y+j][x-1]
+		}
+	}
+}
+
+func (e *encoder) inverseDCT4(y, x, coeffBase int) {
+	const (
+		c1 = 85627 // 65536 * cos(pi/8) * sqrt(2).
+		c2 = 35468 // 65536 * sin(pi/8) * sqrt(2).
+	)
+	var m [4][4]int32
+	for i := 0; i < 4; i++ {
+		a := int32(e.coeff[coeffBase+0]) + int32(e.coeff[coeffBase+8])
+		b := int32(e.coeff[coeffBase+0]) - int32(e.coeff[coeffBase+8])
+		c := (int32(e.coeff[coeffBase+4])*c2)>>16 - (int32(e.coeff[coeffBase+12])*c1)>>16
+		d := (int32(e.coeff[coeffBase+4])*c1)>>16 + (int32(e.coeff[coeffBase+12])*c2)>>16
+		m[i][0] = a + d
+		m[i][1] = b + c
+		m[i][2] = b - c
+		m[i][3] = a - d
+		coeffBase++
+	}
+	for j := 0; j < 4; j++ {
+		dc := m[0][j] + 4
+		a := dc + m[2][j]
+		b := dc - m[2][j]
+		c := (m[1][j]*c2)>>16 - (m[3][j]*c1)>>16
+		d := (m[1][j]*c1)>>16 + (m[3][j]*c2)>>16
+		e.ybr[y+j][x+0] = clip8(int32(e.ybr[y+j][x+0]) + (a+d)>>3)
+		e.ybr[y+j][x+1] = clip8(int32(e.ybr[y+j][x+1]) + (b+c)>>3)
+		e.ybr[y+j][x+2] = clip8(int32(e.ybr[y+j][x+2]) + (b-c)>>3)
+		e.ybr[y+j][x+3] = clip8(int32(e.ybr[y+j][x+3]) + (a-d)>>3)
+	}
+}
+
+func (e *encoder) inverseDCT4DCOnly(y, x, coeffBase int) {
+	dc := (int32(e.coeff[coeffBase+0]) + 4) >> 3
+	for j := 0; j < 4; j++ {
+		for i := 0; i < 4; i++ {
+			e.ybr[y+j][x+i] = clip8(int32(e.ybr[y+j][x+i]) + dc)
+		}
+	}
+}
+
+func (e *encoder) inverseDCT8(y, x, coeffBase int) {
+	e.inverseDCT4(y+0, x+0, coeffBase+0*16)
+	e.inverseDCT4(y+0, x+4, coeffBase+1*16)
+	e.inverseDCT4(y+4, x+0, coeffBase+2*16)
+	e.inverseDCT4(y+4, x+4, coeffBase+3*16)
+}
+
+func (e *encoder) inverseWHT16() {
+	var m [16]int32
+	for i := 0; i < 4; i++ {
+		a0 := int32(e.coeff[384+0+i]) + int32(e.coeff[384+12+i])
+		a1 := int32(e.coeff[384+4+i]) + int32(e.coeff[384+8+i])
+		a2 := int32(e.coeff[384+4+i]) - int32(e.coeff[384+8+i])
+		a3 := int32(e.coeff[384+0+i]) - int32(e.coeff[384+12+i])
+		m[0+i] = a0 + a1
+		m[8+i] = a0 - a1
+		m[4+i] = a3 + a2
+		m[12+i] = a3 - a2
+	}
+	out := 0
+	for i := 0; i < 4; i++ {
+		dc := m[0+i*4] + 3
+		a0 := dc + m[3+i*4]
+		a1 := m[1+i*4] + m[2+i*4]
+		a2 := m[1+i*4] - m[2+i*4]
+		a3 := dc - m[3+i*4]
+		e.coeff[out+0] = int16((a0 + a1) >> 3)
+		e.coeff[out+16] = int16((a3 + a2) >> 3)
+		e.coeff[out+32] = int16((a0 - a1) >> 3)
+		e.coeff[out+48] = int16((a3 - a2) >> 3)
+		out += 64
+	}
+}
+
+func clip8(i int32) uint8 {
+	if i < 0 {
+		return 0
+	}
+	if i > 255 {
+		return 255
+	}
+	return uint8(i)
+}
+
+func clamp(x, lo, hi int) int {
+	return max(lo, min(x, hi))
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func abs32(x int32) int32 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func btoi(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,672 @@
+//go:build webpenc
+
+package webpenc
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+)
+
+// DefaultQuality is the quality used when Options is nil or its Quality is 0.
+const DefaultQuality = 75
+
+// maxDimension is the largest width or height a VP8 frame header can hold.
+const maxDimension = 1<<14 - 1
+
+// Options are the encoding parameters.
+// Quality ranges from 1 to 100 inclusive, higher is better.
+type Options struct {
+	Quality int
+}
+
+// Prediction modes, numbered as in golang.org/x/image/vp8. Only the modes
+// available to 16x16 luma and 8x8 chroma regions are used.
+const (
+	predDC = iota
+	predTM
+	predVE
+	predHE
+	nMode
+	predDCTop     = 10
+	predDCLeft    = 11
+	predDCTopLeft = 12
+)
+
+// Workspace layout, as in golang.org/x/image/vp8's reconstruct.go.
+const (
+	ybrYX = 8
+	ybrYY = 1
+	ybrBX = 8
+	ybrBY = 18
+	ybrRX = 24
+	ybrRY = 18
+)
+
+// Coefficient layout of a macroblock, as in golang.org/x/image/vp8.
+const (
+	bCoeffBase   = 1*16*16 + 0*8*8
+	rCoeffBase   = 1*16*16 + 1*8*8
+	whtCoeffBase = 1*16*16 + 2*8*8
+)
+
+// maxLevel is the largest quantized coefficient magnitude the token tree can code.
+const maxLevel = 2048
+
+// quant holds the DC and AC quantizer steps of each plane.
+type quant struct {
+	y1, y2, uv [2]int32
+}
+
+// macroblock is what the bitstream records for one encoded macroblock. Levels
+// are quantized coefficients in raster order: luma blocks 0-15, Cb 16-19, Cr
+// 20-23 and the luma DC (WHT) block 24.
+type macroblock struct {
+	predY16, predC8 uint8
+	skip            bool
+	levels          [25][16]int16
+}
+
+type encoder struct {
+	width, height int
+	mbw, mbh      int
+
+	// Source planes, padded to whole macroblocks by repeating the edges.
+	y, cb, cr        []uint8
+	yStride, cStride int
+	// Reconstructed planes, as a decoder sees them before loop filtering.
+	ry, rcb, rcr []uint8
+
+	qIndex int
+	q      quant
+
+	ybr   [1 + 16 + 1 + 8][32]uint8
+	coeff [1*16*16 + 2*8*8 + 1*4*4]int16
+
+	mbs []macroblock
+}
+
+// Encode writes the Image m to w in lossy WebP format with the given options.
+// Default parameters are used if a nil *Options is passed. Transparency is
+// discarded.
+func Encode(w io.Writer, m image.Image, o *Options) error {
+	b := m.Bounds()
+	if b.Dx() < 1 || b.Dy() < 1 || b.Dx() > maxDimension || b.Dy() > maxDimension {
+		return errors.New("webpenc: image is too large or empty")
+	}
+	quality := DefaultQuality
+	if o != nil && o.Quality > 0 {
+		quality = min(o.Quality, 100)
+	}
+
+	e := newEncoder(m)
+	e.setQuality(quality)
+	for mby := 0; mby < e.mbh; mby++ {
+		for mbx := 0; mbx < e.mbw; mbx++ {
+			e.encodeMacroblock(mbx, mby)
+		}
+	}
+	frame := e.frame()
+
+	bw := bufio.NewWriter(w)
+	pad := len(frame) & 1
+	var header [20]byte
+	copy(header[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:8], uint32(4+8+len(frame)+pad))
+	copy(header[8:12], "WEBP")
+	copy(header[12:16], "VP8 ")
+	binary.LittleEndian.PutUint32(header[16:20], uint32(len(frame)))
+	bw.Write(header[:])
+	bw.Write(frame)
+	if pad != 0 {
+		bw.WriteByte(0)
+	}
+	return bw.Flush()
+}
+
+// newEncoder converts m to padded 4:2:0 Y'CbCr planes in the limited range VP8 decoders expect.
+func newEncoder(m image.Image) *encoder {
+	bounds := m.Bounds()
+	e := &encoder{width: bounds.Dx(), height: bounds.Dy()}
+	e.mbw = (e.width + 15) >> 4
+	e.mbh = (e.height + 15) >> 4
+	e.yStride = 16 * e.mbw
+	e.cStride = 8 * e.mbw
+	e.y = make([]uint8, e.yStride*16*e.mbh)
+	e.cb = make([]uint8, e.cStride*8*e.mbh)
+	e.cr = make([]uint8, e.cStride*8*e.mbh)
+	e.ry = make([]uint8, len(e.y))
+	e.rcb = make([]uint8, len(e.cb))
+	e.rcr = make([]uint8, len(e.cr))
+	e.mbs = make([]macroblock, e.mbw*e.mbh)
+
+	nrgba, _ := m.(*image.NRGBA)
+	rgb := func(x, y int) (r, g, b int32) {
+		x = bounds.Min.X + min(x, e.width-1)
+		y = bounds.Min.Y + min(y, e.height-1)
+		if nrgba != nil {
+			i := nrgba.PixOffset(x, y)
+			return int32(nrgba.Pix[i]), int32(nrgba.Pix[i+1]), int32(nrgba.Pix[i+2])
+		}
+		c := color.RGBAModel.Convert(m.At(x, y)).(color.RGBA)
+		return int32(c.R), int32(c.G), int32(c.B)
+	}
+
+	for cy := 0; cy < 8*e.mbh; cy++ {
+		for cx := 0; cx < 8*e.mbw; cx++ {
+			var sr, sg, sb int32
+			for j := 0; j < 2; j++ {
+				for i := 0; i < 2; i++ {
+					x, y := 2*cx+i, 2*cy+j
+					r, g, b := rgb(x, y)
+					e.y[y*e.yStride+x] = uint8((16839*r + 33059*g + 6420*b + 16<<16 + 1<<15) >> 16)
+					sr, sg, sb = sr+r, sg+g, sb+b
+				}
+			}
+			e.cb[cy*e.cStride+cx] = uint8((-9719*sr - 19081*sg + 28800*sb + 128<<18 + 1<<17) >> 18)
+			e.cr[cy*e.cStride+cx] = uint8((28800*sr - 24116*sg - 4684*sb + 128<<18 + 1<<17) >> 18)
+		}
+	}
+	return e
+}
+
+// setQuality maps a 1-100 quality to the frame's quantizer index and steps,
+// following the dequantization in section 9.6 of the specification.
+func (e *encoder) setQuality(quality int) {
+	q := (100 - quality) * 127 / 100
+	e.qIndex = q
+	e.q.y1 = [2]int32{int32(dequantTableDC[q]), int32(dequantTableAC[q])}
+	e.q.y2 = [2]int32{int32(dequantTableDC[q]) * 2, int32(dequantTableAC[q]) * 155 / 100}
+	if e.q.y2[1] < 8 {
+		e.q.y2[1] = 8
+	}
+	e.q.uv = [2]int32{int32(dequantTableDC[min(q, 117)]), int32(dequantTableAC[q])}
+}
+
+// encodeMacroblock picks the prediction modes of a macroblock, quantizes its
+// residuals and reconstructs it the way a decoder will.
+func (e *encoder) encodeMacroblock(mbx, mby int) {
+	mb := &e.mbs[mby*e.mbw+mbx]
+	e.prepareYBR(mbx, mby)
+	for i := range e.coeff {
+		e.coeff[i] = 0
+	}
+
+	// Luma: one 16x16 prediction, residual DCs coded through the WHT block.
+	mb.predY16 = e.bestMode(mbx, mby, predFunc16, [][2]int{{ybrYY, ybrYX}}, 16, [][]uint8{e.y}, e.yStride)
+	predFunc16[checkTopLeftPred(mbx, mby, mb.predY16)](e, ybrYY, ybrYX)
+
+	var dc [16]int32
+	var ac [16][16]int32
+	for n := 0; n < 16; n++ {
+		y, x := 4*(n/4), 4*(n%4)
+		ac[n] = e.forwardDCT(e.y, e.yStride, 16*mbx+x, 16*mby+y, ybrYY+y, ybrYX+x)
+		dc[n] = ac[n][0]
+	}
+	wht := forwardWHT(dc)
+	for i := 0; i < 16; i++ {
+		mb.levels[24][i] = quantize(wht[i], e.q.y2[btoi(i > 0)], i == 0)
+		e.coeff[whtCoeffBase+i] = int16(int32(mb.levels[24][i]) * e.q.y2[btoi(i > 0)])
+	}
+	e.inverseWHT16()
+	var nzAC [16]bool
+	for n := 0; n < 16; n++ {
+		for i := 1; i < 16; i++ {
+			level := quantize(ac[n][i], e.q.y1[1], false)
+			mb.levels[n][i] = level
+			e.coeff[16*n+i] = int16(int32(level) * e.q.y1[1])
+			nzAC[n] = nzAC[n] || level != 0
+		}
+	}
+	for n := 0; n < 16; n++ {
+		y, x := ybrYY+4*(n/4), ybrYX+4*(n%4)
+		if nzAC[n] {
+			e.inverseDCT4(y, x, 16*n)
+		} else if e.coeff[16*n] != 0 {
+			e.inverseDCT4DCOnly(y, x, 16*n)
+		}
+	}
+
+	// Chroma: Cb and Cr share one 8x8 prediction mode.
+	mb.predC8 = e.bestMode(mbx, mby, predFunc8, [][2]int{{ybrBY, ybrBX}, {ybrRY, ybrRX}}, 8, [][]uint8{e.cb, e.cr}, e.cStride)
+	p := checkTopLeftPred(mbx, mby, mb.predC8)
+	for c, plane := range [][]uint8{e.cb, e.cr} {
+		by, bx, base := ybrBY, ybrBX, bCoeffBase
+		if c == 1 {
+			by, bx, base = ybrRY, ybrRX, rCoeffBase
+		}
+		predFunc8[p](e, by, bx)
+		nz := false
+		for n := 0; n < 4; n++ {
+			y, x := 4*(n/2), 4*(n%2)
+			coeffs := e.forwardDCT(plane, e.cStride, 8*mbx+x, 8*mby+y, by+y, bx+x)
+			for i := 0; i < 16; i++ {
+				level := quantize(coeffs[i], e.q.uv[btoi(i > 0)], i == 0)
+				mb.levels[16+4*c+n][i] = level
+				e.coeff[base+16*n+i] = int16(int32(level) * e.q.uv[btoi(i > 0)])
+				nz = nz || level != 0
+			}
+		}
+		if nz {
+			e.inverseDCT8(by, bx, base)
+		}
+	}
+
+	mb.skip = true
+	for i := range mb.levels {
+		for _, level := range mb.levels[i] {
+			if level != 0 {
+				mb.skip = false
+			}
+		}
+	}
+
+	for y := 0; y < 16; y++ {
+		copy(e.ry[(16*mby+y)*e.yStride+16*mbx:], e.ybr[ybrYY+y][ybrYX:ybrYX+16])
+	}
+	for y := 0; y < 8; y++ {
+		copy(e.rcb[(8*mby+y)*e.cStride+8*mbx:], e.ybr[ybrBY+y][ybrBX:ybrBX+8])
+		copy(e.rcr[(8*mby+y)*e.cStride+8*mbx:], e.ybr[ybrRY+y][ybrRX:ybrRX+8])
+	}
+}
+
+// bestMode returns the prediction mode whose prediction of the given workspace
+// regions is closest to the source, by sum of absolute differences.
+func (e *encoder) bestMode(mbx, mby int, funcs [13]func(*encoder, int, int), regions [][2]int, size int, planes [][]uint8, stride int) uint8 {
+	best, bestSAD := uint8(predDC), -1
+	for mode := uint8(0); mode < nMode; mode++ {
+		sad := 0
+		for r, region := range regions {
+			funcs[checkTopLeftPred(mbx, mby, mode)](e, region[0], region[1])
+			for j := 0; j < size; j++ {
+				row := planes[r][(size*mby+j)*stride+size*mbx:]
+				for i := 0; i < size; i++ {
+					sad += abs(int(row[i]) - int(e.ybr[region[0]+j][region[1]+i]))
+				}
+			}
+		}
+		if bestSAD < 0 || sad < bestSAD {
+			best, bestSAD = mode, sad
+		}
+	}
+	return best
+}
+
+// forwardDCT transforms the 4x4 residual between the source at (sx, sy) and the
+// prediction in the workspace at (y, x). It mirrors inverseDCT4.
+func (e *encoder) forwardDCT(plane []uint8, stride, sx, sy, y, x int) [16]int32 {
+	var tmp, out [16]int32
+	for i := 0; i < 4; i++ {
+		src := plane[(sy+i)*stride+sx:]
+		d0 := int32(src[0]) - int32(e.ybr[y+i][x+0])
+		d1 := int32(src[1]) - int32(e.ybr[y+i][x+1])
+		d2 := int32(src[2]) - int32(e.ybr[y+i][x+2])
+		d3 := int32(src[3]) - int32(e.ybr[y+i][x+3])
+		a0, a1, a2, a3 := d0+d3, d1+d2, d1-d2, d0-d3
+		tmp[0+i*4] = (a0 + a1) * 8
+		tmp[1+i*4] = (a2*2217 + a3*5352 + 1812) >> 9
+		tmp[2+i*4] = (a0 - a1) * 8
+		tmp[3+i*4] = (a3*2217 - a2*5352 + 937) >> 9
+	}
+	for i := 0; i < 4; i++ {
+		a0 := tmp[0+i] + tmp[12+i]
+		a1 := tmp[4+i] + tmp[8+i]
+		a2 := tmp[4+i] - tmp[8+i]
+		a3 := tmp[0+i] - tmp[12+i]
+		out[0+i] = (a0 + a1 + 7) >> 4
+		out[4+i] = (a2*2217+a3*5352+12000)>>16 + btoi(a3 != 0)
+		out[8+i] = (a0 - a1 + 7) >> 4
+		out[12+i] = (a3*2217 - a2*5352 + 51000) >> 16
+	}
+	return out
+}
+
+// forwardWHT transforms the DC coefficients of the 16 luma blocks. It mirrors inverseWHT16.
+func forwardWHT(dc [16]int32) [16]int32 {
+	var tmp, out [16]int32
+	for i := 0; i < 4; i++ {
+		in := dc[4*i:]
+		a0 := in[0] + in[2]
+		a1 := in[1] + in[3]
+		a2 := in[1] - in[3]
+		a3 := in[0] - in[2]
+		tmp[0+i*4] = a0 + a1
+		tmp[1+i*4] = a3 + a2
+		tmp[2+i*4] = a3 - a2
+		tmp[3+i*4] = a0 - a1
+	}
+	for i := 0; i < 4; i++ {
+		a0 := tmp[0+i] + tmp[8+i]
+		a1 := tmp[4+i] + tmp[12+i]
+		a2 := tmp[4+i] - tmp[12+i]
+		a3 := tmp[0+i] - tmp[8+i]
+		out[0+i] = (a0 + a1) >> 1
+		out[4+i] = (a3 + a2) >> 1
+		out[8+i] = (a3 - a2) >> 1
+		out[12+i] = (a0 - a1) >> 1
+	}
+	return out
+}
+
+// quantize divides a coefficient by its quantizer step, rounding DC coefficients
+// to nearest and AC coefficients with a dead zone that favors zero.
+func quantize(c, step int32, isDC bool) int16 {
+	bias := step / 3
+	if isDC {
+		bias = step / 2
+	}
+	level := (abs32(c) + bias) / step
+	level = min(level, maxLevel)
+	if c < 0 {
+		level = -level
+	}
+	return int16(level)
+}
+
+// frame returns the VP8 key frame: the frame header, the first partition with the
+// frame parameters and per-macroblock modes, and a single token partition.
+func (e *encoder) frame() []byte {
+	skipped := 0
+	for i := range e.mbs {
+		if e.mbs[i].skip {
+			skipped++
+		}
+	}
+	useSkipProb := skipped > 0
+	skipProb := uint8(clamp(255*(len(e.mbs)-skipped)/len(e.mbs), 1, 255))
+
+	var fp boolEncoder
+	fp.init()
+	fp.putBit(0, uniformProb) // Color space.
+	fp.putBit(0, uniformProb) // Clamping type.
+	fp.putBit(0, uniformProb) // No segmentation.
+	fp.putBit(0, uniformProb) // Normal loop filter.
+	fp.putUint(uint32(e.qIndex/4), 6)
+	fp.putUint(0, 3)          // Sharpness.
+	fp.putBit(0, uniformProb) // No loop filter deltas.
+	fp.putUint(0, 2)          // One token partition.
+	fp.putUint(uint32(e.qIndex), 7)
+	for i := 0; i < 5; i++ {
+		fp.putBit(0, uniformProb) // No quantizer deltas.
+	}
+	fp.putBit(0, uniformProb) // Refresh entropy probabilities.
+	for i := range tokenProbUpdateProb {
+		for j := range tokenProbUpdateProb[i] {
+			for k := range tokenProbUpdateProb[i][j] {
+				for l := range tokenProbUpdateProb[i][j][k] {
+					fp.putBit(0, tokenProbUpdateProb[i][j][k][l])
+				}
+			}
+		}
+	}
+	fp.putBit(uint8(btoi(useSkipProb)), uniformProb)
+	if useSkipProb {
+		fp.putUint(uint32(skipProb), 8)
+	}
+
+	var tp boolEncoder
+	tp.init()
+	upNz := make([]nzContext, e.mbw)
+	for mby := 0; mby < e.mbh; mby++ {
+		var leftNz nzContext
+		for mbx := 0; mbx < e.mbw; mbx++ {
+			mb := &e.mbs[mby*e.mbw+mbx]
+			if useSkipProb {
+				fp.putBit(uint8(btoi(mb.skip)), skipProb)
+			}
+			fp.putBit(1, 145) // 16x16 luma prediction.
+			switch mb.predY16 {
+			case predDC:
+				fp.putBit(0, 156)
+				fp.putBit(0, 163)
+			case predVE:
+				fp.putBit(0, 156)
+				fp.putBit(1, 163)
+			case predHE:
+				fp.putBit(1, 156)
+				fp.putBit(0, 128)
+			case predTM:
+				fp.putBit(1, 156)
+				fp.putBit(1, 128)
+			}
+			switch mb.predC8 {
+			case predDC:
+				fp.putBit(0, 142)
+			case predVE:
+				fp.putBit(1, 142)
+				fp.putBit(0, 114)
+			case predHE:
+				fp.putBit(1, 142)
+				fp.putBit(1, 114)
+				fp.putBit(0, 183)
+			case predTM:
+				fp.putBit(1, 142)
+				fp.putBit(1, 114)
+				fp.putBit(1, 183)
+			}
+
+			if mb.skip && useSkipProb {
+				leftNz, upNz[mbx] = nzContext{}, nzContext{}
+				continue
+			}
+			writeResiduals(&tp, mb, &leftNz, &upNz[mbx])
+		}
+	}
+
+	first := fp.flush()
+	tokens := tp.flush()
+
+	frame := make([]byte, 10, 10+len(first)+len(tokens))
+	tag := uint32(len(first))<<5 | 1<<4 // Key frame, version 0, shown.
+	frame[0], frame[1], frame[2] = byte(tag), byte(tag>>8), byte(tag>>16)
+	frame[3], frame[4], frame[5] = 0x9d, 0x01, 0x2a
+	binary.LittleEndian.PutUint16(frame[6:8], uint16(e.width))
+	binary.LittleEndian.PutUint16(frame[8:10], uint16(e.height))
+	frame = append(frame, first...)
+	return append(frame, tokens...)
+}
+
+// nzContext records which 4x4 blocks along a macroblock edge had non-zero
+// coefficients, as the token probabilities of the neighboring blocks depend on it.
+type nzContext struct {
+	y2 uint8
+	y  [4]uint8
+	u  [2]uint8
+	v  [2]uint8
+}
+
+// writeResiduals codes the coefficients of a macroblock in the order
+// golang.org/x/image/vp8's parseResiduals reads them.
+func writeResiduals(w *boolEncoder, mb *macroblock, left, up *nzContext) {
+	nz := writeBlock(w, planeY2, left.y2+up.y2, &mb.levels[24], 0)
+	left.y2, up.y2 = nz, nz
+
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			nz := writeBlock(w, planeY1WithY2, left.y[y]+up.y[x], &mb.levels[4*y+x], 1)
+			left.y[y], up.y[x] = nz, nz
+		}
+	}
+	for c, ctx := range []struct{ left, up *[2]uint8 }{{&left.u, &up.u}, {&left.v, &up.v}} {
+		for y := 0; y < 2; y++ {
+			for x := 0; x < 2; x++ {
+				nz := writeBlock(w, planeUV, ctx.left[y]+ctx.up[x], &mb.levels[16+4*c+2*y+x], 0)
+				ctx.left[y], ctx.up[x] = nz, nz
+			}
+		}
+	}
+}
+
+// writeBlock codes the levels of one 4x4 block from position first in zigzag
+// order, as parseResiduals4 reads them, and returns 1 when any was non-zero.
+func writeBlock(w *boolEncoder, plane int, context uint8, levels *[16]int16, first int) uint8 {
+	prob := &defaultTokenProb[plane]
+	last := -1
+	for n := first; n < 16; n++ {
+		if levels[zigzag[n]] != 0 {
+			last = n
+		}
+	}
+
+	n := first
+	p := prob[bands[n]][context]
+	if last < 0 {
+		w.putBit(0, p[0])
+		return 0
+	}
+	w.putBit(1, p[0])
+	for n < 16 {
+		v := int32(levels[zigzag[n]])
+		n++
+		if v == 0 {
+			w.putBit(0, p[1])
+			p = prob[bands[n]][0]
+			continue
+		}
+		w.putBit(1, p[1])
+
+		a := abs32(v)
+		if a == 1 {
+			w.putBit(0, p[2])
+			p = prob[bands[n]][1]
+		} else {
+			w.putBit(1, p[2])
+			switch {
+			case a <= 4:
+				w.putBit(0, p[3])
+				if a == 2 {
+					w.putBit(0, p[4])
+				} else {
+					w.putBit(1, p[4])
+					w.putBit(uint8(a-3), p[5])
+				}
+			case a <= 10:
+				w.putBit(1, p[3])
+				w.putBit(0, p[6])
+				if a <= 6 {
+					w.putBit(0, p[7])
+					w.putBit(uint8(a-5), 159)
+				} else {
+					w.putBit(1, p[7])
+					w.putBit(uint8((a-7)>>1), 165)
+					w.putBit(uint8((a-7)&1), 145)
+				}
+			default:
+				w.putBit(1, p[3])
+				w.putBit(1, p[6])
+				cat := 3
+				switch {
+				case a < 3+(8<<1):
+					cat = 0
+				case a < 3+(8<<2):
+					cat = 1
+				case a < 3+(8<<3):
+					cat = 2
+				}
+				w.putBit(uint8(cat>>1), p[8])
+				w.putBit(uint8(cat&1), p[9+cat>>1])
+				tab := &cat3456[cat]
+				nBits := 0
+				for tab[nBits] != 0 {
+					nBits++
+				}
+				extra := a - 3 - (8 << cat)
+				for i := 0; i < nBits; i++ {
+					w.putBit(uint8(extra>>(nBits-1-i))&1, tab[i])
+				}
+			}
+			p = prob[bands[n]][2]
+		}
+		w.putBit(uint8(btoi(v < 0)), uniformProb)
+		if n == 16 {
+			break
+		}
+		if n > last {
+			w.putBit(0, p[0])
+			break
+		}
+		w.putBit(1, p[0])
+	}
+	return 1
+}
+
+// uniformProb represents a 50% probability that the next bit is 0.
+const uniformProb = 128
+
+// boolEncoder is the boolean entropy encoder of section 7.3 of the specification.
+type boolEncoder struct {
+	buf      []byte
+	rng      uint32
+	bottom   uint32
+	bitCount int
+}
+
+func (b *boolEncoder) init() {
+	b.rng = 255
+	b.bitCount = 24
+}
+
+// addOne propagates a carry into the bytes already written.
+func (b *boolEncoder) addOne() {
+	i := len(b.buf) - 1
+	for i >= 0 && b.buf[i] == 255 {
+		b.buf[i] = 0
+		i--
+	}
+	if i >= 0 {
+		b.buf[i]++
+	}
+}
+
+// putBit codes bit, which is 0 with probability prob/256.
+func (b *boolEncoder) putBit(bit uint8, prob uint8) {
+	split := 1 + ((b.rng-1)*uint32(prob))>>8
+	if bit != 0 {
+		b.bottom += split
+		b.rng -= split
+	} else {
+		b.rng = split
+	}
+	for b.rng < 128 {
+		b.rng <<= 1
+		if b.bottom&(1<<31) != 0 {
+			b.addOne()
+		}
+		b.bottom <<= 1
+		b.bitCount--
+		if b.bitCount == 0 {
+			b.buf = append(b.buf, byte(b.bottom>>24))
+			b.bottom &= 1<<24 - 1
+			b.bitCount = 8
+		}
+	}
+}
+
+// putUint codes the n low bits of v, most significant first, at uniform probability.
+func (b *boolEncoder) putUint(v uint32, n uint) {
+	for n > 0 {
+		n--
+		b.putBit(uint8(v>>n)&1, uniformProb)
+	}
+}
+
+// flush writes out the remaining state and returns the coded bytes.
+func (b *boolEncoder) flush() []byte {
+	c := b.bitCount
+	v := b.bottom
+	if v&(1<<(32-c)) != 0 {
+		b.addOne()
+	}
+	v <<= uint(c & 7)
+	for c >>= 3; c > 0; c-- {
+		v <<= 8
+	}
+	for i := 0; i < 4; i++ {
+		b.buf = append(b.buf, byte(v>>24))
+		v <<= 8
+	}
+	return b.buf
+}
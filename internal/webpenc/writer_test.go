@@ -0,0 +1,145 @@
+//go:build webpenc
+
+package webpenc
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+// gradient returns a smooth test image, the kind of content stills are made of
+func gradient(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{
+				R: uint8(255 * x / max(w-1, 1)),
+				G: uint8(255 * y / max(h-1, 1)),
+				B: uint8(128 + 64*math.Sin(float64(x+y)/8)),
+				A: 255,
+			})
+		}
+	}
+	return img
+}
+
+// psnr compares the RGB channels of two images of the same size
+func psnr(a, b image.Image) float64 {
+	bounds := a.Bounds()
+	var sum float64
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r1, g1, b1, _ := a.At(x, y).RGBA()
+			r2, g2, b2, _ := b.At(x-bounds.Min.X+b.Bounds().Min.X, y-bounds.Min.Y+b.Bounds().Min.Y).RGBA()
+			for _, d := range []float64{float64(r1>>8) - float64(r2>>8), float64(g1>>8) - float64(g2>>8), float64(b1>>8) - float64(b2>>8)} {
+				sum += d * d
+			}
+		}
+	}
+	mse := sum / float64(3*bounds.Dx()*bounds.Dy())
+	if mse == 0 {
+		return math.Inf(1)
+	}
+	return 10 * math.Log10(255*255/mse)
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		quality       int
+		minPSNR       float64
+	}{
+		{"1x1", 1, 1, 80, 22},
+		{"single row", 17, 1, 80, 22},
+		{"single column", 1, 17, 80, 22},
+		{"one macroblock", 16, 16, 80, 23},
+		{"odd size", 31, 33, 80, 24},
+		{"odd chroma", 45, 7, 80, 22},
+		{"several macroblocks", 100, 75, 80, 25},
+		{"default quality", 64, 48, 0, 25},
+		{"lowest quality", 64, 48, 1, 21},
+		{"highest quality", 64, 48, 100, 26},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := gradient(tt.width, tt.height)
+			var buf bytes.Buffer
+			if err := Encode(&buf, src, &Options{Quality: tt.quality}); err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			decoded, err := webp.Decode(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatalf("decoding the encoded image: %v", err)
+			}
+			if got := decoded.Bounds().Size(); got != src.Bounds().Size() {
+				t.Fatalf("decoded size %v, want %v", got, src.Bounds().Size())
+			}
+			if got := psnr(src, decoded); got < tt.minPSNR {
+				t.Errorf("PSNR %.1f dB, want at least %.1f dB", got, tt.minPSNR)
+			}
+		})
+	}
+}
+
+func TestEncodeSubImage(t *testing.T) {
+	// A sub-image starts away from the origin; the encoder must read it from its bounds
+	full := gradient(40, 40)
+	sub := full.SubImage(image.Rect(5, 7, 26, 20))
+	var buf bytes.Buffer
+	if err := Encode(&buf, sub, nil); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	decoded, err := webp.Decode(&buf)
+	if err != nil {
+		t.Fatalf("decoding the encoded image: %v", err)
+	}
+	if got := decoded.Bounds().Size(); got != image.Pt(21, 13) {
+		t.Fatalf("decoded size %v, want (21,13)", got)
+	}
+	if got := psnr(sub, decoded); got < 20 {
+		t.Errorf("PSNR %.1f dB, want at least 20 dB", got)
+	}
+}
+
+func TestEncodeRejectsInvalidSizes(t *testing.T) {
+	tests := []struct {
+		name string
+		rect image.Rectangle
+	}{
+		{"empty", image.Rect(0, 0, 0, 0)},
+		{"zero width", image.Rect(0, 0, 0, 10)},
+		{"too wide", image.Rect(0, 0, maxDimension+1, 1)},
+		{"too tall", image.Rect(0, 0, 1, maxDimension+1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Encode(&bytes.Buffer{}, image.NewGray(tt.rect), nil); err == nil {
+				t.Error("Encode succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestEncodeRIFFContainer(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Encode(&buf, gradient(3, 5), nil); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	data := buf.Bytes()
+	if len(data)%2 != 0 {
+		t.Errorf("file length %d is odd; RIFF chunks are padded to even sizes", len(data))
+	}
+	if string(data[0:4]) != "RIFF" || string(data[8:16]) != "WEBPVP8 " {
+		t.Errorf("unexpected container header % x", data[:16])
+	}
+	riffSize := int(data[4]) | int(data[5])<<8 | int(data[6])<<16 | int(data[7])<<24
+	if riffSize != len(data)-8 {
+		t.Errorf("RIFF size %d, want %d", riffSize, len(data)-8)
+	}
+}
@@ -0,0 +1,161 @@
+package wordpress
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"excentrico-tools-go/internal/logger"
+	"excentrico-tools-go/internal/services"
+	"excentrico-tools-go/internal/utils"
+)
+
+// Media roles, derived from the Drive folder an optimized image was mirrored from
+const (
+	MediaRoleDirector = "director"
+	MediaRoleStill    = "still"
+	MediaRolePoster   = "poster"
+	MediaRoleOther    = "other"
+)
+
+// posterKeywords mark an image as the film's poster by file name, as for the featured image
+var posterKeywords = []string{"poster", "portada", "cover"}
+
+// ClassifyMedia returns the role of an optimized image from its parent folder
+// (Dir, Stills, Featured Image) or, for posters, a keyword in its file name
+func ClassifyMedia(webFile string) string {
+	folder := strings.ToLower(strings.TrimSpace(filepath.Base(filepath.Dir(webFile))))
+	name := strings.ToLower(filepath.Base(webFile))
+
+	for _, keyword := range posterKeywords {
+		if strings.Contains(name, keyword) {
+			return MediaRolePoster
+		}
+	}
+
+	switch folder {
+	case "dir":
+		return MediaRoleDirector
+	case "stills":
+		return MediaRoleStill
+	case "featured image", "posters", "poster":
+		return MediaRolePoster
+	}
+	return MediaRoleOther
+}
+
+// DeriveAltText builds role-aware alt text: director photos name the director whose
+// name appears in the file name (or all directors), stills and posters name the film
+func DeriveAltText(role, fileName, filmTitle string, directors []string) string {
+	switch role {
+	case MediaRoleDirector:
+		if name := directorForFile(fileName, directors); name != "" {
+			return name
+		}
+		if len(directors) > 0 {
+			return strings.Join(directors, " & ")
+		}
+		return fmt.Sprintf("Director of %s", filmTitle)
+	case MediaRoleStill:
+		return fmt.Sprintf("Still from %s", filmTitle)
+	case MediaRolePoster:
+		return fmt.Sprintf("Poster — %s", filmTitle)
+	}
+	return fmt.Sprintf("Image from %s", filmTitle)
+}
+
+// directorForFile returns the director whose name, or surname, appears in the file name
+func directorForFile(fileName string, directors []string) string {
+	name := CreateWordPressSlug(utils.TrimOptimizedSuffix(fileName))
+	for _, director := range directors {
+		if slug := CreateWordPressSlug(director); slug != "" && strings.Contains(name, slug) {
+			return director
+		}
+	}
+	for _, director := range directors {
+		parts := strings.Fields(director)
+		if len(parts) < 2 {
+			continue
+		}
+		if surname := CreateWordPressSlug(parts[len(parts)-1]); len(surname) > 2 && strings.Contains(name, surname) {
+			return director
+		}
+	}
+	return ""
+}
+
+// ResyncAltText updates the alt text, and for directors and posters the caption, of every
+// media item recorded for a film using the current derivation, without re-uploading files.
+// It returns how many media items were updated
+func ResyncAltText(wordpressService *services.WordPressService, tursoService *services.TursoService, filmID string, filmTitle string, optimizedDir string, directors []string, naming MediaNaming) (int, error) {
+	l := logger.Get()
+	op := l.StartOperation("resync_alt_text")
+	op.WithFilm(filmID, filmTitle, "", "")
+
+	imageMetadata := make(map[string]int)
+	if err := tursoService.GetWPImagesMetadata(filmID, &imageMetadata); err != nil {
+		if errors.Is(err, services.ErrMetadataNotFound) {
+			op.Complete("No media recorded for film")
+			return 0, nil
+		}
+		op.Fail("Failed to load image metadata", err)
+		return 0, fmt.Errorf("failed to load image metadata: %v", err)
+	}
+
+	// Recorded upload paths keep the folder an image came from
+	filePaths := make(map[string]string)
+	var mediaMetadata []map[string]any
+	if err := tursoService.GetMetadata(filmID, "wordpress_media", &mediaMetadata); err == nil {
+		for _, media := range mediaMetadata {
+			if filePath, ok := media["file_path"].(string); ok {
+				filePaths[filepath.Base(filePath)] = filePath
+			}
+		}
+	}
+
+	fileNames := make([]string, 0, len(imageMetadata))
+	for fileName := range imageMetadata {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+
+	updatedCount := 0
+	failedCount := 0
+	for _, fileName := range fileNames {
+		mediaID := imageMetadata[fileName]
+		webFile, ok := filePaths[fileName]
+		if !ok {
+			if webFile = findLocalWebFile(optimizedDir, fileName); webFile == "" {
+				webFile = fileName
+			}
+		}
+
+		role := ClassifyMedia(webFile)
+		altText := naming.AltText(webFile, filmTitle, directors)
+		fields := map[string]any{"alt_text": altText}
+		if role == MediaRoleDirector || role == MediaRolePoster {
+			fields["caption"] = altText
+		}
+
+		if _, err := wordpressService.UpdateMedia(mediaID, fields); err != nil {
+			op.WithContext(fmt.Sprintf("update_error_%d", mediaID), err.Error())
+			failedCount++
+			continue
+		}
+		updatedCount++
+	}
+
+	op.WithContext("media_count", len(imageMetadata))
+	op.WithContext("updated_count", updatedCount)
+	op.WithContext("failed_count", failedCount)
+	if failedCount > 0 {
+		op.Warn(&logger.WideEvent{
+			Message: fmt.Sprintf("Failed to update %d of %d media items", failedCount, len(imageMetadata)),
+		})
+		return updatedCount, nil
+	}
+	op.Complete(fmt.Sprintf("Updated alt text of %d media items", updatedCount))
+	return updatedCount, nil
+}
@@ -0,0 +1,128 @@
+package wordpress
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"excentrico-tools-go/internal/services"
+)
+
+func TestClassifyMedia(t *testing.T) {
+	tests := []struct {
+		webFile string
+		want    string
+	}{
+		{webFile: "films/la-playa/optimized/Dir/ana_ruiz_web.jpg", want: MediaRoleDirector},
+		{webFile: "films/la-playa/optimized/ Dir /ana_ruiz_web.jpg", want: MediaRoleDirector},
+		{webFile: "films/la-playa/optimized/Stills/01_web.jpg", want: MediaRoleStill},
+		{webFile: "films/la-playa/optimized/Featured Image/key_art_web.jpg", want: MediaRolePoster},
+		{webFile: "films/la-playa/optimized/Posters/a3_web.jpg", want: MediaRolePoster},
+		{webFile: "films/la-playa/optimized/Stills/La_Playa_Poster_web.jpg", want: MediaRolePoster},
+		{webFile: "films/la-playa/optimized/Background/portada_web.jpg", want: MediaRolePoster},
+		{webFile: "films/la-playa/optimized/Background/fondo_web.jpg", want: MediaRoleOther},
+		{webFile: "fondo_web.jpg", want: MediaRoleOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.webFile, func(t *testing.T) {
+			if got := ClassifyMedia(tt.webFile); got != tt.want {
+				t.Errorf("ClassifyMedia(%q) = %q, want %q", tt.webFile, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeriveAltText(t *testing.T) {
+	directors := []string{"Ana Ruiz", "José Martínez"}
+	tests := []struct {
+		name      string
+		role      string
+		fileName  string
+		directors []string
+		want      string
+	}{
+		{name: "director by full name", role: MediaRoleDirector, fileName: "ana_ruiz_web.jpg", directors: directors, want: "Ana Ruiz"},
+		{name: "director by accented name", role: MediaRoleDirector, fileName: "Jose-Martinez_web.jpg", directors: directors, want: "José Martínez"},
+		{name: "director by surname", role: MediaRoleDirector, fileName: "retrato_martinez_web.jpg", directors: directors, want: "José Martínez"},
+		{name: "director not named in the file", role: MediaRoleDirector, fileName: "dir_01_web.jpg", directors: directors, want: "Ana Ruiz & José Martínez"},
+		{name: "no directors known", role: MediaRoleDirector, fileName: "dir_01_web.jpg", want: "Director of La playa"},
+		{name: "still", role: MediaRoleStill, fileName: "ana_ruiz_web.jpg", directors: directors, want: "Still from La playa"},
+		{name: "poster", role: MediaRolePoster, fileName: "poster_web.jpg", directors: directors, want: "Poster — La playa"},
+		{name: "other", role: MediaRoleOther, fileName: "fondo_web.jpg", directors: directors, want: "Image from La playa"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DeriveAltText(tt.role, tt.fileName, "La playa", tt.directors); got != tt.want {
+				t.Errorf("DeriveAltText(%q, %q) = %q, want %q", tt.role, tt.fileName, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResyncAltText(t *testing.T) {
+	directors := []string{"Ana Ruiz"}
+	allUpdates := map[int][]map[string]any{
+		101: {{"alt_text": "Ana Ruiz", "caption": "Ana Ruiz"}},
+		102: {{"alt_text": "Still from La playa"}},
+		103: {{"alt_text": "Poster — La playa", "caption": "Poster — La playa"}},
+		104: {{"alt_text": "Image from La playa"}},
+	}
+	tests := []struct {
+		name        string
+		noMetadata  bool
+		deleted     int // media ID missing from WordPress
+		wantUpdated int
+		wantUpdates map[int][]map[string]any
+	}{
+		{name: "every stored media item", wantUpdated: 4, wantUpdates: allUpdates},
+		{
+			name:        "media deleted in WordPress",
+			deleted:     104,
+			wantUpdated: 3,
+			wantUpdates: map[int][]map[string]any{101: allUpdates[101], 102: allUpdates[102], 103: allUpdates[103]},
+		},
+		{name: "no stored media", noMetadata: true, wantUpdates: map[int][]map[string]any{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, wp := newFakeWordPress(t)
+			turso := newTestTurso(t)
+			for id := 101; id <= 104; id++ {
+				if id != tt.deleted {
+					fake.media[id] = &services.WordPressMedia{ID: id, AltText: "La playa"}
+				}
+			}
+
+			// The poster is only found on disk; the background is found nowhere
+			optimizedDir := t.TempDir()
+			writeFile(t, filepath.Join(optimizedDir, "Featured Image", "poster_web.jpg"))
+			if !tt.noMetadata {
+				images := map[string]int{"ana_ruiz_web.jpg": 101, "01_web.jpg": 102, "poster_web.jpg": 103, "fondo_web.jpg": 104}
+				if err := turso.SaveWPImagesMetadata("film-1", images); err != nil {
+					t.Fatal(err)
+				}
+				media := []map[string]any{
+					{"id": 101, "file_path": filepath.Join(optimizedDir, "Dir", "ana_ruiz_web.jpg")},
+					{"id": 102, "file_path": filepath.Join(optimizedDir, "Stills", "01_web.jpg")},
+				}
+				if err := turso.SaveMetadata("film-1", "wordpress_media", media); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			updated, err := ResyncAltText(wp, turso, "film-1", "La playa", optimizedDir, directors, MediaNaming{})
+			if err != nil {
+				t.Fatalf("ResyncAltText: %v", err)
+			}
+			if updated != tt.wantUpdated {
+				t.Errorf("updated %d media items, want %d", updated, tt.wantUpdated)
+			}
+			if !reflect.DeepEqual(fake.mediaUpdates, tt.wantUpdates) {
+				t.Errorf("media updates %v, want %v", fake.mediaUpdates, tt.wantUpdates)
+			}
+			if fake.nextID != 100 {
+				t.Errorf("%d media items uploaded during the resync, want none", fake.nextID-100)
+			}
+		})
+	}
+}
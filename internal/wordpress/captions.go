@@ -0,0 +1,150 @@
+package wordpress
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"excentrico-tools-go/internal/logger"
+	"excentrico-tools-go/internal/services"
+	"excentrico-tools-go/internal/utils"
+)
+
+// captionsFileName is the optional per-film file mapping image file names to captions
+const captionsFileName = "captions.json"
+
+// CaptionFromFilename derives a caption from an image file name by dropping the
+// extension and _web suffix, turning separators into spaces and removing bare numbers,
+// e.g. "03_la-playa_web.jpg" -> "La playa". Returns "" when nothing meaningful is left
+func CaptionFromFilename(fileName string) string {
+	name := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	name = strings.TrimSuffix(name, "_web")
+
+	fields := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '_' || r == '-' || r == '.' || unicode.IsSpace(r)
+	})
+
+	var words []string
+	for _, field := range fields {
+		if strings.IndexFunc(field, func(r rune) bool { return !unicode.IsDigit(r) }) == -1 {
+			continue
+		}
+		words = append(words, field)
+	}
+	if len(words) == 0 {
+		return ""
+	}
+
+	caption := []rune(strings.Join(words, " "))
+	caption[0] = unicode.ToUpper(caption[0])
+	return string(caption)
+}
+
+// loadCaptionMap reads films/<film>/captions.json, keyed by the original or _web file name
+func loadCaptionMap(filmDir string) (map[string]string, error) {
+	data, err := os.ReadFile(filepath.Join(filmDir, captionsFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+
+	captions := make(map[string]string)
+	if err := json.Unmarshal(data, &captions); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", captionsFileName, err)
+	}
+	return captions, nil
+}
+
+// captionFor returns the mapped caption for an optimized (_web.jpg) file, falling back to its file name
+func captionFor(fileName string, captionMap map[string]string) string {
+	if caption, ok := captionMap[fileName]; ok {
+		return caption
+	}
+	base := utils.TrimOptimizedSuffix(fileName)
+	for mappedName, caption := range captionMap {
+		if strings.TrimSuffix(mappedName, filepath.Ext(mappedName)) == base {
+			return caption
+		}
+	}
+	return CaptionFromFilename(fileName)
+}
+
+// ApplyGalleryCaptions sets the WordPress caption of each gallery image from its file name
+// or the film's captions.json. Captions already applied in an earlier run are not sent again
+func ApplyGalleryCaptions(wordpressService *services.WordPressService, tursoService *services.TursoService, filmID string, filmDir string, galleryIds []int) error {
+	if len(galleryIds) == 0 {
+		return nil
+	}
+
+	l := logger.Get()
+	op := l.StartOperation("apply_gallery_captions")
+	op.WithFilm(filmID, "", "", "")
+
+	imageMetadata := make(map[string]int)
+	if err := tursoService.GetWPImagesMetadata(filmID, &imageMetadata); err != nil {
+		op.Fail("Failed to load image metadata", err)
+		return fmt.Errorf("failed to load image metadata: %v", err)
+	}
+	fileNames := make(map[int]string, len(imageMetadata))
+	for fileName, mediaID := range imageMetadata {
+		fileNames[mediaID] = fileName
+	}
+
+	captionMap, err := loadCaptionMap(filmDir)
+	if err != nil {
+		op.Fail("Failed to load caption mapping", err)
+		return err
+	}
+
+	applied := make(map[string]string)
+	if err := tursoService.GetGalleryCaptionsMetadata(filmID, &applied); err != nil && !errors.Is(err, services.ErrMetadataNotFound) {
+		op.WithContext("applied_captions_error", err.Error())
+	}
+
+	updatedCount := 0
+	failedCount := 0
+	for _, mediaID := range galleryIds {
+		fileName, ok := fileNames[mediaID]
+		if !ok {
+			continue
+		}
+		caption := captionFor(fileName, captionMap)
+		key := fmt.Sprintf("%d", mediaID)
+		if caption == "" || applied[key] == caption {
+			continue
+		}
+
+		if _, err := wordpressService.UpdateMedia(mediaID, map[string]any{"caption": caption}); err != nil {
+			failedCount++
+			continue
+		}
+		applied[key] = caption
+		updatedCount++
+	}
+
+	if updatedCount > 0 {
+		if err := tursoService.SaveGalleryCaptionsMetadata(filmID, applied); err != nil {
+			op.Warn(&logger.WideEvent{
+				Message: "Failed to save applied gallery captions",
+			})
+		}
+	}
+
+	op.WithContext("gallery_count", len(galleryIds))
+	op.WithContext("updated_count", updatedCount)
+	op.WithContext("failed_count", failedCount)
+	if failedCount > 0 {
+		op.Warn(&logger.WideEvent{
+			Message: fmt.Sprintf("Failed to set %d of %d gallery captions", failedCount, len(galleryIds)),
+		})
+		return nil
+	}
+	op.Complete(fmt.Sprintf("Applied %d gallery captions", updatedCount))
+	return nil
+}
@@ -0,0 +1,53 @@
+package wordpress
+
+import "testing"
+
+func TestCaptionFromFilename(t *testing.T) {
+	tests := []struct {
+		fileName string
+		want     string
+	}{
+		{fileName: "03_la-playa_web.jpg", want: "La playa"},
+		{fileName: "la playa.png", want: "La playa"},
+		{fileName: "still.final.v2_web.webp", want: "Still final v2"},
+		{fileName: "ñandú_en_el_campo_web.jpg", want: "Ñandú en el campo"},
+		{fileName: "Poster_2025_web.jpg", want: "Poster"},
+		{fileName: "DSC_0042_web.jpg", want: "DSC"},
+		{fileName: "0042_web.jpg", want: ""},
+		{fileName: "01-02_web.jpg", want: ""},
+		{fileName: "_web.jpg", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fileName, func(t *testing.T) {
+			if got := CaptionFromFilename(tt.fileName); got != tt.want {
+				t.Errorf("CaptionFromFilename(%q) = %q, want %q", tt.fileName, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCaptionFor(t *testing.T) {
+	captionMap := map[string]string{
+		"03_la-playa_web.jpg": "Amanecer en la playa",
+		"el_faro.tif":         "El faro de noche",
+		"04_sin_titulo.jpg":   "",
+	}
+	tests := []struct {
+		name     string
+		fileName string
+		want     string
+	}{
+		{name: "mapped by optimized name", fileName: "03_la-playa_web.jpg", want: "Amanecer en la playa"},
+		{name: "mapped by original name", fileName: "el_faro_web.jpg", want: "El faro de noche"},
+		{name: "mapped by original name, other format", fileName: "el_faro_web.webp", want: "El faro de noche"},
+		{name: "mapped to empty", fileName: "04_sin_titulo_web.jpg", want: ""},
+		{name: "not mapped", fileName: "05_la-montaña_web.jpg", want: "La montaña"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := captionFor(tt.fileName, captionMap); got != tt.want {
+				t.Errorf("captionFor(%q) = %q, want %q", tt.fileName, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package wordpress
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"excentrico-tools-go/internal/config"
+	"excentrico-tools-go/internal/services"
+)
+
+// fakeWordPress serves the media and project routes of the WordPress REST API from memory
+type fakeWordPress struct {
+	mu     sync.Mutex
+	nextID int
+	media  map[int]*services.WordPressMedia
+	posts  map[int]*services.WordPressPost
+	// postBodies are the decoded bodies of every project create and update, in order
+	postBodies []map[string]any
+	// mediaUpdates are the decoded bodies of every media update, by media ID
+	mediaUpdates map[int][]map[string]any
+	// deletedMedia are the IDs of deleted media, in order
+	deletedMedia []int
+	// userLookups counts requests for the authenticated user; rejectUser fails them
+	userLookups int
+	rejectUser  bool
+}
+
+// newFakeWordPress starts a fake WordPress site and returns a service talking to it
+func newFakeWordPress(t *testing.T) (*fakeWordPress, *services.WordPressService) {
+	t.Helper()
+	return newFakeWordPressWithConfig(t, config.WordPressConfig{})
+}
+
+// newFakeWordPressWithConfig is newFakeWordPress with extra service settings; the base
+// URL and credentials are filled in
+func newFakeWordPressWithConfig(t *testing.T, cfg config.WordPressConfig) (*fakeWordPress, *services.WordPressService) {
+	t.Helper()
+	fake := &fakeWordPress{
+		nextID:       100,
+		media:        make(map[int]*services.WordPressMedia),
+		posts:        make(map[int]*services.WordPressPost),
+		mediaUpdates: make(map[int][]map[string]any),
+	}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	cfg.BaseURL = srv.URL
+	cfg.Username = "editor"
+	cfg.ApplicationPassword = "secret"
+	return fake, services.NewWordPressService(context.Background(), cfg)
+}
+
+func (f *fakeWordPress) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	route := strings.TrimPrefix(r.URL.Path, "/wp-json")
+	switch {
+	case r.Method == http.MethodPost && route == "/wp/v2/media":
+		name, err := uploadedFileName(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.nextID++
+		media := &services.WordPressMedia{
+			ID:        f.nextID,
+			Title:     services.WordPressRenderedField{Rendered: r.FormValue("title")},
+			SourceURL: "https://example.test/wp-content/uploads/" + name,
+			AltText:   r.FormValue("alt_text"),
+		}
+		f.media[media.ID] = media
+		writeJSON(w, http.StatusCreated, media)
+	case strings.HasPrefix(route, "/wp/v2/media/"):
+		id, err := strconv.Atoi(strings.TrimPrefix(route, "/wp/v2/media/"))
+		media, exists := f.media[id]
+		if err != nil || !exists {
+			writeJSON(w, http.StatusNotFound, map[string]string{"code": "rest_post_invalid_id"})
+			return
+		}
+		if r.Method == http.MethodDelete {
+			delete(f.media, id)
+			f.deletedMedia = append(f.deletedMedia, id)
+			writeJSON(w, http.StatusOK, map[string]any{"deleted": true, "previous": media})
+			return
+		}
+		if r.Method == http.MethodPost || r.Method == http.MethodPut {
+			var fields map[string]any
+			if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			f.mediaUpdates[id] = append(f.mediaUpdates[id], fields)
+			if altText, ok := fields["alt_text"].(string); ok {
+				media.AltText = altText
+			}
+		}
+		writeJSON(w, http.StatusOK, media)
+	case route == "/wp/v2/users/me":
+		f.userLookups++
+		if f.rejectUser {
+			writeJSON(w, http.StatusForbidden, map[string]string{"code": "rest_forbidden"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"id": 1, "username": "editor"})
+	case route == "/wp/v2/project" && r.Method == http.MethodGet:
+		var posts []*services.WordPressPost
+		for _, post := range f.posts {
+			if slug := r.URL.Query().Get("slug"); slug == "" || post.Slug == slug {
+				posts = append(posts, post)
+			}
+		}
+		w.Header().Set("X-WP-TotalPages", "1")
+		writeJSON(w, http.StatusOK, posts)
+	case route == "/wp/v2/project" && r.Method == http.MethodPost:
+		f.nextID++
+		post, err := f.savePost(r, &services.WordPressPost{ID: f.nextID, Status: "draft"})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.posts[post.ID] = post
+		writeJSON(w, http.StatusCreated, post)
+	case strings.HasPrefix(route, "/wp/v2/project/"):
+		id, err := strconv.Atoi(strings.TrimPrefix(route, "/wp/v2/project/"))
+		post, exists := f.posts[id]
+		if err != nil || !exists {
+			writeJSON(w, http.StatusNotFound, map[string]string{"code": "rest_post_invalid_id"})
+			return
+		}
+		if r.Method == http.MethodPut || r.Method == http.MethodPost {
+			if post, err = f.savePost(r, post); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		writeJSON(w, http.StatusOK, post)
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"code": "rest_no_route"})
+	}
+}
+
+// savePost applies the fields sent in a project create or update to post, as WordPress
+// does, and records the request body
+func (f *fakeWordPress) savePost(r *http.Request, post *services.WordPressPost) (*services.WordPressPost, error) {
+	var body map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	f.postBodies = append(f.postBodies, body)
+
+	data, _ := json.Marshal(body)
+	saved := *post
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, err
+	}
+	saved.ID = post.ID
+	if saved.Slug == "" {
+		saved.Slug = fmt.Sprintf("project-%d", saved.ID)
+	}
+	saved.Link = fmt.Sprintf("https://example.test/project/%s/", saved.Slug)
+	saved.Modified = "2025-01-01T00:00:00"
+	return &saved, nil
+}
+
+// uploadedFileName returns the name of the file in a media upload, sent either as a
+// multipart form or as the raw body with a Content-Disposition header
+func uploadedFileName(r *http.Request) (string, error) {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			return "", err
+		}
+		var header *multipart.FileHeader
+		if files := r.MultipartForm.File["file"]; len(files) > 0 {
+			header = files[0]
+		}
+		if header == nil {
+			return "", fmt.Errorf("no file in the upload")
+		}
+		return header.Filename, nil
+	}
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Disposition"))
+	if err != nil {
+		return "", err
+	}
+	return params["filename"], nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,31 @@
+package wordpress
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"excentrico-tools-go/internal/logger"
+	"excentrico-tools-go/internal/services"
+	"excentrico-tools-go/internal/testutil/memsql"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitWithWriter("excentrico-tools-go-test", io.Discard)
+	os.Exit(m.Run())
+}
+
+// newTestTurso returns a TursoService on a fresh in-memory database
+func newTestTurso(t *testing.T) *services.TursoService {
+	t.Helper()
+	db, err := memsql.Open()
+	if err != nil {
+		t.Fatalf("opening in-memory database: %v", err)
+	}
+	service, err := services.NewTursoServiceWithDB(db)
+	if err != nil {
+		t.Fatalf("NewTursoServiceWithDB: %v", err)
+	}
+	t.Cleanup(func() { service.Close() })
+	return service
+}
@@ -0,0 +1,46 @@
+package wordpress
+
+import (
+	"path/filepath"
+	"strings"
+
+	"excentrico-tools-go/internal/utils"
+)
+
+// DefaultMediaTitleTemplate is the media title format used when none is configured
+const DefaultMediaTitleTemplate = "{film} - {name}"
+
+// MediaNaming renders the title and alt text of uploaded media from templates with the
+// placeholders {film} (film title), {name} (file name without the _web.jpg suffix) and {folder}
+// (Drive folder the image came from). An empty alt template keeps the role-derived alt text
+type MediaNaming struct {
+	TitleTemplate string
+	AltTemplate   string
+}
+
+// Title renders the media title of an optimized image
+func (n MediaNaming) Title(webFile, filmTitle string) string {
+	template := n.TitleTemplate
+	if template == "" {
+		template = DefaultMediaTitleTemplate
+	}
+	return renderMediaTemplate(template, webFile, filmTitle)
+}
+
+// AltText renders the alt text of an optimized image, deriving it from the image's role
+// when no alt template is configured
+func (n MediaNaming) AltText(webFile, filmTitle string, directors []string) string {
+	if n.AltTemplate == "" {
+		return DeriveAltText(ClassifyMedia(webFile), filepath.Base(webFile), filmTitle, directors)
+	}
+	return renderMediaTemplate(n.AltTemplate, webFile, filmTitle)
+}
+
+func renderMediaTemplate(template, webFile, filmTitle string) string {
+	name := utils.TrimOptimizedSuffix(filepath.Base(webFile))
+	folder := filepath.Base(filepath.Dir(webFile))
+	if folder == "." || folder == string(filepath.Separator) {
+		folder = ""
+	}
+	return strings.NewReplacer("{film}", filmTitle, "{name}", name, "{folder}", folder).Replace(template)
+}
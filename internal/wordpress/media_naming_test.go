@@ -0,0 +1,58 @@
+package wordpress
+
+import "testing"
+
+func TestMediaNaming(t *testing.T) {
+	tests := []struct {
+		name      string
+		naming    MediaNaming
+		webFile   string
+		wantTitle string
+		wantAlt   string
+	}{
+		{
+			name:      "defaults",
+			webFile:   "films/la-playa/optimized/Stills/01_web.jpg",
+			wantTitle: "La playa - 01",
+			wantAlt:   "Still from La playa",
+		},
+		{
+			name:      "custom templates",
+			naming:    MediaNaming{TitleTemplate: "{film} ({folder}) {name}", AltTemplate: "{folder} of {film}"},
+			webFile:   "films/la-playa/optimized/Stills/01_web.jpg",
+			wantTitle: "La playa (Stills) 01",
+			wantAlt:   "Stills of La playa",
+		},
+		{
+			name:      "other optimized formats",
+			naming:    MediaNaming{TitleTemplate: "{name}"},
+			webFile:   "films/la-playa/optimized/Dir/ana_ruiz_web.png",
+			wantTitle: "ana_ruiz",
+			wantAlt:   "Ana Ruiz",
+		},
+		{
+			name:      "file without a folder",
+			naming:    MediaNaming{TitleTemplate: "{film}/{folder}/{name}", AltTemplate: "{film}"},
+			webFile:   "fondo_web.jpg",
+			wantTitle: "La playa//fondo",
+			wantAlt:   "La playa",
+		},
+		{
+			name:      "template without placeholders",
+			naming:    MediaNaming{TitleTemplate: "Excéntrico 2025", AltTemplate: "Festival image"},
+			webFile:   "films/la-playa/optimized/Stills/01_web.jpg",
+			wantTitle: "Excéntrico 2025",
+			wantAlt:   "Festival image",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.naming.Title(tt.webFile, "La playa"); got != tt.wantTitle {
+				t.Errorf("Title(%q) = %q, want %q", tt.webFile, got, tt.wantTitle)
+			}
+			if got := tt.naming.AltText(tt.webFile, "La playa", []string{"Ana Ruiz"}); got != tt.wantAlt {
+				t.Errorf("AltText(%q) = %q, want %q", tt.webFile, got, tt.wantAlt)
+			}
+		})
+	}
+}
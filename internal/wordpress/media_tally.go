@@ -0,0 +1,67 @@
+package wordpress
+
+import (
+	"fmt"
+	"sync"
+)
+
+// MediaTally counts, across a whole run, the optimized images whose upload was skipped
+// because their content hash matched the uploaded media and the images actually uploaded.
+// It is safe for concurrent use; the zero value is ready and nil ignores every record
+type MediaTally struct {
+	mu         sync.Mutex
+	reused     int
+	uploaded   int
+	bytesSaved int64
+}
+
+// RecordReused counts an image of size bytes that was not uploaded again
+func (t *MediaTally) RecordReused(size int64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.reused++
+	t.bytesSaved += size
+}
+
+// RecordUploaded counts an image sent to WordPress
+func (t *MediaTally) RecordUploaded() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.uploaded++
+}
+
+// Totals returns the reused and uploaded image counts and the bytes not uploaded
+func (t *MediaTally) Totals() (reused, uploaded int, bytesSaved int64) {
+	if t == nil {
+		return 0, 0, 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.reused, t.uploaded, t.bytesSaved
+}
+
+// String summarizes the tally, e.g. "12 images reused, 3 uploaded, 4.2 MB saved"
+func (t *MediaTally) String() string {
+	reused, uploaded, bytesSaved := t.Totals()
+	return fmt.Sprintf("%d images reused, %d uploaded, %s saved", reused, uploaded, formatBytes(bytesSaved))
+}
+
+// formatBytes renders a byte count with a binary unit, e.g. 1536 -> "1.5 KB"
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d bytes", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
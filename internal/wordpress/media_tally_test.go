@@ -0,0 +1,73 @@
+package wordpress
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestMediaTally(t *testing.T) {
+	tests := []struct {
+		name           string
+		reusedSizes    []int64
+		uploads        int
+		wantBytesSaved int64
+		want           string
+	}{
+		{name: "empty", want: "0 images reused, 0 uploaded, 0 bytes saved"},
+		{name: "only uploads", uploads: 3, want: "0 images reused, 3 uploaded, 0 bytes saved"},
+		{name: "bytes", reusedSizes: []int64{300, 200}, uploads: 1, wantBytesSaved: 500, want: "2 images reused, 1 uploaded, 500 bytes saved"},
+		{name: "kilobytes", reusedSizes: []int64{1024, 512}, wantBytesSaved: 1536, want: "2 images reused, 0 uploaded, 1.5 KB saved"},
+		{name: "megabytes", reusedSizes: []int64{3 << 20, 1 << 19}, uploads: 2, wantBytesSaved: 3<<20 + 1<<19, want: "2 images reused, 2 uploaded, 3.5 MB saved"},
+		{name: "gigabytes", reusedSizes: []int64{5 << 30}, wantBytesSaved: 5 << 30, want: "1 images reused, 0 uploaded, 5.0 GB saved"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tally := &MediaTally{}
+			for _, size := range tt.reusedSizes {
+				tally.RecordReused(size)
+			}
+			for range tt.uploads {
+				tally.RecordUploaded()
+			}
+
+			reused, uploaded, bytesSaved := tally.Totals()
+			if reused != len(tt.reusedSizes) || uploaded != tt.uploads || bytesSaved != tt.wantBytesSaved {
+				t.Errorf("Totals() = %d, %d, %d, want %d, %d, %d", reused, uploaded, bytesSaved, len(tt.reusedSizes), tt.uploads, tt.wantBytesSaved)
+			}
+			if got := tally.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMediaTallyNil(t *testing.T) {
+	var tally *MediaTally
+	tally.RecordReused(100)
+	tally.RecordUploaded()
+	if reused, uploaded, bytesSaved := tally.Totals(); reused != 0 || uploaded != 0 || bytesSaved != 0 {
+		t.Errorf("nil Totals() = %d, %d, %d, want zeros", reused, uploaded, bytesSaved)
+	}
+}
+
+func TestMediaTallyConcurrent(t *testing.T) {
+	const workers, perWorker = 8, 100
+	tally := &MediaTally{}
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range perWorker {
+				tally.RecordReused(10)
+				tally.RecordUploaded()
+			}
+		}()
+	}
+	wg.Wait()
+
+	reused, uploaded, bytesSaved := tally.Totals()
+	if reused != workers*perWorker || uploaded != workers*perWorker || bytesSaved != workers*perWorker*10 {
+		t.Errorf("Totals() = %d, %d, %d, want %d, %d, %d", reused, uploaded, bytesSaved, workers*perWorker, workers*perWorker, workers*perWorker*10)
+	}
+}
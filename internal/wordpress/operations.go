@@ -1,11 +1,16 @@
 package wordpress
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"maps"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"unicode"
 
@@ -39,19 +44,25 @@ func CreateWordPressSlug(title string) string {
 	return slug
 }
 
-// UploadMediaToWordPress uploads optimized images to WordPress
-func UploadMediaToWordPress(wordpressService *services.WordPressService, tursoService *services.TursoService, filmDir string, filmTitle string) ([]int, error) {
+// UploadMediaToWordPress uploads the optimized images found under filmDir (the files named
+// with one of the suffixes of formats) to WordPress, with alt text and captions derived
+// from each image's role (see ClassifyMedia). Files already uploaded are skipped unless
+// their content changed, and files whose content matches media uploaded for the film
+// under another name reuse it; with replaceChanged the media of a changed file is deleted
+// once its new version is uploaded. Skipped and uploaded files are recorded in tally,
+// which may be nil
+func UploadMediaToWordPress(wordpressService *services.WordPressService, tursoService *services.TursoService, filmDir string, formats utils.OutputFormats, filmID string, filmTitle string, directors []string, naming MediaNaming, replaceChanged bool, tally *MediaTally) ([]int, error) {
 	l := logger.Get()
 	op := l.StartOperation("upload_wordpress_media")
+	op.KeepAlways()
 	
-	filmID := utils.SanitizeFilename(filmTitle)
 	op.WithFilm(filmID, filmTitle, "", "")
 	op.WithContext("film_dir", filmDir)
 
 	metadata := &models.WordPressMetadata{}
 	err := tursoService.GetWordPressMetadata(filmID, metadata)
 	if err != nil {
-		if strings.Contains(err.Error(), "metadata not found") {
+		if errors.Is(err, services.ErrMetadataNotFound) {
 			op.WithContext("existing_metadata", false)
 			metadata = nil
 		} else {
@@ -63,13 +74,19 @@ func UploadMediaToWordPress(wordpressService *services.WordPressService, tursoSe
 		op.WithContext("existing_metadata", true)
 	}
 
+	suffix := strings.Join(formats.Suffixes(), ", ")
 	var webFiles []string
+	if _, statErr := os.Stat(filmDir); os.IsNotExist(statErr) {
+		op.WithCounts(0, 0, 0, 0, 0, 0)
+		op.Complete(fmt.Sprintf("No optimized image directory found at %s", filmDir))
+		return []int{}, nil
+	}
 	err = filepath.Walk(filmDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		if !info.IsDir() && strings.HasSuffix(strings.ToLower(info.Name()), "_web.jpg") {
+		if !info.IsDir() && formats.IsOptimizedImage(info.Name()) {
 			webFiles = append(webFiles, path)
 		}
 
@@ -77,26 +94,29 @@ func UploadMediaToWordPress(wordpressService *services.WordPressService, tursoSe
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to find _web.jpg files: %v", err)
+		op.Fail(fmt.Sprintf("Failed to find %s files", suffix), err)
+		return nil, fmt.Errorf("failed to find %s files: %v", suffix, err)
 	}
 
 	if len(webFiles) == 0 {
-		log.Printf("No _web.jpg files found for '%s'", filmTitle)
+		op.WithCounts(0, 0, 0, 0, 0, 0)
+		op.Complete(fmt.Sprintf("No %s files found", suffix))
 		return []int{}, nil
 	}
 
-	log.Printf("Found %d _web.jpg files for '%s'", len(webFiles), filmTitle)
+	op.WithContext("web_file_count", len(webFiles))
 
 	existingImageMetadata := make(map[string]int)
 	err = tursoService.GetWPImagesMetadata(filmID, &existingImageMetadata)
 	if err != nil {
-		if strings.Contains(err.Error(), "metadata not found") {
-			log.Printf("No existing image metadata found for '%s', will start fresh", filmTitle)
+		if errors.Is(err, services.ErrMetadataNotFound) {
+			op.WithContext("existing_image_metadata", false)
 		} else {
-			log.Printf("Failed to load existing image metadata: %v", err)
+			op.WithContext("existing_image_metadata_error", err.Error())
 		}
 	} else {
-		log.Printf("Loaded existing image metadata: %d images already uploaded", len(existingImageMetadata))
+		op.WithContext("existing_image_metadata", true)
+		op.WithContext("existing_image_count", len(existingImageMetadata))
 	}
 
 	var uploadedMedia []map[string]any
@@ -106,27 +126,84 @@ func UploadMediaToWordPress(wordpressService *services.WordPressService, tursoSe
 		imageMetadataMap[fileName] = mediaID
 	}
 
+	// Content hashes of the uploaded files, so a re-optimized file with the same name is uploaded again
+	imageHashes := make(map[string]string)
+	if err := tursoService.GetWPImageHashesMetadata(filmID, &imageHashes); err != nil && !errors.Is(err, services.ErrMetadataNotFound) {
+		op.WithContext("image_hashes_error", err.Error())
+	}
+
+	// Hash every file up front. Files uploaded before hashes were recorded only get their
+	// hash stored, so their media joins the content index below before any lookup
+	fileHashes := make(map[string]string, len(webFiles))
+	for _, webFile := range webFiles {
+		hash, hashErr := fileHash(webFile)
+		if hashErr != nil {
+			op.WithContext("hash_error", hashErr.Error())
+			continue
+		}
+		fileHashes[webFile] = hash
+		fileName := filepath.Base(webFile)
+		if _, hashed := imageHashes[fileName]; !hashed {
+			if _, exists := existingImageMetadata[fileName]; exists {
+				imageHashes[fileName] = hash
+			}
+		}
+	}
+
+	// Media of the film by content hash, so a renamed or duplicated file reuses the
+	// media uploaded for identical content instead of adding a copy to the library
+	mediaByHash := make(map[string]int)
+	for fileName, hash := range imageHashes {
+		if mediaID, exists := existingImageMetadata[fileName]; exists && hash != "" {
+			mediaByHash[hash] = mediaID
+		}
+	}
+
 	uploadedCount := 0
 	skippedCount := 0
+	reusedCount := 0
 	failedUploads := 0
+	changedCount := 0
+	replacedCount := 0
 
 	for _, webFile := range webFiles {
 		fileName := filepath.Base(webFile)
+		hash := fileHashes[webFile]
 
-		if _, exists := existingImageMetadata[fileName]; exists {
-			skippedCount++
-			continue
+		previousID, exists := existingImageMetadata[fileName]
+		if exists {
+			if storedHash := imageHashes[fileName]; hash == "" || storedHash == hash {
+				if info, err := os.Stat(webFile); err == nil {
+					tally.RecordReused(info.Size())
+				}
+				skippedCount++
+				continue
+			}
+			changedCount++
 		}
 
-		title := strings.TrimSuffix(fileName, "_web.jpg")
-		title = fmt.Sprintf("%s - %s", filmTitle, title)
+		if mediaID, uploaded := mediaByHash[hash]; uploaded && hash != "" {
+			imageMetadataMap[fileName] = mediaID
+			imageHashes[fileName] = hash
+			if info, err := os.Stat(webFile); err == nil {
+				tally.RecordReused(info.Size())
+			}
+			reusedCount++
+			continue
+		}
 
-		altText := fmt.Sprintf("Image from %s", filmTitle)
+		title := naming.Title(webFile, filmTitle)
+		role := ClassifyMedia(webFile)
+		altText := naming.AltText(webFile, filmTitle, directors)
 
 		uploadOp := l.StartOperation("upload_single_media")
 		uploadOp.WithFilm(filmID, filmTitle, "", "")
 		uploadOp.WithContext("file_name", fileName)
 		uploadOp.WithContext("file_path", webFile)
+		if exists {
+			uploadOp.WithContext("changed_content", true)
+			uploadOp.WithContext("previous_media_id", previousID)
+		}
 
 		media, err := wordpressService.UploadMediaFromFile(webFile, title, altText)
 		if err != nil {
@@ -137,6 +214,23 @@ func UploadMediaToWordPress(wordpressService *services.WordPressService, tursoSe
 
 		uploadOp.WithWordPress(0, media.ID, "")
 		uploadOp.WithContext("media_title", media.Title.String())
+		uploadOp.WithContext("media_role", role)
+
+		// Stills are captioned by the gallery caption settings; other roles carry their alt text
+		if wordpressService.DryRun() {
+			uploadOp.WithContext("dry_run", true)
+		} else if role == MediaRoleDirector || role == MediaRolePoster {
+			if _, err := wordpressService.UpdateMedia(media.ID, map[string]any{"caption": altText}); err != nil {
+				uploadOp.WithContext("caption_error", err.Error())
+			}
+		}
+		if exists && replaceChanged && !wordpressService.DryRun() {
+			if err := wordpressService.DeleteMedia(previousID); err != nil {
+				uploadOp.WithContext("replace_error", err.Error())
+			} else {
+				replacedCount++
+			}
+		}
 		uploadOp.Complete(fmt.Sprintf("Successfully uploaded media: %s", fileName))
 
 		mediaInfo := map[string]any{
@@ -156,6 +250,11 @@ func UploadMediaToWordPress(wordpressService *services.WordPressService, tursoSe
 		uploadedMedia = append(uploadedMedia, mediaInfo)
 
 		imageMetadataMap[fileName] = media.ID
+		if hash != "" {
+			imageHashes[fileName] = hash
+			mediaByHash[hash] = media.ID
+		}
+		tally.RecordUploaded()
 		uploadedCount++
 	}
 
@@ -173,20 +272,76 @@ func UploadMediaToWordPress(wordpressService *services.WordPressService, tursoSe
 		})
 	}
 
+	if err := tursoService.SaveWPImageHashesMetadata(filmID, imageHashes); err != nil {
+		op.Warn(&logger.WideEvent{
+			Message: "Failed to save image hashes",
+		})
+	}
+
+	// The first poster becomes the featured image and is kept out of the stills gallery
+	for _, webFile := range webFiles {
+		fileName := filepath.Base(webFile)
+		if mediaID := imageMetadataMap[fileName]; mediaID > 0 && ClassifyMedia(webFile) == MediaRolePoster {
+			op.WithContext("poster_media_id", mediaID)
+			if err := tursoService.SavePosterMetadata(filmID, &models.PosterMetadata{MediaID: mediaID, FileName: fileName}); err != nil {
+				op.Warn(&logger.WideEvent{
+					Message: "Failed to save poster metadata",
+				})
+			}
+			break
+		}
+	}
+
 	op.WithCounts(len(webFiles), len(webFiles), 0, skippedCount, uploadedCount, 0)
 	op.WithContext("failed_uploads", failedUploads)
-	op.Complete(fmt.Sprintf("Media upload completed: %d new uploads, %d skipped, %d total files", uploadedCount, skippedCount, len(webFiles)))
+	op.WithContext("reused_media", reusedCount)
+	op.WithContext("changed_uploads", changedCount)
+	op.WithContext("replaced_media", replacedCount)
+	op.Complete(fmt.Sprintf("Media upload completed: %d new uploads (%d changed), %d skipped, %d reused by content, %d total files", uploadedCount, changedCount, skippedCount, reusedCount, len(webFiles)))
 
+	// Files sharing content share a media ID, which is listed once. The files are listed by
+	// name so the gallery and the fallback featured image do not change from run to run
 	var imageIds []int
-	for _, mediaID := range imageMetadataMap {
-		imageIds = append(imageIds, mediaID)
+	for _, fileName := range slices.Sorted(maps.Keys(imageMetadataMap)) {
+		if mediaID := imageMetadataMap[fileName]; !slices.Contains(imageIds, mediaID) {
+			imageIds = append(imageIds, mediaID)
+		}
 	}
 
 	return imageIds, nil
 }
 
+// fileHash returns the hex SHA-256 of a file's content
+func fileHash(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to hash %s: %v", path, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// ProjectOptions are the settings CreateOrUpdateWordPressProject saves projects with
+type ProjectOptions struct {
+	// SlugTemplate renders project slugs; empty uses DefaultSlugTemplate (see ProjectSlug)
+	SlugTemplate string
+	// UniqueSlugs appends "-2", "-3", ... to the slug of a new project when another post has it
+	UniqueSlugs bool
+	// VerifyBuilder re-reads every saved project to check the Divi builder flag stuck
+	VerifyBuilder bool
+	// DetectMultipleDirectors splits DIRECCIÓN on its separators when Multi Dir is blank
+	DetectMultipleDirectors bool
+	// PermalinkWriter records the permalink of every saved project; nil records nothing
+	PermalinkWriter PermalinkWriter
+}
+
 // CreateOrUpdateWordPressProject creates or updates a WordPress project
-func CreateOrUpdateWordPressProject(wordpressService *services.WordPressService, diviTemplateService *services.DiviTemplateService, tursoService *services.TursoService, filmDir string, filmData map[string]any, year string, imageIds []int, templateConfig *services.TemplateData) error {
+func CreateOrUpdateWordPressProject(wordpressService *services.WordPressService, diviTemplateService *services.DiviTemplateService, tursoService *services.TursoService, filmDir string, filmID string, filmData map[string]any, year string, imageIds []int, templateConfig *services.TemplateData, opts ProjectOptions) error {
 	l := logger.Get()
 	op := l.StartOperation("create_update_wordpress_project")
 	
@@ -195,8 +350,6 @@ func CreateOrUpdateWordPressProject(wordpressService *services.WordPressService,
 		filmTitle = title.(string)
 	}
 
-	filmID := utils.SanitizeFilename(filmTitle)
-	
 	section := ""
 	if sec, exists := filmData["SECCIÓN"]; exists && sec != nil {
 		section = sec.(string)
@@ -207,10 +360,11 @@ func CreateOrUpdateWordPressProject(wordpressService *services.WordPressService,
 	op.WithContext("image_count", len(imageIds))
 
 	var metadata *models.WordPressMetadata
+	var permalink string
 	existingMetadata := &models.WordPressMetadata{}
 	err := tursoService.GetWordPressMetadata(filmID, existingMetadata)
 	if err != nil {
-		if strings.Contains(err.Error(), "metadata not found") {
+		if errors.Is(err, services.ErrMetadataNotFound) {
 			op.WithContext("existing_metadata", false)
 			metadata = nil
 		} else {
@@ -223,25 +377,45 @@ func CreateOrUpdateWordPressProject(wordpressService *services.WordPressService,
 		op.WithContext("existing_metadata", true)
 	}
 
-	slugParts := []string{}
-	if filmTitle != "Untitled Film" {
-		slugParts = append(slugParts, filmTitle)
-	}
-	if section != "" {
-		slugParts = append(slugParts, section)
-	}
-	if year != "" {
-		slugParts = append(slugParts, year)
-	}
-
-	slugText := strings.Join(slugParts, " ")
-	if slugText == "" {
-		slugText = "untitled-film"
+	slugTitle := filmTitle
+	if slugTitle == "Untitled Film" {
+		slugTitle = ""
 	}
+	slug := ProjectSlug(opts.SlugTemplate, slugTitle, section, year)
 
 	filmDataStruct := ConvertObjToFilmData(filmData)
+	filmDataStruct.DetectMultipleDirectors = opts.DetectMultipleDirectors
+
+	diviTemplate, shortcodes := diviTemplateService.GenerateCompleteTemplate(filmDataStruct, imageIds, wordpressService, tursoService, filmID, year, templateConfig)
+
+	// File and stdout outputs render the template without touching the WordPress post
+	if output := diviTemplateService.TemplateOutput(); output != services.TemplateOutputWordPress {
+		rendered := &services.RenderedTemplate{
+			FilmID:         filmID,
+			FilmDir:        filmDir,
+			ImageIds:       imageIds,
+			Shortcodes:     shortcodes,
+			BuilderVersion: templateConfig.ResolvedBuilderVersion(),
+		}
+		if metadata != nil {
+			rendered.PostID = metadata.PostID
+		}
+		op.WithContext("template_output", output)
+		if err := diviTemplateService.TemplateSink(wordpressService).WriteTemplate(rendered); err != nil {
+			op.Fail("Failed to write Divi template", err)
+			return fmt.Errorf("failed to write Divi template: %v", err)
+		}
+		op.Complete(fmt.Sprintf("Wrote Divi template for '%s' to %s", filmTitle, output))
+		return nil
+	}
 
-	_, _ = diviTemplateService.GenerateCompleteTemplate(filmDataStruct, imageIds, wordpressService, tursoService, filmID, year, templateConfig)
+	if templateConfig != nil && templateConfig.GalleryCaptions == services.GalleryCaptionsFilename && !wordpressService.DryRun() {
+		if err := ApplyGalleryCaptions(wordpressService, tursoService, filmID, filmDir, diviTemplate.ImageGalleryIds); err != nil {
+			op.Warn(&logger.WideEvent{
+				Message: fmt.Sprintf("Failed to apply gallery captions: %v", err),
+			})
+		}
+	}
 
 	var categoryIDs []int
 	if filmDataStruct.Seccion != "" {
@@ -263,29 +437,49 @@ func CreateOrUpdateWordPressProject(wordpressService *services.WordPressService,
 		Title:      services.WordPressRenderedField{Rendered: filmTitle},
 		Status:     "draft",
 		Type:       "post",
-		Slug:       CreateWordPressSlug(slugText),
+		Slug:       slug,
 		Categories: categoryIDs,
 		Meta: map[string]any{
 			"_et_pb_use_builder": "on",
 		},
 	}
 
-	// Try to set a featured image from the uploaded media
-	if len(imageIds) > 0 {
-		if featuredID := selectFeaturedMediaID(imageIds, wordpressService); featuredID > 0 {
-			post.FeaturedMedia = featuredID
-		}
+	if authorID, err := wordpressService.AuthorID(); err != nil {
+		op.Warn(&logger.WideEvent{
+			Message: fmt.Sprintf("Could not resolve post author, leaving WordPress default: %v", err),
+		})
+	} else {
+		post.Author = authorID
+		op.WithContext("author_id", authorID)
+	}
+
+	if templateConfig != nil && templateConfig.ExcerptSource != "" {
+		post.Excerpt = services.WordPressRenderedField{Rendered: filmDataStruct.Synopsis(templateConfig.ExcerptSource)}
+	}
+
+	if featuredID, source := resolveFeaturedMediaID(wordpressService, tursoService, filmID, imageIds, metadata); featuredID > 0 {
+		post.FeaturedMedia = featuredID
+		op.WithContext("featured_media_id", featuredID)
+		op.WithContext("featured_media_source", source)
 	}
 
 	if metadata == nil {
 		createOp := l.StartOperation("create_wordpress_post")
 		createOp.WithFilm(filmID, filmTitle, year, section)
-		createOp.WithContext("slug", CreateWordPressSlug(slugText))
+		if opts.UniqueSlugs {
+			if uniqueSlug, err := uniqueProjectSlug(wordpressService, slug); err != nil {
+				createOp.WithContext("unique_slug_error", err.Error())
+			} else if uniqueSlug != slug {
+				createOp.WithContext("slug_collision", slug)
+				post.Slug = uniqueSlug
+			}
+		}
+		createOp.WithContext("slug", post.Slug)
 
 		createdPost, err := wordpressService.CreatePost(post)
 		if err != nil {
 			createOp.Fail("Failed to create WordPress post", err)
-			return fmt.Errorf("failed to create WordPress post: %v", err)
+			return fmt.Errorf("failed to create WordPress post: %w", err)
 		}
 
 		metadata = &models.WordPressMetadata{
@@ -296,6 +490,7 @@ func CreateOrUpdateWordPressProject(wordpressService *services.WordPressService,
 			CreatedAt: createdPost.Date,
 			UpdatedAt: createdPost.Modified,
 		}
+		permalink = createdPost.Permalink()
 
 		createOp.WithWordPress(createdPost.ID, 0, createdPost.Slug)
 		createOp.Complete(fmt.Sprintf("Created WordPress post ID %d", createdPost.ID))
@@ -308,18 +503,24 @@ func CreateOrUpdateWordPressProject(wordpressService *services.WordPressService,
 		updatedPost, err := wordpressService.UpdatePost(metadata.PostID, post)
 		if err != nil {
 			updateOp.Fail("Failed to update WordPress post", err)
-			return fmt.Errorf("failed to update WordPress post: %v", err)
+			return fmt.Errorf("failed to update WordPress post: %w", err)
 		}
 
 		metadata.Title = updatedPost.Title.String()
 		metadata.Slug = updatedPost.Slug
 		metadata.Status = updatedPost.Status
 		metadata.UpdatedAt = updatedPost.Modified
+		permalink = updatedPost.Permalink()
 
 		updateOp.WithWordPress(updatedPost.ID, 0, updatedPost.Slug)
 		updateOp.Complete(fmt.Sprintf("Updated WordPress post ID %d", updatedPost.ID))
 	}
 
+	if opts.VerifyBuilder && !wordpressService.DryRun() {
+		verifyBuilderEnabled(wordpressService, metadata.PostID, filmID, filmTitle)
+	}
+
+	metadata.FeaturedMediaID = post.FeaturedMedia
 	if err := tursoService.SaveWordPressMetadata(filmID, metadata); err != nil {
 		op.Fail("Failed to save WordPress metadata", err)
 		return fmt.Errorf("failed to save WordPress metadata: %v", err)
@@ -338,11 +539,37 @@ func CreateOrUpdateWordPressProject(wordpressService *services.WordPressService,
 		return fmt.Errorf("failed to save Divi template to file: %v", err)
 	}
 
+	if !wordpressService.DryRun() {
+		recordPermalink(opts.PermalinkWriter, filmData, permalink, metadata.PostID, filmID, filmTitle)
+	}
+
 	op.WithWordPress(metadata.PostID, 0, metadata.Slug)
 	op.Complete(fmt.Sprintf("Successfully created/updated WordPress project for '%s'", filmTitle))
 	return nil
 }
 
+// resolveFeaturedMediaID picks the featured image of a film's post from its uploaded
+// media and reports where the choice came from. The uploaded poster wins; otherwise the
+// featured image stored for the post is kept while its media is still attached, so
+// updates do not flip it, then the header background is used, then a keyword pick
+func resolveFeaturedMediaID(wordpressService *services.WordPressService, tursoService *services.TursoService, filmID string, imageIds []int, metadata *models.WordPressMetadata) (int, string) {
+	poster := &models.PosterMetadata{}
+	if err := tursoService.GetPosterMetadata(filmID, poster); err == nil && slices.Contains(imageIds, poster.MediaID) {
+		return poster.MediaID, "poster"
+	}
+	if metadata != nil && metadata.FeaturedMediaID > 0 && slices.Contains(imageIds, metadata.FeaturedMediaID) {
+		return metadata.FeaturedMediaID, "stored"
+	}
+	background := &models.BackgroundImageMetadata{}
+	if err := tursoService.GetBackgroundImageMetadata(filmID, background); err == nil && slices.Contains(imageIds, background.MediaID) {
+		return background.MediaID, "background"
+	}
+	if featuredID := selectFeaturedMediaID(imageIds, wordpressService); featuredID > 0 {
+		return featuredID, "selected"
+	}
+	return 0, ""
+}
+
 // selectFeaturedMediaID attempts to pick the most suitable featured image ID
 // Preference order by media title/filename/alt text contains: poster, portada, cover
 // Fallbacks to the first available image ID
@@ -391,7 +618,7 @@ func updateMediaMetadataWithPostID(tursoService *services.TursoService, filmID s
 	var mediaMetadata []map[string]any
 	err := tursoService.GetMetadata(filmID, "wordpress_media", &mediaMetadata)
 	if err != nil {
-		if strings.Contains(err.Error(), "metadata not found") {
+		if errors.Is(err, services.ErrMetadataNotFound) {
 			// No media metadata to update
 			return nil
 		}
@@ -430,62 +657,12 @@ func ConvertObjToFilmData(obj map[string]any) *services.FilmData {
 		return ""
 	}
 
-	filmData.TituloOriginal = getString("TÍTULO ORIGINAL")
-	filmData.Direccion = getString("DIRECCIÓN")
-	filmData.Pais = getString("PAIS")
-	filmData.Ano = getString("AÑO")
-	filmData.Duracion = getString("DURAC.")
-	filmData.Edicion = getString("EDICIÓN")
-	filmData.Seccion = getString("SECCIÓN")
-	filmData.Tipo = getString("TIPO")
-	filmData.SocialEtiquetas = getString("SOCIAL/ETIQUETAS")
-	filmData.Idiomas = getString("Idioma(s) / Language(s)")
-	filmData.RelacionAspectRatio = getString("Relación / Aspect Ratio (4:3, 16:9 u otro)")
-	filmData.SinopsisExtendida = getString("Sinopsis extendida (máximo 70 palabras)")
-	filmData.ExtendedSynopsis = getString("Extended synopsis (english)")
-	filmData.SinopsisCompacta = getString("Sinopsis compacta  (máximo 10 palabras)")
-	filmData.ShortSynopsis = getString("Short Synopsis (log line - Uso Pink Label)")
-	filmData.NotasContenido = getString("Notas de contenido / Content notes (*)")
-	filmData.NotaIntencion = getString("Nota de intención")
-	filmData.Produccion = getString("Producción / Producer(s)")
-	filmData.Guion = getString("Guión")
-	filmData.CamaraFoto = getString("Cámara - Foto / Camera - Photography")
-	filmData.ArteDiseno = getString("Arte - Diseño / Art/Design")
-	filmData.SonidoMusica = getString("Sonido - Música / Sound - Music")
-	filmData.EdicionCredits = getString("Edición / Editor(s)")
-	filmData.Interpretes = getString("Intérpretes (especificar pronombres para subtítulos)/ Cast (please specify pronouns for subtitles)")
-	filmData.OtrosCreditos = getString("Otros créditos / Other credits")
-	filmData.FestivalesPremios = getString("Festivales y premios / Festivals & Awards")
-	filmData.BioRealizadorxs = getString("Bio Realizadorxs / Filmaker's Bio (min 150 - max 1500 caracteres)")
-	filmData.CorreoElectronico = getString("Correo electrónico / Email")
-	filmData.Telefono = getString("Teléfono / Phone number")
-	filmData.Enlaces = getString("ENLACES")
-	filmData.WebExcentrico = getString("Web Excentrico")
-	filmData.ImagenesBaja = getString("imágenes en baja")
-	filmData.ObsSubtitulos = getString("Obs. Subtitulos")
-	filmData.PublishedStatus = getString("Published Status")
-	filmData.Categoria = getString("Categoría")
-	filmData.MultiDir = getString("Multi Dir")
-
-	knownFields := map[string]bool{
-		"TÍTULO ORIGINAL": true, "DIRECCIÓN": true, "PAIS": true, "AÑO": true, "DURAC.": true, "EDICIÓN": true,
-		"SECCIÓN": true, "TIPO": true, "SOCIAL/ETIQUETAS": true, "Idioma(s) / Language(s)": true,
-		"Relación / Aspect Ratio (4:3, 16:9 u otro)": true, "Sinopsis extendida (máximo 70 palabras)": true,
-		"Extended synopsis (english)": true, "Sinopsis compacta  (máximo 10 palabras)": true,
-		"Short Synopsis (log line - Uso Pink Label)": true, "Notas de contenido / Content notes (*)": true,
-		"Nota de intención": true, "Producción / Producer(s)": true, "Guión": true,
-		"Cámara - Foto / Camera - Photography": true, "Arte - Diseño / Art/Design": true,
-		"Sonido - Música / Sound - Music": true, "Edición / Editor(s)": true,
-		"Intérpretes (especificar pronombres para subtítulos)/ Cast (please specify pronouns for subtitles)": true,
-		"Otros créditos / Other credits": true, "Festivales y premios / Festivals & Awards": true,
-		"Bio Realizadorxs / Filmaker's Bio (min 150 - max 1500 caracteres)": true,
-		"Correo electrónico / Email":                                        true, "Teléfono / Phone number": true, "ENLACES": true,
-		"Web Excentrico": true, "imágenes en baja": true, "Obs. Subtitulos": true,
-		"Published Status": true, "Categoría": true, "Multi Dir": true,
+	for _, field := range services.SheetFields {
+		field.Set(filmData, getString(field.Header))
 	}
 
 	for key, value := range obj {
-		if !knownFields[key] && value != nil {
+		if !services.IsSheetField(key) && value != nil {
 			if strValue, ok := value.(string); ok && strValue != "" {
 				filmData.AdditionalFields[key] = strValue
 			}
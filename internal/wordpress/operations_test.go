@@ -0,0 +1,471 @@
+package wordpress
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"excentrico-tools-go/internal/config"
+	"excentrico-tools-go/internal/logger"
+	"excentrico-tools-go/internal/models"
+	"excentrico-tools-go/internal/services"
+	"excentrico-tools-go/internal/utils"
+)
+
+func TestPosterIsFeaturedAndLeftOutOfGallery(t *testing.T) {
+	tests := []struct {
+		name   string
+		folder string // Drive folder of the poster
+		file   string
+	}{
+		{"posters folder", "Posters", "one-sheet.jpg"},
+		{"poster folder", "Poster", "one-sheet.jpg"},
+		{"featured image folder", "Featured Image", "one-sheet.jpg"},
+		{"poster named in stills", "Stills", "portada.jpg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, wp := newFakeWordPress(t)
+			turso := newTestTurso(t)
+			filmDir := t.TempDir()
+			const filmID = "La Película"
+
+			driveFiles := []*models.FileWithPath{
+				{ID: "1", Name: tt.file, FolderName: tt.folder, FolderPath: tt.folder},
+				{ID: "2", Name: "still-1.jpg", FolderName: "Stills", FolderPath: "Stills"},
+				{ID: "3", Name: "still-2.jpg", FolderName: "Stills", FolderPath: "Stills"},
+			}
+			for _, file := range driveFiles {
+				webFile := strings.TrimSuffix(file.Name, filepath.Ext(file.Name)) + "_web.jpg"
+				writeFile(t, filepath.Join(filmDir, file.FolderPath, webFile))
+			}
+			if err := turso.SaveDriveFilesMetadata(filmID, driveFiles); err != nil {
+				t.Fatalf("SaveDriveFilesMetadata: %v", err)
+			}
+
+			imageIds, err := UploadMediaToWordPress(wp, turso, filmDir, utils.OutputFormats{}, filmID, "La película", nil, MediaNaming{}, false, nil)
+			if err != nil {
+				t.Fatalf("UploadMediaToWordPress: %v", err)
+			}
+			if len(imageIds) != 3 {
+				t.Fatalf("uploaded %d images, want 3", len(imageIds))
+			}
+
+			poster := &models.PosterMetadata{}
+			if err := turso.GetPosterMetadata(filmID, poster); err != nil {
+				t.Fatalf("GetPosterMetadata: %v", err)
+			}
+			if !strings.HasPrefix(fake.media[poster.MediaID].SourceURL, "https://example.test/wp-content/uploads/"+strings.TrimSuffix(tt.file, ".jpg")) {
+				t.Fatalf("poster media %d is %s, want %s", poster.MediaID, fake.media[poster.MediaID].SourceURL, tt.file)
+			}
+
+			featuredID, source := resolveFeaturedMediaID(wp, turso, filmID, imageIds, nil)
+			if featuredID != poster.MediaID || source != "poster" {
+				t.Errorf("featured media %d (%s), want the poster %d", featuredID, source, poster.MediaID)
+			}
+
+			template := services.NewDiviTemplateService().GenerateDiviTemplateDataWithWordPress(&services.FilmData{TituloOriginal: "La película"}, imageIds, wp, turso, filmID)
+			gallery := strings.Split(template.GalleryMediaIds, ",")
+			if slices.Contains(gallery, strconv.Itoa(poster.MediaID)) {
+				t.Errorf("gallery %s contains the poster %d", template.GalleryMediaIds, poster.MediaID)
+			}
+			if len(gallery) != 2 {
+				t.Errorf("gallery %s, want the two stills", template.GalleryMediaIds)
+			}
+		})
+	}
+}
+
+func TestUploadImageIdsOrder(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     []string // Optimized files, relative to the film folder
+		wantNames []string // Files of the returned media, in order
+	}{
+		{
+			name:      "folders out of name order",
+			files:     []string{filepath.Join("A", "zeta_web.jpg"), filepath.Join("B", "alfa_web.jpg"), filepath.Join("C", "media_web.jpg")},
+			wantNames: []string{"alfa_web.jpg", "media_web.jpg", "zeta_web.jpg"},
+		},
+		{
+			name:      "single folder",
+			files:     []string{filepath.Join("Stills", "still-2_web.jpg"), filepath.Join("Stills", "still-10_web.jpg"), filepath.Join("Stills", "still-1_web.jpg")},
+			wantNames: []string{"still-10_web.jpg", "still-1_web.jpg", "still-2_web.jpg"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, wp := newFakeWordPress(t)
+			turso := newTestTurso(t)
+			filmDir := t.TempDir()
+			for _, file := range tt.files {
+				writeFile(t, filepath.Join(filmDir, file))
+			}
+
+			// The first run uploads the files, later runs list the media recorded in Turso
+			var first []int
+			for run := 0; run < 5; run++ {
+				imageIds, err := UploadMediaToWordPress(wp, turso, filmDir, utils.OutputFormats{}, "film-1", "La película", nil, MediaNaming{}, false, nil)
+				if err != nil {
+					t.Fatalf("UploadMediaToWordPress: %v", err)
+				}
+				if run == 0 {
+					first = imageIds
+					var names []string
+					for _, id := range imageIds {
+						names = append(names, filepath.Base(fake.media[id].SourceURL))
+					}
+					if !slices.Equal(names, tt.wantNames) {
+						t.Fatalf("media of %v, want %v", names, tt.wantNames)
+					}
+				} else if !slices.Equal(imageIds, first) {
+					t.Fatalf("run %d returned %v, want %v as in the first run", run+1, imageIds, first)
+				}
+			}
+
+			// Without a poster, the featured image falls back to the first file by name
+			featuredID, source := resolveFeaturedMediaID(wp, turso, "film-1", first, nil)
+			if featuredID != first[0] || source != "selected" {
+				t.Errorf("featured media %d (%s), want %d of %s", featuredID, source, first[0], tt.wantNames[0])
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(path), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUploadFindsImagesInOutputDir(t *testing.T) {
+	tests := []struct {
+		name      string
+		outputDir string
+		stray     string // optimized file outside the configured location, not uploaded
+	}{
+		{name: "next to originals"},
+		{name: "dedicated directory", outputDir: "optimized", stray: filepath.Join("Stills", "old_web.jpg")},
+		{name: "nested directory", outputDir: filepath.Join("build", "web"), stray: filepath.Join("Stills", "old_web.jpg")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, wp := newFakeWordPress(t)
+			turso := newTestTurso(t)
+			filmDir := t.TempDir()
+			imageService := services.NewImageService(config.ImageConfig{OutputDir: tt.outputDir})
+
+			originals := []string{
+				filepath.Join(filmDir, "Stills", "still-1.jpg"),
+				filepath.Join(filmDir, "Director", "ana.jpg"),
+			}
+			for _, original := range originals {
+				writeFile(t, original)
+				writeFile(t, imageService.OptimizedPath(filmDir, original))
+			}
+			if tt.stray != "" {
+				writeFile(t, filepath.Join(filmDir, tt.stray))
+			}
+			// The output directory mirrors the film folder's structure
+			if got, want := imageService.OptimizedPath(filmDir, originals[0]), filepath.Join(filmDir, tt.outputDir, "Stills", "still-1_web.jpg"); got != want {
+				t.Errorf("OptimizedPath = %s, want %s", got, want)
+			}
+
+			imageIds, err := UploadMediaToWordPress(wp, turso, imageService.OptimizedDir(filmDir), imageService.Formats(), "film-1", "La película", nil, MediaNaming{}, false, nil)
+			if err != nil {
+				t.Fatalf("UploadMediaToWordPress: %v", err)
+			}
+
+			var uploaded []string
+			for _, id := range imageIds {
+				uploaded = append(uploaded, filepath.Base(fake.media[id].SourceURL))
+			}
+			slices.Sort(uploaded)
+			if want := []string{"ana_web.jpg", "still-1_web.jpg"}; !slices.Equal(uploaded, want) {
+				t.Errorf("uploaded %v, want %v", uploaded, want)
+			}
+		})
+	}
+}
+
+func TestUploadEventCounts(t *testing.T) {
+	_, wp := newFakeWordPress(t)
+	turso := newTestTurso(t)
+	filmDir := t.TempDir()
+	still1 := filepath.Join(filmDir, "Stills", "still-1_web.jpg")
+	still2 := filepath.Join(filmDir, "Stills", "still-2_web.jpg")
+	writeFile(t, still1)
+	writeFile(t, still2)
+
+	// Each run builds on the files left by the previous ones
+	tests := []struct {
+		name         string
+		prepare      func(t *testing.T)
+		wantTotal    int
+		wantUploaded int
+		wantSkipped  int
+		wantChanged  float64
+		wantReused   float64
+	}{
+		{name: "first run uploads everything", prepare: func(*testing.T) {}, wantTotal: 2, wantUploaded: 2},
+		{name: "unchanged files are skipped", prepare: func(*testing.T) {}, wantTotal: 2, wantSkipped: 2},
+		{
+			name: "changed file is uploaded again",
+			prepare: func(t *testing.T) {
+				if err := os.WriteFile(still2, []byte("re-optimized"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantTotal: 2, wantUploaded: 1, wantSkipped: 1, wantChanged: 1,
+		},
+		{
+			name: "copy of an uploaded file reuses its media",
+			prepare: func(t *testing.T) {
+				data, err := os.ReadFile(still1)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(filmDir, "Stills", "still-1-copy_web.jpg"), data, 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantTotal: 3, wantSkipped: 2, wantReused: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepare(t)
+			events := captureEvents(t)
+
+			if _, err := UploadMediaToWordPress(wp, turso, filmDir, utils.OutputFormats{}, "film-1", "La película", nil, MediaNaming{}, false, nil); err != nil {
+				t.Fatalf("UploadMediaToWordPress: %v", err)
+			}
+
+			var summary *logger.WideEvent
+			for _, event := range events() {
+				if event.Operation == "upload_wordpress_media" {
+					summary = &event
+				}
+			}
+			if summary == nil {
+				t.Fatal("no upload_wordpress_media event was logged")
+			}
+			if summary.FilmID != "film-1" || summary.TotalFiles != tt.wantTotal || summary.FilesUploaded != tt.wantUploaded || summary.FilesSkipped != tt.wantSkipped {
+				t.Errorf("event film %q total %d uploaded %d skipped %d, want film-1 total %d uploaded %d skipped %d",
+					summary.FilmID, summary.TotalFiles, summary.FilesUploaded, summary.FilesSkipped, tt.wantTotal, tt.wantUploaded, tt.wantSkipped)
+			}
+			if changed := summary.Context["changed_uploads"]; changed != tt.wantChanged {
+				t.Errorf("changed_uploads = %v, want %v", changed, tt.wantChanged)
+			}
+			if reused := summary.Context["reused_media"]; reused != tt.wantReused {
+				t.Errorf("reused_media = %v, want %v", reused, tt.wantReused)
+			}
+		})
+	}
+}
+
+func TestUploadChangedMedia(t *testing.T) {
+	tests := []struct {
+		name           string
+		change         bool // rewrite the optimized file between the two runs
+		forgetHashes   bool // drop the hashes, as for files uploaded before they were recorded
+		replaceChanged bool
+		wantNewID      bool
+		wantDeleted    bool
+	}{
+		{name: "unchanged file is skipped"},
+		{name: "changed file is uploaded again", change: true, wantNewID: true},
+		{name: "changed file replaces its media", change: true, replaceChanged: true, wantNewID: true, wantDeleted: true},
+		{name: "file without a stored hash is skipped", change: true, forgetHashes: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, wp := newFakeWordPress(t)
+			turso := newTestTurso(t)
+			filmDir := t.TempDir()
+			still := filepath.Join(filmDir, "Stills", "still-1_web.jpg")
+			writeFile(t, still)
+
+			upload := func() int {
+				t.Helper()
+				if _, err := UploadMediaToWordPress(wp, turso, filmDir, utils.OutputFormats{}, "film-1", "La película", nil, MediaNaming{}, tt.replaceChanged, nil); err != nil {
+					t.Fatalf("UploadMediaToWordPress: %v", err)
+				}
+				images := make(map[string]int)
+				if err := turso.GetWPImagesMetadata("film-1", &images); err != nil {
+					t.Fatalf("GetWPImagesMetadata: %v", err)
+				}
+				return images["still-1_web.jpg"]
+			}
+			firstID := upload()
+
+			if tt.forgetHashes {
+				if err := turso.DeleteMetadata("film-1", "wp_image_hashes"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.change {
+				if err := os.WriteFile(still, []byte("re-optimized"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			secondID := upload()
+
+			if gotNewID := secondID != firstID; gotNewID != tt.wantNewID {
+				t.Errorf("media ID %d after %d, want a new ID: %v", secondID, firstID, tt.wantNewID)
+			}
+			if _, exists := fake.media[secondID]; !exists {
+				t.Errorf("recorded media %d does not exist", secondID)
+			}
+			wantDeleted := []int(nil)
+			if tt.wantDeleted {
+				wantDeleted = []int{firstID}
+			}
+			if !slices.Equal(fake.deletedMedia, wantDeleted) {
+				t.Errorf("deleted media %v, want %v", fake.deletedMedia, wantDeleted)
+			}
+
+			// A third run with the same content uploads nothing
+			uploads := fake.nextID
+			if thirdID := upload(); thirdID != secondID || fake.nextID != uploads {
+				t.Errorf("third run recorded media %d and uploaded %d files, want %d and none", thirdID, fake.nextID-uploads, secondID)
+			}
+		})
+	}
+}
+
+func TestProjectSynopsisSources(t *testing.T) {
+	filmData := map[string]any{
+		"TÍTULO ORIGINAL":                         "La película",
+		"Sinopsis extendida (máximo 70 palabras)": "Sinopsis extendida.",
+		"Sinopsis compacta  (máximo 10 palabras)": "Sinopsis compacta.",
+		"Extended synopsis (english)":             "Extended synopsis.",
+	}
+	tests := []struct {
+		name        string
+		source      string
+		excerpt     string
+		wantContent string
+		wantExcerpt string // empty when the post is sent without an excerpt
+	}{
+		{name: "defaults", wantContent: "Sinopsis extendida."},
+		{name: "compact content", source: services.SynopsisSourceCompact, wantContent: "Sinopsis compacta."},
+		{name: "compact excerpt", excerpt: services.SynopsisSourceCompact, wantContent: "Sinopsis extendida.", wantExcerpt: "Sinopsis compacta."},
+		{name: "english content, extended excerpt", source: services.SynopsisSourceEnglish, excerpt: services.SynopsisSourceExtended, wantContent: "Extended synopsis.", wantExcerpt: "Sinopsis extendida."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, wp := newFakeWordPress(t)
+			turso := newTestTurso(t)
+			templateConfig := services.DefaultTemplateData()
+			templateConfig.SynopsisSource = tt.source
+			templateConfig.ExcerptSource = tt.excerpt
+
+			filmDir := t.TempDir()
+			if err := CreateOrUpdateWordPressProject(wp, services.NewDiviTemplateService(), turso, filmDir, "film-1", filmData, "2025", nil, templateConfig, ProjectOptions{}); err != nil {
+				t.Fatalf("CreateOrUpdateWordPressProject: %v", err)
+			}
+			if len(fake.postBodies) != 1 {
+				t.Fatalf("%d project requests, want 1", len(fake.postBodies))
+			}
+			body := fake.postBodies[0]
+
+			// The page content is imported from the saved Divi template
+			template, err := os.ReadFile(filepath.Join(filmDir, "divi_template.json"))
+			if err != nil {
+				t.Fatalf("reading the saved template: %v", err)
+			}
+			content := string(template)
+			for _, synopsis := range []string{"Sinopsis extendida.", "Sinopsis compacta.", "Extended synopsis."} {
+				if got := strings.Contains(content, synopsis); got != (synopsis == tt.wantContent) {
+					t.Errorf("content contains %q: %v, want only %q", synopsis, got, tt.wantContent)
+				}
+			}
+			if excerpt, _ := body["excerpt"].(string); excerpt != tt.wantExcerpt {
+				t.Errorf("excerpt = %q, want %q", excerpt, tt.wantExcerpt)
+			}
+		})
+	}
+}
+
+func TestProjectAuthor(t *testing.T) {
+	tests := []struct {
+		name        string
+		authorID    int  // configured author_id
+		rejectUser  bool // the users/me lookup fails
+		wantAuthor  float64
+		wantLookups int
+	}{
+		{name: "configured author", authorID: 7, wantAuthor: 7},
+		{name: "current user, looked up once", wantAuthor: 1, wantLookups: 1},
+		{name: "current user unavailable", rejectUser: true, wantLookups: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, wp := newFakeWordPressWithConfig(t, config.WordPressConfig{AuthorID: tt.authorID})
+			fake.rejectUser = tt.rejectUser
+			turso := newTestTurso(t)
+
+			for _, filmID := range []string{"film-1", "film-2"} {
+				filmData := map[string]any{"TÍTULO ORIGINAL": "Película " + filmID}
+				if err := CreateOrUpdateWordPressProject(wp, services.NewDiviTemplateService(), turso, t.TempDir(), filmID, filmData, "2025", nil, services.DefaultTemplateData(), ProjectOptions{}); err != nil {
+					t.Fatalf("CreateOrUpdateWordPressProject(%s): %v", filmID, err)
+				}
+			}
+
+			if len(fake.postBodies) != 2 {
+				t.Fatalf("%d project requests, want 2", len(fake.postBodies))
+			}
+			for i, body := range fake.postBodies {
+				author, _ := body["author"].(float64)
+				if author != tt.wantAuthor {
+					t.Errorf("post %d author = %v, want %v", i, author, tt.wantAuthor)
+				}
+			}
+			if fake.userLookups != tt.wantLookups {
+				t.Errorf("%d current user lookups, want %d", fake.userLookups, tt.wantLookups)
+			}
+		})
+	}
+}
+
+func TestProjectTemplateOutput(t *testing.T) {
+	tests := []struct {
+		output       string
+		wantRequests int // project creates and updates sent to WordPress
+		wantFile     bool
+	}{
+		{output: services.TemplateOutputWordPress, wantRequests: 1, wantFile: true},
+		{output: services.TemplateOutputFile, wantFile: true},
+		{output: services.TemplateOutputStdout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			fake, wp := newFakeWordPress(t)
+			turso := newTestTurso(t)
+			diviTemplateService := services.NewDiviTemplateService()
+			diviTemplateService.SetTemplateOutput(tt.output)
+
+			filmDir := t.TempDir()
+			filmData := map[string]any{"TÍTULO ORIGINAL": "La película"}
+			if err := CreateOrUpdateWordPressProject(wp, diviTemplateService, turso, filmDir, "film-1", filmData, "2025", nil, services.DefaultTemplateData(), ProjectOptions{}); err != nil {
+				t.Fatalf("CreateOrUpdateWordPressProject: %v", err)
+			}
+
+			if len(fake.postBodies) != tt.wantRequests {
+				t.Errorf("%d project requests, want %d", len(fake.postBodies), tt.wantRequests)
+			}
+			_, err := os.Stat(filepath.Join(filmDir, "divi_template.json"))
+			if exists := err == nil; exists != tt.wantFile {
+				t.Errorf("divi_template.json exists: %v, want %v", exists, tt.wantFile)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package wordpress
+
+import (
+	"fmt"
+
+	"excentrico-tools-go/internal/logger"
+)
+
+// PermalinkWriter records the public URL of a film's project, e.g. in its sheet row.
+// filmData is the film's sheet row as passed to CreateOrUpdateWordPressProject
+type PermalinkWriter func(filmData map[string]any, link string) error
+
+// recordPermalink hands a saved project's permalink to writer, which may be nil. It never
+// fails the film; the post itself was saved
+func recordPermalink(writer PermalinkWriter, filmData map[string]any, link string, postID int, filmID, filmTitle string) {
+	if writer == nil || link == "" {
+		return
+	}
+	op := logger.Get().StartOperation("record_wordpress_permalink")
+	op.WithFilm(filmID, filmTitle, "", "")
+	op.WithWordPress(postID, 0, "")
+	op.WithContext("permalink", link)
+
+	if err := writer(filmData, link); err != nil {
+		op.WithError(err)
+		op.Warn(&logger.WideEvent{
+			Message: fmt.Sprintf("Could not record the permalink of '%s': %v", filmTitle, err),
+		})
+		return
+	}
+	op.Complete(fmt.Sprintf("Recorded permalink of '%s'", filmTitle))
+}
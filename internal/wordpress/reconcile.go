@@ -0,0 +1,177 @@
+package wordpress
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"excentrico-tools-go/internal/logger"
+	"excentrico-tools-go/internal/models"
+	"excentrico-tools-go/internal/services"
+)
+
+// ReconcileResult summarizes the reconciliation of one film's media metadata
+type ReconcileResult struct {
+	FilmID     string
+	Checked    int
+	Dangling   []int
+	Reuploaded int
+	Missing    []string // File names whose local _web.jpg could not be found for re-upload
+}
+
+// ReconcileFilmMedia checks every media ID recorded in Turso for a film against WordPress,
+// removes the IDs that no longer exist and, when reupload is set, uploads the matching
+// local _web.jpg again from optimizedDir so the film's gallery is complete
+func ReconcileFilmMedia(wordpressService *services.WordPressService, tursoService *services.TursoService, filmID string, optimizedDir string, reupload bool, naming MediaNaming) (*ReconcileResult, error) {
+	l := logger.Get()
+	op := l.StartOperation("reconcile_wordpress_media")
+	op.WithFilm(filmID, "", "", "")
+	op.WithContext("reupload", reupload)
+
+	result := &ReconcileResult{FilmID: filmID}
+
+	imageMetadata := make(map[string]int)
+	if err := tursoService.GetWPImagesMetadata(filmID, &imageMetadata); err != nil {
+		if errors.Is(err, services.ErrMetadataNotFound) {
+			op.Complete("No media metadata to reconcile")
+			return result, nil
+		}
+		op.Fail("Failed to load image metadata", err)
+		return nil, fmt.Errorf("failed to load image metadata: %v", err)
+	}
+
+	danglingFiles := make(map[string]int)
+	for fileName, mediaID := range imageMetadata {
+		result.Checked++
+		if _, err := wordpressService.GetMedia(mediaID); err != nil {
+			if services.IsNotFoundError(err) {
+				danglingFiles[fileName] = mediaID
+				result.Dangling = append(result.Dangling, mediaID)
+				continue
+			}
+			op.Fail(fmt.Sprintf("Failed to verify media %d", mediaID), err)
+			return nil, fmt.Errorf("failed to verify media %d: %v", mediaID, err)
+		}
+	}
+
+	op.WithContext("checked_count", result.Checked)
+	op.WithContext("dangling_ids", result.Dangling)
+
+	if len(danglingFiles) == 0 {
+		op.Complete("All recorded media exist in WordPress")
+		return result, nil
+	}
+
+	var mediaMetadata []map[string]any
+	if err := tursoService.GetMetadata(filmID, "wordpress_media", &mediaMetadata); err != nil && !errors.Is(err, services.ErrMetadataNotFound) {
+		op.Fail("Failed to load media metadata", err)
+		return nil, fmt.Errorf("failed to load media metadata: %v", err)
+	}
+	mediaMetadata = pruneMediaEntries(mediaMetadata, danglingFiles)
+
+	for fileName := range danglingFiles {
+		delete(imageMetadata, fileName)
+	}
+
+	if reupload {
+		var postID int
+		wpMetadata := &models.WordPressMetadata{}
+		filmTitle := filmID
+		if err := tursoService.GetWordPressMetadata(filmID, wpMetadata); err == nil {
+			postID = wpMetadata.PostID
+			if wpMetadata.Title != "" {
+				filmTitle = wpMetadata.Title
+			}
+		}
+
+		for fileName := range danglingFiles {
+			webFile := findLocalWebFile(optimizedDir, fileName)
+			if webFile == "" {
+				result.Missing = append(result.Missing, fileName)
+				continue
+			}
+
+			media, err := wordpressService.UploadMediaFromFile(webFile, naming.Title(webFile, filmTitle), naming.AltText(webFile, filmTitle, nil))
+			if err != nil {
+				op.Warn(&logger.WideEvent{
+					Message: fmt.Sprintf("Failed to re-upload %s: %v", fileName, err),
+				})
+				continue
+			}
+
+			imageMetadata[fileName] = media.ID
+			mediaMetadata = append(mediaMetadata, map[string]any{
+				"id":         media.ID,
+				"title":      media.Title.String(),
+				"source_url": media.SourceURL,
+				"alt_text":   media.AltText,
+				"file_path":  webFile,
+				"post_id":    postID,
+			})
+			result.Reuploaded++
+		}
+	}
+
+	// The pruned collections may legitimately be empty, so they replace the stored data
+	if err := tursoService.ReplaceMetadata(filmID, "wp_images", imageMetadata); err != nil {
+		op.Fail("Failed to save reconciled image metadata", err)
+		return nil, fmt.Errorf("failed to save reconciled image metadata: %v", err)
+	}
+	if mediaMetadata != nil {
+		if err := tursoService.ReplaceMetadata(filmID, "wordpress_media", mediaMetadata); err != nil {
+			op.Fail("Failed to save reconciled media metadata", err)
+			return nil, fmt.Errorf("failed to save reconciled media metadata: %v", err)
+		}
+	}
+
+	op.WithContext("reuploaded_count", result.Reuploaded)
+	op.WithContext("missing_local_files", result.Missing)
+	op.KeepAlways()
+	op.Complete(fmt.Sprintf("Removed %d dangling media IDs, re-uploaded %d", len(result.Dangling), result.Reuploaded))
+	return result, nil
+}
+
+// pruneMediaEntries drops the wordpress_media entries whose ID or file name is dangling
+func pruneMediaEntries(mediaMetadata []map[string]any, danglingFiles map[string]int) []map[string]any {
+	if mediaMetadata == nil {
+		return nil
+	}
+
+	danglingIDs := make(map[int]bool, len(danglingFiles))
+	for _, mediaID := range danglingFiles {
+		danglingIDs[mediaID] = true
+	}
+
+	kept := make([]map[string]any, 0, len(mediaMetadata))
+	for _, media := range mediaMetadata {
+		if id, ok := media["id"].(float64); ok && danglingIDs[int(id)] {
+			continue
+		}
+		if id, ok := media["id"].(int); ok && danglingIDs[id] {
+			continue
+		}
+		if filePath, ok := media["file_path"].(string); ok {
+			if _, dangling := danglingFiles[filepath.Base(filePath)]; dangling {
+				continue
+			}
+		}
+		kept = append(kept, media)
+	}
+	return kept
+}
+
+// findLocalWebFile searches optimizedDir for an optimized image with the given file name
+func findLocalWebFile(optimizedDir, fileName string) string {
+	var found string
+	filepath.Walk(optimizedDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || found != "" {
+			return nil
+		}
+		if !info.IsDir() && info.Name() == fileName {
+			found = path
+		}
+		return nil
+	})
+	return found
+}
@@ -0,0 +1,112 @@
+package wordpress
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"excentrico-tools-go/internal/utils"
+)
+
+func TestReconcileFilmMedia(t *testing.T) {
+	tests := []struct {
+		name           string
+		deleted        []string // files whose media is deleted in WordPress
+		removedLocally []string // files whose optimized copy is gone
+		reupload       bool
+		wantDangling   int
+		wantReuploaded int
+		wantMissing    []string
+		wantKept       []string // files still mapped to their original media
+	}{
+		{
+			name:     "all media present",
+			wantKept: []string{"still-1_web.jpg", "still-2_web.jpg", "still-3_web.jpg"},
+		},
+		{
+			name:         "dangling ID is pruned",
+			deleted:      []string{"still-2_web.jpg"},
+			wantDangling: 1,
+			wantKept:     []string{"still-1_web.jpg", "still-3_web.jpg"},
+		},
+		{
+			name:           "dangling ID is re-uploaded",
+			deleted:        []string{"still-2_web.jpg"},
+			reupload:       true,
+			wantDangling:   1,
+			wantReuploaded: 1,
+			wantKept:       []string{"still-1_web.jpg", "still-3_web.jpg"},
+		},
+		{
+			name:           "re-upload without the local file",
+			deleted:        []string{"still-1_web.jpg", "still-2_web.jpg"},
+			removedLocally: []string{"still-1_web.jpg"},
+			reupload:       true,
+			wantDangling:   2,
+			wantReuploaded: 1,
+			wantMissing:    []string{"still-1_web.jpg"},
+			wantKept:       []string{"still-3_web.jpg"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, wp := newFakeWordPress(t)
+			turso := newTestTurso(t)
+			filmDir := t.TempDir()
+			for _, name := range []string{"still-1_web.jpg", "still-2_web.jpg", "still-3_web.jpg"} {
+				writeFile(t, filepath.Join(filmDir, "Stills", name))
+			}
+			if _, err := UploadMediaToWordPress(wp, turso, filmDir, utils.OutputFormats{}, "film-1", "La película", nil, MediaNaming{}, false, nil); err != nil {
+				t.Fatalf("UploadMediaToWordPress: %v", err)
+			}
+			original := make(map[string]int)
+			if err := turso.GetWPImagesMetadata("film-1", &original); err != nil {
+				t.Fatalf("GetWPImagesMetadata: %v", err)
+			}
+
+			for _, name := range tt.deleted {
+				delete(fake.media, original[name])
+			}
+			for _, name := range tt.removedLocally {
+				if err := os.Remove(filepath.Join(filmDir, "Stills", name)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			result, err := ReconcileFilmMedia(wp, turso, "film-1", filmDir, tt.reupload, MediaNaming{})
+			if err != nil {
+				t.Fatalf("ReconcileFilmMedia: %v", err)
+			}
+			if result.Checked != 3 || len(result.Dangling) != tt.wantDangling || result.Reuploaded != tt.wantReuploaded || !slices.Equal(result.Missing, tt.wantMissing) {
+				t.Errorf("result %+v, want 3 checked, %d dangling, %d re-uploaded, missing %v", result, tt.wantDangling, tt.wantReuploaded, tt.wantMissing)
+			}
+
+			reconciled := make(map[string]int)
+			if err := turso.GetWPImagesMetadata("film-1", &reconciled); err != nil {
+				t.Fatalf("GetWPImagesMetadata: %v", err)
+			}
+			if len(reconciled) != len(tt.wantKept)+tt.wantReuploaded {
+				t.Errorf("%d media recorded after reconciling, want %d", len(reconciled), len(tt.wantKept)+tt.wantReuploaded)
+			}
+			for name, mediaID := range reconciled {
+				if fake.media[mediaID] == nil {
+					t.Errorf("%s still maps to deleted media %d", name, mediaID)
+				}
+				if kept := slices.Contains(tt.wantKept, name); kept != (mediaID == original[name]) {
+					t.Errorf("%s maps to media %d, originally %d", name, mediaID, original[name])
+				}
+			}
+
+			var mediaMetadata []map[string]any
+			if err := turso.GetMetadata("film-1", "wordpress_media", &mediaMetadata); err != nil {
+				t.Fatalf("GetMetadata(wordpress_media): %v", err)
+			}
+			for _, media := range mediaMetadata {
+				if id := int(media["id"].(float64)); fake.media[id] == nil {
+					t.Errorf("wordpress_media still lists deleted media %d", id)
+				}
+			}
+		})
+	}
+}
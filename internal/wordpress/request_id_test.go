@@ -0,0 +1,93 @@
+package wordpress
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"excentrico-tools-go/internal/logger"
+	"excentrico-tools-go/internal/models"
+	"excentrico-tools-go/internal/utils"
+)
+
+// captureEvents routes every logged event to a buffer until the test ends and returns a
+// function decoding the events logged so far
+func captureEvents(t *testing.T) func() []logger.WideEvent {
+	t.Helper()
+	var buf bytes.Buffer
+	logger.InitWithWriter("excentrico-tools-go-test", &buf)
+	logger.Get().SetSampleRate(1)
+	t.Cleanup(func() { logger.InitWithWriter("excentrico-tools-go-test", io.Discard) })
+
+	return func() []logger.WideEvent {
+		var events []logger.WideEvent
+		scanner := bufio.NewScanner(bytes.NewReader(buf.Bytes()))
+		scanner.Buffer(nil, 1<<20)
+		for scanner.Scan() {
+			var event logger.WideEvent
+			if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+				t.Fatalf("decoding event %s: %v", scanner.Text(), err)
+			}
+			events = append(events, event)
+		}
+		return events
+	}
+}
+
+func TestServiceEventsCarryRequestID(t *testing.T) {
+	tests := []struct {
+		name      string
+		requestID string // empty uses the services without a request context
+	}{
+		{"film request", "0123456789abcdef"},
+		{"no request", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, wp := newFakeWordPress(t)
+			turso := newTestTurso(t)
+			filmDir := t.TempDir()
+			const filmID = "La Película"
+			writeFile(t, filepath.Join(filmDir, "Stills", "still-1_web.jpg"))
+			driveFiles := []*models.FileWithPath{{ID: "1", Name: "still-1.jpg", FolderName: "Stills", FolderPath: "Stills"}}
+			if err := turso.SaveDriveFilesMetadata(filmID, driveFiles); err != nil {
+				t.Fatalf("SaveDriveFilesMetadata: %v", err)
+			}
+
+			events := captureEvents(t)
+			if tt.requestID != "" {
+				ctx := logger.ContextWithRequestID(context.Background(), tt.requestID)
+				wp, turso = wp.WithContext(ctx), turso.WithContext(ctx)
+			}
+			imageIds, err := UploadMediaToWordPress(wp, turso, filmDir, utils.OutputFormats{}, filmID, "La película", nil, MediaNaming{}, false, nil)
+			if err != nil || len(imageIds) != 1 {
+				t.Fatalf("UploadMediaToWordPress: %v %v", imageIds, err)
+			}
+			// Uploads send their body directly; a lookup goes through makeRequest
+			if _, err := wp.GetMedia(imageIds[0]); err != nil {
+				t.Fatalf("GetMedia: %v", err)
+			}
+
+			seen := make(map[string]bool)
+			for _, event := range events() {
+				if !strings.HasPrefix(event.Operation, "wordpress_") && !strings.HasPrefix(event.Operation, "turso_") {
+					continue
+				}
+				seen[event.Operation] = true
+				if event.RequestID != tt.requestID {
+					t.Errorf("%s event has request ID %q, want %q", event.Operation, event.RequestID, tt.requestID)
+				}
+			}
+			for _, operation := range []string{"wordpress_http_request", "wordpress_upload_media_from_file", "turso_get_metadata", "turso_save_metadata"} {
+				if !seen[operation] {
+					t.Errorf("no %s event was logged", operation)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package wordpress
+
+import (
+	"fmt"
+	"strings"
+
+	"excentrico-tools-go/internal/services"
+)
+
+// DefaultSlugTemplate is the project slug format used when none is configured
+const DefaultSlugTemplate = "{title} {section} {year}"
+
+// maxSlugSuffix bounds the counters tried when making a slug unique
+const maxSlugSuffix = 100
+
+// ProjectSlug renders the slug of a film's project from a template with the placeholders
+// {title}, {section} and {year}; an empty template uses DefaultSlugTemplate. Empty values
+// leave no stray separators, and a slug with nothing left is "untitled-film"
+func ProjectSlug(template, title, section, year string) string {
+	if strings.TrimSpace(template) == "" {
+		template = DefaultSlugTemplate
+	}
+	text := strings.NewReplacer("{title}", title, "{section}", section, "{year}", year).Replace(template)
+	slug := CreateWordPressSlug(text)
+	if slug == "" {
+		return "untitled-film"
+	}
+	return slug
+}
+
+// uniqueProjectSlug returns slug, or slug with the lowest counter no other project uses.
+// A failed lookup keeps the slug as it is
+func uniqueProjectSlug(wordpressService *services.WordPressService, slug string) (string, error) {
+	for n := 1; n <= maxSlugSuffix; n++ {
+		candidate := slug
+		if n > 1 {
+			candidate = fmt.Sprintf("%s-%d", slug, n)
+		}
+		posts, err := wordpressService.GetPosts(map[string]string{"slug": candidate, "status": "any"})
+		if err != nil {
+			return slug, err
+		}
+		if len(posts) == 0 {
+			return candidate, nil
+		}
+	}
+	return slug, fmt.Errorf("no free slug for %q up to suffix %d", slug, maxSlugSuffix)
+}
@@ -0,0 +1,72 @@
+package wordpress
+
+import (
+	"fmt"
+	"testing"
+
+	"excentrico-tools-go/internal/services"
+)
+
+func TestProjectSlug(t *testing.T) {
+	tests := []struct {
+		name                           string
+		template, title, section, year string
+		want                           string
+	}{
+		{"default template", "", "La Película", "Oficial", "2025", "la-pelicula-oficial-2025"},
+		{"blank template", "  ", "La Película", "Oficial", "2025", "la-pelicula-oficial-2025"},
+		{"custom template", "{year} {title}", "La Película", "Oficial", "2025", "2025-la-pelicula"},
+		{"empty section", "", "La Película", "", "2025", "la-pelicula-2025"},
+		{"accented section", "", "El Faro", "Sección Óptica", "2025", "el-faro-seccion-optica-2025"},
+		{"separators in the template", "{section}/{year}: {title}", "Ñandú", "Animación", "2025", "animacion-2025-nandu"},
+		{"nothing left", "{title}", "", "Oficial", "2025", "untitled-film"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ProjectSlug(tt.template, tt.title, tt.section, tt.year); got != tt.want {
+				t.Errorf("ProjectSlug(%q, %q, %q, %q) = %q, want %q", tt.template, tt.title, tt.section, tt.year, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUniqueProjectSlug(t *testing.T) {
+	tests := []struct {
+		name    string
+		taken   []string
+		want    string
+		wantErr bool
+	}{
+		{name: "free slug", want: "la-playa-cortos-2025"},
+		{name: "other slugs taken", taken: []string{"la-playa-largos-2025", "la-playa-cortos-2025-2"}, want: "la-playa-cortos-2025"},
+		{name: "slug taken", taken: []string{"la-playa-cortos-2025"}, want: "la-playa-cortos-2025-2"},
+		{name: "counters taken", taken: []string{"la-playa-cortos-2025", "la-playa-cortos-2025-2", "la-playa-cortos-2025-3"}, want: "la-playa-cortos-2025-4"},
+		{name: "gap in the counters", taken: []string{"la-playa-cortos-2025", "la-playa-cortos-2025-3"}, want: "la-playa-cortos-2025-2"},
+		{name: "every counter taken", taken: takenSlugs("la-playa-cortos-2025", maxSlugSuffix), want: "la-playa-cortos-2025", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, wp := newFakeWordPress(t)
+			for i, slug := range tt.taken {
+				fake.posts[i+1] = &services.WordPressPost{ID: i + 1, Slug: slug}
+			}
+
+			got, err := uniqueProjectSlug(wp, "la-playa-cortos-2025")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("uniqueProjectSlug() error %v, want error: %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("uniqueProjectSlug() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// takenSlugs returns slug followed by slug-2 up to slug-n
+func takenSlugs(slug string, n int) []string {
+	slugs := []string{slug}
+	for i := 2; i <= n; i++ {
+		slugs = append(slugs, fmt.Sprintf("%s-%d", slug, i))
+	}
+	return slugs
+}
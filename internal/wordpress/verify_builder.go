@@ -0,0 +1,52 @@
+package wordpress
+
+import (
+	"fmt"
+
+	"excentrico-tools-go/internal/logger"
+	"excentrico-tools-go/internal/services"
+)
+
+// builderMetaKey is the post meta Divi reads to open a post in its builder
+const builderMetaKey = "_et_pb_use_builder"
+
+// checkBuilderEnabled reports an error unless post carries the builder flag "on"; the
+// meta is missing when a security plugin strips it or the site does not expose it
+func checkBuilderEnabled(post *services.WordPressPost) error {
+	value, ok := post.Meta[builderMetaKey]
+	if !ok {
+		return fmt.Errorf("post %d has no %s meta", post.ID, builderMetaKey)
+	}
+	if value != "on" {
+		return fmt.Errorf("post %d has %s set to %v", post.ID, builderMetaKey, value)
+	}
+	return nil
+}
+
+// verifyBuilderEnabled re-reads a saved project and warns when the Divi builder was not
+// enabled on it. It never fails the film; the post itself was saved
+func verifyBuilderEnabled(wordpressService *services.WordPressService, postID int, filmID, filmTitle string) {
+	op := logger.Get().StartOperation("verify_wordpress_builder")
+	op.WithFilm(filmID, filmTitle, "", "")
+	op.WithWordPress(postID, 0, "")
+
+	post, err := wordpressService.GetPost(postID)
+	if err != nil {
+		op.WithError(err)
+		op.Warn(&logger.WideEvent{
+			Message: fmt.Sprintf("Could not re-read post %d to verify the Divi builder", postID),
+		})
+		return
+	}
+	op.WithContext("content_length", len(post.Content.String()))
+
+	if err := checkBuilderEnabled(post); err != nil {
+		op.WithError(err)
+		op.KeepAlways()
+		op.Warn(&logger.WideEvent{
+			Message: fmt.Sprintf("Divi builder is not enabled on post %d of '%s'", postID, filmTitle),
+		})
+		return
+	}
+	op.Complete(fmt.Sprintf("Divi builder enabled on post %d", postID))
+}
@@ -0,0 +1,97 @@
+package wordpress
+
+import (
+	"strings"
+	"testing"
+
+	"excentrico-tools-go/internal/services"
+)
+
+func TestCheckBuilderEnabled(t *testing.T) {
+	tests := []struct {
+		name    string
+		meta    map[string]interface{}
+		wantErr string
+	}{
+		{name: "builder on", meta: map[string]interface{}{builderMetaKey: "on", "_et_pb_page_layout": "et_full_width_page"}},
+		{name: "builder off", meta: map[string]interface{}{builderMetaKey: "off"}, wantErr: "set to off"},
+		{name: "meta stripped", meta: map[string]interface{}{"_et_pb_page_layout": "et_full_width_page"}, wantErr: "has no _et_pb_use_builder meta"},
+		{name: "no meta", wantErr: "has no _et_pb_use_builder meta"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkBuilderEnabled(&services.WordPressPost{ID: 7, Meta: tt.meta})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkBuilderEnabled() error %v, want none", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkBuilderEnabled() error %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyBuilderEnabled(t *testing.T) {
+	tests := []struct {
+		name        string
+		post        *services.WordPressPost // nil leaves the post missing
+		wantLevel   string
+		wantMessage string
+	}{
+		{
+			name:        "verify pass",
+			post:        &services.WordPressPost{Meta: map[string]interface{}{builderMetaKey: "on"}, Content: services.WordPressRenderedField{Raw: "[et_pb_section][/et_pb_section]"}},
+			wantLevel:   "info",
+			wantMessage: "Divi builder enabled on post 7",
+		},
+		{
+			name:        "meta stripped",
+			post:        &services.WordPressPost{Content: services.WordPressRenderedField{Raw: "[et_pb_section][/et_pb_section]"}},
+			wantLevel:   "warn",
+			wantMessage: "Divi builder is not enabled on post 7 of 'La playa'",
+		},
+		{
+			name:        "builder off",
+			post:        &services.WordPressPost{Meta: map[string]interface{}{builderMetaKey: "off"}},
+			wantLevel:   "warn",
+			wantMessage: "Divi builder is not enabled on post 7 of 'La playa'",
+		},
+		{
+			name:        "post not readable",
+			wantLevel:   "warn",
+			wantMessage: "Could not re-read post 7 to verify the Divi builder",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, wp := newFakeWordPress(t)
+			if tt.post != nil {
+				tt.post.ID = 7
+				fake.posts[7] = tt.post
+			}
+			events := captureEvents(t)
+
+			verifyBuilderEnabled(wp, 7, "la-playa", "La playa")
+
+			var found bool
+			for _, event := range events() {
+				if event.Operation != "verify_wordpress_builder" {
+					continue
+				}
+				found = true
+				if event.Level != tt.wantLevel || event.Message != tt.wantMessage {
+					t.Errorf("event %s %q, want %s %q", event.Level, event.Message, tt.wantLevel, tt.wantMessage)
+				}
+				if tt.wantLevel == "warn" && event.Error == nil {
+					t.Error("warning carries no error")
+				}
+			}
+			if !found {
+				t.Fatal("no verify_wordpress_builder event")
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
 	"excentrico-tools-go/internal/app"
 	"excentrico-tools-go/internal/config"
@@ -10,25 +12,73 @@ import (
 	"excentrico-tools-go/internal/services"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"syscall"
+	"time"
 )
 
 type RuntimeOptions struct {
-	Menu     string
-	Year     string
-	Template string
-	NavMenu  string
+	Menu              string
+	Year              string
+	Template          string
+	NavMenu           string
+	RefreshBackground bool
+	AutoYear          bool
+	ChangedOnly       bool
+	ForceUpdate       bool
+	Section           string
+	WriteStatus       bool
+	ImageURLsOnly     bool
+	SkipPublished     bool
+	Redownload        bool
+	TemplateOutput    string
+	TemplateJSON      string
+	Language          string
+	Deadline          time.Duration
+	RetryFrom         string
+	DryRun            bool
 }
 
 func main() {
 	createConfig := flag.Bool("create-config", false, "Create a default configuration file")
+	dbStatus := flag.Bool("db-status", false, "Print a summary of the metadata stored in the Turso database")
+	reconcile := flag.Bool("reconcile", false, "Remove WordPress media IDs recorded in Turso that no longer exist in WordPress")
+	reconcileReupload := flag.Bool("reconcile-reupload", false, "With -reconcile, re-upload the local optimized image for each removed media ID")
+	resyncAlt := flag.Bool("resync-alt", false, "Update alt text and captions of the stored media of the selected films (-year/-section) without re-uploading")
+	helpFields := flag.Bool("help-fields", false, "List the sheet columns the tool reads and which of them are required")
+	diffTemplates := flag.String("diff-templates", "", "Print the fields that differ between two years' template files: -diff-templates <yearA> <yearB>, given after any other flag (<yearA>,<yearB> also works)")
+	dumpTemplateSchema := flag.Bool("dump-template-schema", false, "Write an example per-year template file and its field documentation to the templates folder")
+	validateFlag := flag.Bool("validate", false, "Check every sheet row (filtered by -year/-section) for data issues and write a report, without processing")
+	validateOutputFlag := flag.String("validate-output", "validation_report.json", "Report path for -validate; a .csv extension writes CSV instead of JSON")
+	missingFlag := flag.Bool("missing", false, "List the sheet rows (filtered by -year/-section) that were never created in WordPress and write a report")
+	missingOutputFlag := flag.String("missing-output", "missing_films.json", "Report path for -missing; a .csv extension writes CSV instead of JSON")
 	yearFlag := flag.String("year", "", "Filter by year (e.g., 2024, 2025)")
+	sectionFlag := flag.String("section", "", "Only process films in this SECCIÓN (case and accent insensitive)")
 	debugFlag := flag.Bool("debug", false, "Enable debug logging")
+	pruneLogsFlag := flag.Int("prune-logs", 0, "Delete run logs older than this many days (0 keeps all logs)")
 	menuFlag := flag.String("menu", "", "Action to run: configuration | process")
 	navMenuFlag := flag.String("nav-menu", "", "Navigation menu to use (from WordPress)")
+	autoYearFlag := flag.Bool("auto-year", false, "Derive the year(s) from the sheet's EDICIÓN values when -year is not set")
+	writeStatusFlag := flag.Bool("write-status", false, "Record \"Published <date>\" in the sheet's Published Status column after a film is processed")
+	skipPublishedFlag := flag.Bool("skip-published", false, "Skip films whose Published Status already marks them as published")
+	changedOnlyFlag := flag.Bool("changed-only", false, "Only process films whose sheet row changed since the last successful run")
+	forceUpdateFlag := flag.Bool("force-update", false, "Resend every selected film's post and rewrite its divi_template.json even if -changed-only finds its row unchanged, e.g. after posts were edited by hand")
+	imageURLsOnlyFlag := flag.Bool("image-urls-only", false, "Reference images by URL in divi_template.json instead of embedding them as base64")
+	redownloadFlag := flag.Bool("redownload", false, "Delete the selected films' local downloads and Drive metadata and download everything again (requires -year or -section)")
+	langFlag := flag.String("lang", "es", "Site language: es (default) | en for the English edition (labels, synopsis, metadata, button)")
+	templateOutputFlag := flag.String("template-output", "wordpress", "Where generated templates go: wordpress (post and divi_template.json) | file | stdout")
+	dryRunFlag := flag.Bool("dry-run", false, "Run the whole pipeline without creating posts, uploading media or writing Turso metadata; divi_template.json is still written")
+	retryFromFlag := flag.String("retry-from", "", "Reprocess only the films that failed in a previous run's log file (logs/run-*.log)")
+	templateJSONFlag := flag.String("template-json", "indented", "Layout of divi_template.json: indented | minified | both (adds divi_template.min.json)")
+	deadlineFlag := flag.Duration("deadline", 0, "Stop starting new films once the run has lasted this long (e.g. 2h, 45m); the film in progress finishes")
+	refreshBackgroundFlag := flag.Bool("refresh-background", false, "Re-evaluate the header background image instead of reusing the stored choice")
 	flag.Parse()
 
 	// Initialize logger
@@ -37,6 +87,10 @@ func main() {
 	if *debugFlag {
 		l.SetSampleRate(1.0) // Log everything in debug mode
 	}
+
+	// Ctrl+C aborts the requests in flight and stops the run before the next film
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 	
 	// Log file path information
 	if logPath := l.GetLogFilePath(); logPath != "" {
@@ -52,6 +106,18 @@ func main() {
 
 	debug.SetEnabled(*debugFlag)
 
+	if *pruneLogsFlag > 0 {
+		op := l.StartOperation("prune_logs")
+		op.WithContext("max_age_days", *pruneLogsFlag)
+		removed, err := l.PruneLogs(*pruneLogsFlag)
+		op.WithContext("removed_count", removed)
+		if err != nil {
+			op.Fail("Failed to prune old run logs", err)
+		} else {
+			op.Complete(fmt.Sprintf("Removed %d run logs older than %d days", removed, *pruneLogsFlag))
+		}
+	}
+
 	if *createConfig {
 		op := l.StartOperation("create_config")
 		if err := config.CreateDefaultConfig(); err != nil {
@@ -62,6 +128,37 @@ func main() {
 		return
 	}
 
+	if *helpFields {
+		printSheetFields(os.Stdout)
+		return
+	}
+
+	if *diffTemplates != "" {
+		op := l.StartOperation("diff_templates")
+		fromYear, toYear, err := diffTemplateYears(*diffTemplates, flag.Args())
+		if err != nil {
+			err = fmt.Errorf("invalid -diff-templates %q: %v", *diffTemplates, err)
+			op.Fail("Invalid -diff-templates arguments", err)
+			log.Fatalf("%v", err)
+		}
+		if err := diffTemplateFiles("templates", fromYear, toYear); err != nil {
+			op.Fail("Failed to diff template files", err)
+			log.Fatalf("Failed to diff template files: %v", err)
+		}
+		op.Complete("Template files compared successfully")
+		return
+	}
+
+	if *dumpTemplateSchema {
+		op := l.StartOperation("dump_template_schema")
+		if err := dumpTemplateExample("templates"); err != nil {
+			op.Fail("Failed to write template example", err)
+			log.Fatalf("Failed to write template example: %v", err)
+		}
+		op.Complete("Template example written successfully")
+		return
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		op := l.StartOperation("load_config")
@@ -81,12 +178,98 @@ func main() {
 		op.Complete("Configuration loaded successfully")
 	}
 
+	if *dbStatus {
+		if cfg == nil {
+			op := l.StartOperation("db_status")
+			op.Fail("Configuration required", fmt.Errorf("configuration is required to query the database"))
+			return
+		}
+		printDatabaseStatus(cfg, l)
+		return
+	}
+
+	if *reconcile {
+		if cfg == nil {
+			op := l.StartOperation("reconcile_media")
+			op.Fail("Configuration required", fmt.Errorf("configuration is required to reconcile media"))
+			return
+		}
+		reconcileMedia(ctx, cfg, l, *reconcileReupload)
+		return
+	}
+
+	if *resyncAlt {
+		if cfg == nil {
+			op := l.StartOperation("resync_alt_text")
+			op.Fail("Configuration required", fmt.Errorf("configuration is required to resync alt text"))
+			return
+		}
+		resyncAltText(ctx, cfg, l, strings.TrimSpace(*yearFlag), strings.TrimSpace(*sectionFlag))
+		return
+	}
+
+	if *validateFlag {
+		if cfg == nil {
+			op := l.StartOperation("validate_sheet")
+			op.Fail("Configuration required", fmt.Errorf("configuration is required to validate the sheet"))
+			return
+		}
+		validateSheet(ctx, cfg, l, strings.TrimSpace(*yearFlag), strings.TrimSpace(*sectionFlag), strings.TrimSpace(*validateOutputFlag))
+		return
+	}
+
+	if *missingFlag {
+		if cfg == nil {
+			op := l.StartOperation("find_missing_films")
+			op.Fail("Configuration required", fmt.Errorf("configuration is required to find missing films"))
+			return
+		}
+		findMissingFilms(ctx, cfg, l, strings.TrimSpace(*yearFlag), strings.TrimSpace(*sectionFlag), strings.TrimSpace(*missingOutputFlag))
+		return
+	}
+
 	// Collect runtime options (from flags or interactive prompts)
 	runtime := &RuntimeOptions{
-		Menu:    strings.TrimSpace(*menuFlag),
-		Year:    strings.TrimSpace(*yearFlag),
-		NavMenu: strings.TrimSpace(*navMenuFlag),
+		Menu:              strings.TrimSpace(*menuFlag),
+		Year:              strings.TrimSpace(*yearFlag),
+		NavMenu:           strings.TrimSpace(*navMenuFlag),
+		RefreshBackground: *refreshBackgroundFlag,
+		AutoYear:          *autoYearFlag,
+		ChangedOnly:       *changedOnlyFlag,
+		ForceUpdate:       *forceUpdateFlag,
+		Section:           strings.TrimSpace(*sectionFlag),
+		WriteStatus:       *writeStatusFlag,
+		ImageURLsOnly:     *imageURLsOnlyFlag,
+		SkipPublished:     *skipPublishedFlag,
+		Redownload:        *redownloadFlag,
+		Deadline:          *deadlineFlag,
+		RetryFrom:         strings.TrimSpace(*retryFromFlag),
+		DryRun:            *dryRunFlag,
+	}
+
+	templateOutput, err := services.ParseTemplateOutput(*templateOutputFlag)
+	if err != nil {
+		op := l.StartOperation("process_films")
+		op.Fail("Invalid -template-output", err)
+		return
+	}
+	runtime.TemplateOutput = templateOutput
+
+	templateJSON, err := services.ParseTemplateJSON(*templateJSONFlag)
+	if err != nil {
+		op := l.StartOperation("process_films")
+		op.Fail("Invalid -template-json", err)
+		return
+	}
+	runtime.TemplateJSON = templateJSON
+
+	language, err := services.ParseLanguage(*langFlag)
+	if err != nil {
+		op := l.StartOperation("process_films")
+		op.Fail("Invalid -lang", err)
+		return
 	}
+	runtime.Language = language
 
 	// Back-compat: if -nav-menu was provided, use it as the template (menu slug)
 	if runtime.Template == "" && runtime.NavMenu != "" {
@@ -116,34 +299,48 @@ func main() {
 		return
 	}
 
-	if runtime.Year == "" {
-		runtime.Year = promptString("Year filter (enter to skip)")
+	var retryFilms []logger.FailedFilm
+	if runtime.RetryFrom != "" {
+		op := l.StartOperation("read_failed_films")
+		op.WithContext("retry_from", runtime.RetryFrom)
+		retryFilms, err = logger.ReadFailedFilms(runtime.RetryFrom)
+		if err != nil {
+			op.Fail("Failed to read the previous run log", err)
+			return
+		}
+		op.WithContext("failed_film_count", len(retryFilms))
+		if len(retryFilms) == 0 {
+			op.Complete(fmt.Sprintf("No failed films in %s; nothing to retry", runtime.RetryFrom))
+			return
+		}
+		op.Complete(fmt.Sprintf("Retrying %d films that failed in %s", len(retryFilms), runtime.RetryFrom))
 	}
 
-	// Search for and load year-based template configuration
-	var templateConfig *services.TemplateData
-	if runtime.Year != "" {
-		templateConfig = loadYearTemplateConfig(runtime.Year, l)
-		if templateConfig != nil {
-			op := l.StartOperation("load_template_config")
-			op.WithContext("year", runtime.Year)
-			op.WithContext("template_path", fmt.Sprintf("templates/%s.json", runtime.Year))
-			op.Complete(fmt.Sprintf("Loaded template configuration for year %s", runtime.Year))
-		} else {
-			op := l.StartOperation("load_template_config")
-			op.WithContext("year", runtime.Year)
-			op.Warn(&logger.WideEvent{
-				Message: fmt.Sprintf("No template configuration found for year %s", runtime.Year),
-			})
+	// Years to process; a single empty entry means no year filter
+	years := []string{runtime.Year}
+	if runtime.Year == "" && len(retryFilms) > 0 {
+		years = failedFilmYears(retryFilms)
+	} else if runtime.Year == "" && runtime.AutoYear {
+		years = selectEditionYears(ctx, cfg, l)
+		if len(years) == 1 {
+			runtime.Year = years[0]
 		}
+	} else if runtime.Year == "" {
+		runtime.Year = promptString("Year filter (enter to skip)")
+		years = []string{runtime.Year}
 	}
 
-	var metadata = loadMetadata(runtime.Year, l)
+	// Re-downloading every film in the sheet is never intended; require a filter
+	if runtime.Redownload && runtime.Section == "" && len(years) == 1 && years[0] == "" {
+		op := l.StartOperation("process_films")
+		op.Fail("Refusing to re-download all films", fmt.Errorf("-redownload requires -year or -section"))
+		return
+	}
 
 	if runtime.Template == "" {
 		// Fetch WordPress menus and select one as the template (menu slug)
 		op := l.StartOperation("list_wordpress_menus")
-		applicationTmp, err := app.New(cfg)
+		applicationTmp, err := app.New(ctx, cfg)
 		if err != nil {
 			op.Fail("Failed to initialize application for menu listing", err)
 		} else {
@@ -204,7 +401,7 @@ func main() {
 	// No separate nav menu prompt; template now represents the selected WP menu
 
 	op := l.StartOperation("initialize_application")
-	application, err := app.New(cfg)
+	application, err := app.New(ctx, cfg)
 	if err != nil {
 		op.Fail("Failed to initialize application", err)
 		log.Fatalf("Failed to initialize application: %v", err)
@@ -212,60 +409,204 @@ func main() {
 	op.Complete("Application initialized successfully")
 	defer application.Close()
 
+	application.SetRefreshBackground(runtime.RefreshBackground)
+	application.SetImageURLsOnly(runtime.ImageURLsOnly)
+	application.SetChangedOnly(runtime.ChangedOnly)
+	application.SetForceUpdate(runtime.ForceUpdate)
+	application.SetSection(runtime.Section)
+	application.SetWriteStatus(runtime.WriteStatus)
+	application.SetSkipPublished(runtime.SkipPublished)
+	application.SetRedownload(runtime.Redownload)
+	application.SetTemplateOutput(runtime.TemplateOutput)
+	application.SetTemplateJSON(runtime.TemplateJSON)
+	application.SetLanguage(runtime.Language)
+	application.SetDeadline(runtime.Deadline)
+	application.SetDryRun(runtime.DryRun)
+	if len(retryFilms) > 0 {
+		names := make([]string, len(retryFilms))
+		for i, failed := range retryFilms {
+			names[i] = failed.FilmName
+		}
+		application.SetRetryFilms(names)
+	}
+
 	if strings.TrimSpace(runtime.Template) == "" {
 		op := l.StartOperation("process_films")
 		op.Fail("No WordPress menu selected", fmt.Errorf("aborting"))
 		return
 	}
 	
-	op = l.StartOperation("process_films")
-	op.WithContext("template", runtime.Template)
-	op.WithContext("year", runtime.Year)
-	op.Complete(fmt.Sprintf("Starting film processing with template '%s'", runtime.Template))
-	// Template is the WP menu slug
+	for _, year := range years {
+		if application.Interrupted() {
+			op = l.StartOperation("process_films")
+			op.WithContext("year", year)
+			op.Warn(&logger.WideEvent{
+				Message: fmt.Sprintf("Run interrupted; year %s not started", year),
+			})
+			continue
+		}
+		if application.DeadlineExceeded() {
+			op = l.StartOperation("process_films")
+			op.WithContext("deadline", runtime.Deadline.String())
+			op.WithContext("year", year)
+			op.Warn(&logger.WideEvent{
+				Message: fmt.Sprintf("Run deadline of %s exceeded; year %s not started", runtime.Deadline, year),
+			})
+			continue
+		}
+		templateConfig, metadata := loadYearResources(year, l)
+		application.VerifyTemplateMenu(year, templateConfig)
 
-	if err := application.ProcessFilms(runtime.Year, templateConfig, metadata); err != nil {
-		op := l.StartOperation("process_films")
+		op = l.StartOperation("process_films")
 		op.WithContext("template", runtime.Template)
-		op.WithContext("year", runtime.Year)
-		op.Fail("Failed to process films", err)
-		log.Fatalf("Failed to process films: %v", err)
+		op.WithContext("year", year)
+		op.Complete(fmt.Sprintf("Starting film processing with template '%s'", runtime.Template))
+		// Template is the WP menu slug
+
+		if err := application.ProcessFilms(year, templateConfig, metadata); err != nil {
+			op := l.StartOperation("process_films")
+			op.WithContext("template", runtime.Template)
+			op.WithContext("year", year)
+			op.Fail("Failed to process films", err)
+			log.Fatalf("Failed to process films: %v", err)
+		}
 	}
 
 	op = l.StartOperation("process_films")
 	op.WithContext("template", runtime.Template)
-	op.WithContext("year", runtime.Year)
+	op.WithContext("years", years)
+	if application.Interrupted() {
+		op.Warn(&logger.WideEvent{
+			Message: "Application interrupted; unfinished films will be processed on the next run",
+		})
+		return
+	}
 	op.Complete("Application completed successfully")
 }
 
+// loadYearResources loads the template configuration and metadata for a year
+func loadYearResources(year string, l *logger.Logger) (*services.TemplateData, *models.Metadata) {
+	// Search for and load year-based template configuration
+	var templateConfig *services.TemplateData
+	if year != "" {
+		templateConfig = loadYearTemplateConfig(year, l)
+		if templateConfig != nil {
+			op := l.StartOperation("load_template_config")
+			op.WithContext("year", year)
+			op.WithContext("template_path", fmt.Sprintf("templates/%s.json", year))
+			op.Complete(fmt.Sprintf("Loaded template configuration for year %s", year))
+		} else {
+			op := l.StartOperation("load_template_config")
+			op.WithContext("year", year)
+			op.Warn(&logger.WideEvent{
+				Message: fmt.Sprintf("No template configuration found for year %s", year),
+			})
+		}
+	}
+
+	return templateConfig, loadMetadata(year, l)
+}
+
+// failedFilmYears returns the distinct years of the films to retry in order. A film
+// without a year means no year filter, which covers every other year as well
+func failedFilmYears(films []logger.FailedFilm) []string {
+	var years []string
+	for _, failed := range films {
+		if failed.FilmYear == "" {
+			return []string{""}
+		}
+		if !slices.Contains(years, failed.FilmYear) {
+			years = append(years, failed.FilmYear)
+		}
+	}
+	sort.Strings(years)
+	return years
+}
+
+// selectEditionYears derives the years present in the sheet's EDICIÓN column.
+// A single year is used directly; with several the user picks one or processes all
+func selectEditionYears(ctx context.Context, cfg *config.Config, l *logger.Logger) []string {
+	op := l.StartOperation("detect_edition_years")
+	application, err := app.New(ctx, cfg)
+	if err != nil {
+		op.Fail("Failed to initialize application for year detection", err)
+		return []string{""}
+	}
+	defer application.Close()
+
+	years, err := application.EditionYears()
+	if err != nil {
+		op.Fail("Failed to read edition years from the sheet", err)
+		return []string{""}
+	}
+	op.WithContext("years", years)
+
+	if len(years) == 0 {
+		op.Warn(&logger.WideEvent{
+			Message: "No year found in EDICIÓN values; processing without a year filter",
+		})
+		return []string{""}
+	}
+	op.Complete(fmt.Sprintf("Detected %d edition year(s)", len(years)))
+
+	if len(years) == 1 {
+		fmt.Printf("Detected edition year: %s\n", years[0])
+		return years
+	}
+
+	fmt.Println("Detected edition years:")
+	for idx, year := range years {
+		fmt.Printf("  %d) %s\n", idx+1, year)
+	}
+	choice := promptString("Enter choice number or year (enter to process all)")
+	if choice == "" {
+		return years
+	}
+	var num int
+	if _, err := fmt.Sscanf(choice, "%d", &num); err == nil && num >= 1 && num <= len(years) {
+		return []string{years[num-1]}
+	}
+	for _, year := range years {
+		if year == choice {
+			return []string{year}
+		}
+	}
+	fmt.Printf("Unknown choice '%s'. Processing all detected years.\n", choice)
+	return years
+}
+
 func promptMenuSelection() string {
 	fmt.Println("Select a menu:")
 	fmt.Println("  1) Configuration")
 	fmt.Println("  2) Process movies")
 	fmt.Print("Enter choice [1-2] or name: ")
-	var input string
-	if _, err := fmt.Scanln(&input); err != nil {
-		// handle empty input (e.g., just Enter)
-		if err.Error() == "unexpected newline" {
-			return "process"
-		}
-		log.Printf("Input error: %v", err)
+	input := strings.ToLower(readLine())
+	// handle empty input (e.g., just Enter)
+	if input == "" {
 		return "process"
 	}
-	return strings.ToLower(strings.TrimSpace(input))
+	return input
 }
 
 func promptString(label string) string {
 	fmt.Printf("%s: ", label)
-	var input string
-	if _, err := fmt.Scanln(&input); err != nil {
-		if err.Error() == "unexpected newline" {
-			return ""
+	return readLine()
+}
+
+// stdinScanner is shared by every prompt: a scanner reads ahead, so a second one would
+// miss input the first has already buffered
+var stdinScanner = bufio.NewScanner(os.Stdin)
+
+// readLine reads a whole line from stdin, spaces included, and trims it. It returns ""
+// for an empty line and at the end of input
+func readLine() string {
+	if !stdinScanner.Scan() {
+		if err := stdinScanner.Err(); err != nil {
+			log.Printf("Input error: %v", err)
 		}
-		log.Printf("Input error: %v", err)
 		return ""
 	}
-	return strings.TrimSpace(input)
+	return strings.TrimSpace(stdinScanner.Text())
 }
 
 func runConfigurationMenu() {
@@ -303,6 +644,291 @@ func runConfigurationMenu() {
 	fmt.Println("Exiting configuration menu.")
 }
 
+// printSheetFields writes every sheet column converted into film data to w, with its
+// FilmData field, whether validation requires it and the header it is read from
+func printSheetFields(w io.Writer) {
+	fmt.Fprintln(w, "Recognized sheet columns (headers in the TODO tab must match exactly)")
+	fmt.Fprintln(w, "Each field has a single header; no alternative spellings are recognized.")
+	fmt.Fprintln(w, "")
+	for _, field := range services.SheetFields {
+		required := "optional"
+		if field.Required {
+			required = "required"
+		}
+		fmt.Fprintf(w, "  %-22s %-8s %s\n", field.Name, required, field.Header)
+	}
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "The Drive image folder link is read from the image_folder_columns setting.")
+	fmt.Fprintln(w, "Any other column is kept as an additional field.")
+}
+
+// printDatabaseStatus prints a summary of the Turso metadata table
+func printDatabaseStatus(cfg *config.Config, l *logger.Logger) {
+	op := l.StartOperation("db_status")
+	tursoService, err := services.NewTursoService(cfg.TursoConfig)
+	if err != nil {
+		op.Fail("Failed to connect to Turso database", err)
+		return
+	}
+	defer tursoService.Close()
+
+	status, err := tursoService.Status()
+	if err != nil {
+		op.Fail("Failed to read database status", err)
+		return
+	}
+
+	fmt.Println("Turso database status")
+	fmt.Printf("  Total rows:     %d\n", status.TotalRows)
+	fmt.Printf("  Distinct films: %d\n", status.DistinctFilms)
+	if status.OldestUpdateAt != "" {
+		fmt.Printf("  Oldest update:  %s\n", status.OldestUpdateAt)
+		fmt.Printf("  Newest update:  %s\n", status.NewestUpdateAt)
+	}
+	if len(status.CountsByType) > 0 {
+		types := make([]string, 0, len(status.CountsByType))
+		for metadataType := range status.CountsByType {
+			types = append(types, metadataType)
+		}
+		sort.Strings(types)
+
+		fmt.Println("  Rows per type:")
+		for _, metadataType := range types {
+			fmt.Printf("    %-20s %d\n", metadataType, status.CountsByType[metadataType])
+		}
+	}
+
+	op.WithContext("total_rows", status.TotalRows)
+	op.WithContext("distinct_films", status.DistinctFilms)
+	op.WithContext("counts_by_type", status.CountsByType)
+	op.Complete("Database status retrieved successfully")
+}
+
+// reconcileMedia prunes dangling WordPress media IDs from Turso and prints a per-film summary
+func reconcileMedia(ctx context.Context, cfg *config.Config, l *logger.Logger, reupload bool) {
+	op := l.StartOperation("reconcile_media")
+	op.WithContext("reupload", reupload)
+	application, err := app.New(ctx, cfg)
+	if err != nil {
+		op.Fail("Failed to initialize application", err)
+		return
+	}
+	defer application.Close()
+
+	results, err := application.ReconcileMedia(reupload)
+	danglingTotal := 0
+	reuploadedTotal := 0
+	for _, result := range results {
+		danglingTotal += len(result.Dangling)
+		reuploadedTotal += result.Reuploaded
+		if len(result.Dangling) == 0 {
+			continue
+		}
+		fmt.Printf("%s: %d of %d media missing in WordPress", result.FilmID, len(result.Dangling), result.Checked)
+		if reupload {
+			fmt.Printf(", %d re-uploaded", result.Reuploaded)
+			if len(result.Missing) > 0 {
+				fmt.Printf(", no local file for: %s", strings.Join(result.Missing, ", "))
+			}
+		}
+		fmt.Println()
+	}
+	fmt.Printf("Reconciled %d films: %d dangling media IDs removed, %d re-uploaded\n", len(results), danglingTotal, reuploadedTotal)
+
+	op.WithContext("film_count", len(results))
+	op.WithContext("dangling_count", danglingTotal)
+	op.WithContext("reuploaded_count", reuploadedTotal)
+	if err != nil {
+		op.Fail("Media reconciliation stopped early", err)
+		return
+	}
+	op.Complete("Media reconciliation completed")
+}
+
+// resyncAltText updates the alt text of the selected films' stored media and prints a summary
+func resyncAltText(ctx context.Context, cfg *config.Config, l *logger.Logger, year, section string) {
+	op := l.StartOperation("resync_alt_text")
+	op.WithContext("year", year)
+	op.WithContext("section", section)
+	application, err := app.New(ctx, cfg)
+	if err != nil {
+		op.Fail("Failed to initialize application", err)
+		return
+	}
+	defer application.Close()
+
+	application.SetSection(section)
+	filmCount, mediaCount, err := application.ResyncAltText(year)
+	fmt.Printf("Updated alt text of %d media items across %d films\n", mediaCount, filmCount)
+
+	op.WithContext("film_count", filmCount)
+	op.WithContext("media_count", mediaCount)
+	if err != nil {
+		op.Fail("Alt text resync stopped early", err)
+		return
+	}
+	op.Complete("Alt text resync completed")
+}
+
+// validateSheet writes a data-quality report for the selected sheet rows and prints a summary
+func validateSheet(ctx context.Context, cfg *config.Config, l *logger.Logger, year, section, outputPath string) {
+	op := l.StartOperation("validate_sheet")
+	op.WithContext("year", year)
+	op.WithContext("section", section)
+	op.WithContext("report_path", outputPath)
+	application, err := app.New(ctx, cfg)
+	if err != nil {
+		op.Fail("Failed to initialize application", err)
+		return
+	}
+	defer application.Close()
+
+	application.SetSection(section)
+	report, err := application.ValidateFilms(year)
+	if err != nil {
+		op.Fail("Failed to validate the sheet", err)
+		return
+	}
+	if err := report.Write(outputPath); err != nil {
+		op.Fail("Failed to write validation report", err)
+		return
+	}
+
+	for _, film := range report.Films {
+		fmt.Printf("%s:\n", film.Film)
+		for _, issue := range film.Issues {
+			fmt.Printf("  - %s\n", issue)
+		}
+	}
+	fmt.Printf("Checked %d rows: %d films with issues. Report written to %s\n", report.CheckedRows, report.FilmsWithIssues, outputPath)
+
+	op.WithContext("checked_rows", report.CheckedRows)
+	op.WithContext("films_with_issues", report.FilmsWithIssues)
+	op.Complete("Sheet validation completed")
+}
+
+// findMissingFilms writes a report of the selected sheet rows with no WordPress project
+func findMissingFilms(ctx context.Context, cfg *config.Config, l *logger.Logger, year, section, outputPath string) {
+	op := l.StartOperation("find_missing_films")
+	op.WithContext("year", year)
+	op.WithContext("section", section)
+	op.WithContext("report_path", outputPath)
+	application, err := app.New(ctx, cfg)
+	if err != nil {
+		op.Fail("Failed to initialize application", err)
+		return
+	}
+	defer application.Close()
+
+	application.SetSection(section)
+	report, err := application.FindMissingFilms(year)
+	if err != nil {
+		op.Fail("Failed to find missing films", err)
+		return
+	}
+	if err := report.Write(outputPath); err != nil {
+		op.Fail("Failed to write missing films report", err)
+		return
+	}
+
+	for _, film := range report.Films {
+		fmt.Printf("row %d: %s (expected slug %s)\n", film.SheetRow, film.Film, film.Slug)
+	}
+	fmt.Printf("Checked %d rows: %d films missing from WordPress. Report written to %s\n", report.CheckedRows, report.MissingCount, outputPath)
+
+	op.WithContext("checked_rows", report.CheckedRows)
+	op.WithContext("missing_count", report.MissingCount)
+	op.Complete("Missing films report completed")
+}
+
+// dumpTemplateExample writes an example template file populated with the default
+// values, plus a companion file documenting each field, into the given directory
+func dumpTemplateExample(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s directory: %v", dir, err)
+	}
+
+	exampleData, err := json.MarshalIndent(services.DefaultTemplateData(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal template example: %v", err)
+	}
+	examplePath := filepath.Join(dir, "template.example.json")
+	if err := os.WriteFile(examplePath, exampleData, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", examplePath, err)
+	}
+
+	docsData, err := json.MarshalIndent(services.TemplateDataFieldDocs(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal template docs: %v", err)
+	}
+	docsPath := filepath.Join(dir, "template.docs.json")
+	if err := os.WriteFile(docsPath, docsData, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", docsPath, err)
+	}
+
+	fmt.Printf("Wrote template example to %s and field documentation to %s\n", examplePath, docsPath)
+	fmt.Println("Copy the example to templates/<year>.json and edit it for your edition")
+	return nil
+}
+
+// diffTemplateYears returns the two years of -diff-templates: the flag value and the one
+// positional argument after it, as in -diff-templates 2024 2025, or both years in the flag
+// value, as in -diff-templates 2024,2025
+func diffTemplateYears(value string, args []string) (string, string, error) {
+	const usage = "expected -diff-templates <yearA> <yearB> after any other flag, or -diff-templates <yearA>,<yearB>"
+	years := strings.Split(value, ",")
+	if len(years) == 1 {
+		if len(args) != 1 {
+			return "", "", fmt.Errorf("%s", usage)
+		}
+		years = append(years, args[0])
+	} else if len(years) != 2 || len(args) != 0 {
+		return "", "", fmt.Errorf("%s", usage)
+	}
+	fromYear, toYear := strings.TrimSpace(years[0]), strings.TrimSpace(years[1])
+	if fromYear == "" || toYear == "" {
+		return "", "", fmt.Errorf("%s", usage)
+	}
+	return fromYear, toYear, nil
+}
+
+// diffTemplateFiles prints the fields that differ between the template files of two years
+func diffTemplateFiles(dir, fromYear, toYear string) error {
+	templates := make([]*services.TemplateData, 2)
+	for i, year := range []string{fromYear, toYear} {
+		templatePath := filepath.Join(dir, year+".json")
+		data, err := os.ReadFile(templatePath)
+		if err != nil {
+			return fmt.Errorf("failed to read template file %s: %v", templatePath, err)
+		}
+		if err := json.Unmarshal(data, &templates[i]); err != nil {
+			return fmt.Errorf("failed to parse template file %s: %v", templatePath, err)
+		}
+	}
+
+	changes, err := services.DiffTemplateData(templates[0], templates[1])
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		fmt.Printf("Templates %s and %s are identical\n", fromYear, toYear)
+		return nil
+	}
+
+	fmt.Printf("Template changes from %s to %s:\n", fromYear, toYear)
+	for _, change := range changes {
+		from, to := change.From, change.To
+		if from == "" {
+			from = "(unset)"
+		}
+		if to == "" {
+			to = "(unset)"
+		}
+		fmt.Printf("  %s: %s -> %s\n", change.Path, from, to)
+	}
+	return nil
+}
+
 // loadYearTemplateConfig searches for and loads a JSON template configuration file
 // based on the provided year from the templates folder
 func loadYearTemplateConfig(year string, l *logger.Logger) *services.TemplateData {
@@ -338,6 +964,12 @@ func loadYearTemplateConfig(year string, l *logger.Logger) *services.TemplateDat
 		op.Fail(fmt.Sprintf("Failed to parse template file %s", templatePath), err)
 		return nil
 	}
+	if templateConfig != nil {
+		if err := templateConfig.Validate(); err != nil {
+			op.Fail(fmt.Sprintf("Invalid template file %s", templatePath), err)
+			return nil
+		}
+	}
 
 	op.Complete(fmt.Sprintf("Successfully loaded template configuration from %s", templatePath))
 	return templateConfig
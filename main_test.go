@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"excentrico-tools-go/internal/logger"
+	"excentrico-tools-go/internal/services"
+)
+
+func TestDiffTemplateYears(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		args     []string
+		from, to string
+		wantErr  bool
+	}{
+		{name: "positional second year", value: "2024", args: []string{"2025"}, from: "2024", to: "2025"},
+		{name: "comma-separated years", value: "2024,2025", from: "2024", to: "2025"},
+		{name: "spaces around years", value: " 2024 , 2025 ", from: "2024", to: "2025"},
+		{name: "missing second year", value: "2024", wantErr: true},
+		{name: "flag after the years", value: "2024", args: []string{"2025", "-year"}, wantErr: true},
+		{name: "both forms", value: "2024,2025", args: []string{"2026"}, wantErr: true},
+		{name: "empty second year", value: "2024,", wantErr: true},
+		{name: "empty first year", value: ",2025", wantErr: true},
+		{name: "three years", value: "2023,2024,2025", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := diffTemplateYears(tt.value, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("diffTemplateYears(%q, %q) error %v, want error: %v", tt.value, tt.args, err, tt.wantErr)
+			}
+			if from != tt.from || to != tt.to {
+				t.Errorf("diffTemplateYears(%q, %q) = %q, %q, want %q, %q", tt.value, tt.args, from, to, tt.from, tt.to)
+			}
+		})
+	}
+}
+
+func TestPrintSheetFields(t *testing.T) {
+	var out bytes.Buffer
+	printSheetFields(&out)
+	if !strings.HasPrefix(out.String(), "Recognized sheet columns (headers in the TODO tab must match exactly)\n") {
+		t.Errorf("listing does not start with its heading:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "no alternative spellings are recognized") {
+		t.Error("listing does not say that header aliases are not recognized")
+	}
+
+	// Field lines are "  <name> <required|optional> <header>"
+	linePattern := regexp.MustCompile(`^  (\S+) +(required|optional) (.+)$`)
+	type listedField struct {
+		required string
+		header   string
+		count    int
+	}
+	listed := make(map[string]*listedField)
+	for _, line := range strings.Split(out.String(), "\n") {
+		match := linePattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		if listed[match[1]] == nil {
+			listed[match[1]] = &listedField{required: match[2], header: match[3]}
+		}
+		listed[match[1]].count++
+	}
+
+	tests := []struct {
+		name     string
+		required string
+		header   string
+	}{
+		{name: "titulo_original", required: "required", header: "TÍTULO ORIGINAL"},
+		{name: "edicion", required: "required", header: "EDICIÓN"},
+		{name: "sinopsis_extendida", required: "required", header: "Sinopsis extendida (máximo 70 palabras)"},
+		{name: "sinopsis_compacta", required: "optional", header: "Sinopsis compacta  (máximo 10 palabras)"},
+		{name: "tipo", required: "optional", header: "TIPO"},
+		{name: "published_status", required: "optional", header: "Published Status"},
+		{name: "multi_dir", required: "optional", header: "Multi Dir"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := listed[tt.name]
+			if got == nil {
+				t.Fatalf("%s not listed:\n%s", tt.name, out.String())
+			}
+			if got.required != tt.required || got.header != tt.header {
+				t.Errorf("%s listed as %s %q, want %s %q", tt.name, got.required, got.header, tt.required, tt.header)
+			}
+		})
+	}
+
+	// Every string field of FilmData is read from a sheet column and must be listed once,
+	// flagged as validation treats it
+	emptyFilmIssues := (&services.FilmData{}).Validate()
+	filmData := reflect.TypeOf(services.FilmData{})
+	for i := 0; i < filmData.NumField(); i++ {
+		field := filmData.Field(i)
+		if field.Type.Kind() != reflect.String {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		got := listed[name]
+		if got == nil || got.count != 1 {
+			t.Errorf("%s is not listed exactly once:\n%s", name, out.String())
+			continue
+		}
+		if required := got.required == "required"; required != slices.Contains(emptyFilmIssues, "missing "+got.header) {
+			t.Errorf("%s listed as %s, but validation disagrees", name, got.required)
+		}
+	}
+}
+
+func TestFailedFilmYears(t *testing.T) {
+	film := func(year string) logger.FailedFilm {
+		return logger.FailedFilm{FilmID: "film-" + year, FilmName: "Film " + year, FilmYear: year}
+	}
+	tests := []struct {
+		name  string
+		films []logger.FailedFilm
+		want  []string
+	}{
+		{name: "one year", films: []logger.FailedFilm{film("2025"), film("2025")}, want: []string{"2025"}},
+		{name: "sorted distinct years", films: []logger.FailedFilm{film("2025"), film("2023"), film("2025"), film("2024")}, want: []string{"2023", "2024", "2025"}},
+		{name: "film without a year", films: []logger.FailedFilm{film("2025"), film(""), film("2024")}, want: []string{""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := failedFilmYears(tt.films); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("failedFilmYears() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}